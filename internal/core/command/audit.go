@@ -0,0 +1,409 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package command
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/command/interfaces"
+	"github.com/edgexfoundry/edgex-go/internal/core/command/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// auditTrail persists the audit records of the commands issued to the devices and deletes them once their retention
+// expires.
+type auditTrail struct {
+	dbClient          interfaces.DBClient
+	retention         time.Duration
+	pruneInterval     time.Duration
+	maxResponseLength int
+	callerHeaders     []string
+	verifier          *jwtVerifier
+	loggingClient     logger.LoggingClient
+}
+
+func newAuditTrail(
+	config AuditInfo,
+	dbClient interfaces.DBClient,
+	loggingClient logger.LoggingClient) (*auditTrail, error) {
+	retention, err := time.ParseDuration(config.Retention)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Audit.Retention '%s': %v", config.Retention, err)
+	}
+	pruneInterval, err := time.ParseDuration(config.PruneInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Audit.PruneInterval '%s': %v", config.PruneInterval, err)
+	}
+	if pruneInterval <= 0 {
+		return nil, fmt.Errorf("invalid Audit.PruneInterval '%s', a positive duration is required", config.PruneInterval)
+	}
+	if config.MaxResponseLength < 0 {
+		return nil, fmt.Errorf("invalid Audit.MaxResponseLength %d", config.MaxResponseLength)
+	}
+	verifier, err := newJWTVerifier(config.JWTSecret, config.JWTPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auditTrail{
+		dbClient:          dbClient,
+		retention:         retention,
+		pruneInterval:     pruneInterval,
+		maxResponseLength: config.MaxResponseLength,
+		callerHeaders:     config.CallerHeaders,
+		verifier:          verifier,
+		loggingClient:     loggingClient,
+	}, nil
+}
+
+// start deletes the expired records at every prune interval until the context is cancelled.
+func (at *auditTrail) start(wg *sync.WaitGroup, ctx context.Context) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(at.pruneInterval)
+		defer ticker.Stop()
+		for {
+			at.prune()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (at *auditTrail) prune() {
+	count, err := at.dbClient.DeleteCommandAuditsOld(at.retention.Nanoseconds() / int64(time.Millisecond))
+	if err != nil {
+		at.loggingClient.Error(fmt.Sprintf("Unable to delete the expired command audit records: %v", err))
+		return
+	}
+	if count > 0 {
+		at.loggingClient.Info(fmt.Sprintf("Deleted %d command audit records older than %s", count, at.retention))
+	}
+}
+
+// commandAudit is carried by the context of a command request down to where the outcome of the command is known.
+type commandAudit struct {
+	trail *auditTrail
+	audit models.Audit
+}
+
+type auditContextKey struct{}
+
+// context returns the context of a command request, carrying what its audit records are made of: the caller, the
+// correlation ID, the body and the query parameters. The commands are not recorded when the trail is disabled.
+func (at *auditTrail) context(r *http.Request, body string) context.Context {
+	ctx := r.Context()
+	if at == nil {
+		return ctx
+	}
+	caller, source := callerIdentity(r, at.callerHeaders, at.verifier)
+	return context.WithValue(ctx, auditContextKey{}, commandAudit{
+		trail: at,
+		audit: models.Audit{
+			Created:       db.MakeTimestamp(),
+			Method:        r.Method,
+			Body:          body,
+			QueryParams:   r.URL.RawQuery,
+			Caller:        caller,
+			CallerSource:  source,
+			CorrelationId: correlation.FromContext(ctx),
+		},
+	})
+}
+
+// recordAudit persists the audit record of a command issued with the context. Failing to persist it is only logged,
+// the command's outcome being unaffected by the trail.
+func recordAudit(ctx context.Context, device string, command string, status int, response string) {
+	ca, ok := ctx.Value(auditContextKey{}).(commandAudit)
+	if !ok {
+		return
+	}
+	ca.record(device, command, status, response)
+}
+
+func (ca commandAudit) record(device string, command string, status int, response string) {
+	at := ca.trail
+	audit := ca.audit
+	audit.Device = device
+	audit.Command = command
+	audit.StatusCode = status
+	audit.Latency = db.MakeTimestamp() - audit.Created
+	audit.Response, audit.Truncated = truncateResponse(response, at.maxResponseLength)
+
+	if _, err := at.dbClient.AddCommandAudit(audit); err != nil {
+		at.loggingClient.Error(fmt.Sprintf("Unable to record the audit of command %s of device %s: %v",
+			command, device, err))
+	}
+}
+
+// auditedExecutor records the outcome of the command it executes.
+type auditedExecutor struct {
+	Executor
+	commandAudit
+	device  string
+	command string
+}
+
+func (ae auditedExecutor) Execute() (string, int, error) {
+	body, status, err := ae.Executor.Execute()
+	if err != nil {
+		ae.record(ae.device, ae.command, http.StatusInternalServerError, err.Error())
+	} else {
+		ae.record(ae.device, ae.command, status, body)
+	}
+	return body, status, err
+}
+
+// auditExecutor wraps the executor of a command so that its outcome is recorded, when issued with an audit context.
+func auditExecutor(ctx context.Context, device contract.Device, command contract.Command, ex Executor) Executor {
+	ca, ok := ctx.Value(auditContextKey{}).(commandAudit)
+	if !ok {
+		return ex
+	}
+	return auditedExecutor{Executor: ex, commandAudit: ca, device: device.Name, command: command.Name}
+}
+
+// auditName returns the name of a device or command, or what it was requested by when it could not be resolved.
+func auditName(name string, requested string) string {
+	if name == "" {
+		return requested
+	}
+	return name
+}
+
+// callerIdentity returns the identity of the caller of a command and where it was read from: the first of the headers
+// present, as set by the API gateway, or else the subject, or issuer, of the bearer JWT. The headers are not verified
+// here, the identity they hold can only be trusted when core-command is reachable through the gateway alone. The
+// signature of the JWT is verified when a verifier is given, the JWTs failing the verification being ignored.
+func callerIdentity(r *http.Request, headers []string, verifier *jwtVerifier) (string, string) {
+	for _, header := range headers {
+		if v := r.Header.Get(header); v != "" {
+			return v, models.CallerSourceHeader
+		}
+	}
+
+	const bearer = "bearer "
+	auth := r.Header.Get("Authorization")
+	if len(auth) <= len(bearer) || strings.ToLower(auth[:len(bearer)]) != bearer {
+		return "", ""
+	}
+	parts := strings.Split(strings.TrimSpace(auth[len(bearer):]), ".")
+	if len(parts) != 3 {
+		return "", ""
+	}
+	payload, err := decodeJWTSegment(parts[1])
+	if err != nil {
+		return "", ""
+	}
+	var claims struct {
+		Subject string `json:"sub"`
+		Issuer  string `json:"iss"`
+		Expires int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", ""
+	}
+	source := models.CallerSourceToken
+	if verifier != nil {
+		if !verifier.verify(parts) || (claims.Expires != 0 && time.Now().Unix() >= claims.Expires) {
+			return "", ""
+		}
+		source = models.CallerSourceVerifiedToken
+	}
+	if claims.Subject != "" {
+		return claims.Subject, source
+	}
+	if claims.Issuer != "" {
+		return claims.Issuer, source
+	}
+	return "", ""
+}
+
+// jwtHashes are the hash functions of the JWT algorithms, by the suffix of their name
+var jwtHashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+// jwtVerifier verifies the signature of the JWTs signed with HMAC (HS*) or RSA PKCS #1 v1.5 (RS*).
+type jwtVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+}
+
+// newJWTVerifier returns a verifier of the JWTs signed with the secret or the private key of the public key read from
+// the PEM file, or nil when neither is configured.
+func newJWTVerifier(secret string, publicKeyFile string) (*jwtVerifier, error) {
+	if secret == "" && publicKeyFile == "" {
+		return nil, nil
+	}
+	v := &jwtVerifier{secret: []byte(secret)}
+	if publicKeyFile == "" {
+		return v, nil
+	}
+
+	data, err := ioutil.ReadFile(publicKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Audit.JWTPublicKey '%s': %v", publicKeyFile, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid Audit.JWTPublicKey '%s': no PEM data found", publicKeyFile)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Audit.JWTPublicKey '%s': %v", publicKeyFile, err)
+	}
+	var ok bool
+	if v.publicKey, ok = key.(*rsa.PublicKey); !ok {
+		return nil, fmt.Errorf("invalid Audit.JWTPublicKey '%s': an RSA public key is required", publicKeyFile)
+	}
+	return v, nil
+}
+
+// verify tells whether the signature of a JWT, split into its header, payload and signature, is valid for the
+// algorithm named in its header.
+func (v *jwtVerifier) verify(parts []string) bool {
+	data, err := decodeJWTSegment(parts[0])
+	if err != nil {
+		return false
+	}
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil || len(header.Algorithm) != 5 {
+		return false
+	}
+	hash, ok := jwtHashes[header.Algorithm[2:]]
+	if !ok {
+		return false
+	}
+	signature, err := decodeJWTSegment(parts[2])
+	if err != nil {
+		return false
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+
+	switch header.Algorithm[:2] {
+	case "HS":
+		if len(v.secret) == 0 {
+			return false
+		}
+		mac := hmac.New(hash.New, v.secret)
+		mac.Write(signed)
+		return hmac.Equal(signature, mac.Sum(nil))
+	case "RS":
+		if v.publicKey == nil {
+			return false
+		}
+		digest := hash.New()
+		digest.Write(signed)
+		return rsa.VerifyPKCS1v15(v.publicKey, hash, digest.Sum(nil), signature) == nil
+	}
+	return false
+}
+
+// decodeJWTSegment decodes a base64url segment of a JWT, with or without padding.
+func decodeJWTSegment(segment string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+}
+
+// truncateResponse returns the beginning of a response, at most max bytes without splitting a character, and whether
+// it was truncated.
+func truncateResponse(response string, max int) (string, bool) {
+	if len(response) <= max {
+		return response, false
+	}
+	for max > 0 && !utf8.RuneStart(response[max]) {
+		max--
+	}
+	return response[:max], true
+}
+
+// restGetCommandAudits returns the most recent audit records, filtered by the device, the time range of their creation
+// and the response status given as query parameters.
+func restGetCommandAudits(
+	w http.ResponseWriter,
+	r *http.Request,
+	loggingClient logger.LoggingClient,
+	dbClient interfaces.DBClient) {
+	query := r.URL.Query()
+	params := map[string]int64{
+		START:  0,
+		END:    math.MaxInt64,
+		STATUS: 0,
+		LIMIT:  int64(Configuration.Service.MaxResultCount),
+	}
+	for name := range params {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			loggingClient.Error(err.Error())
+			http.Error(w, fmt.Sprintf("Invalid %s '%s'", name, v), http.StatusBadRequest)
+			return
+		}
+		params[name] = n
+	}
+	limit := int(params[LIMIT])
+	if limit < 1 {
+		http.Error(w, fmt.Sprintf("Invalid %s %d", LIMIT, limit), http.StatusBadRequest)
+		return
+	}
+	if limit > Configuration.Service.MaxResultCount {
+		http.Error(w, fmt.Sprintf("Exceeded max limit %d", Configuration.Service.MaxResultCount),
+			http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	audits, err := dbClient.GetCommandAudits(query.Get(DEVICE), params[START], params[END], int(params[STATUS]), limit)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+	json.NewEncoder(w).Encode(&audits)
+}
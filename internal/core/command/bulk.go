@@ -0,0 +1,155 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/command/interfaces"
+)
+
+// BulkResult is the outcome of a command issued to one device of a bulk command. The body of the device service's
+// response is in Body when the command succeeded and in Error otherwise.
+type BulkResult struct {
+	Device     string `json:"device"`
+	StatusCode int    `json:"statusCode"`
+	Body       string `json:"body,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// BulkResponse aggregates the outcomes of a command issued to a set of devices, in the order of the devices.
+type BulkResponse struct {
+	Command   string       `json:"command"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Locked    int          `json:"locked"`
+	Results   []BulkResult `json:"results"`
+}
+
+// devicesBySelector returns the devices with a label, of a profile or of a service, the profile and service being
+// given by name.
+func devicesBySelector(
+	selector string,
+	value string,
+	ctx context.Context,
+	deviceClient metadata.DeviceClient) ([]contract.Device, int, error) {
+	var devices []contract.Device
+	var err error
+	switch selector {
+	case LABEL:
+		devices, err = deviceClient.DevicesByLabel(value, ctx)
+	case PROFILE:
+		devices, err = deviceClient.DevicesForProfileByName(value, ctx)
+	case SERVICE:
+		devices, err = deviceClient.DevicesForServiceByName(value, ctx)
+	default:
+		return nil, http.StatusBadRequest, fmt.Errorf("unknown device selector '%s'", selector)
+	}
+
+	if err != nil {
+		chk, ok := err.(types.ErrServiceClient)
+		if ok {
+			return nil, chk.StatusCode, err
+		} else {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+	return devices, http.StatusOK, nil
+}
+
+// bulkCommand issues a command to each device, at most concurrency at once. Locked devices and devices without the
+// command are reported without contacting their device service.
+func bulkCommand(
+	devices []contract.Device,
+	cn string,
+	body string,
+	queryParams string,
+	isPutCommand bool,
+	concurrency int,
+	ctx context.Context,
+	loggingClient logger.LoggingClient,
+	dbClient interfaces.DBClient,
+	vdc valueDescriptorReader) BulkResponse {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	response := BulkResponse{Command: cn, Results: make([]BulkResult, len(devices))}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, d := range devices {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, d contract.Device) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			response.Results[i] = bulkDeviceCommand(d, cn, body, queryParams, isPutCommand, ctx, loggingClient, dbClient, vdc)
+		}(i, d)
+	}
+	wg.Wait()
+
+	for _, result := range response.Results {
+		switch {
+		case result.StatusCode >= http.StatusOK && result.StatusCode < http.StatusMultipleChoices:
+			response.Succeeded++
+		case result.StatusCode == http.StatusLocked:
+			response.Locked++
+		default:
+			response.Failed++
+		}
+	}
+	loggingClient.Info(fmt.Sprintf("Bulk command %s issued to %d devices: %d succeeded, %d failed, %d locked",
+		cn, len(devices), response.Succeeded, response.Failed, response.Locked))
+	return response
+}
+
+func bulkDeviceCommand(
+	d contract.Device,
+	cn string,
+	body string,
+	queryParams string,
+	isPutCommand bool,
+	ctx context.Context,
+	loggingClient logger.LoggingClient,
+	dbClient interfaces.DBClient,
+	vdc valueDescriptorReader) BulkResult {
+	result := BulkResult{Device: d.Name}
+	command, status, err := commandForDevice(d, cn, loggingClient, dbClient)
+	if err != nil {
+		recordAudit(ctx, d.Name, cn, status, err.Error())
+		result.StatusCode = status
+		result.Error = err.Error()
+		return result
+	}
+
+	responseBody, status := commandByDevice(d, command, body, queryParams, isPutCommand, ctx, loggingClient, vdc)
+	result.StatusCode = status
+	if status >= http.StatusOK && status < http.StatusMultipleChoices {
+		result.Body = responseBody
+	} else {
+		result.Error = responseBody
+	}
+	return result
+}
@@ -0,0 +1,190 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package command
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	mdMocks "github.com/edgexfoundry/go-mod-core-contracts/clients/metadata/mocks"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/command/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+const bulkCommandName = "setpoint"
+
+// bulkDevice returns a device whose device service is the test server
+func bulkDevice(t *testing.T, name string, serviceURL string, adminState contract.AdminState) contract.Device {
+	u, err := url.Parse(serviceURL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	port, _ := strconv.Atoi(u.Port())
+	return contract.Device{
+		Id:         name,
+		Name:       name,
+		AdminState: adminState,
+		Service: contract.DeviceService{
+			Addressable: contract.Addressable{Protocol: u.Scheme, Address: u.Hostname(), Port: port},
+		},
+	}
+}
+
+func newBulkCommandMock(deviceIds ...string) *mocks.DBClient {
+	dbMock := &mocks.DBClient{}
+	for _, id := range deviceIds {
+		dbMock.On("GetCommandByNameAndDeviceId", bulkCommandName, id).Return(contract.Command{
+			Name: bulkCommandName,
+			Get:  contract.Get{Action: contract.Action{Path: "/" + id}},
+			Put:  contract.Put{Action: contract.Action{Path: "/" + id}},
+		}, nil)
+	}
+	dbMock.On("GetCommandByNameAndDeviceId", bulkCommandName, "missing").Return(contract.Command{}, db.ErrNotFound)
+	return dbMock
+}
+
+func TestBulkCommand(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/failing" {
+			http.Error(w, "device failure", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer ts.Close()
+
+	devices := []contract.Device{
+		bulkDevice(t, "first", ts.URL, contract.Unlocked),
+		bulkDevice(t, "locked", ts.URL, contract.Locked),
+		bulkDevice(t, "missing", ts.URL, contract.Unlocked),
+		bulkDevice(t, "failing", ts.URL, contract.Unlocked),
+		bulkDevice(t, "second", ts.URL, contract.Unlocked),
+	}
+	dbMock := newBulkCommandMock("first", "failing", "second")
+
+	response := bulkCommand(devices, bulkCommandName, "{}", "", true, 2, context.Background(), logger.NewMockClient(),
+		dbMock, nil)
+	if response.Succeeded != 2 || response.Failed != 2 || response.Locked != 1 {
+		t.Fatalf("Unexpected counts %v", response)
+	}
+
+	expected := []struct {
+		device string
+		status int
+		body   string
+	}{
+		{"first", http.StatusOK, "/first"},
+		{"locked", http.StatusLocked, ""},
+		{"missing", http.StatusNotFound, ""},
+		{"failing", http.StatusInternalServerError, ""},
+		{"second", http.StatusOK, "/second"},
+	}
+	for i, e := range expected {
+		result := response.Results[i]
+		if result.Device != e.device || result.StatusCode != e.status || result.Body != e.body {
+			t.Errorf("Unexpected result %v, expected %v", result, e)
+		}
+		if (e.status != http.StatusOK) == (result.Error == "") {
+			t.Errorf("Expected an error for failed devices only, got %v", result)
+		}
+	}
+	dbMock.AssertNotCalled(t, "GetCommandByNameAndDeviceId", bulkCommandName, "locked")
+}
+
+func TestBulkCommandConcurrency(t *testing.T) {
+	var running, maxRunning int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}))
+	defer ts.Close()
+
+	var devices []contract.Device
+	var ids []string
+	for i := 0; i < 6; i++ {
+		id := "device" + strconv.Itoa(i)
+		ids = append(ids, id)
+		devices = append(devices, bulkDevice(t, id, ts.URL, contract.Unlocked))
+	}
+
+	response := bulkCommand(devices, bulkCommandName, "", "", false, 2, context.Background(), logger.NewMockClient(),
+		newBulkCommandMock(ids...), nil)
+	if response.Succeeded != len(devices) {
+		t.Fatalf("Expected all the devices to succeed, got %v", response)
+	}
+	if maxRunning > 2 {
+		t.Fatalf("Expected at most 2 concurrent commands, got %d", maxRunning)
+	}
+}
+
+func TestDevicesBySelector(t *testing.T) {
+	ctx := context.Background()
+	deviceClient := &mdMocks.DeviceClient{}
+	deviceClient.On("DevicesByLabel", "label", ctx).Return([]contract.Device{{Name: "labeled"}}, nil)
+	deviceClient.On("DevicesForProfileByName", "profile", ctx).Return([]contract.Device{{Name: "profiled"}}, nil)
+	deviceClient.On("DevicesForServiceByName", "service", ctx).Return([]contract.Device{{Name: "serviced"}}, nil)
+	deviceClient.On("DevicesByLabel", "unknown", ctx).Return(nil, types.NewErrServiceClient(http.StatusNotFound, []byte{}))
+	deviceClient.On("DevicesForServiceByName", "error", ctx).Return(nil, errors.New("unexpected error"))
+
+	tests := []struct {
+		name     string
+		selector string
+		value    string
+		device   string
+		status   int
+	}{
+		{"Label", LABEL, "label", "labeled", http.StatusOK},
+		{"Profile", PROFILE, "profile", "profiled", http.StatusOK},
+		{"Service", SERVICE, "service", "serviced", http.StatusOK},
+		{"Metadata error", LABEL, "unknown", "", http.StatusNotFound},
+		{"Unexpected error", SERVICE, "error", "", http.StatusInternalServerError},
+		{"Unknown selector", "model", "model", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devices, status, err := devicesBySelector(tt.selector, tt.value, ctx, deviceClient)
+			if status != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, status)
+			}
+			if tt.status != http.StatusOK {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if len(devices) != 1 || devices[0].Name != tt.device {
+				t.Fatalf("Unexpected devices %v", devices)
+			}
+		})
+	}
+}
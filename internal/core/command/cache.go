@@ -0,0 +1,300 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// Headers of the replies to the cached GET commands
+const (
+	CacheHeader = "X-Cache"
+	AgeHeader   = "Age"
+)
+
+// Values of the X-Cache header
+const (
+	CacheHit    = "HIT"
+	CacheMiss   = "MISS"
+	CacheBypass = "BYPASS"
+)
+
+// responseCache keeps the responses of the devices to the GET commands for the TTL of the command, by name or within
+// its device profile, so that repeated GETs are answered without calling the device service.
+type responseCache struct {
+	mutex              sync.Mutex
+	entries            map[cacheKey]cacheEntry
+	defaultTTL         time.Duration
+	commandTTLs        map[string]time.Duration
+	profileCommandTTLs map[string]time.Duration
+	maxEntries         int
+	loggingClient      logger.LoggingClient
+}
+
+// cacheKey identifies a cached response by the device, the command and the query parameters it was issued with.
+type cacheKey struct {
+	device  string
+	command string
+	query   string
+}
+
+type cacheEntry struct {
+	body    string
+	status  int
+	stored  time.Time
+	expires time.Time
+}
+
+func newResponseCache(config CacheInfo, loggingClient logger.LoggingClient) (*responseCache, error) {
+	defaultTTL, err := time.ParseDuration(config.DefaultTTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Cache.DefaultTTL '%s': %v", config.DefaultTTL, err)
+	}
+	if config.MaxEntries < 1 {
+		return nil, fmt.Errorf("invalid Cache.MaxEntries %d, at least 1 is required", config.MaxEntries)
+	}
+	commandTTLs, err := parseTTLs("Cache.Commands", config.Commands)
+	if err != nil {
+		return nil, err
+	}
+	profileCommandTTLs, err := parseTTLs("Cache.ProfileCommands", config.ProfileCommands)
+	if err != nil {
+		return nil, err
+	}
+
+	return &responseCache{
+		entries:            make(map[cacheKey]cacheEntry),
+		defaultTTL:         defaultTTL,
+		commandTTLs:        commandTTLs,
+		profileCommandTTLs: profileCommandTTLs,
+		maxEntries:         config.MaxEntries,
+		loggingClient:      loggingClient,
+	}, nil
+}
+
+func parseTTLs(section string, ttls map[string]string) (map[string]time.Duration, error) {
+	parsed := make(map[string]time.Duration, len(ttls))
+	for name, ttl := range ttls {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s TTL '%s' of %s: %v", section, ttl, name, err)
+		}
+		parsed[name] = d
+	}
+	return parsed, nil
+}
+
+// ttl returns how long the responses to a command are cached: the TTL of the command within the device's profile,
+// else the TTL of the command's name, else the default TTL.
+func (rc *responseCache) ttl(device contract.Device, command contract.Command) time.Duration {
+	if ttl, ok := rc.profileCommandTTLs[device.Profile.Name+"/"+command.Name]; ok {
+		return ttl
+	}
+	if ttl, ok := rc.commandTTLs[command.Name]; ok {
+		return ttl
+	}
+	return rc.defaultTTL
+}
+
+func (rc *responseCache) get(key cacheKey, now time.Time) (cacheEntry, bool) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	e, ok := rc.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if !now.Before(e.expires) {
+		delete(rc.entries, key)
+		return cacheEntry{}, false
+	}
+	return e, true
+}
+
+// put caches a response, evicting the expired responses and then the oldest ones when the cache is full.
+func (rc *responseCache) put(key cacheKey, e cacheEntry) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	if _, ok := rc.entries[key]; !ok && len(rc.entries) >= rc.maxEntries {
+		for k, one := range rc.entries {
+			if !e.stored.Before(one.expires) {
+				delete(rc.entries, k)
+			}
+		}
+		for len(rc.entries) >= rc.maxEntries {
+			var oldest cacheKey
+			var oldestStored time.Time
+			for k, one := range rc.entries {
+				if oldestStored.IsZero() || one.stored.Before(oldestStored) {
+					oldest, oldestStored = k, one.stored
+				}
+			}
+			delete(rc.entries, oldest)
+		}
+	}
+	rc.entries[key] = e
+}
+
+// invalidate removes the cached responses to a command of a device, whatever their query parameters.
+func (rc *responseCache) invalidate(device string, command string) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	for k := range rc.entries {
+		if k.device == device && k.command == command {
+			delete(rc.entries, k)
+		}
+	}
+}
+
+// cacheLookup is carried by the context of a GET command request for the cached executor to report whether the
+// response came from the cache, and how old it was.
+type cacheLookup struct {
+	bypass bool
+	result string
+	age    time.Duration
+}
+
+type cacheContextKey struct{}
+
+// context returns the context of a GET command request, whose response may come from the cache unless bypass is set.
+// Only the commands issued with such a context are answered from the cache.
+func (rc *responseCache) context(ctx context.Context, bypass bool) context.Context {
+	if rc == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, cacheContextKey{}, &cacheLookup{bypass: bypass})
+}
+
+// executor wraps the executor of a command: the responses to GET commands issued with a cache context are cached
+// for the TTL of the command and successful PUT commands remove the cached responses of the command.
+func (rc *responseCache) executor(
+	ctx context.Context,
+	device contract.Device,
+	command contract.Command,
+	queryParams string,
+	isPutCommand bool,
+	ex Executor) Executor {
+	if rc == nil {
+		return ex
+	}
+	if isPutCommand {
+		return invalidatingExecutor{Executor: ex, cache: rc, device: device.Id, command: command.Name}
+	}
+
+	lookup, ok := ctx.Value(cacheContextKey{}).(*cacheLookup)
+	if !ok {
+		return ex
+	}
+	ttl := rc.ttl(device, command)
+	if ttl <= 0 {
+		return ex
+	}
+	return cachedExecutor{
+		Executor: ex,
+		cache:    rc,
+		lookup:   lookup,
+		key:      cacheKey{device: device.Id, command: command.Name, query: queryParams},
+		ttl:      ttl,
+	}
+}
+
+// cachedExecutor answers a GET command from the cache, calling the device service on a miss or a bypass and caching
+// its successful response.
+type cachedExecutor struct {
+	Executor
+	cache  *responseCache
+	lookup *cacheLookup
+	key    cacheKey
+	ttl    time.Duration
+}
+
+func (ce cachedExecutor) Execute() (string, int, error) {
+	now := time.Now()
+	if ce.lookup.bypass {
+		ce.lookup.result = CacheBypass
+	} else if e, ok := ce.cache.get(ce.key, now); ok {
+		ce.lookup.result = CacheHit
+		ce.lookup.age = now.Sub(e.stored)
+		ce.cache.loggingClient.Debug(fmt.Sprintf("Command %s of device %s answered from the cache, %s old",
+			ce.key.command, ce.key.device, ce.lookup.age))
+		return e.body, e.status, nil
+	} else {
+		ce.lookup.result = CacheMiss
+	}
+
+	body, status, err := ce.Executor.Execute()
+	if err == nil && status >= http.StatusOK && status < http.StatusMultipleChoices {
+		stored := time.Now()
+		ce.cache.put(ce.key, cacheEntry{body: body, status: status, stored: stored, expires: stored.Add(ce.ttl)})
+	}
+	return body, status, err
+}
+
+// invalidatingExecutor removes the cached responses of the command it successfully executes, the device's state
+// having changed.
+type invalidatingExecutor struct {
+	Executor
+	cache   *responseCache
+	device  string
+	command string
+}
+
+func (ie invalidatingExecutor) Execute() (string, int, error) {
+	body, status, err := ie.Executor.Execute()
+	if err == nil && status >= http.StatusOK && status < http.StatusMultipleChoices {
+		ie.cache.invalidate(ie.device, ie.command)
+	}
+	return body, status, err
+}
+
+// writeCacheHeaders tells in the reply to a GET command whether its response came from the cache and, if so, its age
+// in seconds.
+func writeCacheHeaders(w http.ResponseWriter, ctx context.Context) {
+	lookup, ok := ctx.Value(cacheContextKey{}).(*cacheLookup)
+	if !ok || lookup.result == "" {
+		return
+	}
+	w.Header().Set(CacheHeader, lookup.result)
+	if lookup.result == CacheHit {
+		w.Header().Set(AgeHeader, strconv.FormatInt(int64(lookup.age/time.Second), 10))
+	}
+}
+
+// cacheBypass removes the ds-bypasscache parameter from the query parameters of a GET command, passed on to the
+// device service, and returns whether it asked for the cache to be bypassed.
+func cacheBypass(rawQuery string) (string, bool) {
+	if !strings.Contains(rawQuery, DSBYPASSCACHE) {
+		return rawQuery, false
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery, false
+	}
+	bypass := strings.EqualFold(query.Get(DSBYPASSCACHE), "yes")
+	query.Del(DSBYPASSCACHE)
+	return query.Encode(), bypass
+}
@@ -0,0 +1,265 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package command
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+var testCacheInfo = CacheInfo{
+	Enabled:         true,
+	DefaultTTL:      "0s",
+	MaxEntries:      10,
+	Commands:        map[string]string{"temperature": "1h", "humidity": "1h"},
+	ProfileCommands: map[string]string{"Thermostat/humidity": "0s"},
+}
+
+var cacheDevice = contract.Device{Id: "device", Name: "thermostat", Profile: contract.DeviceProfile{Name: "Thermostat"}}
+
+func newTestResponseCache(t *testing.T, info CacheInfo) *responseCache {
+	rc, err := newResponseCache(info, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return rc
+}
+
+// countingExecutor replies with the number of times it was executed
+type countingExecutor struct {
+	count  *int
+	status int
+	err    error
+}
+
+func (ce countingExecutor) Execute() (string, int, error) {
+	*ce.count++
+	return strconv.Itoa(*ce.count), ce.status, ce.err
+}
+
+// cachedGet issues a GET command through the cache and returns the response and the cache lookup
+func cachedGet(rc *responseCache, command string, query string, bypass bool, ex Executor) (string, *cacheLookup) {
+	ctx := rc.context(context.Background(), bypass)
+	body, _, _ := rc.executor(ctx, cacheDevice, contract.Command{Name: command}, query, false, ex).Execute()
+	return body, ctx.Value(cacheContextKey{}).(*cacheLookup)
+}
+
+func TestResponseCache(t *testing.T) {
+	rc := newTestResponseCache(t, testCacheInfo)
+	count := 0
+	ex := countingExecutor{count: &count, status: http.StatusOK}
+
+	tests := []struct {
+		name   string
+		query  string
+		bypass bool
+		body   string
+		result string
+	}{
+		{"Miss", "", false, "1", CacheMiss},
+		{"Hit", "", false, "1", CacheHit},
+		{"Other query", "unit=c", false, "2", CacheMiss},
+		{"Bypass", "", true, "3", CacheBypass},
+		{"Hit after bypass", "", false, "3", CacheHit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, lookup := cachedGet(rc, "temperature", tt.query, tt.bypass, ex)
+			if body != tt.body || lookup.result != tt.result {
+				t.Fatalf("Expected '%s' %s, got '%s' %s", tt.body, tt.result, body, lookup.result)
+			}
+		})
+	}
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	rc := newTestResponseCache(t, testCacheInfo)
+	tests := []struct {
+		name    string
+		command string
+		ttl     time.Duration
+	}{
+		{"Command", "temperature", time.Hour},
+		{"Profile command", "humidity", 0},
+		{"Default", "pressure", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ttl := rc.ttl(cacheDevice, contract.Command{Name: tt.command}); ttl != tt.ttl {
+				t.Fatalf("Expected TTL %s, got %s", tt.ttl, ttl)
+			}
+		})
+	}
+
+	count := 0
+	ex := countingExecutor{count: &count, status: http.StatusOK}
+	if cached := rc.executor(rc.context(context.Background(), false), cacheDevice, contract.Command{Name: "humidity"},
+		"", false, ex); cached != Executor(ex) {
+		t.Fatal("Expected the commands without a TTL not to be cached")
+	}
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	rc := newTestResponseCache(t, testCacheInfo)
+	key := cacheKey{device: "device", command: "temperature"}
+	now := time.Now()
+	rc.put(key, cacheEntry{body: "expired", stored: now.Add(-2 * time.Second), expires: now.Add(-time.Second)})
+	if _, ok := rc.get(key, now); ok {
+		t.Fatal("Expected the expired response not to be returned")
+	}
+	if len(rc.entries) != 0 {
+		t.Fatal("Expected the expired response to be removed")
+	}
+}
+
+func TestResponseCacheEviction(t *testing.T) {
+	rc := newTestResponseCache(t, CacheInfo{DefaultTTL: "1h", MaxEntries: 2})
+	now := time.Now()
+	expires := now.Add(time.Hour)
+	rc.put(cacheKey{query: "oldest"}, cacheEntry{stored: now.Add(-2 * time.Second), expires: expires})
+	rc.put(cacheKey{query: "older"}, cacheEntry{stored: now.Add(-time.Second), expires: expires})
+	rc.put(cacheKey{query: "new"}, cacheEntry{stored: now, expires: expires})
+
+	if len(rc.entries) != 2 {
+		t.Fatalf("Expected 2 cached responses, got %d", len(rc.entries))
+	}
+	if _, ok := rc.get(cacheKey{query: "oldest"}, now); ok {
+		t.Fatal("Expected the oldest response to be evicted")
+	}
+}
+
+func TestResponseCacheErrorsNotCached(t *testing.T) {
+	tests := []struct {
+		name string
+		ex   func(count *int) Executor
+	}{
+		{"Device error", func(count *int) Executor {
+			return countingExecutor{count: count, status: http.StatusInternalServerError}
+		}},
+		{"Error", func(count *int) Executor { return countingExecutor{count: count, err: errors.New("down")} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc := newTestResponseCache(t, testCacheInfo)
+			count := 0
+			cachedGet(rc, "temperature", "", false, tt.ex(&count))
+			if _, lookup := cachedGet(rc, "temperature", "", false, tt.ex(&count)); lookup.result != CacheMiss {
+				t.Fatalf("Expected a cache miss, got %s", lookup.result)
+			}
+		})
+	}
+}
+
+func TestResponseCacheInvalidatedByPut(t *testing.T) {
+	rc := newTestResponseCache(t, testCacheInfo)
+	count := 0
+	ex := countingExecutor{count: &count, status: http.StatusOK}
+	cachedGet(rc, "temperature", "", false, ex)
+	cachedGet(rc, "temperature", "unit=c", false, ex)
+
+	put := rc.executor(context.Background(), cacheDevice, contract.Command{Name: "temperature"}, "", true, ex)
+	put.Execute()
+	if len(rc.entries) != 0 {
+		t.Fatalf("Expected the responses to be removed, got %v", rc.entries)
+	}
+}
+
+func TestResponseCacheWithoutCacheContext(t *testing.T) {
+	rc := newTestResponseCache(t, testCacheInfo)
+	ex := mockExecutor{status: http.StatusOK}
+	if rc.executor(context.Background(), cacheDevice, contract.Command{Name: "temperature"}, "", false,
+		ex) != Executor(ex) {
+		t.Fatal("Expected the executor to be returned unchanged")
+	}
+
+	var disabled *responseCache
+	if disabled.context(context.Background(), false) != context.Background() {
+		t.Fatal("Expected the context to be returned unchanged when the cache is disabled")
+	}
+	if disabled.executor(context.Background(), cacheDevice, contract.Command{}, "", true, ex) != Executor(ex) {
+		t.Fatal("Expected the executor to be returned unchanged when the cache is disabled")
+	}
+}
+
+func TestNewResponseCacheInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		info CacheInfo
+	}{
+		{"DefaultTTL", CacheInfo{DefaultTTL: "soon", MaxEntries: 1}},
+		{"MaxEntries", CacheInfo{DefaultTTL: "1s"}},
+		{"Commands", CacheInfo{DefaultTTL: "1s", MaxEntries: 1, Commands: map[string]string{"temperature": "5"}}},
+		{"ProfileCommands", CacheInfo{DefaultTTL: "1s", MaxEntries: 1, ProfileCommands: map[string]string{"Thermostat/x": "x"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newResponseCache(tt.info, logger.NewMockClient()); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestCacheBypass(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawQuery string
+		query    string
+		bypass   bool
+	}{
+		{"None", "unit=c", "unit=c", false},
+		{"Bypass", "ds-bypasscache=yes&unit=c", "unit=c", true},
+		{"Bypass only", "ds-bypasscache=YES", "", true},
+		{"No bypass", "ds-bypasscache=no", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, bypass := cacheBypass(tt.rawQuery)
+			if query != tt.query || bypass != tt.bypass {
+				t.Fatalf("Expected '%s' %v, got '%s' %v", tt.query, tt.bypass, query, bypass)
+			}
+		})
+	}
+}
+
+func TestWriteCacheHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		lookup *cacheLookup
+		cache  string
+		age    string
+	}{
+		{"Hit", &cacheLookup{result: CacheHit, age: 2500 * time.Millisecond}, CacheHit, "2"},
+		{"Miss", &cacheLookup{result: CacheMiss}, CacheMiss, ""},
+		{"Not cached", &cacheLookup{}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			writeCacheHeaders(rr, context.WithValue(context.Background(), cacheContextKey{}, tt.lookup))
+			if rr.Header().Get(CacheHeader) != tt.cache || rr.Header().Get(AgeHeader) != tt.age {
+				t.Fatalf("Unexpected headers %v", rr.Header())
+			}
+		})
+	}
+}
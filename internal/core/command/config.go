@@ -21,14 +21,19 @@ import (
 
 // ConfigurationStruct contains the configuration properties for the core-command service.
 type ConfigurationStruct struct {
-	Writable    WritableInfo
-	Clients     map[string]config.ClientInfo
-	Databases   config.DatabaseInfo
-	Logging     config.LoggingInfo
-	Registry    config.RegistryInfo
-	Service     config.ServiceInfo
-	SecretStore config.SecretStoreInfo
-	Startup     config.StartupInfo
+	Writable     WritableInfo
+	Clients      map[string]config.ClientInfo
+	Databases    config.DatabaseInfo
+	Logging      config.LoggingInfo
+	Registry     config.RegistryInfo
+	Service      config.ServiceInfo
+	SecretStore  config.SecretStoreInfo
+	Startup      config.StartupInfo
+	Jobs         JobsInfo
+	Bulk         BulkInfo
+	Audit        AuditInfo
+	Cache        CacheInfo
+	MessageQueue config.MessageQueueInfo
 }
 
 // WritableInfo contains configuration properties that can be updated and applied without restarting the service.
@@ -36,6 +41,67 @@ type WritableInfo struct {
 	LogLevel string
 }
 
+// JobsInfo contains the configuration properties of the asynchronous command execution.
+type JobsInfo struct {
+	// Workers is the number of commands executed concurrently.
+	Workers int
+	// QueueSize is the number of commands waiting for a worker beyond which new jobs are rejected.
+	QueueSize int
+	// Retention is how long a finished job remains available for polling, as a duration string.
+	Retention string
+	// PruneInterval is how often the finished jobs older than the retention are removed, as a duration string.
+	PruneInterval string
+	// CallbackTimeout bounds the delivery of a job's result to its callback URL, as a duration string.
+	CallbackTimeout string
+	// CallbackHosts lists the hosts, optionally with a port, the callback URLs may target. Any host is allowed when
+	// empty.
+	CallbackHosts []string
+}
+
+// BulkInfo contains the configuration properties of the commands issued to a set of devices.
+type BulkInfo struct {
+	// Concurrency is the maximum number of devices a bulk command is issued to at once.
+	Concurrency int
+}
+
+// AuditInfo contains the configuration properties of the audit trail of the commands issued to the devices.
+type AuditInfo struct {
+	// Enabled tells whether the commands are recorded.
+	Enabled bool
+	// Retention is how long the records are kept, as a duration string.
+	Retention string
+	// PruneInterval is how often the records older than the retention are deleted, as a duration string.
+	PruneInterval string
+	// MaxResponseLength is the number of bytes of the device service's response kept in a record.
+	MaxResponseLength int
+	// CallerHeaders lists the request headers holding the caller's identity as set by the API gateway, in order of
+	// preference. The subject of the bearer JWT is used when none of them is present. The headers are not verified by
+	// core-command, the recorded caller can only be trusted when the service is reachable through the gateway alone.
+	CallerHeaders []string
+	// JWTSecret is the key verifying the signature of the HS256, HS384 and HS512 bearer JWTs.
+	JWTSecret string
+	// JWTPublicKey is the path of the PEM encoded RSA public key verifying the signature of the RS256, RS384 and RS512
+	// bearer JWTs. When either key is configured the JWTs which fail the verification are ignored, otherwise their
+	// claims are recorded unverified.
+	JWTPublicKey string
+}
+
+// CacheInfo contains the configuration properties of the cache of the devices' responses to the GET commands.
+type CacheInfo struct {
+	// Enabled tells whether the responses are cached.
+	Enabled bool
+	// DefaultTTL is how long the responses to the commands without a TTL of their own are cached, as a duration
+	// string. Zero disables the cache for those commands.
+	DefaultTTL string
+	// MaxEntries is the number of responses cached beyond which the oldest are evicted.
+	MaxEntries int
+	// Commands maps command names to the TTL of their responses, as duration strings.
+	Commands map[string]string
+	// ProfileCommands maps the commands of a device profile, as <profile>/<command>, to the TTL of their responses,
+	// as duration strings. They take precedence over Commands.
+	ProfileCommands map[string]string
+}
+
 // UpdateFromRaw converts configuration received from the registry to a service-specific configuration struct which is
 // then used to overwrite the service's existing configuration struct.
 func (c *ConfigurationStruct) UpdateFromRaw(rawConfig interface{}) bool {
@@ -22,4 +22,17 @@ const (
 	COMMANDID        = "commandid"
 	COMMANDNAME      = "commandname"
 	DEVICE           = "device"
+	JOB              = "job"
+	ASYNC            = "async"
+	CALLBACK         = "callback"
+	PUBLISH          = "publish"
+	LABEL            = "label"
+	PROFILE          = "profile"
+	SERVICE          = "service"
+	AUDIT            = "audit"
+	START            = "start"
+	END              = "end"
+	STATUS           = "status"
+	LIMIT            = "limit"
+	DSBYPASSCACHE    = "ds-bypasscache"
 )
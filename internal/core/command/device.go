@@ -16,8 +16,10 @@ package command
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata"
@@ -38,22 +40,41 @@ func commandByDeviceID(
 	ctx context.Context,
 	loggingClient logger.LoggingClient,
 	dbClient interfaces.DBClient,
-	deviceClient metadata.DeviceClient) (string, int) {
+	deviceClient metadata.DeviceClient,
+	vdc valueDescriptorReader) (string, int) {
+	d, c, status, err := deviceCommandByDeviceID(deviceID, commandID, ctx, loggingClient, dbClient, deviceClient)
+	if err != nil {
+		recordAudit(ctx, auditName(d.Name, deviceID), auditName(c.Name, commandID), status, err.Error())
+		return err.Error(), status
+	}
+
+	return commandByDevice(d, c, body, queryParams, isPutCommand, ctx, loggingClient, vdc)
+}
+
+// deviceCommandByDeviceID looks up an unlocked device and one of its commands by their IDs. The returned status is
+// the one to reply with when they cannot be resolved.
+func deviceCommandByDeviceID(
+	deviceID string,
+	commandID string,
+	ctx context.Context,
+	loggingClient logger.LoggingClient,
+	dbClient interfaces.DBClient,
+	deviceClient metadata.DeviceClient) (contract.Device, contract.Command, int, error) {
 	d, err := deviceClient.Device(deviceID, ctx)
 	if err != nil {
 		loggingClient.Error(err.Error())
 
 		chk, ok := err.(types.ErrServiceClient)
 		if ok {
-			return err.Error(), chk.StatusCode
+			return contract.Device{}, contract.Command{}, chk.StatusCode, err
 		} else {
-			return err.Error(), http.StatusInternalServerError
+			return contract.Device{}, contract.Command{}, http.StatusInternalServerError, err
 		}
 	}
 
 	if d.AdminState == contract.Locked {
 		loggingClient.Error(d.Name + " is in admin locked state")
-		return errors.NewErrDeviceLocked(d.Name).Error(), http.StatusLocked
+		return d, contract.Command{}, http.StatusLocked, errors.NewErrDeviceLocked(d.Name)
 	}
 
 	//once command service have its own persistence layer this call will be changed.
@@ -61,9 +82,9 @@ func commandByDeviceID(
 	if err != nil {
 		loggingClient.Error(err.Error())
 		if err == db.ErrNotFound {
-			return err.Error(), http.StatusNotFound
+			return d, contract.Command{}, http.StatusNotFound, err
 		} else {
-			return err.Error(), http.StatusInternalServerError
+			return d, contract.Command{}, http.StatusInternalServerError, err
 		}
 	}
 
@@ -76,12 +97,12 @@ func commandByDeviceID(
 	}
 
 	if c.String() == (contract.Command{}).String() {
-		errMsg := fmt.Sprintf("Command with id '%v' does not belong to device with id '%v'.", commandID, deviceID)
-		loggingClient.Error(errMsg)
-		return errMsg, http.StatusNotFound
+		err = fmt.Errorf("Command with id '%v' does not belong to device with id '%v'.", commandID, deviceID)
+		loggingClient.Error(err.Error())
+		return d, c, http.StatusNotFound, err
 	}
 
-	return commandByDevice(d, c, body, queryParams, isPutCommand, ctx, loggingClient)
+	return d, c, http.StatusOK, nil
 }
 
 func commandByNames(
@@ -93,34 +114,64 @@ func commandByNames(
 	ctx context.Context,
 	loggingClient logger.LoggingClient,
 	dbClient interfaces.DBClient,
-	deviceClient metadata.DeviceClient) (string, int) {
+	deviceClient metadata.DeviceClient,
+	vdc valueDescriptorReader) (string, int) {
+	d, command, status, err := deviceCommandByNames(dn, cn, ctx, loggingClient, dbClient, deviceClient)
+	if err != nil {
+		recordAudit(ctx, dn, cn, status, err.Error())
+		return err.Error(), status
+	}
+
+	return commandByDevice(d, command, body, queryParams, isPutCommand, ctx, loggingClient, vdc)
+}
+
+// deviceCommandByNames looks up an unlocked device and one of its commands by their names. The returned status is
+// the one to reply with when they cannot be resolved.
+func deviceCommandByNames(
+	dn string,
+	cn string,
+	ctx context.Context,
+	loggingClient logger.LoggingClient,
+	dbClient interfaces.DBClient,
+	deviceClient metadata.DeviceClient) (contract.Device, contract.Command, int, error) {
 	d, err := deviceClient.DeviceForName(dn, ctx)
 	if err != nil {
 		loggingClient.Error(err.Error())
 		chk, ok := err.(types.ErrServiceClient)
 		if ok {
-			return err.Error(), chk.StatusCode
+			return contract.Device{}, contract.Command{}, chk.StatusCode, err
 		} else {
-			return err.Error(), http.StatusInternalServerError
+			return contract.Device{}, contract.Command{}, http.StatusInternalServerError, err
 		}
 	}
 
+	command, status, err := commandForDevice(d, cn, loggingClient, dbClient)
+	return d, command, status, err
+}
+
+// commandForDevice looks up a command of an unlocked device by name. The returned status is the one to reply with
+// when it cannot be resolved.
+func commandForDevice(
+	d contract.Device,
+	cn string,
+	loggingClient logger.LoggingClient,
+	dbClient interfaces.DBClient) (contract.Command, int, error) {
 	if d.AdminState == contract.Locked {
 		loggingClient.Error(d.Name + " is in admin locked state")
-		return errors.NewErrDeviceLocked(d.Name).Error(), http.StatusLocked
+		return contract.Command{}, http.StatusLocked, errors.NewErrDeviceLocked(d.Name)
 	}
 
 	command, err := dbClient.GetCommandByNameAndDeviceId(cn, d.Id)
 	if err != nil {
 		loggingClient.Error(err.Error())
 		if err == db.ErrNotFound {
-			return err.Error(), http.StatusNotFound
+			return contract.Command{}, http.StatusNotFound, err
 		} else {
-			return err.Error(), http.StatusInternalServerError
+			return contract.Command{}, http.StatusInternalServerError, err
 		}
 	}
 
-	return commandByDevice(d, command, body, queryParams, isPutCommand, ctx, loggingClient)
+	return command, http.StatusOK, nil
 }
 
 func commandByDevice(
@@ -130,18 +181,12 @@ func commandByDevice(
 	queryParams string,
 	isPutCommand bool,
 	ctx context.Context,
-	loggingClient logger.LoggingClient) (string, int) {
-	var ex Executor
-	var err error
-	if isPutCommand {
-		ex, err = NewPutCommand(device, command, body, ctx, &http.Client{}, loggingClient)
-	} else {
-		ex, err = NewGetCommand(device, command, queryParams, ctx, &http.Client{}, loggingClient)
-	}
-
+	loggingClient logger.LoggingClient,
+	vdc valueDescriptorReader) (string, int) {
+	ex, err := newCommandExecutor(device, command, body, queryParams, isPutCommand, ctx, loggingClient, vdc)
 	if err != nil {
 		loggingClient.Error(err.Error())
-		return err.Error(), http.StatusInternalServerError
+		return commandErrorResponse(err)
 	}
 
 	responseBody, responseCode, err := ex.Execute()
@@ -153,6 +198,57 @@ func commandByDevice(
 	return responseBody, responseCode
 }
 
+func newCommandExecutor(
+	device contract.Device,
+	command contract.Command,
+	body string,
+	queryParams string,
+	isPutCommand bool,
+	ctx context.Context,
+	loggingClient logger.LoggingClient,
+	vdc valueDescriptorReader) (Executor, error) {
+	var ex Executor
+	var err error
+	if isPutCommand {
+		err = validatePutCommand(device, command, body, ctx, vdc, loggingClient)
+		if err == nil {
+			ex, err = NewPutCommand(device, command, body, ctx, commandClient(), loggingClient)
+		}
+	} else {
+		ex, err = NewGetCommand(device, command, queryParams, ctx, commandClient(), loggingClient)
+	}
+	if err != nil {
+		responseBody, status := commandErrorResponse(err)
+		recordAudit(ctx, device.Name, command.Name, status, responseBody)
+		return nil, err
+	}
+	ex = responses.executor(ctx, device, command, queryParams, isPutCommand, ex)
+	return auditExecutor(ctx, device, command, ex), nil
+}
+
+// commandClient returns the client issuing the commands to the device services, giving up on a device service which
+// does not reply within the service timeout.
+func commandClient() *http.Client {
+	return &http.Client{Timeout: time.Duration(Configuration.Service.Timeout) * time.Millisecond}
+}
+
+// commandErrorResponse returns the body and status of the reply to a command whose executor could not be created.
+func commandErrorResponse(err error) (string, int) {
+	if invalid, ok := err.(errors.ErrInvalidParameters); ok {
+		return invalidParametersBody(invalid), http.StatusBadRequest
+	}
+	return err.Error(), http.StatusInternalServerError
+}
+
+// invalidParametersBody returns the JSON body of the reply to a PUT command with invalid parameters.
+func invalidParametersBody(invalid errors.ErrInvalidParameters) string {
+	b, err := json.Marshal(invalid)
+	if err != nil {
+		return invalid.Error()
+	}
+	return string(b)
+}
+
 func getCommands(
 	ctx context.Context,
 	loggingClient logger.LoggingClient,
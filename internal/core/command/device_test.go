@@ -19,6 +19,7 @@ import (
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	mdMocks "github.com/edgexfoundry/go-mod-core-contracts/clients/metadata/mocks"
@@ -113,7 +114,8 @@ func TestExecuteGETCommandByDeviceIdAndCommandId(t *testing.T) {
 				context.Background(),
 				logger.NewMockClient(),
 				newCommandMock(),
-				newMockDeviceClient())
+				newMockDeviceClient(),
+				nil)
 			if tt.expectedStatus != statusCode {
 				t.Errorf("status code mismatch -- expected %v got %v", tt.expectedStatus, statusCode)
 				return
@@ -146,3 +148,12 @@ func newCommandMock() interfaces.DBClient {
 
 	return dbMock
 }
+
+func TestCommandClientTimeout(t *testing.T) {
+	defer func(timeout int) { Configuration.Service.Timeout = timeout }(Configuration.Service.Timeout)
+	Configuration.Service.Timeout = 5000
+
+	if timeout := commandClient().Timeout; timeout != 5*time.Second {
+		t.Fatalf("Expected the service timeout, got %v", timeout)
+	}
+}
@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type ErrDeviceLocked struct {
 	device string
@@ -13,3 +16,35 @@ func (e ErrDeviceLocked) Error() string {
 func NewErrDeviceLocked(name string) error {
 	return ErrDeviceLocked{device: name}
 }
+
+// Reasons of the rejection of a parameter of a PUT command
+const (
+	ReasonInvalidBody      = "INVALID_BODY"
+	ReasonUnknownParameter = "UNKNOWN_PARAMETER"
+	ReasonInvalidType      = "INVALID_TYPE"
+	ReasonOutOfRange       = "OUT_OF_RANGE"
+)
+
+// ParameterError describes why a parameter of a PUT command was rejected. Parameter is empty when the body as a
+// whole is rejected.
+type ParameterError struct {
+	Parameter string `json:"parameter,omitempty"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+}
+
+// ErrInvalidParameters is returned when the body of a PUT command does not match the parameters of the command.
+type ErrInvalidParameters struct {
+	Device     string           `json:"device"`
+	Command    string           `json:"command"`
+	Parameters []ParameterError `json:"parameters"`
+}
+
+func (e ErrInvalidParameters) Error() string {
+	messages := make([]string, len(e.Parameters))
+	for i, p := range e.Parameters {
+		messages[i] = p.Message
+	}
+	return fmt.Sprintf("invalid parameters for command '%s' of device '%s': %s",
+		e.Command, e.Device, strings.Join(messages, "; "))
+}
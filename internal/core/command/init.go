@@ -17,6 +17,7 @@ package command
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	container "github.com/edgexfoundry/edgex-go/internal/core/command/containers"
@@ -27,8 +28,11 @@ import (
 	"github.com/edgexfoundry/edgex-go/internal/pkg/errorconcept"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/coredata"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	"github.com/edgexfoundry/go-mod-messaging/messaging"
+	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
 )
 
 // Global variables
@@ -37,6 +41,15 @@ var Configuration = &ConfigurationStruct{}
 // Global ErrorConcept variables
 var httpErrorHandler errorconcept.ErrorHandler
 
+// jobs executes the commands issued asynchronously
+var jobs *jobRunner
+
+// audits records the commands issued to the devices, nil when the audit trail is disabled
+var audits *auditTrail
+
+// responses caches the devices' responses to the GET commands, nil when the cache is disabled
+var responses *responseCache
+
 // BootstrapHandler fulfills the BootstrapHandler contract and performs initialization needed by the command service.
 func BootstrapHandler(wg *sync.WaitGroup, ctx context.Context, startupTimer startup.Timer, dic *di.Container) bool {
 	loggingClient := bootstrapContainer.LoggingClientFrom(dic.Get)
@@ -56,7 +69,66 @@ func BootstrapHandler(wg *sync.WaitGroup, ctx context.Context, startupTimer star
 				},
 				endpoint.Endpoint{RegistryClient: &registryClient})
 		},
+		container.CoreDataValueDescriptorClientName: func(get di.Get) interface{} {
+			return coredata.NewValueDescriptorClient(
+				types.EndpointParams{
+					ServiceKey:  clients.CoreDataServiceKey,
+					Path:        clients.ApiValueDescriptorRoute,
+					UseRegistry: registryClient != nil,
+					Url:         Configuration.Clients["CoreData"].Url() + clients.ApiValueDescriptorRoute,
+					Interval:    Configuration.Service.ClientMonitor,
+				},
+				endpoint.Endpoint{RegistryClient: &registryClient})
+		},
 	})
 
+	var publisher jobPublisher
+	if Configuration.MessageQueue.Type != "" {
+		msgClient, err := messaging.NewMessageClient(
+			msgTypes.MessageBusConfig{
+				PublishHost: msgTypes.HostInfo{
+					Host:     Configuration.MessageQueue.Host,
+					Port:     Configuration.MessageQueue.Port,
+					Protocol: Configuration.MessageQueue.Protocol,
+				},
+				Type: Configuration.MessageQueue.Type,
+			})
+		if err == nil {
+			err = msgClient.Connect()
+		}
+		if err != nil {
+			loggingClient.Error(fmt.Sprintf("failed to create messaging client: %s", err.Error()))
+			return false
+		}
+		publisher = msgClient
+	}
+
+	var err error
+	jobs, err = newJobRunner(Configuration.Jobs, publisher, Configuration.MessageQueue.Topic, loggingClient)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return false
+	}
+	jobs.start(wg, ctx)
+
+	if Configuration.Audit.Enabled {
+		audits, err = newAuditTrail(Configuration.Audit, bootstrapContainer.DBClientFrom(dic.Get), loggingClient)
+		if err != nil {
+			loggingClient.Error(err.Error())
+			return false
+		}
+		audits.start(wg, ctx)
+	}
+
+	descriptors = newDescriptorCache()
+
+	if Configuration.Cache.Enabled {
+		responses, err = newResponseCache(Configuration.Cache, loggingClient)
+		if err != nil {
+			loggingClient.Error(err.Error())
+			return false
+		}
+	}
+
 	return true
 }
@@ -2,6 +2,8 @@ package interfaces
 
 import (
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/command/models"
 )
 
 type DBClient interface {
@@ -11,4 +13,13 @@ type DBClient interface {
 	GetCommandsByName(id string) ([]contract.Command, error)
 	GetCommandsByDeviceId(id string) ([]contract.Command, error)
 	GetCommandByNameAndDeviceId(cname string, did string) (contract.Command, error)
+
+	// AddCommandAudit persists the audit record of a command and returns its ID.
+	AddCommandAudit(a models.Audit) (string, error)
+	// GetCommandAudits returns the most recent audit records created between start and end, both included, for a
+	// device and a response status, at most limit of them. An empty device, a zero status and a zero limit match any.
+	GetCommandAudits(device string, start int64, end int64, status int, limit int) ([]models.Audit, error)
+	// DeleteCommandAuditsOld deletes the audit records created more than age milliseconds ago and returns how many
+	// were deleted.
+	DeleteCommandAuditsOld(age int64) (int, error)
 }
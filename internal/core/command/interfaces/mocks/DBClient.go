@@ -2,29 +2,73 @@
 
 package mocks
 
+import go_mod_core_contractsmodels "github.com/edgexfoundry/go-mod-core-contracts/models"
+
 import mock "github.com/stretchr/testify/mock"
-import models "github.com/edgexfoundry/go-mod-core-contracts/models"
+import models "github.com/edgexfoundry/edgex-go/internal/core/command/models"
 
 // DBClient is an autogenerated mock type for the DBClient type
 type DBClient struct {
 	mock.Mock
 }
 
+// AddCommandAudit provides a mock function with given fields: a
+func (_m *DBClient) AddCommandAudit(a models.Audit) (string, error) {
+	ret := _m.Called(a)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(models.Audit) string); ok {
+		r0 = rf(a)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Audit) error); ok {
+		r1 = rf(a)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CloseSession provides a mock function with given fields:
 func (_m *DBClient) CloseSession() {
 	_m.Called()
 }
 
+// DeleteCommandAuditsOld provides a mock function with given fields: age
+func (_m *DBClient) DeleteCommandAuditsOld(age int64) (int, error) {
+	ret := _m.Called(age)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(int64) int); ok {
+		r0 = rf(age)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(age)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllCommands provides a mock function with given fields:
-func (_m *DBClient) GetAllCommands() ([]models.Command, error) {
+func (_m *DBClient) GetAllCommands() ([]go_mod_core_contractsmodels.Command, error) {
 	ret := _m.Called()
 
-	var r0 []models.Command
-	if rf, ok := ret.Get(0).(func() []models.Command); ok {
+	var r0 []go_mod_core_contractsmodels.Command
+	if rf, ok := ret.Get(0).(func() []go_mod_core_contractsmodels.Command); ok {
 		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Command)
+			r0 = ret.Get(0).([]go_mod_core_contractsmodels.Command)
 		}
 	}
 
@@ -38,15 +82,38 @@ func (_m *DBClient) GetAllCommands() ([]models.Command, error) {
 	return r0, r1
 }
 
+// GetCommandAudits provides a mock function with given fields: device, start, end, status, limit
+func (_m *DBClient) GetCommandAudits(device string, start int64, end int64, status int, limit int) ([]models.Audit, error) {
+	ret := _m.Called(device, start, end, status, limit)
+
+	var r0 []models.Audit
+	if rf, ok := ret.Get(0).(func(string, int64, int64, int, int) []models.Audit); ok {
+		r0 = rf(device, start, end, status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Audit)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int64, int64, int, int) error); ok {
+		r1 = rf(device, start, end, status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetCommandById provides a mock function with given fields: id
-func (_m *DBClient) GetCommandById(id string) (models.Command, error) {
+func (_m *DBClient) GetCommandById(id string) (go_mod_core_contractsmodels.Command, error) {
 	ret := _m.Called(id)
 
-	var r0 models.Command
-	if rf, ok := ret.Get(0).(func(string) models.Command); ok {
+	var r0 go_mod_core_contractsmodels.Command
+	if rf, ok := ret.Get(0).(func(string) go_mod_core_contractsmodels.Command); ok {
 		r0 = rf(id)
 	} else {
-		r0 = ret.Get(0).(models.Command)
+		r0 = ret.Get(0).(go_mod_core_contractsmodels.Command)
 	}
 
 	var r1 error
@@ -60,14 +127,14 @@ func (_m *DBClient) GetCommandById(id string) (models.Command, error) {
 }
 
 // GetCommandByNameAndDeviceId provides a mock function with given fields: cname, did
-func (_m *DBClient) GetCommandByNameAndDeviceId(cname string, did string) (models.Command, error) {
+func (_m *DBClient) GetCommandByNameAndDeviceId(cname string, did string) (go_mod_core_contractsmodels.Command, error) {
 	ret := _m.Called(cname, did)
 
-	var r0 models.Command
-	if rf, ok := ret.Get(0).(func(string, string) models.Command); ok {
+	var r0 go_mod_core_contractsmodels.Command
+	if rf, ok := ret.Get(0).(func(string, string) go_mod_core_contractsmodels.Command); ok {
 		r0 = rf(cname, did)
 	} else {
-		r0 = ret.Get(0).(models.Command)
+		r0 = ret.Get(0).(go_mod_core_contractsmodels.Command)
 	}
 
 	var r1 error
@@ -81,15 +148,15 @@ func (_m *DBClient) GetCommandByNameAndDeviceId(cname string, did string) (model
 }
 
 // GetCommandsByDeviceId provides a mock function with given fields: id
-func (_m *DBClient) GetCommandsByDeviceId(id string) ([]models.Command, error) {
+func (_m *DBClient) GetCommandsByDeviceId(id string) ([]go_mod_core_contractsmodels.Command, error) {
 	ret := _m.Called(id)
 
-	var r0 []models.Command
-	if rf, ok := ret.Get(0).(func(string) []models.Command); ok {
+	var r0 []go_mod_core_contractsmodels.Command
+	if rf, ok := ret.Get(0).(func(string) []go_mod_core_contractsmodels.Command); ok {
 		r0 = rf(id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Command)
+			r0 = ret.Get(0).([]go_mod_core_contractsmodels.Command)
 		}
 	}
 
@@ -104,15 +171,15 @@ func (_m *DBClient) GetCommandsByDeviceId(id string) ([]models.Command, error) {
 }
 
 // GetCommandsByName provides a mock function with given fields: id
-func (_m *DBClient) GetCommandsByName(id string) ([]models.Command, error) {
+func (_m *DBClient) GetCommandsByName(id string) ([]go_mod_core_contractsmodels.Command, error) {
 	ret := _m.Called(id)
 
-	var r0 []models.Command
-	if rf, ok := ret.Get(0).(func(string) []models.Command); ok {
+	var r0 []go_mod_core_contractsmodels.Command
+	if rf, ok := ret.Get(0).(func(string) []go_mod_core_contractsmodels.Command); ok {
 		r0 = rf(id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]models.Command)
+			r0 = ret.Get(0).([]go_mod_core_contractsmodels.Command)
 		}
 	}
 
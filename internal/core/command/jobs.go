@@ -0,0 +1,342 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	"github.com/google/uuid"
+
+	"github.com/edgexfoundry/edgex-go/internal"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// Job statuses
+const (
+	JobPending   = "PENDING"
+	JobRunning   = "RUNNING"
+	JobCompleted = "COMPLETED"
+	JobFailed    = "FAILED"
+)
+
+// ErrJobQueueFull is returned when a job is submitted while all the workers are busy and the queue is full.
+var ErrJobQueueFull = errors.New("command job queue is full")
+
+// maxCallbackRedirects is the number of redirects followed when delivering a job to its callback.
+const maxCallbackRedirects = 10
+
+// Job tracks a command executed asynchronously. A job is COMPLETED when the device service replied with a 2xx status
+// and FAILED otherwise.
+type Job struct {
+	Id            string `json:"id"`
+	Status        string `json:"status"`
+	Device        string `json:"device"`
+	Command       string `json:"command"`
+	Method        string `json:"method"`
+	Callback      string `json:"callback,omitempty"`
+	Publish       bool   `json:"publish,omitempty"`
+	CorrelationId string `json:"correlationId,omitempty"`
+	Created       int64  `json:"created"`
+	Started       int64  `json:"started,omitempty"`
+	Completed     int64  `json:"completed,omitempty"`
+	ResponseCode  int    `json:"responseCode,omitempty"`
+	Result        string `json:"result,omitempty"`
+}
+
+func (j Job) finished() bool {
+	return j.Status == JobCompleted || j.Status == JobFailed
+}
+
+// jobPublisher publishes the results of the jobs on the message bus.
+type jobPublisher interface {
+	Publish(message msgTypes.MessageEnvelope, topic string) error
+}
+
+type queuedJob struct {
+	id string
+	ex Executor
+}
+
+// jobRunner executes the commands of the jobs with a pool of workers and keeps the jobs for polling until their
+// retention expires.
+type jobRunner struct {
+	mutex          sync.Mutex
+	jobs           map[string]*Job
+	queue          chan queuedJob
+	workers        int
+	retention      time.Duration
+	pruneInterval  time.Duration
+	callbackCaller internal.HttpCaller
+	callbackHosts  map[string]bool
+	publisher      jobPublisher
+	topic          string
+	loggingClient  logger.LoggingClient
+}
+
+func newJobRunner(
+	config JobsInfo,
+	publisher jobPublisher,
+	topic string,
+	loggingClient logger.LoggingClient) (*jobRunner, error) {
+	retention, err := time.ParseDuration(config.Retention)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Jobs.Retention '%s': %v", config.Retention, err)
+	}
+	pruneInterval, err := time.ParseDuration(config.PruneInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Jobs.PruneInterval '%s': %v", config.PruneInterval, err)
+	}
+	if pruneInterval <= 0 {
+		return nil, fmt.Errorf("invalid Jobs.PruneInterval '%s', a positive duration is required", config.PruneInterval)
+	}
+	callbackTimeout, err := time.ParseDuration(config.CallbackTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Jobs.CallbackTimeout '%s': %v", config.CallbackTimeout, err)
+	}
+	if config.Workers < 1 {
+		return nil, fmt.Errorf("invalid Jobs.Workers %d, at least one worker is required", config.Workers)
+	}
+
+	jr := &jobRunner{
+		jobs:          make(map[string]*Job),
+		queue:         make(chan queuedJob, config.QueueSize),
+		workers:       config.Workers,
+		retention:     retention,
+		pruneInterval: pruneInterval,
+		callbackHosts: make(map[string]bool),
+		publisher:     publisher,
+		topic:         topic,
+		loggingClient: loggingClient,
+	}
+	for _, host := range config.CallbackHosts {
+		jr.callbackHosts[strings.ToLower(host)] = true
+	}
+	// the redirects are held to the same restrictions as the callback itself
+	jr.callbackCaller = &http.Client{
+		Timeout: callbackTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxCallbackRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxCallbackRedirects)
+			}
+			return jr.checkCallbackURL(req.URL)
+		},
+	}
+	return jr, nil
+}
+
+// checkCallback returns an error unless the callback is an absolute http or https URL whose host is allowed by the
+// Jobs.CallbackHosts configuration.
+func (jr *jobRunner) checkCallback(callback string) error {
+	u, err := url.ParseRequestURI(callback)
+	if err != nil {
+		return err
+	}
+	return jr.checkCallbackURL(u)
+}
+
+func (jr *jobRunner) checkCallbackURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme '%s' is not supported, http or https is required", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return errors.New("a host is required")
+	}
+	if len(jr.callbackHosts) > 0 &&
+		!jr.callbackHosts[strings.ToLower(u.Hostname())] && !jr.callbackHosts[strings.ToLower(u.Host)] {
+		return fmt.Errorf("host '%s' is not allowed", u.Host)
+	}
+	return nil
+}
+
+// start runs the workers, and removes the expired jobs at every prune interval, until the context is cancelled.
+func (jr *jobRunner) start(wg *sync.WaitGroup, ctx context.Context) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(jr.pruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				jr.prune()
+			}
+		}
+	}()
+
+	for i := 0; i < jr.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case qj := <-jr.queue:
+					jr.run(qj)
+				}
+			}
+		}()
+	}
+}
+
+// submit queues the execution of a job, the returned copy being the job as accepted.
+func (jr *jobRunner) submit(job Job, ex Executor) (Job, error) {
+	if job.Publish && jr.publisher == nil {
+		return Job{}, errors.New("results cannot be published, no message bus is configured")
+	}
+
+	job.Id = uuid.New().String()
+	job.Status = JobPending
+	job.Created = db.MakeTimestamp()
+
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+
+	select {
+	case jr.queue <- queuedJob{id: job.Id, ex: ex}:
+	default:
+		return Job{}, ErrJobQueueFull
+	}
+	jr.jobs[job.Id] = &job
+	return job, nil
+}
+
+// job returns a copy of a job by ID.
+func (jr *jobRunner) job(id string) (Job, bool) {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+	job, ok := jr.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// prune removes the finished jobs older than the retention.
+func (jr *jobRunner) prune() {
+	limit := db.MakeTimestamp() - jr.retention.Nanoseconds()/int64(time.Millisecond)
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+	for id, job := range jr.jobs {
+		if job.finished() && job.Completed < limit {
+			delete(jr.jobs, id)
+		}
+	}
+}
+
+func (jr *jobRunner) run(qj queuedJob) {
+	jr.update(qj.id, func(job *Job) {
+		job.Status = JobRunning
+		job.Started = db.MakeTimestamp()
+	})
+
+	body, status, err := qj.ex.Execute()
+	if err != nil {
+		jr.loggingClient.Error(err.Error())
+		body = err.Error()
+		if status == DefaultErrorCode {
+			status = http.StatusInternalServerError
+		}
+	}
+
+	job := jr.update(qj.id, func(job *Job) {
+		job.Completed = db.MakeTimestamp()
+		job.ResponseCode = status
+		job.Result = body
+		if status >= http.StatusOK && status < http.StatusMultipleChoices {
+			job.Status = JobCompleted
+		} else {
+			job.Status = JobFailed
+		}
+	})
+	jr.loggingClient.Info(fmt.Sprintf("Command job %s %s with status %d", job.Id, job.Status, status))
+
+	if job.Callback != "" {
+		jr.deliver(job)
+	}
+	if job.Publish {
+		jr.publish(job)
+	}
+}
+
+// update applies a change to a job and returns a copy of the changed job.
+func (jr *jobRunner) update(id string, change func(job *Job)) Job {
+	jr.mutex.Lock()
+	defer jr.mutex.Unlock()
+	job := jr.jobs[id]
+	change(job)
+	return *job
+}
+
+// deliver posts a finished job to its callback URL.
+func (jr *jobRunner) deliver(job Job) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		jr.loggingClient.Error(fmt.Sprintf("Unable to marshal command job %s: %v", job.Id, err))
+		return
+	}
+	req, err := http.NewRequest(http.MethodPost, job.Callback, bytes.NewReader(data))
+	if err != nil {
+		jr.loggingClient.Error(fmt.Sprintf("Invalid callback of command job %s: %v", job.Id, err))
+		return
+	}
+	req.Header.Set(clients.ContentType, clients.ContentTypeJSON)
+	if job.CorrelationId != "" {
+		req.Header.Set(clients.CorrelationHeader, job.CorrelationId)
+	}
+
+	resp, err := jr.callbackCaller.Do(req)
+	if err != nil {
+		jr.loggingClient.Error(fmt.Sprintf("Unable to deliver command job %s to %s: %v", job.Id, job.Callback, err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		jr.loggingClient.Error(fmt.Sprintf("Callback %s of command job %s replied with status %d",
+			job.Callback, job.Id, resp.StatusCode))
+	}
+}
+
+// publish puts a finished job on the message bus.
+func (jr *jobRunner) publish(job Job) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		jr.loggingClient.Error(fmt.Sprintf("Unable to marshal command job %s: %v", job.Id, err))
+		return
+	}
+	ctx := context.WithValue(context.Background(), clients.CorrelationHeader, job.CorrelationId)
+	ctx = context.WithValue(ctx, clients.ContentType, clients.ContentTypeJSON)
+
+	err = jr.publisher.Publish(msgTypes.NewMessageEnvelope(data, ctx), jr.topic)
+	if err != nil {
+		jr.loggingClient.Error(fmt.Sprintf("Unable to publish command job %s: %v", job.Id, err))
+		return
+	}
+	jr.loggingClient.Debug(fmt.Sprintf("Command job %s published on topic %s", job.Id, jr.topic))
+}
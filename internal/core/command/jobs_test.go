@@ -0,0 +1,305 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	"github.com/gorilla/mux"
+)
+
+var testJobsInfo = JobsInfo{Workers: 2, QueueSize: 10, Retention: "1h", PruneInterval: "1m",
+	CallbackTimeout: "1s"}
+
+// mockExecutor replies with a fixed response
+type mockExecutor struct {
+	body   string
+	status int
+	err    error
+}
+
+func (me mockExecutor) Execute() (string, int, error) {
+	return me.body, me.status, me.err
+}
+
+// mockPublisher records the published messages
+type mockPublisher struct {
+	messages chan msgTypes.MessageEnvelope
+}
+
+func (mp mockPublisher) Publish(message msgTypes.MessageEnvelope, topic string) error {
+	mp.messages <- message
+	return nil
+}
+
+func newTestJobRunner(t *testing.T, info JobsInfo, publisher jobPublisher) (*jobRunner, context.CancelFunc) {
+	jr, err := newJobRunner(info, publisher, "results", logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	jr.start(&sync.WaitGroup{}, ctx)
+	return jr, cancel
+}
+
+// waitForJob polls a job until it is finished
+func waitForJob(t *testing.T, jr *jobRunner, id string) Job {
+	for i := 0; i < 100; i++ {
+		if job, ok := jr.job(id); ok && job.finished() {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not finish", id)
+	return Job{}
+}
+
+func TestJobRunner(t *testing.T) {
+	tests := []struct {
+		name     string
+		executor Executor
+		status   string
+		code     int
+		result   string
+	}{
+		{"Completed", mockExecutor{body: "ok", status: http.StatusOK}, JobCompleted, http.StatusOK, "ok"},
+		{"Failed", mockExecutor{body: "bad", status: http.StatusBadRequest}, JobFailed, http.StatusBadRequest, "bad"},
+		{"Error", mockExecutor{err: errors.New("unreachable")}, JobFailed, http.StatusInternalServerError, "unreachable"},
+	}
+	jr, cancel := newTestJobRunner(t, testJobsInfo, nil)
+	defer cancel()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accepted, err := jr.submit(Job{Device: "device", Command: "command"}, tt.executor)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if accepted.Id == "" || accepted.Status != JobPending {
+				t.Fatalf("Unexpected accepted job %v", accepted)
+			}
+
+			job := waitForJob(t, jr, accepted.Id)
+			if job.Status != tt.status || job.ResponseCode != tt.code || job.Result != tt.result {
+				t.Fatalf("Unexpected job %v", job)
+			}
+			if job.Started == 0 || job.Completed < job.Started {
+				t.Fatalf("Unexpected job times %v", job)
+			}
+		})
+	}
+}
+
+func TestJobRunnerCallback(t *testing.T) {
+	delivered := make(chan Job, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var job Job
+		json.NewDecoder(r.Body).Decode(&job)
+		delivered <- job
+	}))
+	defer ts.Close()
+
+	jr, cancel := newTestJobRunner(t, testJobsInfo, nil)
+	defer cancel()
+	accepted, err := jr.submit(Job{Callback: ts.URL}, mockExecutor{body: "ok", status: http.StatusOK})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case job := <-delivered:
+		if job.Id != accepted.Id || job.Status != JobCompleted || job.Result != "ok" {
+			t.Fatalf("Unexpected delivered job %v", job)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The job was not delivered to the callback")
+	}
+}
+
+func TestJobRunnerPublish(t *testing.T) {
+	publisher := mockPublisher{messages: make(chan msgTypes.MessageEnvelope, 1)}
+	jr, cancel := newTestJobRunner(t, testJobsInfo, publisher)
+	defer cancel()
+	accepted, err := jr.submit(Job{Publish: true, CorrelationId: "correlation"}, mockExecutor{status: http.StatusOK})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case message := <-publisher.messages:
+		var job Job
+		if err := json.Unmarshal(message.Payload, &job); err != nil || job.Id != accepted.Id {
+			t.Fatalf("Unexpected published job %s", string(message.Payload))
+		}
+		if message.CorrelationID != "correlation" {
+			t.Fatalf("Expected the correlation ID of the job, got '%s'", message.CorrelationID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The job was not published")
+	}
+}
+
+func TestJobRunnerPublishWithoutMessageBus(t *testing.T) {
+	jr, cancel := newTestJobRunner(t, testJobsInfo, nil)
+	defer cancel()
+	if _, err := jr.submit(Job{Publish: true}, mockExecutor{}); err == nil {
+		t.Fatal("Expected an error when no message bus is configured")
+	}
+}
+
+func TestJobRunnerCheckCallback(t *testing.T) {
+	info := testJobsInfo
+	info.CallbackHosts = []string{"app-service", "Gateway:8443"}
+	jr, err := newJobRunner(info, nil, "", logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		callback string
+		valid    bool
+	}{
+		{"Allowed host", "http://app-service:48095/jobs", true},
+		{"Allowed host and port", "https://gateway:8443/jobs", true},
+		{"Other port", "https://gateway:443/jobs", false},
+		{"Other host", "http://169.254.169.254/latest/meta-data", false},
+		{"Scheme", "file:///etc/passwd", false},
+		{"No host", "http:///jobs", false},
+		{"Relative", "/jobs", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := jr.checkCallback(tt.callback); (err == nil) != tt.valid {
+				t.Fatalf("Expected valid %t, got %v", tt.valid, err)
+			}
+		})
+	}
+
+	jr, _ = newJobRunner(testJobsInfo, nil, "", logger.NewMockClient())
+	if err := jr.checkCallback("http://localhost/jobs"); err != nil {
+		t.Fatalf("Expected any host to be allowed without CallbackHosts, got %v", err)
+	}
+}
+
+func TestJobRunnerCallbackRedirect(t *testing.T) {
+	redirected := make(chan struct{}, 1)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected <- struct{}{}
+	}))
+	defer target.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer ts.Close()
+
+	// only the host of the callback is allowed, not the one it redirects to
+	info := testJobsInfo
+	info.CallbackHosts = []string{ts.Listener.Addr().String()}
+	jr, err := newJobRunner(info, nil, "", logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	jr.deliver(Job{Id: "1", Callback: ts.URL})
+	select {
+	case <-redirected:
+		t.Fatal("Expected the redirect to a host which is not allowed to be refused")
+	default:
+	}
+}
+
+func TestJobRunnerQueueFull(t *testing.T) {
+	info := JobsInfo{Workers: 1, QueueSize: 1, Retention: "1h", PruneInterval: "1m", CallbackTimeout: "1s"}
+	jr, err := newJobRunner(info, nil, "", logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// the workers are not started so that the first job stays queued
+	if _, err := jr.submit(Job{}, mockExecutor{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := jr.submit(Job{}, mockExecutor{}); err != ErrJobQueueFull {
+		t.Fatalf("Expected ErrJobQueueFull, got %v", err)
+	}
+}
+
+func TestJobRunnerRetention(t *testing.T) {
+	info := JobsInfo{Workers: 1, QueueSize: 10, Retention: "50ms", PruneInterval: "10ms", CallbackTimeout: "1s"}
+	jr, cancel := newTestJobRunner(t, info, nil)
+	defer cancel()
+	first, _ := jr.submit(Job{}, mockExecutor{status: http.StatusOK})
+	waitForJob(t, jr, first.Id)
+
+	// the job is removed by a later pruning without any other job being submitted
+	for i := 0; i < 100; i++ {
+		if _, ok := jr.job(first.Id); !ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Expected the finished job to be expired")
+}
+
+func TestNewJobRunnerInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		info JobsInfo
+	}{
+		{"Retention", JobsInfo{Workers: 1, Retention: "soon", PruneInterval: "1m", CallbackTimeout: "1s"}},
+		{"PruneInterval", JobsInfo{Workers: 1, Retention: "1h", PruneInterval: "0s", CallbackTimeout: "1s"}},
+		{"CallbackTimeout", JobsInfo{Workers: 1, Retention: "1h", PruneInterval: "1m", CallbackTimeout: "10"}},
+		{"Workers", JobsInfo{Retention: "1h", PruneInterval: "1m", CallbackTimeout: "1s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newJobRunner(tt.info, nil, "", logger.NewMockClient()); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestRestGetJob(t *testing.T) {
+	jr, cancel := newTestJobRunner(t, testJobsInfo, nil)
+	defer cancel()
+	accepted, _ := jr.submit(Job{}, mockExecutor{status: http.StatusOK})
+
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{"Found", accepted.Id, http.StatusOK},
+		{"Not found", "unknown", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/"+JOB+"/"+tt.id, nil), map[string]string{ID: tt.id})
+			rr := httptest.NewRecorder()
+			restGetJob(rr, req, logger.NewMockClient(), jr)
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package models
+
+// Sources of the caller's identity recorded in an audit
+const (
+	// CallerSourceHeader is a request header set by the API gateway.
+	CallerSourceHeader = "header"
+	// CallerSourceToken is the bearer JWT, whose signature was not verified.
+	CallerSourceToken = "token"
+	// CallerSourceVerifiedToken is the bearer JWT, whose signature was verified with the configured key.
+	CallerSourceVerifiedToken = "verifiedToken"
+)
+
+// Audit records a command issued to a device through core-command, whether its device service was called or the
+// command was rejected beforehand, such as for a locked device or invalid parameters.
+type Audit struct {
+	Id          string `json:"id"`
+	Created     int64  `json:"created"`
+	Device      string `json:"device"`
+	Command     string `json:"command"`
+	Method      string `json:"method"`
+	Body        string `json:"body,omitempty"`
+	QueryParams string `json:"queryParams,omitempty"`
+	Caller      string `json:"caller,omitempty"`
+	// CallerSource tells where Caller was read from, one of the CallerSource constants.
+	CallerSource  string `json:"callerSource,omitempty"`
+	CorrelationId string `json:"correlationId,omitempty"`
+	StatusCode    int    `json:"statusCode"`
+	// Latency is the time in milliseconds from the command request to the device service's reply, or to the
+	// rejection of the command.
+	Latency  int64  `json:"latency"`
+	Response string `json:"response,omitempty"`
+	// Truncated tells whether Response holds only the beginning of the reply.
+	Truncated bool `json:"truncated,omitempty"`
+}
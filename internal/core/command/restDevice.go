@@ -18,13 +18,17 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/coredata"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/gorilla/mux"
 
 	"github.com/edgexfoundry/edgex-go/internal/core/command/interfaces"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation"
 )
 
 func restGetDeviceCommandByCommandID(
@@ -32,8 +36,9 @@ func restGetDeviceCommandByCommandID(
 	r *http.Request,
 	loggingClient logger.LoggingClient,
 	dbClient interfaces.DBClient,
-	deviceClient metadata.DeviceClient) {
-	issueDeviceCommand(w, r, false, loggingClient, dbClient, deviceClient)
+	deviceClient metadata.DeviceClient,
+	vdc coredata.ValueDescriptorClient) {
+	issueDeviceCommand(w, r, false, loggingClient, dbClient, deviceClient, vdc)
 }
 
 func restPutDeviceCommandByCommandID(
@@ -41,8 +46,9 @@ func restPutDeviceCommandByCommandID(
 	r *http.Request,
 	loggingClient logger.LoggingClient,
 	dbClient interfaces.DBClient,
-	deviceClient metadata.DeviceClient) {
-	issueDeviceCommand(w, r, true, loggingClient, dbClient, deviceClient)
+	deviceClient metadata.DeviceClient,
+	vdc coredata.ValueDescriptorClient) {
+	issueDeviceCommand(w, r, true, loggingClient, dbClient, deviceClient, vdc)
 }
 
 func issueDeviceCommand(
@@ -51,7 +57,8 @@ func issueDeviceCommand(
 	isPutCommand bool,
 	loggingClient logger.LoggingClient,
 	dbClient interfaces.DBClient,
-	deviceClient metadata.DeviceClient) {
+	deviceClient metadata.DeviceClient,
+	vdc coredata.ValueDescriptorClient) {
 	defer r.Body.Close()
 
 	vars := mux.Vars(r)
@@ -63,19 +70,39 @@ func issueDeviceCommand(
 		return
 	}
 
+	r = r.WithContext(audits.context(r, string(b)))
 	ctx := r.Context()
+	if isAsyncRequest(r) {
+		d, c, status, err := deviceCommandByDeviceID(did, cid, ctx, loggingClient, dbClient, deviceClient)
+		if err != nil {
+			recordAudit(ctx, auditName(d.Name, did), auditName(c.Name, cid), status, err.Error())
+			http.Error(w, err.Error(), status)
+			return
+		}
+		submitJob(w, r, d, c, string(b), isPutCommand, loggingClient, vdc, jobs)
+		return
+	}
+
+	query := r.URL.RawQuery
+	if !isPutCommand {
+		var bypass bool
+		query, bypass = cacheBypass(query)
+		ctx = responses.context(ctx, bypass)
+	}
 	body, status := commandByDeviceID(
 		did,
 		cid,
 		string(b),
-		r.URL.RawQuery,
+		query,
 		isPutCommand,
 		ctx,
 		loggingClient,
 		dbClient,
-		deviceClient)
+		deviceClient,
+		vdc)
+	writeCacheHeaders(w, ctx)
 	if status != http.StatusOK {
-		http.Error(w, body, status)
+		writeCommandError(w, body, status)
 	} else {
 		if len(body) > 0 {
 			w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
@@ -89,8 +116,9 @@ func restGetDeviceCommandByNames(
 	r *http.Request,
 	loggingClient logger.LoggingClient,
 	dbClient interfaces.DBClient,
-	deviceClient metadata.DeviceClient) {
-	issueDeviceCommandByNames(w, r, false, loggingClient, dbClient, deviceClient)
+	deviceClient metadata.DeviceClient,
+	vdc coredata.ValueDescriptorClient) {
+	issueDeviceCommandByNames(w, r, false, loggingClient, dbClient, deviceClient, vdc)
 }
 
 func restPutDeviceCommandByNames(
@@ -98,8 +126,9 @@ func restPutDeviceCommandByNames(
 	r *http.Request,
 	loggingClient logger.LoggingClient,
 	dbClient interfaces.DBClient,
-	deviceClient metadata.DeviceClient) {
-	issueDeviceCommandByNames(w, r, true, loggingClient, dbClient, deviceClient)
+	deviceClient metadata.DeviceClient,
+	vdc coredata.ValueDescriptorClient) {
+	issueDeviceCommandByNames(w, r, true, loggingClient, dbClient, deviceClient, vdc)
 }
 
 func issueDeviceCommandByNames(
@@ -108,33 +137,54 @@ func issueDeviceCommandByNames(
 	isPutCommand bool,
 	loggingClient logger.LoggingClient,
 	dbClient interfaces.DBClient,
-	deviceClient metadata.DeviceClient) {
+	deviceClient metadata.DeviceClient,
+	vdc coredata.ValueDescriptorClient) {
 	defer r.Body.Close()
 
 	vars := mux.Vars(r)
 	dn := vars[NAME]
 	cn := vars[COMMANDNAME]
 
-	ctx := r.Context()
-
 	b, err := ioutil.ReadAll(r.Body)
 	if b == nil && err != nil {
 		loggingClient.Error(err.Error())
 		return
 	}
+
+	r = r.WithContext(audits.context(r, string(b)))
+	ctx := r.Context()
+	if isAsyncRequest(r) {
+		d, c, status, err := deviceCommandByNames(dn, cn, ctx, loggingClient, dbClient, deviceClient)
+		if err != nil {
+			recordAudit(ctx, dn, cn, status, err.Error())
+			http.Error(w, err.Error(), status)
+			return
+		}
+		submitJob(w, r, d, c, string(b), isPutCommand, loggingClient, vdc, jobs)
+		return
+	}
+
+	query := r.URL.RawQuery
+	if !isPutCommand {
+		var bypass bool
+		query, bypass = cacheBypass(query)
+		ctx = responses.context(ctx, bypass)
+	}
 	body, status := commandByNames(
 		dn,
 		cn,
 		string(b),
-		r.URL.RawQuery,
+		query,
 		isPutCommand,
 		ctx,
 		loggingClient,
 		dbClient,
-		deviceClient)
+		deviceClient,
+		vdc)
 
+	writeCacheHeaders(w, ctx)
 	if status != http.StatusOK {
-		http.Error(w, body, status)
+		writeCommandError(w, body, status)
 	} else {
 		if len(body) > 0 {
 			w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
@@ -143,6 +193,149 @@ func issueDeviceCommandByNames(
 	}
 }
 
+// writeCommandError replies with the error of a command. JSON errors, such as the parameter validation errors or the
+// structured errors of the device services, are replied as JSON.
+func writeCommandError(w http.ResponseWriter, body string, status int) {
+	if !json.Valid([]byte(body)) {
+		http.Error(w, body, status)
+		return
+	}
+	w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+	w.WriteHeader(status)
+	w.Write([]byte(body))
+}
+
+// restIssueBulkCommand issues a command to the devices selected by a label, profile name or service name. The reply
+// holds the outcome for each device, with a 207 status when the command did not succeed on all of them.
+func restIssueBulkCommand(
+	w http.ResponseWriter,
+	r *http.Request,
+	selector string,
+	loggingClient logger.LoggingClient,
+	dbClient interfaces.DBClient,
+	deviceClient metadata.DeviceClient,
+	vdc coredata.ValueDescriptorClient) {
+	defer r.Body.Close()
+
+	vars := mux.Vars(r)
+	cn := vars[COMMANDNAME]
+	b, err := ioutil.ReadAll(r.Body)
+	if b == nil && err != nil {
+		loggingClient.Error(err.Error())
+		return
+	}
+
+	r = r.WithContext(audits.context(r, string(b)))
+	ctx := r.Context()
+	devices, status, err := devicesBySelector(selector, vars[selector], ctx, deviceClient)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if len(devices) == 0 {
+		http.Error(w, "No devices found for "+selector+" "+vars[selector], http.StatusNotFound)
+		return
+	}
+
+	response := bulkCommand(
+		devices,
+		cn,
+		string(b),
+		r.URL.RawQuery,
+		r.Method == http.MethodPut,
+		Configuration.Bulk.Concurrency,
+		ctx,
+		loggingClient,
+		dbClient,
+		vdc)
+
+	w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+	if response.Succeeded != len(devices) {
+		w.WriteHeader(http.StatusMultiStatus)
+	}
+	json.NewEncoder(w).Encode(&response)
+}
+
+// isAsyncRequest tells whether the command is to be executed asynchronously, as requested with the async parameter.
+func isAsyncRequest(r *http.Request) bool {
+	async, _ := strconv.ParseBool(r.URL.Query().Get(ASYNC))
+	return async
+}
+
+// submitJob queues the execution of a command and replies with the accepted job. The callback and publish
+// parameters of the request select how the result is delivered, the remaining parameters being passed on to the
+// device service.
+func submitJob(
+	w http.ResponseWriter,
+	r *http.Request,
+	device contract.Device,
+	command contract.Command,
+	body string,
+	isPutCommand bool,
+	loggingClient logger.LoggingClient,
+	vdc valueDescriptorReader,
+	jobs *jobRunner) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	job := Job{
+		Device:        device.Name,
+		Command:       command.Name,
+		Method:        r.Method,
+		Callback:      query.Get(CALLBACK),
+		CorrelationId: correlation.FromContext(ctx),
+	}
+	job.Publish, _ = strconv.ParseBool(query.Get(PUBLISH))
+	if job.Callback != "" {
+		if err := jobs.checkCallback(job.Callback); err != nil {
+			loggingClient.Error(err.Error())
+			recordAudit(ctx, device.Name, command.Name, http.StatusBadRequest, "Invalid callback URL: "+err.Error())
+			http.Error(w, "Invalid callback URL: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	query.Del(ASYNC)
+	query.Del(DSBYPASSCACHE)
+	query.Del(CALLBACK)
+	query.Del(PUBLISH)
+	ex, err := newCommandExecutor(device, command, body, query.Encode(), isPutCommand, ctx, loggingClient, vdc)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		body, status := commandErrorResponse(err)
+		writeCommandError(w, body, status)
+		return
+	}
+
+	job, err = jobs.submit(job, ex)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		status := http.StatusBadRequest
+		if err == ErrJobQueueFull {
+			status = http.StatusServiceUnavailable
+		}
+		recordAudit(ctx, device.Name, command.Name, status, err.Error())
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(&job)
+}
+
+func restGetJob(w http.ResponseWriter, r *http.Request, loggingClient logger.LoggingClient, jobs *jobRunner) {
+	id := mux.Vars(r)[ID]
+	job, ok := jobs.job(id)
+	if !ok {
+		loggingClient.Error("Command job not found: " + id)
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+	json.NewEncoder(w).Encode(&job)
+}
+
 func restGetCommandsByDeviceID(
 	w http.ResponseWriter,
 	r *http.Request,
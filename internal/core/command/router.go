@@ -51,6 +51,8 @@ func LoadRestRoutes(dic *di.Container) *mux.Router {
 	b := r.PathPrefix(clients.ApiBase).Subrouter()
 
 	loadDeviceRoutes(b, dic)
+	loadJobRoutes(b, dic)
+	loadAuditRoutes(b, dic)
 
 	r.Use(correlation.ManageHeader)
 	r.Use(correlation.OnResponseComplete)
@@ -87,7 +89,8 @@ func loadDeviceRoutes(b *mux.Router, dic *di.Container) {
 			r,
 			bootstrapContainer.LoggingClientFrom(dic.Get),
 			bootstrapContainer.DBClientFrom(dic.Get),
-			container.MetadataDeviceClientFrom(dic.Get))
+			container.MetadataDeviceClientFrom(dic.Get),
+			container.CoreDataValueDescriptorClientFrom(dic.Get))
 	}).Methods(http.MethodGet)
 	d.HandleFunc("/{"+ID+"}/"+COMMAND+"/{"+COMMANDID+"}", func(w http.ResponseWriter, r *http.Request) {
 		restPutDeviceCommandByCommandID(
@@ -95,9 +98,25 @@ func loadDeviceRoutes(b *mux.Router, dic *di.Container) {
 			r,
 			bootstrapContainer.LoggingClientFrom(dic.Get),
 			bootstrapContainer.DBClientFrom(dic.Get),
-			container.MetadataDeviceClientFrom(dic.Get))
+			container.MetadataDeviceClientFrom(dic.Get),
+			container.CoreDataValueDescriptorClientFrom(dic.Get))
 	}).Methods(http.MethodPut)
 
+	// /api/<version>/device/{label|profile|service}/{value}/command/{commandname}
+	for _, selector := range []string{LABEL, PROFILE, SERVICE} {
+		selector := selector
+		d.HandleFunc("/"+selector+"/{"+selector+"}/"+COMMAND+"/{"+COMMANDNAME+"}", func(w http.ResponseWriter, r *http.Request) {
+			restIssueBulkCommand(
+				w,
+				r,
+				selector,
+				bootstrapContainer.LoggingClientFrom(dic.Get),
+				bootstrapContainer.DBClientFrom(dic.Get),
+				container.MetadataDeviceClientFrom(dic.Get),
+				container.CoreDataValueDescriptorClientFrom(dic.Get))
+		}).Methods(http.MethodGet, http.MethodPut)
+	}
+
 	// /api/<version>/device/name
 	dn := d.PathPrefix("/" + NAME).Subrouter()
 
@@ -115,7 +134,8 @@ func loadDeviceRoutes(b *mux.Router, dic *di.Container) {
 			r,
 			bootstrapContainer.LoggingClientFrom(dic.Get),
 			bootstrapContainer.DBClientFrom(dic.Get),
-			container.MetadataDeviceClientFrom(dic.Get))
+			container.MetadataDeviceClientFrom(dic.Get),
+			container.CoreDataValueDescriptorClientFrom(dic.Get))
 	}).Methods(http.MethodGet)
 	dn.HandleFunc("/{"+NAME+"}/"+COMMAND+"/{"+COMMANDNAME+"}", func(w http.ResponseWriter, r *http.Request) {
 		restPutDeviceCommandByNames(
@@ -123,10 +143,29 @@ func loadDeviceRoutes(b *mux.Router, dic *di.Container) {
 			r,
 			bootstrapContainer.LoggingClientFrom(dic.Get),
 			bootstrapContainer.DBClientFrom(dic.Get),
-			container.MetadataDeviceClientFrom(dic.Get))
+			container.MetadataDeviceClientFrom(dic.Get),
+			container.CoreDataValueDescriptorClientFrom(dic.Get))
 	}).Methods(http.MethodPut)
 }
 
+func loadJobRoutes(b *mux.Router, dic *di.Container) {
+	// /api/<version>/job
+	b.HandleFunc("/"+JOB+"/{"+ID+"}", func(w http.ResponseWriter, r *http.Request) {
+		restGetJob(w, r, bootstrapContainer.LoggingClientFrom(dic.Get), jobs)
+	}).Methods(http.MethodGet)
+}
+
+func loadAuditRoutes(b *mux.Router, dic *di.Container) {
+	// /api/<version>/audit
+	b.HandleFunc("/"+AUDIT, func(w http.ResponseWriter, r *http.Request) {
+		restGetCommandAudits(
+			w,
+			r,
+			bootstrapContainer.LoggingClientFrom(dic.Get),
+			bootstrapContainer.DBClientFrom(dic.Get))
+	}).Methods(http.MethodGet)
+}
+
 // Test if the service is working
 func pingHandler(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set(clients.ContentType, clients.ContentTypeText)
@@ -0,0 +1,330 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/command/errors"
+)
+
+// valueDescriptorReader reads the value descriptors of the parameters, as coredata.ValueDescriptorClient does.
+type valueDescriptorReader interface {
+	ValueDescriptorForName(name string, ctx context.Context) (contract.ValueDescriptor, error)
+}
+
+// Kinds of values checked by the parameter validation
+const (
+	kindBool  = "bool"
+	kindInt   = "int"
+	kindUint  = "uint"
+	kindFloat = "float"
+)
+
+// validatePutCommand checks the body of a PUT command, a JSON object of the parameters' values, against the parameter
+// names of the command and the types and ranges of the parameters' value descriptors. The value descriptors are read
+// from core-data, falling back to the device resources of the device's profile for the parameters core-data has no
+// value descriptor for. Commands declaring no parameter names are not checked. An error other than
+// ErrInvalidParameters is returned when core-data cannot be read.
+func validatePutCommand(
+	device contract.Device,
+	command contract.Command,
+	body string,
+	ctx context.Context,
+	vdr valueDescriptorReader,
+	loggingClient logger.LoggingClient) error {
+	if len(command.Put.ParameterNames) == 0 || strings.TrimSpace(body) == "" {
+		return nil
+	}
+
+	invalid := errors.ErrInvalidParameters{Device: device.Name, Command: command.Name}
+	var values map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		invalid.Parameters = append(invalid.Parameters, errors.ParameterError{
+			Reason:  errors.ReasonInvalidBody,
+			Message: "the body is not a JSON object of parameters: " + err.Error(),
+		})
+		return invalid
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !isParameterName(command, name) {
+			invalid.Parameters = append(invalid.Parameters, errors.ParameterError{
+				Parameter: name,
+				Reason:    errors.ReasonUnknownParameter,
+				Message: fmt.Sprintf("%s is not a parameter of the command, expected one of %s",
+					name, strings.Join(command.Put.ParameterNames, ", ")),
+			})
+			continue
+		}
+
+		vd, ok, err := parameterDescriptor(device, name, ctx, vdr, loggingClient)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if perr := validateParameter(name, values[name], vd); perr != nil {
+			invalid.Parameters = append(invalid.Parameters, *perr)
+		}
+	}
+
+	if len(invalid.Parameters) > 0 {
+		return invalid
+	}
+	return nil
+}
+
+func isParameterName(command contract.Command, name string) bool {
+	for _, n := range command.Put.ParameterNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parameterDescriptor returns the value descriptor of a parameter, from core-data or, when core-data has none, built
+// from the device resource of the same name. It fails when core-data cannot be read.
+func parameterDescriptor(
+	device contract.Device,
+	name string,
+	ctx context.Context,
+	vdr valueDescriptorReader,
+	loggingClient logger.LoggingClient) (contract.ValueDescriptor, bool, error) {
+	if vdr != nil {
+		vd, found, err := descriptors.valueDescriptor(vdr, name, ctx)
+		if err != nil {
+			return contract.ValueDescriptor{}, false,
+				fmt.Errorf("failed to read the value descriptor of parameter %s: %s", name, err.Error())
+		}
+		if found {
+			return vd, true, nil
+		}
+		loggingClient.Debug(fmt.Sprintf("No value descriptor %s, using the device profile", name))
+	}
+
+	for _, dr := range device.Profile.DeviceResources {
+		if dr.Name == name {
+			return contract.From(dr), true, nil
+		}
+	}
+	return contract.ValueDescriptor{}, false, nil
+}
+
+// descriptors caches the value descriptors of the parameters, nil when they are read each time
+var descriptors *descriptorCache
+
+// The value descriptors of the parameters, or their absence, are cached for descriptorTTL, up to maxDescriptors of
+// them.
+const (
+	descriptorTTL  = time.Minute
+	maxDescriptors = 1000
+)
+
+// descriptorCache keeps the value descriptors of the parameters read from core-data, and which parameters have none,
+// so that core-data is not called for each parameter of each PUT command, nor for each device of a bulk command.
+type descriptorCache struct {
+	mutex   sync.Mutex
+	entries map[string]cachedDescriptor
+}
+
+type cachedDescriptor struct {
+	vd      contract.ValueDescriptor
+	found   bool
+	expires time.Time
+}
+
+func newDescriptorCache() *descriptorCache {
+	return &descriptorCache{entries: make(map[string]cachedDescriptor)}
+}
+
+// valueDescriptor returns the value descriptor of the name and whether core-data has one, which is only known to be
+// missing when core-data answers 404. Other errors are returned and not cached. A nil cache reads the value descriptor
+// each time.
+func (dc *descriptorCache) valueDescriptor(
+	vdr valueDescriptorReader,
+	name string,
+	ctx context.Context) (contract.ValueDescriptor, bool, error) {
+	now := time.Now()
+	if dc != nil {
+		dc.mutex.Lock()
+		e, ok := dc.entries[name]
+		dc.mutex.Unlock()
+		if ok && now.Before(e.expires) {
+			return e.vd, e.found, nil
+		}
+	}
+
+	vd, err := vdr.ValueDescriptorForName(name, ctx)
+	if err != nil {
+		chk, ok := err.(types.ErrServiceClient)
+		if !ok || chk.StatusCode != http.StatusNotFound {
+			return contract.ValueDescriptor{}, false, err
+		}
+	}
+	found := err == nil
+
+	if dc != nil {
+		dc.put(name, cachedDescriptor{vd: vd, found: found, expires: now.Add(descriptorTTL)})
+	}
+	return vd, found, nil
+}
+
+// put caches a value descriptor, evicting the expired ones and then the first to expire when the cache is full.
+func (dc *descriptorCache) put(name string, e cachedDescriptor) {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	if _, ok := dc.entries[name]; !ok && len(dc.entries) >= maxDescriptors {
+		now := time.Now()
+		var first string
+		var firstExpires time.Time
+		for n, one := range dc.entries {
+			if !now.Before(one.expires) {
+				delete(dc.entries, n)
+			} else if firstExpires.IsZero() || one.expires.Before(firstExpires) {
+				first, firstExpires = n, one.expires
+			}
+		}
+		if len(dc.entries) >= maxDescriptors {
+			delete(dc.entries, first)
+		}
+	}
+	dc.entries[name] = e
+}
+
+// validateParameter checks the type and range of a parameter's value. The values of the string and binary types are
+// only checked to be scalars.
+func validateParameter(name string, value interface{}, vd contract.ValueDescriptor) *errors.ParameterError {
+	kind, bits := valueKind(vd.Type)
+	text, ok := parameterText(value)
+	if ok && kind != "" {
+		var err error
+		switch kind {
+		case kindBool:
+			_, err = strconv.ParseBool(text)
+		case kindInt:
+			_, err = strconv.ParseInt(text, 10, bits)
+		case kindUint:
+			_, err = strconv.ParseUint(text, 10, bits)
+		case kindFloat:
+			_, err = strconv.ParseFloat(text, bits)
+		}
+		if numErr, isNumErr := err.(*strconv.NumError); isNumErr && numErr.Err == strconv.ErrRange {
+			return &errors.ParameterError{
+				Parameter: name,
+				Reason:    errors.ReasonOutOfRange,
+				Message:   fmt.Sprintf("%s: %s is out of the range of type %s", name, text, vd.Type),
+			}
+		}
+		ok = err == nil
+	}
+	if !ok {
+		return &errors.ParameterError{
+			Parameter: name,
+			Reason:    errors.ReasonInvalidType,
+			Message:   fmt.Sprintf("%s: %v is not a valid %s value", name, value, vd.Type),
+		}
+	}
+
+	if kind == "" || kind == kindBool {
+		return nil
+	}
+	n, _ := strconv.ParseFloat(text, 64)
+	if min, ok := bound(vd.Min); ok && n < min {
+		return &errors.ParameterError{
+			Parameter: name,
+			Reason:    errors.ReasonOutOfRange,
+			Message:   fmt.Sprintf("%s: %s is below the minimum %v", name, text, vd.Min),
+		}
+	}
+	if max, ok := bound(vd.Max); ok && n > max {
+		return &errors.ParameterError{
+			Parameter: name,
+			Reason:    errors.ReasonOutOfRange,
+			Message:   fmt.Sprintf("%s: %s is above the maximum %v", name, text, vd.Max),
+		}
+	}
+	return nil
+}
+
+// valueKind returns the kind and size in bits of the values of a value descriptor type, such as Int16 or Float32,
+// the legacy one letter types included. The kind is empty for the types which are not checked.
+func valueKind(valueType string) (string, int) {
+	t := strings.ToLower(valueType)
+	switch t {
+	case "b":
+		return kindBool, 0
+	case "i":
+		return kindInt, 64
+	case "f":
+		return kindFloat, 64
+	}
+
+	for _, kind := range []string{kindBool, kindUint, kindInt, kindFloat} {
+		if strings.HasPrefix(t, kind) {
+			bits, err := strconv.Atoi(t[len(kind):])
+			if err != nil {
+				bits = 64
+			}
+			return kind, bits
+		}
+	}
+	return "", 0
+}
+
+// parameterText returns the text of a parameter's value, the values being either JSON strings, numbers or booleans.
+func parameterText(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// bound returns a minimum or maximum of a value descriptor, unset when empty.
+func bound(v interface{}) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	b, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(v)), 64)
+	return b, err == nil
+}
@@ -0,0 +1,236 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/command/errors"
+)
+
+// mockValueDescriptorReader returns the value descriptors it holds, a 404 error for the others
+type mockValueDescriptorReader map[string]contract.ValueDescriptor
+
+func (m mockValueDescriptorReader) ValueDescriptorForName(name string, ctx context.Context) (contract.ValueDescriptor, error) {
+	vd, ok := m[name]
+	if !ok {
+		return contract.ValueDescriptor{}, types.NewErrServiceClient(http.StatusNotFound, []byte{})
+	}
+	return vd, nil
+}
+
+var testValueDescriptors = mockValueDescriptorReader{
+	"setpoint": {Name: "setpoint", Type: "Float32", Min: "5", Max: 30.0},
+	"enabled":  {Name: "enabled", Type: "Bool"},
+	"mode":     {Name: "mode", Type: "String"},
+	"count":    {Name: "count", Type: "Uint8", Min: "", Max: nil},
+}
+
+var validationDevice = contract.Device{
+	Name: "thermostat",
+	Profile: contract.DeviceProfile{
+		DeviceResources: []contract.DeviceResource{{
+			Name: "offset",
+			Properties: contract.ProfileProperty{
+				Value: contract.PropertyValue{Type: "Int16", Minimum: "-10", Maximum: "10"},
+			},
+		}},
+	},
+}
+
+var validationCommand = contract.Command{
+	Name: "settings",
+	Put:  contract.Put{ParameterNames: []string{"setpoint", "enabled", "mode", "count", "offset", "free"}},
+}
+
+func TestValidatePutCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		parameters []errors.ParameterError
+	}{
+		{"Valid strings", `{"setpoint":"20.5","enabled":"true","mode":"eco","count":"3","offset":"-2"}`, nil},
+		{"Valid JSON values", `{"setpoint":20,"enabled":false,"count":255}`, nil},
+		{"Empty body", ``, nil},
+		{"Undescribed parameter", `{"free":"anything"}`, nil},
+		{"Invalid body", `["setpoint"]`, []errors.ParameterError{{Reason: errors.ReasonInvalidBody}}},
+		{"Unknown parameter", `{"humidity":"40"}`, []errors.ParameterError{
+			{Parameter: "humidity", Reason: errors.ReasonUnknownParameter}}},
+		{"Invalid float", `{"setpoint":"warm"}`, []errors.ParameterError{
+			{Parameter: "setpoint", Reason: errors.ReasonInvalidType}}},
+		{"Invalid bool", `{"enabled":"yes"}`, []errors.ParameterError{
+			{Parameter: "enabled", Reason: errors.ReasonInvalidType}}},
+		{"Invalid value", `{"mode":{"eco":true}}`, []errors.ParameterError{
+			{Parameter: "mode", Reason: errors.ReasonInvalidType}}},
+		{"Below minimum", `{"setpoint":"4.5"}`, []errors.ParameterError{
+			{Parameter: "setpoint", Reason: errors.ReasonOutOfRange}}},
+		{"Above maximum", `{"setpoint":31}`, []errors.ParameterError{
+			{Parameter: "setpoint", Reason: errors.ReasonOutOfRange}}},
+		{"Out of type range", `{"count":"256"}`, []errors.ParameterError{
+			{Parameter: "count", Reason: errors.ReasonOutOfRange}}},
+		{"Negative unsigned", `{"count":"-1"}`, []errors.ParameterError{
+			{Parameter: "count", Reason: errors.ReasonInvalidType}}},
+		{"Profile range", `{"offset":"11"}`, []errors.ParameterError{
+			{Parameter: "offset", Reason: errors.ReasonOutOfRange}}},
+		{"Several errors", `{"setpoint":"100","humidity":"40"}`, []errors.ParameterError{
+			{Parameter: "humidity", Reason: errors.ReasonUnknownParameter},
+			{Parameter: "setpoint", Reason: errors.ReasonOutOfRange}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePutCommand(validationDevice, validationCommand, tt.body, context.Background(),
+				testValueDescriptors, logger.NewMockClient())
+			if tt.parameters == nil {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			invalid, ok := err.(errors.ErrInvalidParameters)
+			if !ok {
+				t.Fatalf("Expected ErrInvalidParameters, got %v", err)
+			}
+			if invalid.Device != validationDevice.Name || invalid.Command != validationCommand.Name {
+				t.Fatalf("Unexpected device and command %v", invalid)
+			}
+			if len(invalid.Parameters) != len(tt.parameters) {
+				t.Fatalf("Expected %d parameter errors, got %v", len(tt.parameters), invalid.Parameters)
+			}
+			for i, p := range tt.parameters {
+				actual := invalid.Parameters[i]
+				if actual.Parameter != p.Parameter || actual.Reason != p.Reason || actual.Message == "" {
+					t.Errorf("Expected %v, got %v", p, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePutCommandWithoutParameterNames(t *testing.T) {
+	err := validatePutCommand(validationDevice, contract.Command{}, `{"anything":"goes"}`, context.Background(),
+		testValueDescriptors, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestCommandByDeviceInvalidParameters(t *testing.T) {
+	called := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer ts.Close()
+
+	device := bulkDevice(t, "thermostat", ts.URL, contract.Unlocked)
+	body, status := commandByDevice(device, validationCommand, `{"setpoint":"hot"}`, "", true, context.Background(),
+		logger.NewMockClient(), testValueDescriptors)
+	if status != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, status)
+	}
+	if called {
+		t.Fatal("Expected the device service not to be called")
+	}
+
+	var invalid errors.ErrInvalidParameters
+	if err := json.Unmarshal([]byte(body), &invalid); err != nil || len(invalid.Parameters) != 1 {
+		t.Fatalf("Expected a JSON validation error, got %s", body)
+	}
+}
+
+// countingValueDescriptorReader counts the value descriptors read, failing with err when set
+type countingValueDescriptorReader struct {
+	mockValueDescriptorReader
+	err   error
+	reads int
+}
+
+func (c *countingValueDescriptorReader) ValueDescriptorForName(name string, ctx context.Context) (contract.ValueDescriptor, error) {
+	c.reads++
+	if c.err != nil {
+		return contract.ValueDescriptor{}, c.err
+	}
+	return c.mockValueDescriptorReader.ValueDescriptorForName(name, ctx)
+}
+
+func TestCommandByDeviceCoreDataUnavailable(t *testing.T) {
+	called := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer ts.Close()
+
+	// the device profile is not used in place of core-data when core-data fails
+	vdr := &countingValueDescriptorReader{err: types.NewErrServiceClient(http.StatusServiceUnavailable, []byte{})}
+	device := bulkDevice(t, "thermostat", ts.URL, contract.Unlocked)
+	device.Profile.DeviceResources = validationDevice.Profile.DeviceResources
+	_, status := commandByDevice(device, validationCommand, `{"offset":"2"}`, "", true, context.Background(),
+		logger.NewMockClient(), vdr)
+	if status != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d, got %d", http.StatusInternalServerError, status)
+	}
+	if called {
+		t.Fatal("Expected the device service not to be called")
+	}
+}
+
+func TestDescriptorCache(t *testing.T) {
+	dc := newDescriptorCache()
+	vdr := &countingValueDescriptorReader{mockValueDescriptorReader: testValueDescriptors}
+
+	for i := 0; i < 2; i++ {
+		if vd, found, err := dc.valueDescriptor(vdr, "setpoint", context.Background()); err != nil || !found ||
+			vd.Name != "setpoint" {
+			t.Fatalf("Expected the value descriptor, got %v %v %v", vd, found, err)
+		}
+		if _, found, err := dc.valueDescriptor(vdr, "offset", context.Background()); err != nil || found {
+			t.Fatalf("Expected no value descriptor, got %v %v", found, err)
+		}
+	}
+	if vdr.reads != 2 {
+		t.Fatalf("Expected each value descriptor to be read once, got %d reads", vdr.reads)
+	}
+
+	// failures are not cached
+	vdr.err = types.NewErrServiceClient(http.StatusInternalServerError, []byte{})
+	for i := 0; i < 2; i++ {
+		if _, _, err := dc.valueDescriptor(vdr, "count", context.Background()); err == nil {
+			t.Fatal("Expected the error reading the value descriptor")
+		}
+	}
+	if vdr.reads != 4 {
+		t.Fatalf("Expected the failed reads to be retried, got %d reads", vdr.reads)
+	}
+}
+
+func TestDescriptorCacheBounded(t *testing.T) {
+	dc := newDescriptorCache()
+	for i := 0; i < maxDescriptors+10; i++ {
+		dc.put(strconv.Itoa(i), cachedDescriptor{found: true, expires: time.Now().Add(descriptorTTL)})
+	}
+	if len(dc.entries) != maxDescriptors {
+		t.Fatalf("Expected %d cached value descriptors, got %d", maxDescriptors, len(dc.entries))
+	}
+}
@@ -0,0 +1,131 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package data
+
+import (
+	"fmt"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// Aggregation functions which can be applied to the readings of a bucket
+const (
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+	AggregateAvg   = "avg"
+	AggregateSum   = "sum"
+	AggregateCount = "count"
+	AggregateFirst = "first"
+	AggregateLast  = "last"
+)
+
+// ReadingAggregate is the value of an aggregation function over the readings created in [Start, End)
+type ReadingAggregate struct {
+	Start int64   `json:"start"`
+	End   int64   `json:"end"`
+	Count int     `json:"count"`
+	Value float64 `json:"value"`
+}
+
+// aggregateValue returns the result of the aggregation function for the bucket
+func aggregateValue(function string, b db.ReadingBucket) (float64, error) {
+	switch function {
+	case AggregateMin:
+		return b.Min, nil
+	case AggregateMax:
+		return b.Max, nil
+	case AggregateAvg:
+		return b.Sum / float64(b.Count), nil
+	case AggregateSum:
+		return b.Sum, nil
+	case AggregateCount:
+		return float64(b.Count), nil
+	case AggregateFirst:
+		return b.First, nil
+	case AggregateLast:
+		return b.Last, nil
+	default:
+		return 0, errors.NewErrInvalidAggregation(fmt.Sprintf("unknown function '%s'", function))
+	}
+}
+
+// isNumericValueDescriptor returns true for the value descriptor types which can be aggregated, see validate.go
+func isNumericValueDescriptor(t string) bool {
+	return t == "F" || t == "I"
+}
+
+// aggregateReadings applies the aggregation function to the readings of the device for the value descriptor
+// created between start and end, grouped in buckets of width milliseconds. Empty buckets are omitted.
+func aggregateReadings(
+	device string,
+	name string,
+	start int64,
+	end int64,
+	width int64,
+	function string,
+	loggingClient logger.LoggingClient) ([]ReadingAggregate, error) {
+
+	if _, err := aggregateValue(function, db.ReadingBucket{Count: 1}); err != nil {
+		return nil, err
+	}
+	if width <= 0 {
+		return nil, errors.NewErrInvalidAggregation("bucket width must be greater than 0")
+	}
+	if start > end {
+		return nil, errors.NewErrInvalidAggregation("start must not be after end")
+	}
+
+	// Every bucket is returned as one result so the number of buckets is subject to the max limit
+	buckets := (end-start)/width + 1
+	if buckets > int64(Configuration.Service.MaxResultCount) {
+		loggingClient.Error(maxExceededString)
+		return nil, errors.NewErrLimitExceeded(int(buckets))
+	}
+
+	vd, err := dbClient.ValueDescriptorByName(name)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		if err == db.ErrNotFound {
+			return nil, errors.NewErrValueDescriptorNotFound(name)
+		}
+		return nil, err
+	}
+	if !isNumericValueDescriptor(vd.Type) {
+		return nil, errors.NewErrInvalidAggregation(
+			fmt.Sprintf("value descriptor '%s' of type '%s' is not numeric", name, vd.Type))
+	}
+
+	stats, err := dbClient.ReadingBuckets(device, name, start, end, width)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return nil, err
+	}
+
+	aggregates := make([]ReadingAggregate, len(stats))
+	for i, b := range stats {
+		value, _ := aggregateValue(function, b)
+		aggregates[i] = ReadingAggregate{
+			Start: b.Start,
+			End:   b.Start + width,
+			Count: b.Count,
+			Value: value,
+		}
+	}
+
+	return aggregates, nil
+}
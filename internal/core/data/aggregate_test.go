@@ -0,0 +1,149 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+const (
+	testAggregateDevice = "aggregateDevice"
+	testAggregateName   = "temperature"
+)
+
+func newAggregateMockDB(vdType string) *dbMock.DBClient {
+	myMock := &dbMock.DBClient{}
+
+	myMock.On("ValueDescriptorByName", testAggregateName).Return(models.ValueDescriptor{Name: testAggregateName, Type: vdType}, nil)
+	myMock.On("ReadingBuckets", testAggregateDevice, testAggregateName, int64(0), int64(99), int64(50)).Return([]db.ReadingBucket{
+		{Start: 0, Count: 2, Sum: 6, Min: 2, Max: 4, First: 4, Last: 2},
+		{Start: 50, Count: 1, Sum: 7, Min: 7, Max: 7, First: 7, Last: 7},
+	}, nil)
+
+	return myMock
+}
+
+func TestAggregateReadings(t *testing.T) {
+	tests := []struct {
+		function string
+		expected []float64
+	}{
+		{AggregateMin, []float64{2, 7}},
+		{AggregateMax, []float64{4, 7}},
+		{AggregateAvg, []float64{3, 7}},
+		{AggregateSum, []float64{6, 7}},
+		{AggregateCount, []float64{2, 1}},
+		{AggregateFirst, []float64{4, 7}},
+		{AggregateLast, []float64{2, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			reset()
+			Configuration.Service.MaxResultCount = 5
+			dbClient = newAggregateMockDB("F")
+
+			aggregates, err := aggregateReadings(testAggregateDevice, testAggregateName, 0, 99, 50, tt.function, logger.NewMockClient())
+			if err != nil {
+				t.Errorf("Unexpected error aggregating readings: %s", err.Error())
+				return
+			}
+			if len(aggregates) != len(tt.expected) {
+				t.Errorf("Expected %d aggregates, got %d", len(tt.expected), len(aggregates))
+				return
+			}
+			for i, a := range aggregates {
+				if a.Value != tt.expected[i] {
+					t.Errorf("Expected value %v for bucket %d, got %v", tt.expected[i], i, a.Value)
+				}
+				if a.End != a.Start+50 {
+					t.Errorf("Expected bucket %d to end at %d, got %d", i, a.Start+50, a.End)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateReadingsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		start    int64
+		end      int64
+		width    int64
+		vdType   string
+	}{
+		{"Unknown function", "median", 0, 99, 50, "F"},
+		{"Zero width", AggregateAvg, 0, 99, 0, "F"},
+		{"Start after end", AggregateAvg, 99, 0, 50, "F"},
+		{"Non-numeric value descriptor", AggregateAvg, 0, 99, 50, "S"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset()
+			Configuration.Service.MaxResultCount = 5
+			dbClient = newAggregateMockDB(tt.vdType)
+
+			_, err := aggregateReadings(testAggregateDevice, testAggregateName, tt.start, tt.end, tt.width, tt.function, logger.NewMockClient())
+			if _, ok := err.(errors.ErrInvalidAggregation); !ok {
+				t.Errorf("Expected ErrInvalidAggregation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAggregateReadingsOverLimit(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 1
+	dbClient = newAggregateMockDB("F")
+
+	_, err := aggregateReadings(testAggregateDevice, testAggregateName, 0, 99, 50, AggregateAvg, logger.NewMockClient())
+	if _, ok := err.(errors.ErrLimitExceeded); !ok {
+		t.Errorf("Expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+func TestAggregateReadingsValueDescriptorNotFound(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 5
+	myMock := &dbMock.DBClient{}
+	myMock.On("ValueDescriptorByName", testAggregateName).Return(models.ValueDescriptor{}, db.ErrNotFound)
+	dbClient = myMock
+
+	_, err := aggregateReadings(testAggregateDevice, testAggregateName, 0, 99, 50, AggregateAvg, logger.NewMockClient())
+	if _, ok := err.(errors.ErrValueDescriptorNotFound); !ok {
+		t.Errorf("Expected ErrValueDescriptorNotFound, got %v", err)
+	}
+}
+
+func TestAggregateReadingsError(t *testing.T) {
+	reset()
+	Configuration.Service.MaxResultCount = 5
+	myMock := &dbMock.DBClient{}
+	myMock.On("ValueDescriptorByName", testAggregateName).Return(models.ValueDescriptor{Name: testAggregateName, Type: "I"}, nil)
+	myMock.On("ReadingBuckets", testAggregateDevice, testAggregateName, int64(0), int64(99), int64(50)).Return(nil, fmt.Errorf("some error"))
+	dbClient = myMock
+
+	_, err := aggregateReadings(testAggregateDevice, testAggregateName, 0, 99, 50, AggregateAvg, logger.NewMockClient())
+	if err == nil {
+		t.Errorf("Expected error aggregating readings")
+	}
+}
@@ -0,0 +1,211 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package data
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/google/uuid"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/errorconcept"
+)
+
+// eventBatchSize is the number of valid events of a batch which are persisted at once
+const eventBatchSize = 100
+
+// EventResult is the outcome of adding one event of a batch, either the id of the added event or the error the
+// event was rejected with. Code is the HTTP status code the event would have been answered with on its own.
+type EventResult struct {
+	ID    string `json:"id,omitempty"`
+	Code  int    `json:"code"`
+	Error string `json:"error,omitempty"`
+}
+
+// newEventFailure returns the result of an event rejected with the error
+func newEventFailure(err error) EventResult {
+	return EventResult{
+		Code: errorconcept.StatusCode(
+			err,
+			[]errorconcept.ErrorConceptType{
+				errorconcept.ValueDescriptors.NotFound,
+				errorconcept.ValueDescriptors.Invalid,
+				errorconcept.Common.InvalidID,
+				errorconcept.Database.NotUnique,
+				errorconcept.NewServiceClientHttpError(err),
+			},
+			errorconcept.Default.InternalServerError),
+		Error: err.Error(),
+	}
+}
+
+// addNewEvents validates each event of the batch like addNewEvent does and adds the valid ones to the database in
+// batches of eventBatchSize. An event which is rejected does not affect the others, the results are in the order
+// of the events. The IDs the events are given are checked beforehand so that no event can fail the insert of the
+// others; should the insert of a batch fail all the same, its events are added one at a time. Duplicates of events
+// added before the batch, or earlier in it, are answered like the original.
+func addNewEvents(events []models.Event, ctx context.Context, loggingClient logger.LoggingClient) []EventResult {
+	results := make([]EventResult, len(events))
+
+	var pending []models.Event
+	var positions []int
+	ids := make(map[string]bool)
+	accepted := make(map[string]int)
+	duplicates := make(map[int]int)
+	for i, e := range events {
+		if err := validateEventIDs(e, ids); err != nil {
+			loggingClient.Error(fmt.Sprintf("event %d of the batch rejected: %s", i, err.Error()))
+			results[i] = newEventFailure(err)
+			continue
+		}
+		if err := validateEvent(&e, ctx, loggingClient); err != nil {
+			loggingClient.Error(fmt.Sprintf("event %d of the batch rejected: %s", i, err.Error()))
+			results[i] = newEventFailure(err)
+			continue
+		}
+		if detectsDuplicates(e, idempotencyWindow(loggingClient)) {
+			key := duplicateKey(e)
+			if original, ok := accepted[key]; ok {
+				loggingClient.Debug(fmt.Sprintf("event %d of the batch is a duplicate of event %d", i, original))
+				duplicates[i] = original
+				continue
+			}
+			accepted[key] = i
+		}
+		flush := func() {
+			if len(pending) > 0 {
+				addEventBatch(pending, positions, results, ctx, loggingClient)
+				pending, positions = nil, nil
+			}
+		}
+		if id, ok := duplicateEventID(e, loggingClient, flush); ok {
+			loggingClient.Debug(fmt.Sprintf("event %d of the batch is a duplicate of event %s", i, id))
+			results[i] = EventResult{ID: id, Code: http.StatusOK}
+			continue
+		}
+
+		pending = append(pending, e)
+		positions = append(positions, i)
+		if len(pending) == eventBatchSize {
+			addEventBatch(pending, positions, results, ctx, loggingClient)
+			pending, positions = nil, nil
+		}
+	}
+	if len(pending) > 0 {
+		addEventBatch(pending, positions, results, ctx, loggingClient)
+	}
+	for i, original := range duplicates {
+		results[i] = results[original]
+	}
+
+	return results
+}
+
+// validateEventIDs checks that the IDs the event and its readings are given, if any, are UUIDs which are not used by
+// another event or reading of the batch, and then records them as used
+func validateEventIDs(e models.Event, used map[string]bool) error {
+	ids := make([]string, 0, len(e.Readings)+1)
+	if e.ID != "" {
+		ids = append(ids, e.ID)
+	}
+	for _, r := range e.Readings {
+		if r.Id != "" {
+			ids = append(ids, r.Id)
+		}
+	}
+
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			return errors.NewErrInvalidId(id)
+		}
+		if used[id] || seen[id] {
+			return db.ErrNotUnique
+		}
+		seen[id] = true
+	}
+	for id := range seen {
+		used[id] = true
+	}
+	return nil
+}
+
+// addEventBatch persists and publishes validated events, recording their results at the given positions
+func addEventBatch(
+	events []models.Event,
+	positions []int,
+	results []EventResult,
+	ctx context.Context,
+	loggingClient logger.LoggingClient) {
+
+	if Configuration.Writable.PersistData {
+		ids, err := dbClient.AddEvents(events)
+		if err != nil {
+			loggingClient.Error(fmt.Sprintf("failed to add a batch of %d events: %s", len(events), err.Error()))
+			events, positions = addEventsSingly(events, positions, results, loggingClient)
+		} else {
+			for i := range events {
+				events[i].ID = ids[i]
+				recordEvent(events[i], loggingClient)
+			}
+		}
+	}
+
+	for i, e := range events {
+		results[positions[i]] = EventResult{ID: e.ID, Code: http.StatusOK}
+		publishEvent(e, eventContext(e, ctx), loggingClient)
+	}
+}
+
+// addEventsSingly adds the events of a batch whose insert failed one at a time so that only the events at fault are
+// rejected. It returns the events which were added along with their positions.
+func addEventsSingly(
+	events []models.Event,
+	positions []int,
+	results []EventResult,
+	loggingClient logger.LoggingClient) ([]models.Event, []int) {
+
+	var added []models.Event
+	var addedPositions []int
+	for i, e := range events {
+		id, err := dbClient.AddEvent(e)
+		if err != nil {
+			releaseEvent(e)
+			loggingClient.Error(fmt.Sprintf("event %d of the batch rejected: %s", positions[i], err.Error()))
+			results[positions[i]] = newEventFailure(err)
+			continue
+		}
+		e.ID = id
+		recordEvent(e, loggingClient)
+		added = append(added, e)
+		addedPositions = append(addedPositions, positions[i])
+	}
+	return added, addedPositions
+}
+
+// eventContext returns the context an event of a batch is published with. The events of a CBOR sequence each carry
+// the checksum of their own payload.
+func eventContext(e models.Event, ctx context.Context) context.Context {
+	if clients.FromContext(clients.ContentType, ctx) != clients.ContentTypeCBOR {
+		return ctx
+	}
+	return context.WithValue(ctx, checksumContextKey, e.Checksum)
+}
@@ -0,0 +1,248 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/edgexfoundry/go-mod-messaging/messaging"
+	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+func resetBatch() {
+	reset()
+	Configuration.Writable.PersistData = true
+	Configuration.Writable.ValidateCheck = true
+
+	// no need to mock this since it's all in process
+	msgClient, _ = messaging.NewMessageClient(msgTypes.MessageBusConfig{
+		PublishHost: msgTypes.HostInfo{
+			Host:     "*",
+			Protocol: "tcp",
+			Port:     5563,
+		},
+		Type: "zero",
+	})
+	chEvents = make(chan interface{}, 4*eventBatchSize)
+}
+
+func newBatchEvent(name string, value string) models.Event {
+	return models.Event{Event: contract.Event{
+		Device:   testDeviceName,
+		Readings: []contract.Reading{{Name: name, Value: value}},
+	}}
+}
+
+func newBatchMockDB() *dbMock.DBClient {
+	myMock := &dbMock.DBClient{}
+	myMock.On("ValueDescriptorByName", "temperature").Return(contract.ValueDescriptor{Name: "temperature", Type: "F"}, nil)
+	myMock.On("ValueDescriptorByName", "unknown").Return(contract.ValueDescriptor{}, db.ErrNotFound)
+	myMock.On("AddEvents", mock.Anything).Return(func(events []models.Event) []string {
+		ids := make([]string, len(events))
+		for i := range events {
+			ids[i] = fmt.Sprintf("id%d", i)
+		}
+		return ids
+	}, nil)
+	return myMock
+}
+
+func TestAddNewEvents(t *testing.T) {
+	resetBatch()
+	myMock := newBatchMockDB()
+	dbClient = myMock
+
+	events := []models.Event{
+		newBatchEvent("temperature", "1.5"),
+		newBatchEvent("unknown", "1"),
+		newBatchEvent("temperature", "abc"),
+		newBatchEvent("temperature", "2.5"),
+	}
+	results := addNewEvents(events, context.Background(), logger.NewMockClient())
+
+	expected := []EventResult{
+		{ID: "id0", Code: http.StatusOK},
+		{Code: http.StatusNotFound},
+		{Code: http.StatusBadRequest},
+		{ID: "id1", Code: http.StatusOK},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+	for i, r := range results {
+		if r.ID != expected[i].ID || r.Code != expected[i].Code {
+			t.Errorf("Expected result %d to be %+v, got %+v", i, expected[i], r)
+		}
+		if (r.Error != "") != (r.Code != http.StatusOK) {
+			t.Errorf("Expected an error message only for rejected event %d, got %+v", i, r)
+		}
+	}
+	myMock.AssertNumberOfCalls(t, "AddEvents", 1)
+}
+
+func TestAddNewEventsRateOfChange(t *testing.T) {
+	resetBatch()
+	readingHistory = &lastReadings{readings: make(map[string]contract.Reading)}
+	myMock := &dbMock.DBClient{}
+	myMock.On("ValueDescriptorByName", "temperature").Return(contract.ValueDescriptor{Name: "temperature", Type: "F",
+		Labels: []string{ValidationRulePrefix + "maxRateOfChange=1"}}, nil)
+	myMock.On("AddEvents", mock.Anything).Return([]string{"id0", "id1"}, nil)
+	dbClient = myMock
+
+	events := make([]models.Event, 3)
+	for i, value := range []string{"20", "30", "20.5"} {
+		events[i] = newBatchEvent("temperature", value)
+		events[i].Origin = int64(i+1) * 1000
+	}
+	results := addNewEvents(events, context.Background(), logger.NewMockClient())
+
+	// the jump is checked against the first event of the batch, the last one against the first as well
+	expected := []int{http.StatusOK, http.StatusBadRequest, http.StatusOK}
+	for i, r := range results {
+		if r.Code != expected[i] {
+			t.Errorf("Expected event %d to get %d, got %+v", i, expected[i], r)
+		}
+	}
+}
+
+func TestAddNewEventsInBatches(t *testing.T) {
+	resetBatch()
+	myMock := newBatchMockDB()
+	dbClient = myMock
+
+	events := make([]models.Event, eventBatchSize+1)
+	for i := range events {
+		events[i] = newBatchEvent("temperature", "1")
+	}
+	results := addNewEvents(events, context.Background(), logger.NewMockClient())
+
+	for i, r := range results {
+		if r.Code != http.StatusOK {
+			t.Errorf("Expected event %d to be added, got %+v", i, r)
+		}
+	}
+	if results[eventBatchSize].ID != "id0" {
+		t.Errorf("Expected the last event to be added in a second batch, got %+v", results[eventBatchSize])
+	}
+	myMock.AssertNumberOfCalls(t, "AddEvents", 2)
+}
+
+func TestAddNewEventsDBError(t *testing.T) {
+	resetBatch()
+	myMock := &dbMock.DBClient{}
+	myMock.On("ValueDescriptorByName", "temperature").Return(contract.ValueDescriptor{Name: "temperature", Type: "F"}, nil)
+	myMock.On("AddEvents", mock.Anything).Return(nil, fmt.Errorf("some error"))
+	myMock.On("AddEvent", mock.MatchedBy(func(e models.Event) bool { return e.Readings[0].Value == "1" })).
+		Return("", fmt.Errorf("some error"))
+	myMock.On("AddEvent", mock.Anything).Return("id1", nil)
+	dbClient = myMock
+
+	events := []models.Event{newBatchEvent("temperature", "1"), newBatchEvent("temperature", "2")}
+	results := addNewEvents(events, context.Background(), logger.NewMockClient())
+
+	if r := results[0]; r.Code != http.StatusInternalServerError || r.Error == "" || r.ID != "" {
+		t.Errorf("Expected event 0 to fail, got %+v", r)
+	}
+	if r := results[1]; r.Code != http.StatusOK || r.ID != "id1" {
+		t.Errorf("Expected event 1 to be added singly after the batch failed, got %+v", r)
+	}
+	myMock.AssertNumberOfCalls(t, "AddEvent", 2)
+}
+
+func TestAddNewEventsInvalidIDs(t *testing.T) {
+	resetBatch()
+	myMock := newBatchMockDB()
+	dbClient = myMock
+
+	id := uuid.New().String()
+	withID := func(eventID string, readingID string) models.Event {
+		e := newBatchEvent("temperature", "1")
+		e.ID = eventID
+		e.Readings[0].Id = readingID
+		return e
+	}
+	events := []models.Event{
+		withID(id, ""),
+		withID("not-a-uuid", ""),
+		withID("", "not-a-uuid"),
+		withID(id, ""),
+		withID("", id),
+		withID(uuid.New().String(), uuid.New().String()),
+	}
+	results := addNewEvents(events, context.Background(), logger.NewMockClient())
+
+	expected := []int{
+		http.StatusOK,
+		http.StatusBadRequest,
+		http.StatusBadRequest,
+		http.StatusConflict,
+		http.StatusConflict,
+		http.StatusOK,
+	}
+	for i, r := range results {
+		if r.Code != expected[i] {
+			t.Errorf("Expected event %d to be answered with %d, got %+v", i, expected[i], r)
+		}
+	}
+	myMock.AssertNumberOfCalls(t, "AddEvents", 1)
+	for _, call := range myMock.Calls {
+		if added, ok := call.Arguments.Get(0).([]models.Event); ok && len(added) != 2 {
+			t.Errorf("Expected only the 2 valid events to be inserted, got %d", len(added))
+		}
+	}
+}
+
+func TestAddNewEventsDuplicateInBatch(t *testing.T) {
+	resetBatch()
+	recentEvents = newEventCache()
+	Configuration.Writable.IdempotencyWindow = "1m"
+	myMock := newBatchMockDB()
+	myMock.On("EventsByChecksum", mock.Anything).Return([]contract.Event{}, nil)
+	dbClient = myMock
+
+	withChecksum := func(checksum string) models.Event {
+		e := newBatchEvent("temperature", "1")
+		e.Checksum = checksum
+		return e
+	}
+	events := []models.Event{withChecksum("abc"), withChecksum("abc"), withChecksum("def")}
+	results := addNewEvents(events, context.Background(), logger.NewMockClient())
+
+	expected := []EventResult{
+		{ID: "id0", Code: http.StatusOK},
+		{ID: "id0", Code: http.StatusOK},
+		{ID: "id1", Code: http.StatusOK},
+	}
+	for i, r := range results {
+		if r != expected[i] {
+			t.Errorf("Expected result %d to be %+v, got %+v", i, expected[i], r)
+		}
+	}
+	for _, call := range myMock.Calls {
+		if added, ok := call.Arguments.Get(0).([]models.Event); ok && len(added) != 2 {
+			t.Errorf("Expected the duplicate not to be inserted, got %d events", len(added))
+		}
+	}
+}
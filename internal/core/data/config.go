@@ -28,6 +28,7 @@ type ConfigurationStruct struct {
 	Service      config.ServiceInfo
 	SecretStore  config.SecretStoreInfo
 	Startup      config.StartupInfo
+	Retention    RetentionInfo
 }
 
 type WritableInfo struct {
@@ -38,6 +39,37 @@ type WritableInfo struct {
 	ValidateCheck              bool
 	LogLevel                   string
 	ChecksumAlgo               string
+	// ValidationPolicy applies to the readings which violate a validation rule of their value descriptor or device
+	// resource when ValidateCheck is enabled: 'reject' (the default) rejects them, 'flag' stores them with the
+	// violations recorded in the flags of their event. The flags are write-only: they are stored with the event and
+	// published with it when it was posted as JSON, but no query returns them.
+	ValidationPolicy string
+	// IdempotencyWindow is how long, e.g. '10m', an event posted again with the same checksum and device is answered
+	// with the id of the original instead of being stored. Duplicates are not detected when empty. Only the events
+	// posted as CBOR carry a checksum, the events posted as JSON are never detected as duplicates.
+	IdempotencyWindow string
+	// IdempotencyCacheSize is the number of recent events remembered in memory, older ones are looked up in the
+	// database
+	IdempotencyCacheSize int
+}
+
+// RetentionInfo configures the background job which downsamples and removes old events
+type RetentionInfo struct {
+	// Interval between two runs of the job, e.g. '1h'. The job does not run when empty.
+	Interval string
+	Policies []RetentionPolicyInfo
+}
+
+// RetentionPolicyInfo defines how long the events of a device, or the events holding only readings for a value
+// descriptor, are kept. A policy with neither Device nor ValueDescriptor set applies to all other events.
+type RetentionPolicyInfo struct {
+	Device          string
+	ValueDescriptor string
+	// RawDays is the number of days events are kept before they are rolled up into hourly aggregates
+	RawDays int
+	// AggregateMonths is the number of months (of 30 days) the hourly aggregates are kept, no aggregates are
+	// created when 0
+	AggregateMonths int
 }
 
 // UpdateFromRaw converts configuration received from the registry to a service-specific configuration struct which is
@@ -23,7 +23,12 @@ const (
 	UOMLABEL       = "uomlabel"
 	UOMLABEL_PARAM = "uomLabel"
 	LIMIT          = "limit"
+	OFFSET         = "offset"
 	REMOVEOLD      = "removeold"
+	RETENTION      = "retention"
+	EXPORT         = "export"
+	BATCH          = "batch"
+	FORMAT         = "format"
 	AGE            = "age"
 	START          = "start"
 	END            = "end"
@@ -35,4 +40,8 @@ const (
 	NAMES          = "names"
 	DEVICE         = "device"
 	USAGE          = "usage"
+	AGGREGATE      = "aggregate"
+	FUNCTION       = "function"
+	WIDTH          = "width"
+	LINK_HEADER    = "Link"
 )
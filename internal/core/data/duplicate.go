@@ -0,0 +1,229 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package data
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+// defaultIdempotencyCacheSize is the number of recent events remembered when IdempotencyCacheSize is not set
+const defaultIdempotencyCacheSize = 1000
+
+// eventCache remembers the ids of the most recently added events by device and checksum, the least recently used
+// entries are evicted first. The keys of the events being added are reserved until they are added, so that a
+// duplicate posted meanwhile is answered like the event it repeats instead of being added too.
+type eventCache struct {
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	// pending holds the reserved keys, the channel of a key is closed once its reservation ends
+	pending map[string]chan struct{}
+}
+
+type eventCacheEntry struct {
+	key   string
+	id    string
+	added time.Time
+}
+
+func newEventCache() *eventCache {
+	return &eventCache{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		pending: make(map[string]chan struct{}),
+	}
+}
+
+// get returns the id of the event added under the key since the given time
+func (c *eventCache) get(key string, since time.Time) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.lookup(key, since)
+}
+
+// reserve returns the id of the event added under the key since the given time. When there is none the key is
+// reserved for the caller, which ends the reservation with add or release, unless it is already reserved: the
+// returned channel is then closed once the other reservation ends.
+func (c *eventCache) reserve(key string, since time.Time) (string, bool, <-chan struct{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if id, ok := c.lookup(key, since); ok {
+		return id, true, nil
+	}
+	if wait, ok := c.pending[key]; ok {
+		return "", false, wait
+	}
+	c.pending[key] = make(chan struct{})
+	return "", false, nil
+}
+
+// lookup is get, the mutex being held
+func (c *eventCache) lookup(key string, since time.Time) (string, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := element.Value.(*eventCacheEntry)
+	if entry.added.Before(since) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return entry.id, true
+}
+
+// add remembers the id of the event added under the key, evicting entries beyond the capacity, and ends the
+// reservation of the key
+func (c *eventCache) add(key string, id string, added time.Time, capacity int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+	}
+	c.entries[key] = c.order.PushFront(&eventCacheEntry{key: key, id: id, added: added})
+
+	for c.order.Len() > capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*eventCacheEntry).key)
+	}
+	c.unreserve(key)
+}
+
+// release ends the reservation of the key, the event reserved under it was not added
+func (c *eventCache) release(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.unreserve(key)
+}
+
+func (c *eventCache) unreserve(key string) {
+	if wait, ok := c.pending[key]; ok {
+		close(wait)
+		delete(c.pending, key)
+	}
+}
+
+// recentEvents backs the idempotency window, the database is queried when an event is not found in it
+var recentEvents = newEventCache()
+
+// idempotencyWindow returns the configured idempotency window, 0 when duplicates are not detected
+func idempotencyWindow(loggingClient logger.LoggingClient) time.Duration {
+	if Configuration.Writable.IdempotencyWindow == "" {
+		return 0
+	}
+	window, err := time.ParseDuration(Configuration.Writable.IdempotencyWindow)
+	if err != nil {
+		loggingClient.Error(fmt.Sprintf("invalid IdempotencyWindow '%s': %s",
+			Configuration.Writable.IdempotencyWindow, err.Error()))
+		return 0
+	}
+	return window
+}
+
+func duplicateKey(e models.Event) string {
+	return e.Device + "/" + e.Checksum
+}
+
+// duplicateEventID returns the id of an event with the same checksum and device as e which was added within the
+// idempotency window. Only the events which carry a checksum, those posted as CBOR, can be detected as duplicates.
+// When there is no such event, the caller is to add e and then call recordEvent, or releaseEvent should it fail to.
+// An event with the same checksum and device being added meanwhile is waited for, flush being called first, when not
+// nil, to add the events the caller has not added yet so that no two requests wait for each other.
+func duplicateEventID(e models.Event, loggingClient logger.LoggingClient, flush func()) (string, bool) {
+	window := idempotencyWindow(loggingClient)
+	if !detectsDuplicates(e, window) {
+		return "", false
+	}
+
+	since := time.Now().Add(-window)
+	key := duplicateKey(e)
+	for {
+		id, ok, wait := recentEvents.reserve(key, since)
+		if ok {
+			return id, true
+		}
+		if wait == nil {
+			break
+		}
+		if flush != nil {
+			flush()
+		}
+		<-wait
+	}
+
+	// The event may have been added before the service started or evicted from the cache
+	events, err := dbClient.EventsByChecksum(e.Checksum)
+	if err != nil {
+		if err != db.ErrNotFound {
+			loggingClient.Error(fmt.Sprintf("failed to look up events by checksum %s: %s", e.Checksum, err.Error()))
+		}
+		return "", false
+	}
+	sinceMillis := since.UnixNano() / int64(time.Millisecond)
+	for _, original := range events {
+		if original.Device != e.Device || original.Created < sinceMillis {
+			continue
+		}
+		added := time.Unix(0, original.Created*int64(time.Millisecond))
+		recentEvents.add(key, original.ID, added, idempotencyCacheSize())
+		return original.ID, true
+	}
+	return "", false
+}
+
+// detectsDuplicates tells whether duplicates of e are looked for within the given idempotency window
+func detectsDuplicates(e models.Event, window time.Duration) bool {
+	return window > 0 && e.Checksum != "" && Configuration.Writable.PersistData
+}
+
+// recordEvent remembers an added event for the idempotency window
+func recordEvent(e models.Event, loggingClient logger.LoggingClient) {
+	if e.Checksum == "" {
+		return
+	}
+	if idempotencyWindow(loggingClient) <= 0 {
+		recentEvents.release(duplicateKey(e))
+		return
+	}
+	recentEvents.add(duplicateKey(e), e.ID, time.Now(), idempotencyCacheSize())
+}
+
+// releaseEvent ends the reservation duplicateEventID made for an event which could not be added
+func releaseEvent(e models.Event) {
+	if e.Checksum != "" {
+		recentEvents.release(duplicateKey(e))
+	}
+}
+
+func idempotencyCacheSize() int {
+	if Configuration.Writable.IdempotencyCacheSize <= 0 {
+		return defaultIdempotencyCacheSize
+	}
+	return Configuration.Writable.IdempotencyCacheSize
+}
@@ -0,0 +1,262 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/stretchr/testify/mock"
+
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+func resetDuplicates() {
+	reset()
+	recentEvents = newEventCache()
+	Configuration.Writable.PersistData = true
+	Configuration.Writable.IdempotencyWindow = "1m"
+}
+
+func newChecksumEvent(device string, checksum string) models.Event {
+	return models.Event{Checksum: checksum, Event: contract.Event{Device: device}}
+}
+
+func TestEventCache(t *testing.T) {
+	cache := newEventCache()
+	now := time.Now()
+
+	cache.add("a", "1", now, 2)
+	cache.add("b", "2", now, 2)
+	if _, ok := cache.get("a", now.Add(-time.Minute)); !ok {
+		t.Fatalf("Expected a to be cached")
+	}
+	// b is now the least recently used
+	cache.add("c", "3", now, 2)
+	if _, ok := cache.get("b", now.Add(-time.Minute)); ok {
+		t.Errorf("Expected b to be evicted")
+	}
+	if id, ok := cache.get("a", now.Add(-time.Minute)); !ok || id != "1" {
+		t.Errorf("Expected a to be kept, got %s", id)
+	}
+
+	if _, ok := cache.get("c", now.Add(time.Second)); ok {
+		t.Errorf("Expected c to be expired")
+	}
+	if _, ok := cache.get("c", now.Add(-time.Minute)); ok {
+		t.Errorf("Expected the expired entry to be removed")
+	}
+}
+
+func TestEventCacheReserve(t *testing.T) {
+	cache := newEventCache()
+	now := time.Now()
+
+	if _, ok, wait := cache.reserve("a", now); ok || wait != nil {
+		t.Fatalf("Expected a to be reserved")
+	}
+	_, ok, wait := cache.reserve("a", now)
+	if ok || wait == nil {
+		t.Fatalf("Expected to wait for a")
+	}
+	cache.add("a", "1", now, 2)
+	select {
+	case <-wait:
+	default:
+		t.Fatalf("Expected the reservation of a to end")
+	}
+	if id, ok, _ := cache.reserve("a", now); !ok || id != "1" {
+		t.Errorf("Expected a to be added, got %s", id)
+	}
+
+	cache.reserve("b", now)
+	_, _, wait = cache.reserve("b", now)
+	cache.release("b")
+	<-wait
+	if _, ok, wait := cache.reserve("b", now); ok || wait != nil {
+		t.Errorf("Expected b to be reserved again")
+	}
+}
+
+func TestDuplicateEventIDFromCache(t *testing.T) {
+	resetDuplicates()
+	myMock := &dbMock.DBClient{}
+	dbClient = myMock
+
+	e := newChecksumEvent(testDeviceName, "abc")
+	e.ID = "original"
+	recordEvent(e, logger.NewMockClient())
+
+	id, ok := duplicateEventID(newChecksumEvent(testDeviceName, "abc"), logger.NewMockClient(), nil)
+	if !ok || id != "original" {
+		t.Errorf("Expected duplicate of original, got %s %v", id, ok)
+	}
+	myMock.AssertNotCalled(t, "EventsByChecksum", "abc")
+}
+
+func TestDuplicateEventIDFromDatabase(t *testing.T) {
+	now := db.MakeTimestamp()
+	tests := []struct {
+		name     string
+		device   string
+		events   []contract.Event
+		err      error
+		expected string
+	}{
+		{"Found", testDeviceName, []contract.Event{{ID: "original", Device: testDeviceName, Created: now - 1000}}, nil, "original"},
+		{"Other device", "other", []contract.Event{{ID: "original", Device: testDeviceName, Created: now - 1000}}, nil, ""},
+		{"Outside window", testDeviceName, []contract.Event{{ID: "original", Device: testDeviceName, Created: now - 120000}}, nil, ""},
+		{"Not found", testDeviceName, []contract.Event{}, nil, ""},
+		{"None with checksum", testDeviceName, nil, db.ErrNotFound, ""},
+		{"Database error", testDeviceName, nil, fmt.Errorf("some error"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetDuplicates()
+			myMock := &dbMock.DBClient{}
+			myMock.On("EventsByChecksum", "abc").Return(tt.events, tt.err)
+			dbClient = myMock
+
+			id, ok := duplicateEventID(newChecksumEvent(tt.device, "abc"), logger.NewMockClient(), nil)
+			if ok != (tt.expected != "") || id != tt.expected {
+				t.Errorf("Expected duplicate %s, got %s %v", tt.expected, id, ok)
+			}
+
+			// the event found in the database is cached
+			if tt.expected != "" {
+				if id, _ = duplicateEventID(newChecksumEvent(tt.device, "abc"), logger.NewMockClient(), nil); id != tt.expected {
+					t.Errorf("Expected duplicate %s again, got %s", tt.expected, id)
+				}
+				myMock.AssertNumberOfCalls(t, "EventsByChecksum", 1)
+			}
+		})
+	}
+}
+
+func TestDuplicateEventIDDisabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		window   string
+		checksum string
+	}{
+		{"No window", "", "abc"},
+		{"Invalid window", "soon", "abc"},
+		{"No checksum", "1m", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetDuplicates()
+			Configuration.Writable.IdempotencyWindow = tt.window
+			dbClient = &dbMock.DBClient{}
+
+			e := newChecksumEvent(testDeviceName, tt.checksum)
+			e.ID = "original"
+			recordEvent(e, logger.NewMockClient())
+
+			if _, ok := duplicateEventID(e, logger.NewMockClient(), nil); ok {
+				t.Errorf("Expected no duplicate detection")
+			}
+		})
+	}
+}
+
+func TestAddNewEventDuplicate(t *testing.T) {
+	resetDuplicates()
+	myMock := &dbMock.DBClient{}
+	dbClient = myMock
+
+	original := newChecksumEvent(testDeviceName, "abc")
+	original.ID = "original"
+	recordEvent(original, logger.NewMockClient())
+
+	id, err := addNewEvent(newChecksumEvent(testDeviceName, "abc"), context.Background(), logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if id != "original" {
+		t.Errorf("Expected the id of the original event, got %s", id)
+	}
+	myMock.AssertNotCalled(t, "AddEvent", mock.Anything)
+}
+
+func TestAddNewEventConcurrentDuplicates(t *testing.T) {
+	resetBatch()
+	resetDuplicates()
+	Configuration.Writable.ValidateCheck = false
+
+	adding := make(chan struct{})
+	added := make(chan struct{})
+	myMock := &dbMock.DBClient{}
+	myMock.On("EventsByChecksum", "abc").Return(nil, db.ErrNotFound)
+	myMock.On("AddEvent", mock.Anything).Run(func(args mock.Arguments) {
+		close(adding)
+		<-added
+	}).Return("original", nil).Once()
+	dbClient = myMock
+
+	ids := make(chan string, 2)
+	post := func() {
+		id, err := addNewEvent(newChecksumEvent(testDeviceName, "abc"), context.Background(), logger.NewMockClient())
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err.Error())
+		}
+		ids <- id
+	}
+	go post()
+	<-adding
+	go post()
+	time.Sleep(10 * time.Millisecond)
+	close(added)
+
+	for i := 0; i < 2; i++ {
+		if id := <-ids; id != "original" {
+			t.Errorf("Expected the id of the original event, got %s", id)
+		}
+	}
+	myMock.AssertNumberOfCalls(t, "AddEvent", 1)
+}
+
+func TestAddNewEventJSONNotDetected(t *testing.T) {
+	resetBatch()
+	resetDuplicates()
+	Configuration.Writable.ValidateCheck = false
+
+	myMock := &dbMock.DBClient{}
+	myMock.On("AddEvent", mock.Anything).Return("id", nil)
+	dbClient = myMock
+
+	for i := 0; i < 2; i++ {
+		ctx := context.Background()
+		e, err := jsonReader{}.Read(strings.NewReader(`{"device":"`+testDeviceName+`"}`), &ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+		if e.Checksum != "" {
+			t.Fatalf("Expected no checksum for a JSON event, got %s", e.Checksum)
+		}
+		if _, err := addNewEvent(e, ctx, logger.NewMockClient()); err != nil {
+			t.Fatalf("Unexpected error: %s", err.Error())
+		}
+	}
+	// events posted as JSON carry no checksum, they are never detected as duplicates
+	myMock.AssertNumberOfCalls(t, "AddEvent", 2)
+	myMock.AssertNotCalled(t, "EventsByChecksum", mock.Anything)
+}
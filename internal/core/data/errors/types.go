@@ -170,3 +170,15 @@ func (e ErrInvalidId) Error() string {
 func NewErrInvalidId(id string) error {
 	return ErrInvalidId{id: id}
 }
+
+type ErrInvalidAggregation struct {
+	reason string
+}
+
+func (e ErrInvalidAggregation) Error() string {
+	return fmt.Sprintf("invalid aggregation: %s", e.reason)
+}
+
+func NewErrInvalidAggregation(reason string) error {
+	return ErrInvalidAggregation{reason: reason}
+}
@@ -89,45 +89,87 @@ func getEvents(limit int) ([]contract.Event, error) {
 }
 
 func addNewEvent(e models.Event, ctx context.Context, loggingClient logger.LoggingClient) (string, error) {
-	err := checkDevice(e.Device, ctx)
+	err := validateEvent(&e, ctx, loggingClient)
 	if err != nil {
 		return "", err
 	}
 
+	// A duplicate of a recent event is neither stored nor published again
+	if id, ok := duplicateEventID(e, loggingClient, nil); ok {
+		loggingClient.Debug(fmt.Sprintf("duplicate of event %s with checksum %s ignored", id, e.Checksum))
+		return id, nil
+	}
+
+	// Add the event and readings to the database
+	if Configuration.Writable.PersistData {
+		id, err := dbClient.AddEvent(e)
+		if err != nil {
+			releaseEvent(e)
+			return "", err
+		}
+		e.ID = id
+		recordEvent(e, loggingClient)
+	}
+
+	publishEvent(e, ctx, loggingClient)
+
+	return e.ID, nil
+}
+
+// validateEvent checks the device of the event and, when validation is enabled, its readings against their value
+// descriptors. The readings accepted under the flag validation policy are flagged on the event.
+func validateEvent(e *models.Event, ctx context.Context, loggingClient logger.LoggingClient) error {
+	e.Flags = nil
+
+	// The device profile is only known when the device is checked, its resources may define validation rules
+	var resources []contract.DeviceResource
+	if Configuration.Writable.MetaDataCheck {
+		device, err := mdc.CheckForDevice(e.Device, ctx)
+		if err != nil {
+			return err
+		}
+		resources = device.Profile.DeviceResources
+	}
+
 	if Configuration.Writable.ValidateCheck {
 		loggingClient.Debug("Validation enabled, parsing events")
-		for reading := range e.Readings {
+		for _, reading := range eventReadings(e.Event) {
 			// Check value descriptor
-			name := e.Readings[reading].Name
+			name := reading.Name
 			vd, err := dbClient.ValueDescriptorByName(name)
 			if err != nil {
 				if err == db.ErrNotFound {
-					return "", errors.NewErrValueDescriptorNotFound(name)
+					return errors.NewErrValueDescriptorNotFound(name)
 				} else {
-					return "", err
+					return err
 				}
 			}
-			err = isValidValueDescriptor(vd, e.Readings[reading])
+			err = isValidValueDescriptor(vd, reading)
+			if err != nil {
+				return err
+			}
+			flag, err := applyValidationRules(vd, deviceResource(resources, name), reading, loggingClient)
 			if err != nil {
-				return "", err
+				return err
 			}
+			if flag != "" {
+				if e.Flags == nil {
+					e.Flags = make(map[string]string)
+				}
+				e.Flags[name] = flag
+			}
+			readingHistory.record(reading)
 		}
 	}
 
-	// Add the event and readings to the database
-	if Configuration.Writable.PersistData {
-		id, err := dbClient.AddEvent(e)
-		if err != nil {
-			return "", err
-		}
-		e.ID = id
-	}
+	return nil
+}
 
+// publishEvent sends a new event to the export services and updates the last reported time of its device
+func publishEvent(e models.Event, ctx context.Context, loggingClient logger.LoggingClient) {
 	putEventOnQueue(e, ctx, loggingClient)          // Push the aux struct to export service (It has the actual readings)
 	chEvents <- DeviceLastReported{e.Device}        // update last reported connected (device)
 	chEvents <- DeviceServiceLastReported{e.Device} // update last reported connected (device service)
-
-	return e.ID, nil
 }
 
 func updateEvent(from models.Event, ctx context.Context) error {
@@ -260,8 +302,18 @@ func putEventOnQueue(evt models.Event, ctx context.Context, loggingClient logger
 	}
 }
 
-func getEventsByDeviceIdLimit(limit int, deviceId string, loggingClient logger.LoggingClient) ([]contract.Event, error) {
-	eventList, err := dbClient.EventsForDeviceLimit(deviceId, limit)
+func getEventsByDeviceIdLimit(limit int, offset int, deviceId string, loggingClient logger.LoggingClient) ([]contract.Event, error) {
+	eventList, err := dbClient.EventsForDeviceWithOffset(deviceId, limit, offset)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return nil, err
+	}
+
+	return eventList, nil
+}
+
+func getEventsByCreationTime(limit int, offset int, start int64, end int64, loggingClient logger.LoggingClient) ([]contract.Event, error) {
+	eventList, err := dbClient.EventsByCreationTimeWithOffset(start, end, limit, offset)
 	if err != nil {
 		loggingClient.Error(err.Error())
 		return nil, err
@@ -270,8 +322,10 @@ func getEventsByDeviceIdLimit(limit int, deviceId string, loggingClient logger.L
 	return eventList, nil
 }
 
-func getEventsByCreationTime(limit int, start int64, end int64, loggingClient logger.LoggingClient) ([]contract.Event, error) {
-	eventList, err := dbClient.EventsByCreationTime(start, end, limit)
+// getEventsByCreationTimeAfter returns the page of events created between start and end which follows the event
+// created at afterCreated with the id afterId, see DBClient.EventsByCreationTimeAfter
+func getEventsByCreationTimeAfter(limit int, start int64, end int64, afterCreated int64, afterId string, loggingClient logger.LoggingClient) ([]contract.Event, error) {
+	eventList, err := dbClient.EventsByCreationTimeAfter(start, end, afterCreated, afterId, limit)
 	if err != nil {
 		loggingClient.Error(err.Error())
 		return nil, err
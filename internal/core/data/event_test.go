@@ -279,6 +279,41 @@ func TestAddEventNoPersistence(t *testing.T) {
 	myMock.AssertExpectations(t)
 }
 
+func TestAddEventFlagged(t *testing.T) {
+	reset()
+
+	// no need to mock this since it's all in process
+	msgClient, _ = messaging.NewMessageClient(msgTypes.MessageBusConfig{
+		PublishHost: msgTypes.HostInfo{
+			Host:     "*",
+			Protocol: "tcp",
+			Port:     5563,
+		},
+		Type: "zero",
+	})
+
+	vd := models.ValueDescriptor{Name: "state", Type: "S", Labels: []string{ValidationRulePrefix + "enum=open,closed"}}
+	myMock := &dbMock.DBClient{}
+	myMock.On("ValueDescriptorByName", "state").Return(vd, nil)
+	myMock.On("AddEvent", mock.MatchedBy(func(e correlation.Event) bool {
+		return e.Flags["state"] != "" && len(e.Flags) == 1
+	})).Return("3c5badcb-2008-47f2-ba78-eb2d992f8422", nil)
+	dbClient = myMock
+	chEvents = make(chan interface{}, 10)
+	Configuration.Writable.PersistData = true
+	Configuration.Writable.ValidateCheck = true
+	Configuration.Writable.ValidationPolicy = ValidationPolicyFlag
+
+	evt := models.Event{Device: testDeviceName, Origin: testOrigin, Readings: []models.Reading{
+		{Name: "state", Value: "ajar"},
+	}}
+	if _, err := addNewEvent(correlation.Event{Event: evt}, context.Background(), logger.NewMockClient()); err != nil {
+		t.Fatalf("Expected the flagged reading to be accepted, got %s", err.Error())
+	}
+
+	myMock.AssertExpectations(t)
+}
+
 func TestUpdateEventNotFound(t *testing.T) {
 	reset()
 	myMock := &dbMock.DBClient{}
@@ -489,13 +524,13 @@ func TestGetEventsByDeviceIdLimit(t *testing.T) {
 	reset()
 	myMock := &dbMock.DBClient{}
 
-	myMock.On("EventsForDeviceLimit", mock.MatchedBy(func(deviceId string) bool {
+	myMock.On("EventsForDeviceWithOffset", mock.MatchedBy(func(deviceId string) bool {
 		return deviceId == "valid"
-	}), mock.Anything).Return([]models.Event{testEvent}, nil)
+	}), mock.Anything, mock.Anything).Return([]models.Event{testEvent}, nil)
 
 	dbClient = myMock
 
-	expectedList, expectedNil := getEventsByDeviceIdLimit(0, "valid", logger.NewMockClient())
+	expectedList, expectedNil := getEventsByDeviceIdLimit(0, 0, "valid", logger.NewMockClient())
 
 	if expectedNil != nil {
 		t.Errorf("Should not throw error")
@@ -514,13 +549,13 @@ func TestGetEventsByDeviceIdLimitDBThrowsError(t *testing.T) {
 	reset()
 	myMock := &dbMock.DBClient{}
 
-	myMock.On("EventsForDeviceLimit", mock.MatchedBy(func(deviceId string) bool {
+	myMock.On("EventsForDeviceWithOffset", mock.MatchedBy(func(deviceId string) bool {
 		return deviceId == "error"
-	}), mock.Anything).Return(nil, fmt.Errorf("some error"))
+	}), mock.Anything, mock.Anything).Return(nil, fmt.Errorf("some error"))
 
 	dbClient = myMock
 
-	expectedNil, expectedErr := getEventsByDeviceIdLimit(0, "error", logger.NewMockClient())
+	expectedNil, expectedErr := getEventsByDeviceIdLimit(0, 0, "error", logger.NewMockClient())
 
 	if expectedNil != nil {
 		t.Errorf("Should not return list")
@@ -535,13 +570,13 @@ func TestGetEventsByCreationTime(t *testing.T) {
 	reset()
 	myMock := &dbMock.DBClient{}
 
-	myMock.On("EventsByCreationTime", mock.MatchedBy(func(start int64) bool {
+	myMock.On("EventsByCreationTimeWithOffset", mock.MatchedBy(func(start int64) bool {
 		return start == 0xF00D
-	}), mock.Anything, mock.Anything).Return([]models.Event{}, nil)
+	}), mock.Anything, mock.Anything, mock.Anything).Return([]models.Event{}, nil)
 
 	dbClient = myMock
 
-	expectedReadings, expectedNil := getEventsByCreationTime(0, 0xF00D, 0, logger.NewMockClient())
+	expectedReadings, expectedNil := getEventsByCreationTime(0, 0, 0xF00D, 0, logger.NewMockClient())
 
 	if expectedReadings == nil {
 		t.Errorf("Should return Events")
@@ -556,13 +591,13 @@ func TestGetEventsByCreationTimeDBThrowsError(t *testing.T) {
 	reset()
 	myMock := &dbMock.DBClient{}
 
-	myMock.On("EventsByCreationTime", mock.MatchedBy(func(start int64) bool {
+	myMock.On("EventsByCreationTimeWithOffset", mock.MatchedBy(func(start int64) bool {
 		return start == 0xBADF00D
-	}), mock.Anything, mock.Anything).Return(nil, fmt.Errorf("some error"))
+	}), mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("some error"))
 
 	dbClient = myMock
 
-	expectedNil, expectedErr := getEventsByCreationTime(0, 0xBADF00D, 0, logger.NewMockClient())
+	expectedNil, expectedErr := getEventsByCreationTime(0, 0, 0xBADF00D, 0, logger.NewMockClient())
 
 	if expectedNil != nil {
 		t.Errorf("Should not return list")
@@ -0,0 +1,211 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package data
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+const (
+	// Formats of the event export
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+
+	ContentTypeNDJSON = "application/x-ndjson"
+	ContentTypeCSV    = "text/csv"
+
+	// exportPageSize is the number of events read from the database at once, it bounds the memory used by an export
+	exportPageSize = 1000
+)
+
+// exportCSVHeader names the columns of the CSV export, which holds one row per reading
+var exportCSVHeader = []string{"eventId", "device", "created", "origin", "readingId", "name", "value", "readingCreated", "readingOrigin"}
+
+// exportFilter selects the events of an export
+type exportFilter struct {
+	start int64
+	end   int64
+	// device restricts the export to the events of the device when not empty
+	device string
+	// name restricts the export to the readings for the value descriptor when not empty, events without such a
+	// reading are skipped
+	name string
+}
+
+// eventWriter writes the exported events in one of the export formats
+type eventWriter interface {
+	// Begin is called once before the first event
+	Begin() error
+	Write(e contract.Event) error
+	// Flush is called after each page of events
+	Flush() error
+}
+
+type ndjsonEventWriter struct {
+	encoder *json.Encoder
+}
+
+func (n ndjsonEventWriter) Begin() error {
+	return nil
+}
+
+func (n ndjsonEventWriter) Write(e contract.Event) error {
+	return n.encoder.Encode(e)
+}
+
+func (n ndjsonEventWriter) Flush() error {
+	return nil
+}
+
+type csvEventWriter struct {
+	writer *csv.Writer
+}
+
+func (c csvEventWriter) Begin() error {
+	return c.writer.Write(exportCSVHeader)
+}
+
+func (c csvEventWriter) Write(e contract.Event) error {
+	for _, r := range e.Readings {
+		err := c.writer.Write([]string{
+			e.ID,
+			e.Device,
+			strconv.FormatInt(e.Created, 10),
+			strconv.FormatInt(e.Origin, 10),
+			r.Id,
+			r.Name,
+			r.Value,
+			strconv.FormatInt(r.Created, 10),
+			strconv.FormatInt(r.Origin, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c csvEventWriter) Flush() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// errUnsupportedExportFormat is returned for an export format other than ndjson or csv
+type errUnsupportedExportFormat struct {
+	format string
+}
+
+func (e errUnsupportedExportFormat) Error() string {
+	return fmt.Sprintf("unsupported export format '%s'", e.format)
+}
+
+// newEventWriter returns the writer for the export format along with its content type
+func newEventWriter(format string, w io.Writer) (eventWriter, string, error) {
+	switch format {
+	case ExportFormatNDJSON:
+		return ndjsonEventWriter{encoder: json.NewEncoder(w)}, ContentTypeNDJSON, nil
+	case ExportFormatCSV:
+		return csvEventWriter{writer: csv.NewWriter(w)}, ContentTypeCSV, nil
+	default:
+		return nil, "", errUnsupportedExportFormat{format: format}
+	}
+}
+
+// filterEvent returns the event restricted to the readings selected by the filter and whether it is exported
+func (f exportFilter) filterEvent(e contract.Event) (contract.Event, bool) {
+	if f.device != "" && e.Device != f.device {
+		return e, false
+	}
+	if f.name == "" {
+		return e, true
+	}
+
+	var readings []contract.Reading
+	for _, r := range e.Readings {
+		if r.Name == f.name {
+			readings = append(readings, r)
+		}
+	}
+	e.Readings = readings
+	return e, len(readings) > 0
+}
+
+// exportEvents pages through the events created between the start and end of the filter, in the order of their
+// creation time and id, and writes the selected ones to the response as they are read, so that only one page of
+// events is held in memory. Each page starts after the last event of the previous one, so events added or removed
+// while the export is running neither shift the pages nor cause others to be skipped or exported twice. It returns
+// whether the response has been written to, errors which occur afterwards can no longer be reported to the client.
+func exportEvents(
+	w http.ResponseWriter,
+	format string,
+	filter exportFilter,
+	loggingClient logger.LoggingClient) (bool, error) {
+
+	writer, contentType, err := newEventWriter(format, w)
+	if err != nil {
+		return false, err
+	}
+	flusher, _ := w.(http.Flusher)
+
+	started := false
+	var afterCreated int64
+	var afterId string
+	for {
+		events, err := getEventsByCreationTimeAfter(exportPageSize, filter.start, filter.end, afterCreated, afterId, loggingClient)
+		if err != nil {
+			return started, err
+		}
+
+		if !started {
+			w.Header().Set(clients.ContentType, contentType)
+			w.WriteHeader(http.StatusOK)
+			if err = writer.Begin(); err != nil {
+				return true, err
+			}
+			started = true
+		}
+
+		for _, e := range events {
+			e, ok := filter.filterEvent(e)
+			if !ok {
+				continue
+			}
+			if err = writer.Write(e); err != nil {
+				return true, err
+			}
+		}
+		if err = writer.Flush(); err != nil {
+			return true, err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if len(events) < exportPageSize {
+			return true, nil
+		}
+		last := events[len(events)-1]
+		afterCreated, afterId = last.Created, last.ID
+	}
+}
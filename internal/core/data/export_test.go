@@ -0,0 +1,148 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+)
+
+// newExportMockDB returns a mock holding one full page of events followed by a partial page
+func newExportMockDB() *dbMock.DBClient {
+	first := make([]contract.Event, exportPageSize)
+	for i := range first {
+		first[i] = contract.Event{ID: fmt.Sprintf("event%d", i), Device: "first", Created: int64(i), Readings: []contract.Reading{
+			{Name: "temperature", Value: "1"},
+			{Name: "humidity", Value: "2"},
+		}}
+	}
+	second := []contract.Event{
+		{ID: "last1", Device: "second", Readings: []contract.Reading{{Name: "temperature", Value: "3"}}},
+		{ID: "last2", Device: "second", Readings: []contract.Reading{{Name: "humidity", Value: "4"}}},
+	}
+
+	myMock := &dbMock.DBClient{}
+	myMock.On("EventsByCreationTimeAfter", int64(0), int64(100), int64(0), "", exportPageSize).Return(first, nil)
+	// the next page starts after the last event of the first
+	last := first[exportPageSize-1]
+	myMock.On("EventsByCreationTimeAfter", int64(0), int64(100), last.Created, last.ID, exportPageSize).Return(second, nil)
+	return myMock
+}
+
+func TestExportEventsNDJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   exportFilter
+		expected int
+	}{
+		{"All events", exportFilter{end: 100}, exportPageSize + 2},
+		{"Device", exportFilter{end: 100, device: "second"}, 2},
+		{"Value descriptor", exportFilter{end: 100, device: "second", name: "humidity"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset()
+			dbClient = newExportMockDB()
+			rr := httptest.NewRecorder()
+
+			started, err := exportEvents(rr, ExportFormatNDJSON, tt.filter, logger.NewMockClient())
+			if err != nil || !started {
+				t.Fatalf("Unexpected error exporting events: %v", err)
+			}
+			if contentType := rr.Header().Get(clients.ContentType); contentType != ContentTypeNDJSON {
+				t.Errorf("Expected content type %s, got %s", ContentTypeNDJSON, contentType)
+			}
+
+			count := 0
+			scanner := bufio.NewScanner(rr.Body)
+			for scanner.Scan() {
+				var e contract.Event
+				if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+					t.Fatalf("Invalid NDJSON line: %s", err.Error())
+				}
+				if tt.filter.name != "" && (len(e.Readings) != 1 || e.Readings[0].Name != tt.filter.name) {
+					t.Errorf("Expected only %s readings, got %v", tt.filter.name, e.Readings)
+				}
+				count++
+			}
+			if count != tt.expected {
+				t.Errorf("Expected %d events, got %d", tt.expected, count)
+			}
+		})
+	}
+}
+
+func TestExportEventsCSV(t *testing.T) {
+	reset()
+	dbClient = newExportMockDB()
+	rr := httptest.NewRecorder()
+
+	_, err := exportEvents(rr, ExportFormatCSV, exportFilter{end: 100, name: "temperature"}, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error exporting events: %s", err.Error())
+	}
+	if contentType := rr.Header().Get(clients.ContentType); contentType != ContentTypeCSV {
+		t.Errorf("Expected content type %s, got %s", ContentTypeCSV, contentType)
+	}
+
+	records, err := csv.NewReader(rr.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %s", err.Error())
+	}
+	// header and one temperature reading per event except last2
+	if len(records) != exportPageSize+2 {
+		t.Fatalf("Expected %d records, got %d", exportPageSize+2, len(records))
+	}
+	last := records[len(records)-1]
+	if last[0] != "last1" || last[1] != "second" || last[5] != "temperature" || last[6] != "3" {
+		t.Errorf("Unexpected last record %v", last)
+	}
+}
+
+func TestExportEventsUnsupportedFormat(t *testing.T) {
+	reset()
+	dbClient = &dbMock.DBClient{}
+	rr := httptest.NewRecorder()
+
+	started, err := exportEvents(rr, "xml", exportFilter{end: 100}, logger.NewMockClient())
+	if _, ok := err.(errUnsupportedExportFormat); !ok || started {
+		t.Errorf("Expected errUnsupportedExportFormat before the export started, got %v", err)
+	}
+}
+
+func TestExportEventsError(t *testing.T) {
+	reset()
+	myMock := &dbMock.DBClient{}
+	myMock.On("EventsByCreationTimeAfter", int64(0), int64(100), int64(0), "", exportPageSize).Return(nil, fmt.Errorf("some error"))
+	dbClient = myMock
+	rr := httptest.NewRecorder()
+
+	started, err := exportEvents(rr, ExportFormatCSV, exportFilter{end: 100}, logger.NewMockClient())
+	if err == nil || started {
+		t.Errorf("Expected error before the export started")
+	}
+	if rr.Body.Len() != 0 {
+		t.Errorf("Expected nothing written, got %s", rr.Body.String())
+	}
+}
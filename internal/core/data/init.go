@@ -95,5 +95,7 @@ func BootstrapHandler(wg *sync.WaitGroup, ctx context.Context, startupTimer star
 	chEvents = make(chan interface{}, 100)
 	initEventHandlers(loggingClient)
 
+	startRetention(wg, ctx, loggingClient)
+
 	return true
 }
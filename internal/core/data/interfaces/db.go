@@ -15,6 +15,7 @@ package interfaces
 
 import (
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
@@ -36,6 +37,10 @@ type DBClient interface {
 	// NoValueDescriptor - no existing value descriptor for a reading in the event
 	AddEvent(e models.Event) (string, error)
 
+	// Add a batch of events, returning their ids in the order of the batch
+	// UnexpectedError - failed to add to database, none or only part of the batch may have been added
+	AddEvents(events []models.Event) ([]string, error)
+
 	// Update an event - do NOT update readings
 	// UnexpectedError - problem updating in database
 	// NotFound - no event with the ID was found
@@ -70,6 +75,9 @@ type DBClient interface {
 	// Get a list of events based on the device id and limit
 	EventsForDeviceLimit(id string, limit int) ([]contract.Event, error)
 
+	// Get a page of events based on the device id, skipping the first offset events
+	EventsForDeviceWithOffset(id string, limit int, offset int) ([]contract.Event, error)
+
 	// Get a list of events based on the device id
 	EventsForDevice(id string) ([]contract.Event, error)
 
@@ -80,6 +88,15 @@ type DBClient interface {
 	// Limit the number of results by limit
 	EventsByCreationTime(startTime, endTime int64, limit int) ([]contract.Event, error)
 
+	// Return a page of events whos creation time is between startTime and endTime
+	// The first offset events are skipped and the number of results is limited by limit
+	EventsByCreationTimeWithOffset(startTime, endTime int64, limit int, offset int) ([]contract.Event, error)
+
+	// Return a page of events whos creation time is between startTime and endTime in ascending order of creation
+	// time and id, starting after the event created at afterCreated with the id afterId, which need not exist
+	// anymore. An empty afterId starts with the first event. The number of results is limited by limit
+	EventsByCreationTimeAfter(startTime, endTime int64, afterCreated int64, afterId string, limit int) ([]contract.Event, error)
+
 	// Return a list of readings for a device filtered by the value descriptor and limited by the limit
 	// The readings are linked to the device through an event
 	ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) ([]contract.Reading, error)
@@ -132,10 +149,16 @@ type DBClient interface {
 	// Sort the list of readings on creation date
 	ReadingsByDevice(id string, limit int) ([]contract.Reading, error)
 
+	// Return a page of readings for the given device, skipping the first offset readings
+	ReadingsByDeviceWithOffset(id string, limit int, offset int) ([]contract.Reading, error)
+
 	// Return a list of readings for the given value descriptor
 	// 413 - the number exceeds the current max limit
 	ReadingsByValueDescriptor(name string, limit int) ([]contract.Reading, error)
 
+	// Return a page of readings for the given value descriptor, skipping the first offset readings
+	ReadingsByValueDescriptorWithOffset(name string, limit int, offset int) ([]contract.Reading, error)
+
 	// Return a list of readings whose name is in the list of value descriptor names
 	ReadingsByValueDescriptorNames(names []string, limit int) ([]contract.Reading, error)
 
@@ -152,6 +175,15 @@ type DBClient interface {
 	// Return a list of readings whos created time is between the start and end times
 	ReadingsByCreationTime(start, end int64, limit int) ([]contract.Reading, error)
 
+	// Aggregate the numeric readings of a device for a value descriptor whose created time is between the
+	// start and end times into buckets of the given width (in milliseconds)
+	// Buckets without readings are omitted, the remaining ones are sorted by start time
+	ReadingBuckets(deviceId, valueDescriptor string, start, end int64, width int64) ([]db.ReadingBucket, error)
+
+	// Return a page of readings whos created time is between the start and end times
+	// The first offset readings are skipped
+	ReadingsByCreationTimeWithOffset(start, end int64, limit int, offset int) ([]contract.Reading, error)
+
 	// ************************** VALUE DESCRIPTOR FUNCTIONS ***************************
 	// Add a value descriptor
 	// 409 - Formatting is bad or it is not unique
@@ -4,6 +4,7 @@ package mocks
 
 import go_mod_core_contractsmodels "github.com/edgexfoundry/go-mod-core-contracts/models"
 
+import db "github.com/edgexfoundry/edgex-go/internal/pkg/db"
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
 
@@ -33,6 +34,29 @@ func (_m *DBClient) AddEvent(e models.Event) (string, error) {
 	return r0, r1
 }
 
+// AddEvents provides a mock function with given fields: events
+func (_m *DBClient) AddEvents(events []models.Event) ([]string, error) {
+	ret := _m.Called(events)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func([]models.Event) []string); ok {
+		r0 = rf(events)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]models.Event) error); ok {
+		r1 = rf(events)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddReading provides a mock function with given fields: r
 func (_m *DBClient) AddReading(r go_mod_core_contractsmodels.Reading) (string, error) {
 	ret := _m.Called(r)
@@ -289,6 +313,52 @@ func (_m *DBClient) EventsByCreationTime(startTime int64, endTime int64, limit i
 	return r0, r1
 }
 
+// EventsByCreationTimeAfter provides a mock function with given fields: startTime, endTime, afterCreated, afterId, limit
+func (_m *DBClient) EventsByCreationTimeAfter(startTime int64, endTime int64, afterCreated int64, afterId string, limit int) ([]go_mod_core_contractsmodels.Event, error) {
+	ret := _m.Called(startTime, endTime, afterCreated, afterId, limit)
+
+	var r0 []go_mod_core_contractsmodels.Event
+	if rf, ok := ret.Get(0).(func(int64, int64, int64, string, int) []go_mod_core_contractsmodels.Event); ok {
+		r0 = rf(startTime, endTime, afterCreated, afterId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]go_mod_core_contractsmodels.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, int64, int64, string, int) error); ok {
+		r1 = rf(startTime, endTime, afterCreated, afterId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EventsByCreationTimeWithOffset provides a mock function with given fields: startTime, endTime, limit, offset
+func (_m *DBClient) EventsByCreationTimeWithOffset(startTime int64, endTime int64, limit int, offset int) ([]go_mod_core_contractsmodels.Event, error) {
+	ret := _m.Called(startTime, endTime, limit, offset)
+
+	var r0 []go_mod_core_contractsmodels.Event
+	if rf, ok := ret.Get(0).(func(int64, int64, int, int) []go_mod_core_contractsmodels.Event); ok {
+		r0 = rf(startTime, endTime, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]go_mod_core_contractsmodels.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, int64, int, int) error); ok {
+		r1 = rf(startTime, endTime, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // EventsForDevice provides a mock function with given fields: id
 func (_m *DBClient) EventsForDevice(id string) ([]go_mod_core_contractsmodels.Event, error) {
 	ret := _m.Called(id)
@@ -335,6 +405,29 @@ func (_m *DBClient) EventsForDeviceLimit(id string, limit int) ([]go_mod_core_co
 	return r0, r1
 }
 
+// EventsForDeviceWithOffset provides a mock function with given fields: id, limit, offset
+func (_m *DBClient) EventsForDeviceWithOffset(id string, limit int, offset int) ([]go_mod_core_contractsmodels.Event, error) {
+	ret := _m.Called(id, limit, offset)
+
+	var r0 []go_mod_core_contractsmodels.Event
+	if rf, ok := ret.Get(0).(func(string, int, int) []go_mod_core_contractsmodels.Event); ok {
+		r0 = rf(id, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]go_mod_core_contractsmodels.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int, int) error); ok {
+		r1 = rf(id, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // EventsOlderThanAge provides a mock function with given fields: age
 func (_m *DBClient) EventsOlderThanAge(age int64) ([]go_mod_core_contractsmodels.Event, error) {
 	ret := _m.Called(age)
@@ -404,6 +497,29 @@ func (_m *DBClient) EventsWithLimit(limit int) ([]go_mod_core_contractsmodels.Ev
 	return r0, r1
 }
 
+// ReadingBuckets provides a mock function with given fields: deviceId, valueDescriptor, start, end, width
+func (_m *DBClient) ReadingBuckets(deviceId string, valueDescriptor string, start int64, end int64, width int64) ([]db.ReadingBucket, error) {
+	ret := _m.Called(deviceId, valueDescriptor, start, end, width)
+
+	var r0 []db.ReadingBucket
+	if rf, ok := ret.Get(0).(func(string, string, int64, int64, int64) []db.ReadingBucket); ok {
+		r0 = rf(deviceId, valueDescriptor, start, end, width)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ReadingBucket)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, int64, int64, int64) error); ok {
+		r1 = rf(deviceId, valueDescriptor, start, end, width)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReadingById provides a mock function with given fields: id
 func (_m *DBClient) ReadingById(id string) (go_mod_core_contractsmodels.Reading, error) {
 	ret := _m.Called(id)
@@ -492,6 +608,29 @@ func (_m *DBClient) ReadingsByCreationTime(start int64, end int64, limit int) ([
 	return r0, r1
 }
 
+// ReadingsByCreationTimeWithOffset provides a mock function with given fields: start, end, limit, offset
+func (_m *DBClient) ReadingsByCreationTimeWithOffset(start int64, end int64, limit int, offset int) ([]go_mod_core_contractsmodels.Reading, error) {
+	ret := _m.Called(start, end, limit, offset)
+
+	var r0 []go_mod_core_contractsmodels.Reading
+	if rf, ok := ret.Get(0).(func(int64, int64, int, int) []go_mod_core_contractsmodels.Reading); ok {
+		r0 = rf(start, end, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]go_mod_core_contractsmodels.Reading)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64, int64, int, int) error); ok {
+		r1 = rf(start, end, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReadingsByDevice provides a mock function with given fields: id, limit
 func (_m *DBClient) ReadingsByDevice(id string, limit int) ([]go_mod_core_contractsmodels.Reading, error) {
 	ret := _m.Called(id, limit)
@@ -538,6 +677,29 @@ func (_m *DBClient) ReadingsByDeviceAndValueDescriptor(deviceId string, valueDes
 	return r0, r1
 }
 
+// ReadingsByDeviceWithOffset provides a mock function with given fields: id, limit, offset
+func (_m *DBClient) ReadingsByDeviceWithOffset(id string, limit int, offset int) ([]go_mod_core_contractsmodels.Reading, error) {
+	ret := _m.Called(id, limit, offset)
+
+	var r0 []go_mod_core_contractsmodels.Reading
+	if rf, ok := ret.Get(0).(func(string, int, int) []go_mod_core_contractsmodels.Reading); ok {
+		r0 = rf(id, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]go_mod_core_contractsmodels.Reading)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int, int) error); ok {
+		r1 = rf(id, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReadingsByValueDescriptor provides a mock function with given fields: name, limit
 func (_m *DBClient) ReadingsByValueDescriptor(name string, limit int) ([]go_mod_core_contractsmodels.Reading, error) {
 	ret := _m.Called(name, limit)
@@ -584,6 +746,29 @@ func (_m *DBClient) ReadingsByValueDescriptorNames(names []string, limit int) ([
 	return r0, r1
 }
 
+// ReadingsByValueDescriptorWithOffset provides a mock function with given fields: name, limit, offset
+func (_m *DBClient) ReadingsByValueDescriptorWithOffset(name string, limit int, offset int) ([]go_mod_core_contractsmodels.Reading, error) {
+	ret := _m.Called(name, limit, offset)
+
+	var r0 []go_mod_core_contractsmodels.Reading
+	if rf, ok := ret.Get(0).(func(string, int, int) []go_mod_core_contractsmodels.Reading); ok {
+		r0 = rf(name, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]go_mod_core_contractsmodels.Reading)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int, int) error); ok {
+		r1 = rf(name, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ScrubAllEvents provides a mock function with given fields:
 func (_m *DBClient) ScrubAllEvents() error {
 	ret := _m.Called()
@@ -36,6 +36,11 @@ type EventReader interface {
 	Read(reader io.Reader, ctx *context.Context) (models.Event, error)
 }
 
+// EventsReader unmarshals a request body holding a batch of events
+type EventsReader interface {
+	ReadAll(reader io.Reader, ctx *context.Context) ([]models.Event, error)
+}
+
 // jsonReader handles unmarshaling of a JSON request body payload
 type jsonReader struct{}
 
@@ -53,6 +58,20 @@ func (jsonReader) Read(reader io.Reader, ctx *context.Context) (models.Event, er
 	return event, nil
 }
 
+// ReadAll reads and converts the request's JSON array of events into Event structs
+func (jsonReader) ReadAll(reader io.Reader, ctx *context.Context) ([]models.Event, error) {
+	c := context.WithValue(*ctx, clients.ContentType, clients.ContentTypeJSON)
+	*ctx = c
+
+	var events []models.Event
+	err := json.NewDecoder(reader).Decode(&events)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
 // cborReader handles unmarshaling of a CBOR request body payload
 type cborReader struct{}
 
@@ -71,12 +90,7 @@ func (cborReader) Read(reader io.Reader, ctx *context.Context) (models.Event, er
 		return event, err
 	}
 
-	switch Configuration.Writable.ChecksumAlgo {
-	case ChecksumAlgoxxHash:
-		event.Checksum = fmt.Sprintf("%x", xxhash.Checksum64(bytes))
-	default:
-		event.Checksum = fmt.Sprintf("%x", md5.Sum(bytes))
-	}
+	event.Checksum = checksum(bytes)
 	c = context.WithValue(c, checksumContextKey, event.Checksum)
 	*ctx = c
 	event.Bytes = bytes
@@ -84,6 +98,49 @@ func (cborReader) Read(reader io.Reader, ctx *context.Context) (models.Event, er
 	return event, nil
 }
 
+// ReadAll reads and converts the request's CBOR sequence (RFC 8742) of events into Event structs, each event holds
+// the checksum of its own CBOR data item
+func (cborReader) ReadAll(reader io.Reader, ctx *context.Context) ([]models.Event, error) {
+	c := context.WithValue(*ctx, clients.ContentType, clients.ContentTypeCBOR)
+	*ctx = c
+
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []models.Event
+	x := codec.CborHandle{}
+	decoder := codec.NewDecoderBytes(bytes, &x)
+	for decoder.NumBytesRead() < len(bytes) {
+		start := decoder.NumBytesRead()
+		event := models.Event{}
+		err = decoder.Decode(&event)
+		if err != nil {
+			return nil, err
+		}
+		// the decoder does not always notice an event cut short at the end of the payload
+		if decoder.NumBytesRead() > len(bytes) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		event.Bytes = bytes[start:decoder.NumBytesRead()]
+		event.Checksum = checksum(event.Bytes)
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// checksum returns the checksum of an event's payload using the configured algorithm
+func checksum(bytes []byte) string {
+	switch Configuration.Writable.ChecksumAlgo {
+	case ChecksumAlgoxxHash:
+		return fmt.Sprintf("%x", xxhash.Checksum64(bytes))
+	default:
+		return fmt.Sprintf("%x", md5.Sum(bytes))
+	}
+}
+
 // NewRequestReader returns a BodyReader capable of processing the request body
 func NewRequestReader(request *http.Request) EventReader {
 	contentType := request.Header.Get(clients.ContentType)
@@ -95,3 +152,15 @@ func NewRequestReader(request *http.Request) EventReader {
 		return jsonReader{}
 	}
 }
+
+// NewBatchRequestReader returns an EventsReader capable of processing the request body holding a batch of events
+func NewBatchRequestReader(request *http.Request) EventsReader {
+	contentType := request.Header.Get(clients.ContentType)
+
+	switch strings.ToLower(contentType) {
+	case clients.ContentTypeCBOR:
+		return cborReader{}
+	default:
+		return jsonReader{}
+	}
+}
@@ -3,6 +3,7 @@ package data
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -105,6 +106,65 @@ func TestCborSerialization(t *testing.T) {
 
 }
 
+func TestJsonBatchSerialization(t *testing.T) {
+	events := []models.Event{{Event: TestEvent}, {Event: TestEvent}}
+	data, _ := json.Marshal(events)
+	r := ioutil.NopCloser(bytes.NewBuffer(data))
+
+	ctx := context.Background()
+	result, err := jsonReader{}.ReadAll(r, &ctx)
+
+	if err != nil {
+		t.Errorf("Should not encounter an error")
+	}
+	if len(result) != len(events) {
+		t.Errorf("TestJsonBatchSerialization() read %d events, want %d", len(result), len(events))
+	}
+	if clients.FromContext(clients.ContentType, ctx) != clients.ContentTypeJSON {
+		t.Errorf("TestJsonBatchSerialization() should set the JSON content type")
+	}
+}
+
+func TestCborSequenceSerialization(t *testing.T) {
+	reset()
+	other := TestEvent
+	other.Device = "OtherDevice"
+	first := models.Event{Event: TestEvent}
+	second := models.Event{Event: other}
+	data := append(first.CBOR(), second.CBOR()...)
+	r := ioutil.NopCloser(bytes.NewBuffer(data))
+
+	ctx := context.Background()
+	result, err := cborReader{}.ReadAll(r, &ctx)
+
+	if err != nil {
+		t.Fatalf("Should not encounter an error: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("TestCborSequenceSerialization() read %d events, want 2", len(result))
+	}
+	if !reflect.DeepEqual(*result[1].ToContract(), other) {
+		t.Errorf("TestCborSequenceSerialization() = %v, want %v", result[1], other)
+	}
+	if result[0].Checksum != checksum(first.CBOR()) || result[1].Checksum != checksum(second.CBOR()) {
+		t.Errorf("TestCborSequenceSerialization() should checksum each event of the sequence")
+	}
+}
+
+func TestCborSequenceSerializationInvalid(t *testing.T) {
+	reset()
+	event := models.Event{Event: TestEvent}
+	data := event.CBOR()
+	r := ioutil.NopCloser(bytes.NewBuffer(data[:len(data)-1]))
+
+	ctx := context.Background()
+	_, err := cborReader{}.ReadAll(r, &ctx)
+
+	if err == nil {
+		t.Errorf("Should encounter an error for a truncated sequence")
+	}
+}
+
 func newRequestWithContentType(contentType string) *http.Request {
 	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader("Test body"))
 	req.Header.Set(clients.ContentType, contentType)
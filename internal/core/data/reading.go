@@ -81,6 +81,14 @@ func validateReading(reading contract.Reading, loggingClient logger.LoggingClien
 		return err
 	}
 
+	// A reading on its own is not checked against the device profile, nor does it have an event to be flagged on
+	_, err = applyValidationRules(vd, nil, reading, loggingClient)
+	if err != nil {
+		loggingClient.Error(err.Error())
+		return err
+	}
+	readingHistory.record(reading)
+
 	return nil
 }
 
@@ -172,14 +180,14 @@ func countReadings(loggingClient logger.LoggingClient) (count int, err error) {
 	return count, nil
 }
 
-func getReadingsByDevice(deviceId string, limit int, ctx context.Context, loggingClient logger.LoggingClient) (readings []contract.Reading, err error) {
+func getReadingsByDevice(deviceId string, limit int, offset int, ctx context.Context, loggingClient logger.LoggingClient) (readings []contract.Reading, err error) {
 	if checkDevice(deviceId, ctx) != nil {
 		loggingClient.Error(fmt.Sprintf("error checking device %s %v", deviceId, err))
 
 		return []contract.Reading{}, err
 	}
 
-	readings, err = dbClient.ReadingsByDevice(deviceId, limit)
+	readings, err = dbClient.ReadingsByDeviceWithOffset(deviceId, limit, offset)
 	if err != nil {
 		loggingClient.Error(err.Error())
 		return []contract.Reading{}, err
@@ -188,7 +196,7 @@ func getReadingsByDevice(deviceId string, limit int, ctx context.Context, loggin
 	return readings, nil
 }
 
-func getReadingsByValueDescriptor(name string, limit int, loggingClient logger.LoggingClient) (readings []contract.Reading, err error) {
+func getReadingsByValueDescriptor(name string, limit int, offset int, loggingClient logger.LoggingClient) (readings []contract.Reading, err error) {
 	// Limit is too large
 	err = checkMaxLimit(limit, loggingClient)
 	if err != nil {
@@ -203,7 +211,7 @@ func getReadingsByValueDescriptor(name string, limit int, loggingClient logger.L
 		}
 	}
 
-	readings, err = dbClient.ReadingsByValueDescriptor(name, limit)
+	readings, err = dbClient.ReadingsByValueDescriptorWithOffset(name, limit, offset)
 	if err != nil {
 		loggingClient.Error(err.Error())
 		return []contract.Reading{}, err
@@ -222,8 +230,8 @@ func getReadingsByValueDescriptorNames(listOfNames []string, limit int, loggingC
 	return readings, nil
 }
 
-func getReadingsByCreationTime(start int64, end int64, limit int, loggingClient logger.LoggingClient) (readings []contract.Reading, err error) {
-	readings, err = dbClient.ReadingsByCreationTime(start, end, limit)
+func getReadingsByCreationTime(start int64, end int64, limit int, offset int, loggingClient logger.LoggingClient) (readings []contract.Reading, err error) {
+	readings, err = dbClient.ReadingsByCreationTimeWithOffset(start, end, limit, offset)
 	if err != nil {
 		loggingClient.Error(err.Error())
 		return nil, err
@@ -229,11 +229,11 @@ func TestGetReadingsByDevice(t *testing.T) {
 	reset()
 	myMock := &dbMock.DBClient{}
 
-	myMock.On("ReadingsByDevice", mock.Anything, mock.Anything).Return(buildReadings(), nil)
+	myMock.On("ReadingsByDeviceWithOffset", mock.Anything, mock.Anything, mock.Anything).Return(buildReadings(), nil)
 
 	dbClient = myMock
 
-	expectedReadings, err := getReadingsByDevice("valid", 0, context.Background(), logger.NewMockClient())
+	expectedReadings, err := getReadingsByDevice("valid", 0, 0, context.Background(), logger.NewMockClient())
 
 	if err != nil {
 		t.Errorf("Unexpected error in getReadingsByDevice")
@@ -248,11 +248,11 @@ func TestGetReadingsByDeviceError(t *testing.T) {
 	reset()
 	myMock := &dbMock.DBClient{}
 
-	myMock.On("ReadingsByDevice", mock.Anything, mock.Anything).Return([]models.Reading{}, fmt.Errorf("some error"))
+	myMock.On("ReadingsByDeviceWithOffset", mock.Anything, mock.Anything, mock.Anything).Return([]models.Reading{}, fmt.Errorf("some error"))
 
 	dbClient = myMock
 
-	_, err := getReadingsByDevice("error", 0, context.Background(), logger.NewMockClient())
+	_, err := getReadingsByDevice("error", 0, 0, context.Background(), logger.NewMockClient())
 
 	if err == nil {
 		t.Errorf("Expected error in getReadingsByDevice")
@@ -263,11 +263,11 @@ func TestGetReadingsByValueDescriptor(t *testing.T) {
 	reset()
 	myMock := &dbMock.DBClient{}
 
-	myMock.On("ReadingsByValueDescriptor", mock.Anything, mock.Anything).Return([]models.Reading{}, nil)
+	myMock.On("ReadingsByValueDescriptorWithOffset", mock.Anything, mock.Anything, mock.Anything).Return([]models.Reading{}, nil)
 
 	dbClient = myMock
 
-	_, err := getReadingsByValueDescriptor("valid", 0, logger.NewMockClient())
+	_, err := getReadingsByValueDescriptor("valid", 0, 0, logger.NewMockClient())
 
 	if err != nil {
 		t.Errorf("Unexpected error getting readings by value descriptor")
@@ -278,7 +278,7 @@ func TestGetReadingsByValueDescriptorOverLimit(t *testing.T) {
 	reset()
 	dbClient = nil
 
-	_, err := getReadingsByValueDescriptor("", math.MaxInt32, logger.NewMockClient())
+	_, err := getReadingsByValueDescriptor("", math.MaxInt32, 0, logger.NewMockClient())
 
 	if err == nil {
 		t.Errorf("Expected error getting readings by value descriptor")
@@ -289,11 +289,11 @@ func TestGetReadingsByValueDescriptorError(t *testing.T) {
 	reset()
 	myMock := &dbMock.DBClient{}
 
-	myMock.On("ReadingsByValueDescriptor", mock.Anything, mock.Anything).Return([]models.Reading{}, fmt.Errorf("some error"))
+	myMock.On("ReadingsByValueDescriptorWithOffset", mock.Anything, mock.Anything, mock.Anything).Return([]models.Reading{}, fmt.Errorf("some error"))
 
 	dbClient = myMock
 
-	_, err := getReadingsByValueDescriptor("error", 0, logger.NewMockClient())
+	_, err := getReadingsByValueDescriptor("error", 0, 0, logger.NewMockClient())
 
 	if err == nil {
 		t.Errorf("Expected error in getting readings by value descriptor")
@@ -334,11 +334,11 @@ func TestGetReadingsByCreationTime(t *testing.T) {
 	reset()
 	myMock := &dbMock.DBClient{}
 
-	myMock.On("ReadingsByCreationTime", mock.Anything, mock.Anything, mock.Anything).Return([]models.Reading{}, nil)
+	myMock.On("ReadingsByCreationTimeWithOffset", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.Reading{}, nil)
 
 	dbClient = myMock
 
-	_, err := getReadingsByCreationTime(0xBEEF, 0, 0, logger.NewMockClient())
+	_, err := getReadingsByCreationTime(0xBEEF, 0, 0, 0, logger.NewMockClient())
 
 	if err != nil {
 		t.Errorf("Unexpected error getting readings by creation time")
@@ -349,11 +349,11 @@ func TestGetReadingsByCreationTimeError(t *testing.T) {
 	reset()
 	myMock := &dbMock.DBClient{}
 
-	myMock.On("ReadingsByCreationTime", mock.Anything, mock.Anything, mock.Anything).Return([]models.Reading{}, fmt.Errorf("some error"))
+	myMock.On("ReadingsByCreationTimeWithOffset", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]models.Reading{}, fmt.Errorf("some error"))
 
 	dbClient = myMock
 
-	_, err := getReadingsByCreationTime(0xDEADBEEF, 0, 0, logger.NewMockClient())
+	_, err := getReadingsByCreationTime(0xDEADBEEF, 0, 0, 0, logger.NewMockClient())
 
 	if err == nil {
 		t.Errorf("Expected error in getting readings by creation time")
@@ -0,0 +1,483 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+const (
+	// RollupDeviceSuffix is appended to the device name of the events holding the hourly aggregates of a device
+	RollupDeviceSuffix = ".hourly"
+
+	// The suffixes of the names of the readings holding the statistics of a value descriptor in an hourly aggregate,
+	// the reading named after the value descriptor holding the mean
+	RollupMinSuffix   = ".min"
+	RollupMaxSuffix   = ".max"
+	RollupSumSuffix   = ".sum"
+	RollupCountSuffix = ".count"
+
+	retentionHour  = int64(time.Hour / time.Millisecond)
+	retentionDay   = 24 * retentionHour
+	retentionMonth = 30 * retentionDay
+
+	// retentionPageSize is the number of events the retention job reads at once
+	retentionPageSize = 1000
+)
+
+// rollupNamespace is the namespace of the ids of the hourly aggregate events, which are derived from their device,
+// hour and policy so that rolling up the same events again replaces their aggregate
+var rollupNamespace = uuid.MustParse("6f1a3c52-9a0e-4d7b-8c21-5e4b7f9d0a13")
+
+// RetentionReport describes what a run of the retention job removed and created
+type RetentionReport struct {
+	Started           int64  `json:"started"`
+	Finished          int64  `json:"finished"`
+	EventsRemoved     int    `json:"eventsRemoved"`
+	ReadingsRemoved   int    `json:"readingsRemoved"`
+	AggregatesCreated int    `json:"aggregatesCreated"`
+	AggregatesRemoved int    `json:"aggregatesRemoved"`
+	Error             string `json:"error,omitempty"`
+}
+
+// lastRetention holds the report of the most recent run of the retention job
+var lastRetention struct {
+	mutex  sync.Mutex
+	report RetentionReport
+}
+
+// retentionRun is held by the run of the retention job in progress, no other run starting until it is released
+var retentionRun = make(chan struct{}, 1)
+
+// errRetentionRunning is returned when the retention job is asked to run while a run is in progress
+var errRetentionRunning = errors.New("the retention job is already running")
+
+// rollupKey identifies the hourly aggregate event of a device under a policy
+type rollupKey struct {
+	device string
+	start  int64
+	policy string
+}
+
+// startRetention runs the retention policies at the configured interval until the context is done
+func startRetention(wg *sync.WaitGroup, ctx context.Context, loggingClient logger.LoggingClient) {
+	if Configuration.Retention.Interval == "" {
+		return
+	}
+
+	interval, err := time.ParseDuration(Configuration.Retention.Interval)
+	if err != nil || interval <= 0 {
+		loggingClient.Error(fmt.Sprintf("invalid retention interval '%s', retention disabled", Configuration.Retention.Interval))
+		return
+	}
+
+	loggingClient.Info(fmt.Sprintf("Retention starting, running every %s", interval))
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				loggingClient.Info("Retention stopped")
+				return
+			case <-ticker.C:
+				if _, err := runRetention(loggingClient); err != nil {
+					loggingClient.Warn(fmt.Sprintf("scheduled retention skipped: %s", err.Error()))
+				}
+			}
+		}
+	}()
+}
+
+// runRetention applies the configured retention policies once and records the report. It returns
+// errRetentionRunning without running the policies while another run is in progress, the failure of the run
+// itself being given by the Error of the report.
+func runRetention(loggingClient logger.LoggingClient) (RetentionReport, error) {
+	select {
+	case retentionRun <- struct{}{}:
+	default:
+		return RetentionReport{}, errRetentionRunning
+	}
+	defer func() { <-retentionRun }()
+
+	report, err := applyRetention(db.MakeTimestamp(), Configuration.Retention.Policies, loggingClient)
+	if err != nil {
+		loggingClient.Error(fmt.Sprintf("retention failed: %s", err.Error()))
+		report.Error = err.Error()
+	}
+	report.Finished = db.MakeTimestamp()
+
+	loggingClient.Info(fmt.Sprintf(
+		"retention removed %d events (%d readings) and %d aggregates, created %d aggregates",
+		report.EventsRemoved,
+		report.ReadingsRemoved,
+		report.AggregatesRemoved,
+		report.AggregatesCreated))
+
+	lastRetention.mutex.Lock()
+	lastRetention.report = report
+	lastRetention.mutex.Unlock()
+
+	return report, nil
+}
+
+// lastRetentionReport returns the report of the most recent run of the retention job
+func lastRetentionReport() RetentionReport {
+	lastRetention.mutex.Lock()
+	defer lastRetention.mutex.Unlock()
+
+	return lastRetention.report
+}
+
+// applyRetention removes the hourly aggregates which have expired and then rolls up and removes the events
+// which are older than their policy allows
+func applyRetention(
+	now int64,
+	policies []RetentionPolicyInfo,
+	loggingClient logger.LoggingClient) (RetentionReport, error) {
+
+	report := RetentionReport{Started: now}
+
+	var valid []RetentionPolicyInfo
+	for _, p := range policies {
+		if p.RawDays <= 0 || p.AggregateMonths < 0 {
+			loggingClient.Warn(fmt.Sprintf("ignoring retention policy for device '%s' value descriptor '%s': "+
+				"RawDays must be greater than 0 and AggregateMonths must not be negative", p.Device, p.ValueDescriptor))
+			continue
+		}
+		valid = append(valid, p)
+	}
+	if len(valid) == 0 {
+		return report, nil
+	}
+
+	if err := expireAggregates(now, valid, &report, loggingClient); err != nil {
+		return report, err
+	}
+	if err := downsampleEvents(now, valid, &report, loggingClient); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// rawCutoff returns the creation time before which events fall under the rollup of the policy. It is aligned
+// on the hour so that every hour is rolled up in a single run.
+func rawCutoff(now int64, p RetentionPolicyInfo) int64 {
+	return db.BucketStart(now-int64(p.RawDays)*retentionDay, 0, retentionHour)
+}
+
+// aggregateCutoff returns the creation time before which the aggregates of the policy are removed
+func aggregateCutoff(now int64, p RetentionPolicyInfo) int64 {
+	return now - int64(p.AggregateMonths)*retentionMonth
+}
+
+// retentionPolicyFor returns the policy applying to the events of the device with the given readings. A device
+// policy takes precedence over a value descriptor policy, which takes precedence over the default policy. A value
+// descriptor policy only applies to the events whose readings are all for the value descriptor, so that removing them
+// removes no reading of another value descriptor.
+func retentionPolicyFor(device string, readings []contract.Reading, policies []RetentionPolicyInfo) (RetentionPolicyInfo, bool) {
+	for _, p := range policies {
+		if p.Device != "" && p.Device == device {
+			return p, true
+		}
+	}
+	if len(readings) > 0 {
+		for _, p := range policies {
+			if p.Device == "" && p.ValueDescriptor != "" && onlyReadingsFor(readings, p.ValueDescriptor) {
+				return p, true
+			}
+		}
+	}
+	for _, p := range policies {
+		if p.Device == "" && p.ValueDescriptor == "" {
+			return p, true
+		}
+	}
+	return RetentionPolicyInfo{}, false
+}
+
+// onlyReadingsFor tells whether the readings are all for the value descriptor
+func onlyReadingsFor(readings []contract.Reading, valueDescriptor string) bool {
+	for _, r := range readings {
+		if r.Name != valueDescriptor {
+			return false
+		}
+	}
+	return true
+}
+
+// rolledUpReadings returns the readings of an hourly aggregate named after the value descriptor of their statistic
+func rolledUpReadings(readings []contract.Reading) []contract.Reading {
+	rolledUp := make([]contract.Reading, len(readings))
+	for i, r := range readings {
+		for _, suffix := range []string{RollupMinSuffix, RollupMaxSuffix, RollupSumSuffix, RollupCountSuffix} {
+			if strings.HasSuffix(r.Name, suffix) {
+				r.Name = strings.TrimSuffix(r.Name, suffix)
+				break
+			}
+		}
+		rolledUp[i] = r
+	}
+	return rolledUp
+}
+
+// eventsCreatedBefore calls fn with the events created before the cutoff, a page at a time in ascending order of
+// creation time. The events of a page may be removed by fn.
+func eventsCreatedBefore(cutoff int64, fn func(events []contract.Event) error) error {
+	var afterCreated int64
+	var afterId string
+	for {
+		events, err := dbClient.EventsByCreationTimeAfter(0, cutoff-1, afterCreated, afterId, retentionPageSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		last := events[len(events)-1]
+		afterCreated, afterId = last.Created, last.ID
+
+		if err := fn(events); err != nil {
+			return err
+		}
+		if len(events) < retentionPageSize {
+			return nil
+		}
+	}
+}
+
+// latestCutoff returns the most recent of the cutoffs
+func latestCutoff(cutoffs []int64) int64 {
+	latest := cutoffs[0]
+	for _, c := range cutoffs[1:] {
+		if c > latest {
+			latest = c
+		}
+	}
+	return latest
+}
+
+// expireAggregates removes the hourly aggregate events which are older than their policy allows
+func expireAggregates(
+	now int64,
+	policies []RetentionPolicyInfo,
+	report *RetentionReport,
+	loggingClient logger.LoggingClient) error {
+
+	var cutoffs []int64
+	for _, p := range policies {
+		if p.AggregateMonths > 0 {
+			cutoffs = append(cutoffs, aggregateCutoff(now, p))
+		}
+	}
+	if len(cutoffs) == 0 {
+		return nil
+	}
+
+	return eventsCreatedBefore(latestCutoff(cutoffs), func(events []contract.Event) error {
+		for _, e := range events {
+			if !strings.HasSuffix(e.Device, RollupDeviceSuffix) {
+				continue
+			}
+			device := strings.TrimSuffix(e.Device, RollupDeviceSuffix)
+			p, ok := retentionPolicyFor(device, rolledUpReadings(e.Readings), policies)
+			if !ok || e.Created >= aggregateCutoff(now, p) {
+				continue
+			}
+			if err := deleteEvent(e, loggingClient); err != nil {
+				return err
+			}
+			report.AggregatesRemoved++
+		}
+		return nil
+	})
+}
+
+// downsampleEvents rolls up the numeric readings of the events which are older than their policy allows into
+// hourly aggregate events, then removes the events. The events are processed an hour at a time, the aggregates of
+// the hour being stored before its events are removed. As the aggregates are replaced when the same events are
+// rolled up again, a run which failed before removing the events of an hour is completed by the next one.
+func downsampleEvents(
+	now int64,
+	policies []RetentionPolicyInfo,
+	report *RetentionReport,
+	loggingClient logger.LoggingClient) error {
+
+	cutoffs := make([]int64, len(policies))
+	for i, p := range policies {
+		cutoffs[i] = rawCutoff(now, p)
+	}
+
+	hour := int64(-1)
+	var expired []contract.Event
+	rollups := make(map[rollupKey]map[string]*db.ReadingBucket)
+	flush := func() error {
+		for _, e := range rollupEvents(rollups) {
+			if err := replaceEvent(e, loggingClient); err != nil {
+				return err
+			}
+			report.AggregatesCreated++
+		}
+		for _, e := range expired {
+			if err := deleteEvent(e, loggingClient); err != nil {
+				return err
+			}
+			report.EventsRemoved++
+			report.ReadingsRemoved += len(e.Readings)
+		}
+		expired = nil
+		rollups = make(map[rollupKey]map[string]*db.ReadingBucket)
+		return nil
+	}
+
+	err := eventsCreatedBefore(latestCutoff(cutoffs), func(events []contract.Event) error {
+		for _, e := range events {
+			if strings.HasSuffix(e.Device, RollupDeviceSuffix) {
+				continue
+			}
+			p, ok := retentionPolicyFor(e.Device, e.Readings, policies)
+			if !ok || e.Created >= rawCutoff(now, p) {
+				continue
+			}
+
+			start := db.BucketStart(e.Created, 0, retentionHour)
+			if start != hour {
+				if err := flush(); err != nil {
+					return err
+				}
+				hour = start
+			}
+			expired = append(expired, e)
+
+			if p.AggregateMonths == 0 {
+				continue
+			}
+			key := rollupKey{device: e.Device, start: start, policy: p.Device + "/" + p.ValueDescriptor}
+			for _, r := range e.Readings {
+				value, err := strconv.ParseFloat(r.Value, 64)
+				if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+					continue
+				}
+				if rollups[key] == nil {
+					rollups[key] = make(map[string]*db.ReadingBucket)
+				}
+				b, ok := rollups[key][r.Name]
+				if !ok {
+					b = &db.ReadingBucket{Start: key.start}
+					rollups[key][r.Name] = b
+				}
+				b.Add(value)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// replaceEvent adds the event, replacing the event stored with the same id
+func replaceEvent(e contract.Event, loggingClient logger.LoggingClient) error {
+	stored, err := dbClient.EventById(e.ID)
+	if err == nil {
+		if err := deleteEvent(stored, loggingClient); err != nil {
+			return err
+		}
+	} else if err != db.ErrNotFound {
+		return err
+	}
+
+	_, err = dbClient.AddEvent(models.Event{Event: e})
+	return err
+}
+
+// rollupEvents returns the hourly aggregate events, sorted by device and hour. Each value descriptor has a reading
+// holding its mean and readings holding its minimum, maximum, sum and count, so that the aggregates of a device and
+// hour rolled up under different policies can be combined. The aggregates are created and originated at the start of
+// their hour so that they expire with the events they were rolled up from rather than with the run creating them.
+func rollupEvents(rollups map[rollupKey]map[string]*db.ReadingBucket) []contract.Event {
+	keys := make([]rollupKey, 0, len(rollups))
+	for k := range rollups {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].device != keys[j].device {
+			return keys[i].device < keys[j].device
+		}
+		if keys[i].start != keys[j].start {
+			return keys[i].start < keys[j].start
+		}
+		return keys[i].policy < keys[j].policy
+	})
+
+	events := make([]contract.Event, len(keys))
+	for i, k := range keys {
+		names := make([]string, 0, len(rollups[k]))
+		for name := range rollups[k] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		device := k.device + RollupDeviceSuffix
+		var readings []contract.Reading
+		for _, name := range names {
+			b := rollups[k][name]
+			for _, statistic := range []struct {
+				suffix string
+				value  float64
+			}{
+				{"", b.Sum / float64(b.Count)},
+				{RollupMinSuffix, b.Min},
+				{RollupMaxSuffix, b.Max},
+				{RollupSumSuffix, b.Sum},
+				{RollupCountSuffix, float64(b.Count)},
+			} {
+				readings = append(readings, contract.Reading{
+					Device:  device,
+					Name:    name + statistic.suffix,
+					Created: k.start,
+					Origin:  k.start,
+					Value:   strconv.FormatFloat(statistic.value, 'f', -1, 64),
+				})
+			}
+		}
+		id := uuid.NewSHA1(rollupNamespace, []byte(fmt.Sprintf("%s/%d/%s", k.device, k.start, k.policy)))
+		events[i] = contract.Event{ID: id.String(), Device: device, Created: k.start, Origin: k.start, Readings: readings}
+	}
+
+	return events
+}
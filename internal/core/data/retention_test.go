@@ -0,0 +1,328 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	dbMock "github.com/edgexfoundry/edgex-go/internal/core/data/interfaces/mocks"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+)
+
+const testRetentionNow = 1000*retentionDay + 30*60*1000
+
+func newRetentionMockDB(events []contract.Event, added *[]models.Event) *dbMock.DBClient {
+	myMock := &dbMock.DBClient{}
+
+	myMock.On("EventsByCreationTimeAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(func(start int64, end int64, afterCreated int64, afterId string, limit int) []contract.Event {
+			return createdAfter(events, start, end, afterCreated, afterId, limit)
+		}, nil)
+	myMock.On("EventById", mock.Anything).Return(contract.Event{}, db.ErrNotFound)
+	myMock.On("AddEvent", mock.Anything).Return("id", nil).Run(func(args mock.Arguments) {
+		*added = append(*added, args.Get(0).(models.Event))
+	})
+	myMock.On("DeleteReadingById", mock.Anything).Return(nil)
+	myMock.On("DeleteEventById", mock.Anything).Return(nil)
+
+	return myMock
+}
+
+// createdAfter returns the page of the events EventsByCreationTimeAfter returns
+func createdAfter(events []contract.Event, start int64, end int64, afterCreated int64, afterId string, limit int) []contract.Event {
+	sorted := append([]contract.Event{}, events...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Created != sorted[j].Created {
+			return sorted[i].Created < sorted[j].Created
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	page := []contract.Event{}
+	for _, e := range sorted {
+		if e.Created < start || e.Created > end {
+			continue
+		}
+		if afterId != "" && (e.Created < afterCreated || (e.Created == afterCreated && e.ID <= afterId)) {
+			continue
+		}
+		if len(page) == limit {
+			break
+		}
+		page = append(page, e)
+	}
+	return page
+}
+
+func TestApplyRetention(t *testing.T) {
+	reset()
+	rolledUp := testRetentionNow - 8*retentionDay
+	events := []contract.Event{
+		{ID: "raw1", Device: "dev", Created: rolledUp, Readings: []contract.Reading{
+			{Name: "temperature", Value: "1"},
+			{Name: "temperature", Value: "3"},
+			{Name: "status", Value: "ok"},
+		}},
+		{ID: "raw2", Device: "dev", Created: rolledUp + 60*1000, Readings: []contract.Reading{
+			{Name: "temperature", Value: "5"},
+		}},
+		{ID: "recent", Device: "dev", Created: testRetentionNow - retentionDay},
+		{ID: "other", Device: "other", Created: rolledUp},
+		{ID: "expired", Device: "dev" + RollupDeviceSuffix, Created: testRetentionNow - 40*retentionDay},
+		{ID: "aggregate", Device: "dev" + RollupDeviceSuffix, Created: testRetentionNow - 10*retentionDay},
+	}
+	var added []models.Event
+	myMock := newRetentionMockDB(events, &added)
+	dbClient = myMock
+
+	policies := []RetentionPolicyInfo{{Device: "dev", RawDays: 7, AggregateMonths: 1}}
+	report, err := applyRetention(testRetentionNow, policies, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error applying retention: %s", err.Error())
+	}
+
+	if report.EventsRemoved != 2 || report.ReadingsRemoved != 4 {
+		t.Errorf("Expected 2 events and 4 readings removed, got %d and %d", report.EventsRemoved, report.ReadingsRemoved)
+	}
+	if report.AggregatesRemoved != 1 || report.AggregatesCreated != 1 {
+		t.Errorf("Expected 1 aggregate removed and 1 created, got %d and %d", report.AggregatesRemoved, report.AggregatesCreated)
+	}
+	for _, id := range []string{"raw1", "raw2", "expired"} {
+		myMock.AssertCalled(t, "DeleteEventById", id)
+	}
+	for _, id := range []string{"recent", "other", "aggregate"} {
+		myMock.AssertNotCalled(t, "DeleteEventById", id)
+	}
+
+	if len(added) != 1 {
+		t.Fatalf("Expected 1 aggregate event, got %d", len(added))
+	}
+	aggregate := added[0]
+	hour := rolledUp - rolledUp%retentionHour
+	if aggregate.Device != "dev"+RollupDeviceSuffix || aggregate.Origin != hour || aggregate.Created != hour {
+		t.Errorf("Unexpected aggregate event device %s origin %d created %d", aggregate.Device, aggregate.Origin,
+			aggregate.Created)
+	}
+	if _, err := uuid.Parse(aggregate.ID); err != nil {
+		t.Errorf("Expected the aggregate event to be given an id, got %s", aggregate.ID)
+	}
+	expected := []struct {
+		name  string
+		value string
+	}{
+		{"temperature", "3"},
+		{"temperature" + RollupMinSuffix, "1"},
+		{"temperature" + RollupMaxSuffix, "5"},
+		{"temperature" + RollupSumSuffix, "9"},
+		{"temperature" + RollupCountSuffix, "3"},
+	}
+	if len(aggregate.Readings) != len(expected) {
+		t.Fatalf("Expected %d aggregate readings, got %d", len(expected), len(aggregate.Readings))
+	}
+	for i, r := range aggregate.Readings {
+		if r.Name != expected[i].name || r.Value != expected[i].value || r.Created != hour {
+			t.Errorf("Expected %s %s created at %d, got %s %s created at %d", expected[i].name, expected[i].value,
+				hour, r.Name, r.Value, r.Created)
+		}
+	}
+}
+
+func TestApplyRetentionReplacesAggregates(t *testing.T) {
+	reset()
+	rolledUp := testRetentionNow - 8*retentionDay
+	events := []contract.Event{
+		{ID: "raw", Device: "dev", Created: rolledUp, Readings: []contract.Reading{{Name: "temperature", Value: "1"}}},
+	}
+	policies := []RetentionPolicyInfo{{Device: "dev", RawDays: 7, AggregateMonths: 1}}
+
+	var first []models.Event
+	dbClient = newRetentionMockDB(events, &first)
+	if _, err := applyRetention(testRetentionNow, policies, logger.NewMockClient()); err != nil {
+		t.Fatalf("Unexpected error applying retention: %s", err.Error())
+	}
+
+	// the run is repeated as if it had failed before removing the event
+	var second []models.Event
+	myMock := &dbMock.DBClient{}
+	stored := first[0].Event
+	myMock.On("EventsByCreationTimeAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(func(start int64, end int64, afterCreated int64, afterId string, limit int) []contract.Event {
+			return createdAfter(events, start, end, afterCreated, afterId, limit)
+		}, nil)
+	myMock.On("EventById", stored.ID).Return(stored, nil)
+	myMock.On("AddEvent", mock.Anything).Return("id", nil).Run(func(args mock.Arguments) {
+		second = append(second, args.Get(0).(models.Event))
+	})
+	myMock.On("DeleteReadingById", mock.Anything).Return(nil)
+	myMock.On("DeleteEventById", mock.Anything).Return(nil)
+	dbClient = myMock
+
+	report, err := applyRetention(testRetentionNow, policies, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error applying retention: %s", err.Error())
+	}
+	if report.AggregatesCreated != 1 || len(second) != 1 || second[0].ID != stored.ID {
+		t.Fatalf("Expected the aggregate %s to be created again, got %v", stored.ID, second)
+	}
+	myMock.AssertCalled(t, "DeleteEventById", stored.ID)
+	myMock.AssertCalled(t, "DeleteEventById", "raw")
+}
+
+func TestApplyRetentionPages(t *testing.T) {
+	reset()
+	// events every minute over more than a page, each hour being rolled up into its own aggregate
+	count := retentionPageSize + retentionPageSize/2
+	start := db.BucketStart(testRetentionNow-9*retentionDay, 0, retentionHour)
+	events := make([]contract.Event, count)
+	for i := range events {
+		events[i] = contract.Event{ID: fmt.Sprintf("raw%04d", i), Device: "dev", Created: start + int64(i)*60*1000,
+			Readings: []contract.Reading{{Name: "temperature", Value: "1"}}}
+	}
+	var added []models.Event
+	myMock := newRetentionMockDB(events, &added)
+	dbClient = myMock
+
+	policies := []RetentionPolicyInfo{{Device: "dev", RawDays: 7, AggregateMonths: 1}}
+	report, err := applyRetention(testRetentionNow, policies, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error applying retention: %s", err.Error())
+	}
+
+	hours := (count + 59) / 60
+	if report.EventsRemoved != count || report.AggregatesCreated != hours || len(added) != hours {
+		t.Fatalf("Expected %d events removed into %d aggregates, got %+v", count, hours, report)
+	}
+	for i, a := range added {
+		if a.Created != start+int64(i)*retentionHour {
+			t.Errorf("Expected aggregate %d to be created at the start of its hour, got %d", i, a.Created)
+		}
+	}
+}
+
+func TestApplyRetentionWithoutAggregates(t *testing.T) {
+	reset()
+	events := []contract.Event{
+		{ID: "raw", Device: "dev", Created: testRetentionNow - 2*retentionDay, Readings: []contract.Reading{
+			{Name: "temperature", Value: "1"},
+		}},
+	}
+	var added []models.Event
+	myMock := newRetentionMockDB(events, &added)
+	dbClient = myMock
+
+	policies := []RetentionPolicyInfo{{ValueDescriptor: "temperature", RawDays: 1}}
+	report, err := applyRetention(testRetentionNow, policies, logger.NewMockClient())
+	if err != nil {
+		t.Fatalf("Unexpected error applying retention: %s", err.Error())
+	}
+
+	if report.EventsRemoved != 1 || report.AggregatesCreated != 0 || len(added) != 0 {
+		t.Errorf("Expected the event removed without aggregates, got %+v", report)
+	}
+}
+
+func TestApplyRetentionInvalidPolicies(t *testing.T) {
+	reset()
+	myMock := &dbMock.DBClient{}
+	dbClient = myMock
+
+	policies := []RetentionPolicyInfo{{Device: "dev"}, {Device: "dev", RawDays: 1, AggregateMonths: -1}}
+	_, err := applyRetention(testRetentionNow, policies, logger.NewMockClient())
+	if err != nil {
+		t.Errorf("Unexpected error applying retention: %s", err.Error())
+	}
+	myMock.AssertNotCalled(t, "EventsByCreationTimeAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything)
+}
+
+func TestApplyRetentionError(t *testing.T) {
+	reset()
+	myMock := &dbMock.DBClient{}
+	myMock.On("EventsByCreationTimeAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("some error"))
+	dbClient = myMock
+
+	policies := []RetentionPolicyInfo{{Device: "dev", RawDays: 1}}
+	_, err := applyRetention(testRetentionNow, policies, logger.NewMockClient())
+	if err == nil {
+		t.Errorf("Expected error applying retention")
+	}
+}
+
+func TestRunRetentionInProgress(t *testing.T) {
+	reset()
+	myMock := &dbMock.DBClient{}
+	dbClient = myMock
+
+	retentionRun <- struct{}{}
+	_, err := runRetention(logger.NewMockClient())
+	<-retentionRun
+	if err != errRetentionRunning {
+		t.Fatalf("Expected errRetentionRunning, got %v", err)
+	}
+	myMock.AssertNotCalled(t, "EventsByCreationTimeAfter", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+		mock.Anything)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/event/retention", nil)
+	retentionRun <- struct{}{}
+	rr := httptest.NewRecorder()
+	retentionHandler(rr, req, logger.NewMockClient())
+	<-retentionRun
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, rr.Code)
+	}
+}
+
+func TestRetentionPolicyFor(t *testing.T) {
+	policies := []RetentionPolicyInfo{
+		{ValueDescriptor: "temperature", RawDays: 2},
+		{RawDays: 3},
+		{Device: "dev", RawDays: 1},
+	}
+	tests := []struct {
+		name     string
+		device   string
+		readings []contract.Reading
+		expected int
+	}{
+		{"Device", "dev", []contract.Reading{{Name: "temperature"}}, 1},
+		{"Value descriptor", "other", []contract.Reading{{Name: "temperature"}, {Name: "temperature"}}, 2},
+		{"Other value descriptors", "other", []contract.Reading{{Name: "humidity"}, {Name: "temperature"}}, 3},
+		{"Default", "other", []contract.Reading{{Name: "humidity"}}, 3},
+		{"Aggregate", "other", rolledUpReadings([]contract.Reading{{Name: "temperature"},
+			{Name: "temperature" + RollupMinSuffix}, {Name: "temperature" + RollupCountSuffix}}), 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := retentionPolicyFor(tt.device, tt.readings, policies)
+			if !ok || p.RawDays != tt.expected {
+				t.Errorf("Expected the policy keeping %d days, got %+v", tt.expected, p)
+			}
+		})
+	}
+
+	if _, ok := retentionPolicyFor("other", nil, policies[:1]); ok {
+		t.Errorf("Expected no policy")
+	}
+}
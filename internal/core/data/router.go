@@ -15,6 +15,7 @@ package data
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -81,6 +82,14 @@ func LoadRestRoutes(dic *di.Container) *mux.Router {
 
 	e.HandleFunc("/"+COUNT+"/{"+DEVICEID_PARAM+"}", eventCountByDeviceIdHandler).Methods(http.MethodGet)
 
+	e.HandleFunc("/"+BATCH, func(writer http.ResponseWriter, request *http.Request) {
+		eventBatchHandler(writer, request, container.LoggingClientFrom(dic.Get))
+	}).Methods(http.MethodPost)
+
+	e.HandleFunc("/"+RETENTION, func(writer http.ResponseWriter, request *http.Request) {
+		retentionHandler(writer, request, container.LoggingClientFrom(dic.Get))
+	}).Methods(http.MethodGet, http.MethodPost)
+
 	e.HandleFunc("/{"+ID+"}", func(writer http.ResponseWriter, request *http.Request) {
 		getEventByIdHandler(writer, request, container.LoggingClientFrom(dic.Get))
 	}).Methods(http.MethodGet)
@@ -104,6 +113,12 @@ func LoadRestRoutes(dic *di.Container) *mux.Router {
 		eventByAgeHandler(writer, request, container.LoggingClientFrom(dic.Get))
 	}).Methods(http.MethodDelete)
 
+	e.HandleFunc(
+		"/"+EXPORT+"/{"+START+":[0-9]+}/{"+END+":[0-9]+}",
+		func(writer http.ResponseWriter, request *http.Request) {
+			exportEventsHandler(writer, request, container.LoggingClientFrom(dic.Get))
+		}).Methods(http.MethodGet)
+
 	e.HandleFunc(
 		"/{"+START+":[0-9]+}/{"+END+":[0-9]+}/{"+LIMIT+":[0-9]+}",
 		func(writer http.ResponseWriter, request *http.Request) {
@@ -167,6 +182,12 @@ func LoadRestRoutes(dic *di.Container) *mux.Router {
 			readingByValueDescriptorAndDeviceHandler(writer, request, container.LoggingClientFrom(dic.Get))
 		}).Methods(http.MethodGet)
 
+	rd.HandleFunc(
+		"/"+AGGREGATE+"/{"+FUNCTION+"}/"+NAME+"/{"+NAME+"}/"+DEVICE+"/{"+DEVICE+"}/{"+START+":[0-9]+}/{"+END+":[0-9]+}/{"+WIDTH+":[0-9]+}",
+		func(writer http.ResponseWriter, request *http.Request) {
+			readingAggregateHandler(writer, request, container.LoggingClientFrom(dic.Get))
+		}).Methods(http.MethodGet)
+
 	// Value descriptors
 	r.HandleFunc(
 		clients.ApiValueDescriptorRoute,
@@ -292,6 +313,72 @@ func eventByAgeHandler(w http.ResponseWriter, r *http.Request, loggingClient log
 	w.Write([]byte(strconv.Itoa(count)))
 }
 
+// Return the report of the last run of the retention job (GET) or run the retention policies now and return
+// the report of the run (POST)
+// event/retention
+func retentionHandler(w http.ResponseWriter, r *http.Request, loggingClient logger.LoggingClient) {
+	defer r.Body.Close()
+
+	switch r.Method {
+	case http.MethodGet:
+		pkg.Encode(lastRetentionReport(), w, loggingClient)
+	case http.MethodPost:
+		report, err := runRetention(loggingClient)
+		if err != nil {
+			loggingClient.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if report.Error == "" {
+			pkg.Encode(report, w, loggingClient)
+			return
+		}
+
+		// The report of a failed run still tells what was removed before the failure
+		w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+		w.WriteHeader(http.StatusInternalServerError)
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			loggingClient.Error("Error encoding the data: " + err.Error())
+		}
+	}
+}
+
+// Add a batch of events given as a JSON array or, with the CBOR content type, as a CBOR sequence
+// Status code 200 - all the events were added, 207 - some events were rejected, see the result of each event
+// api/v1/event/batch
+func eventBatchHandler(w http.ResponseWriter, r *http.Request, loggingClient logger.LoggingClient) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	events, err := NewBatchRequestReader(r).ReadAll(r.Body, &ctx)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
+
+	err = checkMaxLimit(len(events), loggingClient)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.LimitExceeded)
+		return
+	}
+
+	results := addNewEvents(events, ctx, loggingClient)
+
+	status := http.StatusOK
+	for _, result := range results {
+		if result.Error != "" {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+
+	w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		loggingClient.Error("Error encoding the data: " + err.Error())
+	}
+}
+
 /*
 Handler for the event API
 Status code 400 - Unsupported content type, or invalid data
@@ -427,6 +514,7 @@ func getEventByIdHandler(w http.ResponseWriter, r *http.Request, loggingClient l
 // Returns the events for the given device sorted by creation date and limited by 'limit'
 // {deviceId} - the device that the events are for
 // {limit} - the limit of events
+// ?offset - the number of events to skip, a Link header points at the next page when there may be more
 // api/v1/event/device/{deviceId}/{limit}
 func getEventByDeviceHandler(w http.ResponseWriter, r *http.Request, loggingClient logger.LoggingClient) {
 	defer r.Body.Close()
@@ -449,6 +537,12 @@ func getEventByDeviceHandler(w http.ResponseWriter, r *http.Request, loggingClie
 		return
 	}
 
+	offset, err := parseOffset(r)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
+
 	// Check device
 	if err := checkDevice(deviceId, ctx); err != nil {
 		httpErrorHandler.HandleOneVariant(
@@ -466,13 +560,14 @@ func getEventByDeviceHandler(w http.ResponseWriter, r *http.Request, loggingClie
 			return
 		}
 
-		eventList, err := getEventsByDeviceIdLimit(limitNum, deviceId, loggingClient)
+		eventList, err := getEventsByDeviceIdLimit(limitNum, offset, deviceId, loggingClient)
 
 		if err != nil {
 			httpErrorHandler.Handle(w, err, errorconcept.Default.InternalServerError)
 			return
 		}
 
+		setNextPageLink(w, r, limitNum, offset, len(eventList))
 		pkg.Encode(eventList, w, loggingClient)
 	}
 }
@@ -610,6 +705,7 @@ func deleteByDeviceIdHandler(w http.ResponseWriter, r *http.Request) {
 // Sort the events by creation date
 // 413 - number of results exceeds limit
 // 503 - service unavailable
+// ?offset - the number of events to skip, a Link header points at the next page when there may be more
 // api/v1/event/{start}/{end}/{limit}
 func eventByCreationTimeHandler(w http.ResponseWriter, r *http.Request, loggingClient logger.LoggingClient) {
 	defer r.Body.Close()
@@ -636,6 +732,12 @@ func eventByCreationTimeHandler(w http.ResponseWriter, r *http.Request, loggingC
 		return
 	}
 
+	offset, err := parseOffset(r)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		err := checkMaxLimit(limit, loggingClient)
@@ -644,17 +746,59 @@ func eventByCreationTimeHandler(w http.ResponseWriter, r *http.Request, loggingC
 			return
 		}
 
-		eventList, err := getEventsByCreationTime(limit, start, end, loggingClient)
+		eventList, err := getEventsByCreationTime(limit, offset, start, end, loggingClient)
 
 		if err != nil {
 			httpErrorHandler.Handle(w, err, errorconcept.Default.InternalServerError)
 			return
 		}
 
+		setNextPageLink(w, r, limit, offset, len(eventList))
 		pkg.Encode(eventList, w, loggingClient)
 	}
 }
 
+// Stream the events created between the start and end as NDJSON (default) or CSV
+// ?format - ndjson or csv
+// ?device - only export the events of the device
+// ?name - only export the readings for the value descriptor
+// api/v1/event/export/{start}/{end}
+func exportEventsHandler(w http.ResponseWriter, r *http.Request, loggingClient logger.LoggingClient) {
+	defer r.Body.Close()
+
+	vars := mux.Vars(r)
+	start, err := strconv.ParseInt(vars[START], 10, 64)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
+	end, err := strconv.ParseInt(vars[END], 10, 64)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get(FORMAT)
+	if format == "" {
+		format = ExportFormatNDJSON
+	}
+	filter := exportFilter{start: start, end: end, device: query.Get(DEVICE), name: query.Get(NAME)}
+
+	started, err := exportEvents(w, format, filter, loggingClient)
+	if err != nil {
+		if started {
+			loggingClient.Error(fmt.Sprintf("event export aborted: %s", err.Error()))
+			return
+		}
+		if _, ok := err.(errUnsupportedExportFormat); ok {
+			httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+			return
+		}
+		httpErrorHandler.Handle(w, err, errorconcept.Default.InternalServerError)
+	}
+}
+
 // Scrub all the events that have been pushed
 // Also remove the readings associated with the events
 // api/v1/event/scrub
@@ -854,6 +998,7 @@ func deleteReadingByIdHandler(w http.ResponseWriter, r *http.Request, loggingCli
 // Get all the readings for the device - sort by creation date
 // 404 - device ID or name doesn't match
 // 413 - max count exceeded
+// ?offset - the number of readings to skip, a Link header points at the next page when there may be more
 // api/v1/reading/device/{deviceId}/{limit}
 func readingByDeviceHandler(w http.ResponseWriter, r *http.Request, loggingClient logger.LoggingClient) {
 	defer r.Body.Close()
@@ -871,6 +1016,11 @@ func readingByDeviceHandler(w http.ResponseWriter, r *http.Request, loggingClien
 		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
 		return
 	}
+	offset, err := parseOffset(r)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
 
 	ctx := r.Context()
 
@@ -882,7 +1032,7 @@ func readingByDeviceHandler(w http.ResponseWriter, r *http.Request, loggingClien
 			return
 		}
 
-		readings, err := getReadingsByDevice(deviceId, limit, ctx, loggingClient)
+		readings, err := getReadingsByDevice(deviceId, limit, offset, ctx, loggingClient)
 		if err != nil {
 			httpErrorHandler.HandleOneVariant(
 				w,
@@ -892,12 +1042,14 @@ func readingByDeviceHandler(w http.ResponseWriter, r *http.Request, loggingClien
 			return
 		}
 
+		setNextPageLink(w, r, limit, offset, len(readings))
 		pkg.Encode(readings, w, loggingClient)
 	}
 }
 
 // Return a list of readings associated with a value descriptor, limited by limit
 // HTTP 413 (limit exceeded) if the limit is greater than max limit
+// ?offset - the number of readings to skip, a Link header points at the next page when there may be more
 // api/v1/reading/name/{name}/{limit}
 func readingbyValueDescriptorHandler(w http.ResponseWriter, r *http.Request, loggingClient logger.LoggingClient) {
 	defer r.Body.Close()
@@ -916,12 +1068,19 @@ func readingbyValueDescriptorHandler(w http.ResponseWriter, r *http.Request, log
 		return
 	}
 
-	read, err := getReadingsByValueDescriptor(name, limit, loggingClient)
+	offset, err := parseOffset(r)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
+
+	read, err := getReadingsByValueDescriptor(name, limit, offset, loggingClient)
 	if err != nil {
 		httpErrorHandler.Handle(w, err, errorconcept.Default.InternalServerError)
 		return
 	}
 
+	setNextPageLink(w, r, limit, offset, len(read))
 	pkg.Encode(read, w, loggingClient)
 }
 
@@ -1069,6 +1228,7 @@ func readingByTypeHandler(w http.ResponseWriter, r *http.Request, loggingClient
 }
 
 // Return a list of readings between the start and end (creation time)
+// ?offset - the number of readings to skip, a Link header points at the next page when there may be more
 // /reading/{start}/{end}/{limit}
 func readingByCreationTimeHandler(w http.ResponseWriter, r *http.Request, loggingClient logger.LoggingClient) {
 	defer r.Body.Close()
@@ -1089,6 +1249,11 @@ func readingByCreationTimeHandler(w http.ResponseWriter, r *http.Request, loggin
 		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
 		return
 	}
+	offset, err := parseOffset(r)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
 
 	switch r.Method {
 	case http.MethodGet:
@@ -1098,12 +1263,13 @@ func readingByCreationTimeHandler(w http.ResponseWriter, r *http.Request, loggin
 			return
 		}
 
-		readings, err := getReadingsByCreationTime(start, end, limit, loggingClient)
+		readings, err := getReadingsByCreationTime(start, end, limit, offset, loggingClient)
 		if err != nil {
 			httpErrorHandler.Handle(w, err, errorconcept.Default.InternalServerError)
 			return
 		}
 
+		setNextPageLink(w, r, limit, offset, len(readings))
 		pkg.Encode(readings, w, loggingClient)
 	}
 }
@@ -1174,6 +1340,58 @@ func readingByValueDescriptorAndDeviceHandler(w http.ResponseWriter, r *http.Req
 	pkg.Encode(readings, w, loggingClient)
 }
 
+// Return the readings associated with the device and value descriptor between start and end (creation
+// time) aggregated into buckets of width milliseconds, function is one of min, max, avg, sum, count,
+// first or last
+// api/v1/reading/aggregate/{function}/name/{name}/device/{device}/{start}/{end}/{width}
+func readingAggregateHandler(w http.ResponseWriter, r *http.Request, loggingClient logger.LoggingClient) {
+	defer r.Body.Close()
+
+	vars := mux.Vars(r)
+
+	name, err := url.QueryUnescape(vars[NAME])
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
+	device, err := url.QueryUnescape(vars[DEVICE])
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
+	start, err := strconv.ParseInt(vars[START], 10, 64)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
+	end, err := strconv.ParseInt(vars[END], 10, 64)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
+	width, err := strconv.ParseInt(vars[WIDTH], 10, 64)
+	if err != nil {
+		httpErrorHandler.Handle(w, err, errorconcept.Common.InvalidRequest_StatusBadRequest)
+		return
+	}
+
+	aggregates, err := aggregateReadings(device, name, start, end, width, vars[FUNCTION], loggingClient)
+	if err != nil {
+		httpErrorHandler.HandleManyVariants(
+			w,
+			err,
+			[]errorconcept.ErrorConceptType{
+				errorconcept.Readings.InvalidAggregation,
+				errorconcept.ValueDescriptors.LimitExceeded,
+				errorconcept.ValueDescriptors.NotFound,
+			},
+			errorconcept.Default.InternalServerError)
+		return
+	}
+
+	pkg.Encode(aggregates, w, loggingClient)
+}
+
 // Value Descriptors
 
 // GET, POST, and PUT for value descriptors
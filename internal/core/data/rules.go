@@ -0,0 +1,348 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+)
+
+const (
+	// Policies applied to a reading which violates a validation rule
+	ValidationPolicyReject = "reject"
+	ValidationPolicyFlag   = "flag"
+
+	// ValidationRulePrefix marks the value descriptor labels, written as 'validation:<rule>=<parameter>', and the
+	// device resource attributes, keyed 'validation:<rule>', which define validation rules
+	ValidationRulePrefix = "validation:"
+)
+
+// validationRule checks the value of a reading beyond the type and limits of its value descriptor
+type validationRule interface {
+	// Validate checks the reading, previous is the last reading accepted for the same device and value descriptor
+	// and is nil when unknown
+	Validate(reading contract.Reading, previous *contract.Reading) error
+}
+
+// validationRuleFactory creates a rule from its parameter
+type validationRuleFactory func(param string) (validationRule, error)
+
+// validationRules holds the factories of the supported rules by name, a rule is supported once its factory is
+// registered here
+var validationRules = map[string]validationRuleFactory{
+	"regex":           newRegexRule,
+	"enum":            newEnumRule,
+	"jsonSchema":      newJSONSchemaRule,
+	"maxRateOfChange": newRateOfChangeRule,
+	"finite":          newFiniteRule,
+}
+
+// regexRule requires the whole value to match a regular expression, which is anchored at both ends
+type regexRule struct {
+	pattern    string
+	expression *regexp.Regexp
+}
+
+func newRegexRule(param string) (validationRule, error) {
+	expression, err := regexp.Compile("^(?:" + param + ")$")
+	if err != nil {
+		return nil, err
+	}
+	return regexRule{pattern: param, expression: expression}, nil
+}
+
+func (r regexRule) Validate(reading contract.Reading, previous *contract.Reading) error {
+	if !r.expression.MatchString(reading.Value) {
+		return fmt.Errorf("Value does not match %s", r.pattern)
+	}
+	return nil
+}
+
+// enumRule requires the value to be one of a comma separated list of values
+type enumRule struct {
+	values []string
+}
+
+func newEnumRule(param string) (validationRule, error) {
+	values := strings.Split(param, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return enumRule{values: values}, nil
+}
+
+func (r enumRule) Validate(reading contract.Reading, previous *contract.Reading) error {
+	for _, v := range r.values {
+		if reading.Value == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("Value is not one of %s", strings.Join(r.values, ", "))
+}
+
+// jsonSchemaRule requires the value to be a JSON document valid against a JSON Schema
+type jsonSchemaRule struct {
+	schema jsonSchema
+}
+
+func newJSONSchemaRule(param string) (validationRule, error) {
+	var schema jsonSchema
+	if err := json.Unmarshal([]byte(param), &schema); err != nil {
+		return nil, err
+	}
+	return jsonSchemaRule{schema: schema}, nil
+}
+
+func (r jsonSchemaRule) Validate(reading contract.Reading, previous *contract.Reading) error {
+	var document interface{}
+	if err := json.Unmarshal([]byte(reading.Value), &document); err != nil {
+		return err
+	}
+	return r.schema.validate(document, "$")
+}
+
+// rateOfChangeRule limits how fast a numeric value may change, in units per second, since the previous reading.
+// Readings without an origin are not checked.
+type rateOfChangeRule struct {
+	max float64
+}
+
+func newRateOfChangeRule(param string) (validationRule, error) {
+	max, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return nil, err
+	}
+	return rateOfChangeRule{max: max}, nil
+}
+
+func (r rateOfChangeRule) Validate(reading contract.Reading, previous *contract.Reading) error {
+	value, err := strconv.ParseFloat(reading.Value, 64)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return nil
+	}
+	last, err := strconv.ParseFloat(previous.Value, 64)
+	if err != nil {
+		return nil
+	}
+
+	// origins are in milliseconds
+	elapsed := reading.Origin - previous.Origin
+	if reading.Origin == 0 || previous.Origin == 0 || elapsed <= 0 {
+		return nil
+	}
+	rate := math.Abs(value-last) / (float64(elapsed) / 1000)
+	if rate > r.max {
+		return fmt.Errorf("Value changes by %g per second, over the limit of %g", rate, r.max)
+	}
+	return nil
+}
+
+// finiteRule bans NaN and infinite values when its parameter is true
+type finiteRule struct {
+	enabled bool
+}
+
+func newFiniteRule(param string) (validationRule, error) {
+	enabled, err := strconv.ParseBool(param)
+	if err != nil {
+		return nil, err
+	}
+	return finiteRule{enabled: enabled}, nil
+}
+
+func (r finiteRule) Validate(reading contract.Reading, previous *contract.Reading) error {
+	if !r.enabled {
+		return nil
+	}
+	value, err := strconv.ParseFloat(reading.Value, 64)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("Value is not a finite number")
+	}
+	return nil
+}
+
+// ruleCache holds the rules created from their definition, so that for instance a regular expression is compiled
+// only once. The definitions which cannot be created are kept as nil rules.
+var ruleCache = struct {
+	mutex sync.Mutex
+	rules map[string]validationRule
+}{rules: make(map[string]validationRule)}
+
+// getValidationRule returns the rule of the name with the parameter, or nil when the rule is unknown or its
+// parameter invalid
+func getValidationRule(name string, param string, loggingClient logger.LoggingClient) validationRule {
+	key := name + "=" + param
+
+	ruleCache.mutex.Lock()
+	defer ruleCache.mutex.Unlock()
+
+	rule, ok := ruleCache.rules[key]
+	if ok {
+		return rule
+	}
+
+	factory, ok := validationRules[name]
+	if !ok {
+		loggingClient.Error(fmt.Sprintf("unknown validation rule '%s' ignored", name))
+	} else {
+		var err error
+		rule, err = factory(param)
+		if err != nil {
+			loggingClient.Error(fmt.Sprintf("validation rule '%s' ignored: %s", key, err.Error()))
+			rule = nil
+		}
+	}
+	ruleCache.rules[key] = rule
+	return rule
+}
+
+// ruleDefinitions returns the parameters of the validation rules by name. The rules come from the labels of the
+// value descriptor and, when known, from the attributes of the device resource, which take precedence.
+func ruleDefinitions(vd contract.ValueDescriptor, resource *contract.DeviceResource) map[string]string {
+	definitions := make(map[string]string)
+	for _, label := range vd.Labels {
+		if !strings.HasPrefix(label, ValidationRulePrefix) {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(label, ValidationRulePrefix), "=", 2)
+		if len(parts) == 2 {
+			definitions[parts[0]] = parts[1]
+		}
+	}
+	if resource != nil {
+		for key, param := range resource.Attributes {
+			if strings.HasPrefix(key, ValidationRulePrefix) {
+				definitions[strings.TrimPrefix(key, ValidationRulePrefix)] = param
+			}
+		}
+	}
+	return definitions
+}
+
+// deviceResource returns the resource of the device profile for the value descriptor, or nil
+func deviceResource(resources []contract.DeviceResource, name string) *contract.DeviceResource {
+	for i := range resources {
+		if resources[i].Name == name {
+			return &resources[i]
+		}
+	}
+	return nil
+}
+
+// applyValidationRules checks the reading against the validation rules of its value descriptor and device resource.
+// A violation is returned under the reject policy. Under the flag policy the reading is accepted and the violations
+// are returned as its flag, which is empty when the reading complies.
+func applyValidationRules(
+	vd contract.ValueDescriptor,
+	resource *contract.DeviceResource,
+	reading contract.Reading,
+	loggingClient logger.LoggingClient) (string, error) {
+
+	definitions := ruleDefinitions(vd, resource)
+	if len(definitions) == 0 {
+		return "", nil
+	}
+
+	// apply the rules in a stable order so that the same violation is reported each time
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	previous := readingHistory.previous(reading.Device, reading.Name)
+	var violations []string
+	for _, name := range names {
+		rule := getValidationRule(name, definitions[name], loggingClient)
+		if rule == nil {
+			continue
+		}
+		err := rule.Validate(reading, previous)
+		if err == nil {
+			continue
+		}
+
+		if Configuration.Writable.ValidationPolicy == ValidationPolicyFlag {
+			loggingClient.Warn(fmt.Sprintf("reading %s of device %s flagged by rule %s: %s",
+				reading.Name, reading.Device, name, err.Error()))
+			violations = append(violations, name+": "+err.Error())
+			continue
+		}
+		return "", errors.NewErrValueDescriptorInvalid(vd.Name, err)
+	}
+	return strings.Join(violations, "; "), nil
+}
+
+// readingHistory keeps the last reading accepted for each device and value descriptor, which the rate of change
+// rule compares against. The readings are recorded as soon as they are validated, so that the readings of a batch are
+// compared with one another, and it only knows the readings received since the service started.
+var readingHistory = &lastReadings{readings: make(map[string]contract.Reading)}
+
+type lastReadings struct {
+	mutex    sync.Mutex
+	readings map[string]contract.Reading
+}
+
+func (l *lastReadings) previous(device string, name string) *contract.Reading {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	r, ok := l.readings[device+"/"+name]
+	if !ok {
+		return nil
+	}
+	return &r
+}
+
+func (l *lastReadings) record(readings ...contract.Reading) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, r := range readings {
+		l.readings[r.Device+"/"+r.Name] = r
+	}
+}
+
+// eventReadings returns the readings of the event with their device and origin defaulting to those of the event
+func eventReadings(e contract.Event) []contract.Reading {
+	readings := make([]contract.Reading, len(e.Readings))
+	for i, r := range e.Readings {
+		if r.Device == "" {
+			r.Device = e.Device
+		}
+		if r.Origin == 0 {
+			r.Origin = e.Origin
+		}
+		readings[i] = r
+	}
+	return readings
+}
@@ -0,0 +1,229 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+)
+
+func TestValidationRules(t *testing.T) {
+	previous := &contract.Reading{Value: "10", Origin: 1000}
+
+	tests := []struct {
+		name     string
+		rule     string
+		param    string
+		value    string
+		origin   int64
+		previous *contract.Reading
+		err      bool
+	}{
+		{"regex match", "regex", "^[A-Z]+$", "OK", 0, nil, false},
+		{"regex mismatch", "regex", "^[A-Z]+$", "ok", 0, nil, true},
+		{"regex anchored", "regex", "[A-Z]+", "okOK", 0, nil, true},
+		{"regex alternation anchored", "regex", "on|off", "often", 0, nil, true},
+		{"regex alternation match", "regex", "on|off", "off", 0, nil, false},
+		{"enum member", "enum", "open, closed", "closed", 0, nil, false},
+		{"enum other", "enum", "open, closed", "ajar", 0, nil, true},
+		{"json schema valid", "jsonSchema", `{"type":"object","required":["a"]}`, `{"a":1}`, 0, nil, false},
+		{"json schema invalid", "jsonSchema", `{"type":"object","required":["a"]}`, `{"b":1}`, 0, nil, true},
+		{"json schema not json", "jsonSchema", `{"type":"object"}`, `{`, 0, nil, true},
+		{"rate without previous", "maxRateOfChange", "1", "100", 2000, nil, false},
+		{"rate under limit", "maxRateOfChange", "5", "14", 2000, previous, false},
+		{"rate over limit", "maxRateOfChange", "5", "16", 2000, previous, true},
+		{"rate falling over limit", "maxRateOfChange", "5", "4", 2000, previous, true},
+		{"rate without origin", "maxRateOfChange", "5", "100", 0, previous, false},
+		{"rate not numeric", "maxRateOfChange", "5", "abc", 2000, previous, true},
+		{"finite number", "finite", "true", "1.5", 0, nil, false},
+		{"finite NaN", "finite", "true", "NaN", 0, nil, true},
+		{"finite Inf", "finite", "true", "-Inf", 0, nil, true},
+		{"finite disabled", "finite", "false", "NaN", 0, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := validationRules[tt.rule](tt.param)
+			if err != nil {
+				t.Fatalf("Unexpected error creating the rule: %s", err.Error())
+			}
+			err = rule.Validate(contract.Reading{Value: tt.value, Origin: tt.origin}, tt.previous)
+			if (err != nil) != tt.err {
+				t.Errorf("Expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestValidationRulesInvalidParameter(t *testing.T) {
+	tests := []struct {
+		rule  string
+		param string
+	}{
+		{"regex", "("},
+		{"jsonSchema", "{"},
+		{"jsonSchema", `{"pattern":"("}`},
+		{"maxRateOfChange", "fast"},
+		{"finite", "maybe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			if _, err := validationRules[tt.rule](tt.param); err == nil {
+				t.Errorf("Expected an error for parameter %s", tt.param)
+			}
+			if rule := getValidationRule(tt.rule, tt.param, logger.NewMockClient()); rule != nil {
+				t.Errorf("Expected the rule to be ignored")
+			}
+		})
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"required": ["temperature"],
+		"additionalProperties": false,
+		"properties": {
+			"temperature": {"type": "number", "minimum": -40, "exclusiveMaximum": 100},
+			"unit": {"enum": ["C", "F"]},
+			"sensor": {"type": "string", "minLength": 2, "maxLength": 4, "pattern": "^s"},
+			"samples": {"type": "array", "maxItems": 2, "items": {"type": "integer"}},
+			"note": {"type": ["string", "null"]}
+		}
+	}`
+
+	tests := []struct {
+		name     string
+		document string
+		err      bool
+	}{
+		{"Valid", `{"temperature":21.5,"unit":"C","sensor":"s1","samples":[1,2],"note":null}`, false},
+		{"Wrong type", `[1]`, true},
+		{"Missing required", `{"unit":"C"}`, true},
+		{"Additional property", `{"temperature":1,"other":1}`, true},
+		{"Under minimum", `{"temperature":-41}`, true},
+		{"Exclusive maximum", `{"temperature":100}`, true},
+		{"Not in enum", `{"temperature":1,"unit":"K"}`, true},
+		{"Too short", `{"temperature":1,"sensor":"s"}`, true},
+		{"Too long", `{"temperature":1,"sensor":"s12345"}`, true},
+		{"Pattern mismatch", `{"temperature":1,"sensor":"x1"}`, true},
+		{"Too many items", `{"temperature":1,"samples":[1,2,3]}`, true},
+		{"Item not integer", `{"temperature":1,"samples":[1.5]}`, true},
+		{"Type list", `{"temperature":1,"note":1}`, true},
+	}
+
+	rule, err := newJSONSchemaRule(schema)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing the schema: %s", err.Error())
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rule.Validate(contract.Reading{Value: tt.document}, nil)
+			if (err != nil) != tt.err {
+				t.Errorf("Expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestApplyValidationRules(t *testing.T) {
+	vd := contract.ValueDescriptor{
+		Name:   "state",
+		Type:   "S",
+		Labels: []string{"sensor", ValidationRulePrefix + "enum=open,closed", ValidationRulePrefix + "regex=^[a-z]+$"},
+	}
+	resource := &contract.DeviceResource{
+		Name:       "state",
+		Attributes: map[string]string{ValidationRulePrefix + "enum": "open,closed,ajar"},
+	}
+
+	tests := []struct {
+		name     string
+		resource *contract.DeviceResource
+		policy   string
+		value    string
+		err      bool
+	}{
+		{"Valid", nil, ValidationPolicyReject, "open", false},
+		{"Rejected", nil, ValidationPolicyReject, "ajar", true},
+		{"Default policy rejects", nil, "", "ajar", true},
+		{"Flagged", nil, ValidationPolicyFlag, "ajar", false},
+		{"Device resource overrides", resource, ValidationPolicyReject, "ajar", false},
+		{"Value descriptor rule kept", resource, ValidationPolicyReject, "AJAR", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset()
+			Configuration.Writable.ValidationPolicy = tt.policy
+
+			reading := contract.Reading{Device: testDeviceName, Name: "state", Value: tt.value}
+			flag, err := applyValidationRules(vd, tt.resource, reading, logger.NewMockClient())
+			if (err != nil) != tt.err {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if (flag != "") != (tt.policy == ValidationPolicyFlag) {
+				t.Errorf("Expected the reading to be flagged only under the flag policy, got flag '%s'", flag)
+			}
+			if err != nil {
+				if _, ok := err.(errors.ErrValueDescriptorInvalid); !ok {
+					t.Errorf("Expected ErrValueDescriptorInvalid, got %T", err)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyValidationRulesRateOfChange(t *testing.T) {
+	reset()
+	Configuration.Writable.ValidationPolicy = ValidationPolicyReject
+	readingHistory = &lastReadings{readings: make(map[string]contract.Reading)}
+
+	vd := contract.ValueDescriptor{Name: "temperature", Type: "F", Labels: []string{ValidationRulePrefix + "maxRateOfChange=1"}}
+	first := contract.Reading{Device: testDeviceName, Name: "temperature", Value: "20", Origin: 1000}
+	if _, err := applyValidationRules(vd, nil, first, logger.NewMockClient()); err != nil {
+		t.Fatalf("Unexpected error for the first reading: %s", err.Error())
+	}
+	readingHistory.record(first)
+
+	jump := contract.Reading{Device: testDeviceName, Name: "temperature", Value: "30", Origin: 2000}
+	if _, err := applyValidationRules(vd, nil, jump, logger.NewMockClient()); err == nil {
+		t.Errorf("Expected the jump to be rejected")
+	}
+
+	other := contract.Reading{Device: "other", Name: "temperature", Value: "30", Origin: 2000}
+	if _, err := applyValidationRules(vd, nil, other, logger.NewMockClient()); err != nil {
+		t.Errorf("Expected the reading of another device to be accepted, got %s", err.Error())
+	}
+}
+
+func TestEventReadings(t *testing.T) {
+	e := contract.Event{Device: testDeviceName, Origin: 5, Readings: []contract.Reading{
+		{Name: "a"},
+		{Name: "b", Device: "other", Origin: 7},
+	}}
+
+	readings := eventReadings(e)
+	if readings[0].Device != testDeviceName || readings[0].Origin != 5 {
+		t.Errorf("Expected the reading to default to the event device and origin, got %+v", readings[0])
+	}
+	if readings[1].Device != "other" || readings[1].Origin != 7 {
+		t.Errorf("Expected the reading to keep its device and origin, got %+v", readings[1])
+	}
+	if e.Readings[0].Device != "" {
+		t.Errorf("Expected the event to be unchanged")
+	}
+}
@@ -0,0 +1,237 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+)
+
+// jsonSchema is the subset of JSON Schema used to validate JSON readings: the type, enum, numeric, string, object
+// and array keywords. Other keywords are ignored.
+type jsonSchema struct {
+	Type                 json.RawMessage        `json:"type"`
+	Enum                 []interface{}          `json:"enum"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+
+	types        []string
+	pattern      *regexp.Regexp
+	additional   *jsonSchema
+	noAdditional bool
+}
+
+// UnmarshalJSON decodes the schema and prepares the keywords which are not plain values
+func (s *jsonSchema) UnmarshalJSON(data []byte) error {
+	type alias jsonSchema
+	a := alias{}
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	*s = jsonSchema(a)
+
+	if len(s.Type) > 0 {
+		var single string
+		if err := json.Unmarshal(s.Type, &single); err == nil {
+			s.types = []string{single}
+		} else if err := json.Unmarshal(s.Type, &s.types); err != nil {
+			return fmt.Errorf("invalid type: %s", err.Error())
+		}
+	}
+
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+		s.pattern = pattern
+	}
+
+	if len(s.AdditionalProperties) > 0 {
+		var allowed bool
+		if err := json.Unmarshal(s.AdditionalProperties, &allowed); err == nil {
+			s.noAdditional = !allowed
+		} else {
+			s.additional = &jsonSchema{}
+			if err := json.Unmarshal(s.AdditionalProperties, s.additional); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks the decoded JSON document, path locates the document in the reading for the error message
+func (s *jsonSchema) validate(document interface{}, path string) error {
+	if len(s.types) > 0 && !s.hasType(document) {
+		return fmt.Errorf("%s is not of type %v", path, s.types)
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, v := range s.Enum {
+			if reflect.DeepEqual(v, document) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s is not one of %v", path, s.Enum)
+		}
+	}
+
+	switch value := document.(type) {
+	case float64:
+		return s.validateNumber(value, path)
+	case string:
+		return s.validateString(value, path)
+	case map[string]interface{}:
+		return s.validateObject(value, path)
+	case []interface{}:
+		return s.validateArray(value, path)
+	}
+	return nil
+}
+
+func (s *jsonSchema) hasType(document interface{}) bool {
+	for _, t := range s.types {
+		switch t {
+		case "null":
+			if document == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := document.(bool); ok {
+				return true
+			}
+		case "number":
+			if _, ok := document.(float64); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := document.(float64); ok && n == math.Trunc(n) {
+				return true
+			}
+		case "string":
+			if _, ok := document.(string); ok {
+				return true
+			}
+		case "object":
+			if _, ok := document.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := document.([]interface{}); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *jsonSchema) validateNumber(value float64, path string) error {
+	if s.Minimum != nil && value < *s.Minimum {
+		return fmt.Errorf("%s is under the minimum %g", path, *s.Minimum)
+	}
+	if s.Maximum != nil && value > *s.Maximum {
+		return fmt.Errorf("%s is over the maximum %g", path, *s.Maximum)
+	}
+	if s.ExclusiveMinimum != nil && value <= *s.ExclusiveMinimum {
+		return fmt.Errorf("%s is not over the exclusive minimum %g", path, *s.ExclusiveMinimum)
+	}
+	if s.ExclusiveMaximum != nil && value >= *s.ExclusiveMaximum {
+		return fmt.Errorf("%s is not under the exclusive maximum %g", path, *s.ExclusiveMaximum)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateString(value string, path string) error {
+	length := utf8.RuneCountInString(value)
+	if s.MinLength != nil && length < *s.MinLength {
+		return fmt.Errorf("%s is shorter than %d", path, *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		return fmt.Errorf("%s is longer than %d", path, *s.MaxLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		return fmt.Errorf("%s does not match %s", path, s.Pattern)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateObject(value map[string]interface{}, path string) error {
+	for _, name := range s.Required {
+		if _, ok := value[name]; !ok {
+			return fmt.Errorf("%s.%s is required", path, name)
+		}
+	}
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property := value[name]
+		if schema, ok := s.Properties[name]; ok {
+			if err := schema.validate(property, path+"."+name); err != nil {
+				return err
+			}
+			continue
+		}
+		if s.noAdditional {
+			return fmt.Errorf("%s.%s is not allowed", path, name)
+		}
+		if s.additional != nil {
+			if err := s.additional.validate(property, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateArray(value []interface{}, path string) error {
+	if s.MinItems != nil && len(value) < *s.MinItems {
+		return fmt.Errorf("%s has fewer than %d items", path, *s.MinItems)
+	}
+	if s.MaxItems != nil && len(value) > *s.MaxItems {
+		return fmt.Errorf("%s has more than %d items", path, *s.MaxItems)
+	}
+	if s.Items != nil {
+		for i, item := range value {
+			if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -14,11 +14,15 @@
 package data
 
 import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 
 	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
-	"io"
-	"io/ioutil"
 )
 
 // Printing function purely for debugging purposes
@@ -42,3 +46,35 @@ func checkMaxLimit(limit int, loggingClient logger.LoggingClient) error {
 
 	return nil
 }
+
+// parseOffset returns the number of results to skip as given by the optional offset query parameter
+func parseOffset(r *http.Request) (int, error) {
+	value := r.URL.Query().Get(OFFSET)
+	if value == "" {
+		return 0, nil
+	}
+
+	offset, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("offset must not be negative: %d", offset)
+	}
+
+	return offset, nil
+}
+
+// setNextPageLink adds a Link header pointing at the next page of results when the current page is full.
+// A page holding fewer results than the limit is the last one so no link is added.
+func setNextPageLink(w http.ResponseWriter, r *http.Request, limit int, offset int, count int) {
+	if limit <= 0 || count < limit {
+		return
+	}
+
+	next := *r.URL
+	query := next.Query()
+	query.Set(OFFSET, strconv.Itoa(offset+count))
+	next.RawQuery = query.Encode()
+	w.Header().Set(LINK_HEADER, fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+}
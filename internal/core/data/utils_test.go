@@ -0,0 +1,77 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+package data
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseOffset(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		expected    int
+		expectError bool
+	}{
+		{"No offset", "/api/v1/event/device/dev/10", 0, false},
+		{"Valid offset", "/api/v1/event/device/dev/10?offset=20", 20, false},
+		{"Invalid offset", "/api/v1/event/device/dev/10?offset=abc", 0, true},
+		{"Negative offset", "/api/v1/event/device/dev/10?offset=-1", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			offset, err := parseOffset(req)
+			if tt.expectError && err == nil {
+				t.Errorf("Expected an error")
+				return
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			if offset != tt.expected {
+				t.Errorf("Expected offset %d, got %d", tt.expected, offset)
+			}
+		})
+	}
+}
+
+func TestSetNextPageLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		limit    int
+		offset   int
+		count    int
+		expected string
+	}{
+		{"Full first page", "/api/v1/reading/0/100/10", 10, 0, 10, "</api/v1/reading/0/100/10?offset=10>; rel=\"next\""},
+		{"Full later page", "/api/v1/reading/0/100/10?offset=10", 10, 10, 10, "</api/v1/reading/0/100/10?offset=20>; rel=\"next\""},
+		{"Last page", "/api/v1/reading/0/100/10?offset=20", 10, 20, 5, ""},
+		{"Zero limit", "/api/v1/reading/0/100/0", 0, 0, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			rr := httptest.NewRecorder()
+			setNextPageLink(rr, req, tt.limit, tt.offset, tt.count)
+			if link := rr.Header().Get(LINK_HEADER); link != tt.expected {
+				t.Errorf("Expected link %q, got %q", tt.expected, link)
+			}
+		})
+	}
+}
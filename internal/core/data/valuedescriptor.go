@@ -259,7 +259,7 @@ func updateValueDescriptor(from contract.ValueDescriptor, loggingClient logger.L
 	if from.Name != "" {
 		// Check if value descriptor is still in use by readings if the name changes
 		if from.Name != to.Name {
-			r, err := getReadingsByValueDescriptor(to.Name, 10, loggingClient) // Arbitrary limit, we're just checking if there are any readings
+			r, err := getReadingsByValueDescriptor(to.Name, 10, 0, loggingClient) // Arbitrary limit, we're just checking if there are any readings
 			if err != nil {
 				loggingClient.Error("Error checking the readings for the value descriptor: " + err.Error())
 				return err
@@ -18,8 +18,8 @@ package client
 import (
 	"time"
 
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
-	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/google/uuid"
 )
 
@@ -31,7 +31,7 @@ import (
  */
 
 type MemDB struct {
-	regs []contract.Registration
+	regs []export.Registration
 }
 
 func (m *MemDB) CloseSession() {
@@ -41,11 +41,11 @@ func (m *MemDB) Connect() error {
 	return nil
 }
 
-func (mc *MemDB) Registrations() ([]contract.Registration, error) {
+func (mc *MemDB) Registrations() ([]export.Registration, error) {
 	return mc.regs, nil
 }
 
-func (mc *MemDB) AddRegistration(reg contract.Registration) (string, error) {
+func (mc *MemDB) AddRegistration(reg export.Registration) (string, error) {
 	ticks := time.Now().Unix()
 	reg.Created = ticks
 	reg.Modified = ticks
@@ -56,7 +56,7 @@ func (mc *MemDB) AddRegistration(reg contract.Registration) (string, error) {
 	return reg.ID, nil
 }
 
-func (mc *MemDB) UpdateRegistration(reg contract.Registration) error {
+func (mc *MemDB) UpdateRegistration(reg export.Registration) error {
 	for i, r := range mc.regs {
 		if r.ID == reg.ID {
 			mc.regs[i] = reg
@@ -66,24 +66,24 @@ func (mc *MemDB) UpdateRegistration(reg contract.Registration) error {
 	return db.ErrNotFound
 }
 
-func (mc *MemDB) RegistrationById(id string) (contract.Registration, error) {
+func (mc *MemDB) RegistrationById(id string) (export.Registration, error) {
 	for _, reg := range mc.regs {
 		if reg.ID == id {
 			return reg, nil
 		}
 	}
 
-	return contract.Registration{}, db.ErrNotFound
+	return export.Registration{}, db.ErrNotFound
 }
 
-func (mc *MemDB) RegistrationByName(name string) (contract.Registration, error) {
+func (mc *MemDB) RegistrationByName(name string) (export.Registration, error) {
 	for _, reg := range mc.regs {
 		if reg.Name == name {
 			return reg, nil
 		}
 	}
 
-	return contract.Registration{}, db.ErrNotFound
+	return export.Registration{}, db.ErrNotFound
 }
 
 func (mc *MemDB) DeleteRegistrationById(id string) error {
@@ -107,6 +107,6 @@ func (mc *MemDB) DeleteRegistrationByName(name string) error {
 }
 
 func (mc *MemDB) ScrubAllRegistrations() error {
-	mc.regs = make([]contract.Registration, 0)
+	mc.regs = make([]export.Registration, 0)
 	return nil
 }
@@ -9,13 +9,18 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/gorilla/mux"
 )
@@ -58,27 +63,13 @@ func getRegList(w http.ResponseWriter, r *http.Request) {
 
 	switch t {
 	case typeAlgorithms:
-		list = append(list, models.EncNone)
-		list = append(list, models.EncAes)
+		list = export.Algorithms
 	case typeCompressions:
-		list = append(list, models.CompNone)
-		list = append(list, models.CompGzip)
-		list = append(list, models.CompZip)
+		list = export.Compressions
 	case typeFormats:
-		list = append(list, models.FormatJSON)
-		list = append(list, models.FormatXML)
-		list = append(list, models.FormatIoTCoreJSON)
-		list = append(list, models.FormatAzureJSON)
-		list = append(list, models.FormatAWSJSON)
-		list = append(list, models.FormatThingsBoardJSON)
-		list = append(list, models.FormatNOOP)
+		list = export.Formats
 	case typeDestinations:
-		list = append(list, models.DestMQTT)
-		list = append(list, models.DestIotCoreMQTT)
-		list = append(list, models.DestAzureMQTT)
-		list = append(list, models.DestRest)
-		list = append(list, models.DestXMPP)
-		list = append(list, models.DestAWSMQTT)
+		list = export.Destinations
 	default:
 		LoggingClient.Error("Unknown type: " + t)
 		http.Error(w, "Unknown type: "+t, http.StatusBadRequest)
@@ -125,16 +116,16 @@ func addReg(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	reg := models.Registration{}
+	reg := export.Registration{}
 	if err := json.Unmarshal(data, &reg); err != nil {
 		LoggingClient.Error(fmt.Sprintf("Failed to query add registration. Error: %s", err.Error()))
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if valid, err := reg.Validate(); !valid {
+	if valid, err := export.ValidateRegistration(reg); !valid {
 		LoggingClient.Error(fmt.Sprintf("Failed to validate registrations fields: %X. Error: %s", data, err.Error()))
-		http.Error(w, "Could not validate json fields", http.StatusBadRequest)
+		http.Error(w, "Could not validate json fields: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -163,6 +154,36 @@ func addReg(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(id))
 }
 
+// updateOptions replaces the options present in the body of an update, null or empty options being cleared, the
+// options absent from the body being left unchanged
+func updateOptions(to *export.Options, from export.Options, body map[string]*json.RawMessage) {
+	present := func(key string) bool {
+		_, ok := body[key]
+		return ok
+	}
+	if present("template") {
+		to.Template = from.Template
+	}
+	if present("filterExpression") {
+		to.FilterExpression = from.FilterExpression
+	}
+	if present("transform") {
+		to.Transform = from.Transform
+	}
+	if present("kafka") {
+		to.Kafka = from.Kafka
+	}
+	if present("mqtt") {
+		to.Mqtt = from.Mqtt
+	}
+	if present("batch") {
+		to.Batch = from.Batch
+	}
+	if present("csv") {
+		to.CSV = from.CSV
+	}
+}
+
 func updateReg(w http.ResponseWriter, r *http.Request) {
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
@@ -171,7 +192,7 @@ func updateReg(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var fromReg models.Registration
+	var fromReg export.Registration
 	if err := json.Unmarshal(data, &fromReg); err != nil {
 		LoggingClient.Error(fmt.Sprintf("Failed to unmarshal update registration. Error: %s", err.Error()))
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -179,7 +200,7 @@ func updateReg(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if the registration exists
-	var toReg models.Registration
+	var toReg export.Registration
 	if fromReg.ID != "" {
 		toReg, err = dbClient.RegistrationById(fromReg.ID)
 	} else if fromReg.Name != "" {
@@ -231,10 +252,11 @@ func updateReg(w http.ResponseWriter, r *http.Request) {
 	if objmap["enable"] != nil {
 		toReg.Enable = fromReg.Enable
 	}
+	updateOptions(&toReg.Options, fromReg.Options, objmap)
 
-	if valid, err := toReg.Validate(); !valid {
+	if valid, err := export.ValidateRegistration(toReg); !valid {
 		LoggingClient.Error(fmt.Sprintf("Failed to validate registrations fields: %X. Error: %s", data, err.Error()))
-		http.Error(w, "Could not validate json fields", http.StatusBadRequest)
+		http.Error(w, "Could not validate json fields: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -303,6 +325,53 @@ func delRegByName(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("true"))
 }
 
+// dryRunReg validates the registration of the dry run, then has distro run the event through it and replies with the
+// result of distro
+func dryRunReg(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Failed to read dry run. Error: %s", err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var dryRun export.DryRun
+	if err := json.Unmarshal(data, &dryRun); err != nil {
+		LoggingClient.Error(fmt.Sprintf("Failed to unmarshal dry run. Error: %s", err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if valid, err := export.ValidateRegistration(dryRun.Registration); !valid {
+		LoggingClient.Error(fmt.Sprintf("Failed to validate registrations fields: %X. Error: %s", data, err.Error()))
+		http.Error(w, "Could not validate json fields: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, Configuration.Clients["Distro"].Url()+export.ApiDryRunRoute,
+		bytes.NewReader(data))
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Failed to create dry run request. Error: %s", err.Error()))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	req.Header.Set(clients.ContentType, clients.ContentTypeJSON)
+	req.Header.Set(clients.CorrelationHeader, r.Header.Get(clients.CorrelationHeader))
+
+	client := &http.Client{Timeout: time.Duration(Configuration.Service.Timeout) * time.Millisecond}
+	resp, err := client.Do(req)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Failed to run dry run in distro. Error: %s", err.Error()))
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer resp.Body.Close()
+
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
 func notifyUpdatedRegistrations(update models.NotifyUpdate) {
 	go func() {
 		err := dc.NotifyRegistrations(update, context.Background())
@@ -9,6 +9,7 @@ package client
 import (
 	"net/http"
 
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	"github.com/edgexfoundry/edgex-go/internal/pkg"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/telemetry"
@@ -54,6 +55,7 @@ func LoadRestRoutes() *mux.Router {
 	r.HandleFunc(clients.ApiRegistrationRoute, getAllReg).Methods(http.MethodGet)
 	r.HandleFunc(clients.ApiRegistrationRoute, addReg).Methods(http.MethodPost)
 	r.HandleFunc(clients.ApiRegistrationRoute, updateReg).Methods(http.MethodPut)
+	r.HandleFunc(export.ApiDryRunRoute, dryRunReg).Methods(http.MethodPost)
 	reg := r.PathPrefix(clients.ApiRegistrationRoute).Subrouter()
 	reg.HandleFunc("/{"+ID+"}", getRegByID).Methods(http.MethodGet)
 	reg.HandleFunc("/"+REFERENCE+"/{"+TYPE+"}", getRegList).Methods(http.MethodGet)
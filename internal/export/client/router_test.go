@@ -16,9 +16,14 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
+
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
@@ -376,7 +381,7 @@ func TestRegistrationGetList(t *testing.T) {
 	}
 }
 
-func getRegistrations(t *testing.T, serverUrl string) []models.Registration {
+func getRegistrations(t *testing.T, serverUrl string) []export.Registration {
 	response, err := http.Get(serverUrl + clients.ApiRegistrationRoute)
 	if err != nil {
 		t.Errorf("Error getting registrations: %v", err)
@@ -388,7 +393,7 @@ func getRegistrations(t *testing.T, serverUrl string) []models.Registration {
 
 	var data []byte
 	data, _ = ioutil.ReadAll(response.Body)
-	var regs []models.Registration
+	var regs []export.Registration
 	if err := json.Unmarshal(data, &regs); err != nil {
 		t.Errorf("Registrations could not be parsed: %v", err)
 	}
@@ -412,3 +417,176 @@ func TestRegistrationGetAll(t *testing.T) {
 		t.Errorf("There should be only one registrations: %v", regs)
 	}
 }
+
+// prepareDistro starts a fake distro replying to the dry runs with a filtered result, the returned function stops it
+func prepareDistro(t *testing.T) func() {
+	distroServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != export.ApiDryRunRoute {
+			t.Errorf("Unexpected distro path %s", r.URL.Path)
+		}
+		w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+		json.NewEncoder(w).Encode(export.DryRunResult{Filtered: true})
+	}))
+
+	distroURL, _ := url.Parse(distroServer.URL)
+	port, _ := strconv.Atoi(distroURL.Port())
+	Configuration = &ConfigurationStruct{Clients: map[string]config.ClientInfo{
+		"Distro": {Protocol: distroURL.Scheme, Host: distroURL.Hostname(), Port: port},
+	}}
+	return func() {
+		distroServer.Close()
+		Configuration = &ConfigurationStruct{}
+	}
+}
+
+func TestRegistrationDryRun(t *testing.T) {
+	defer prepareDistro(t)()
+
+	invalidReg := testRegistration
+	invalidReg.Format = "YAML"
+
+	var tests = []struct {
+		name   string
+		dryRun export.DryRun
+		status int
+	}{
+		{"ok", export.DryRun{Registration: export.Registration{Registration: testRegistration},
+			Event: models.Event{Device: "livingroomthermosat"}}, http.StatusOK},
+		{"invalidFormat", export.DryRun{Registration: export.Registration{Registration: invalidReg}},
+			http.StatusBadRequest},
+	}
+
+	ts := prepareTest(t)
+	defer ts.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, _ := json.Marshal(tt.dryRun)
+			response, err := http.Post(ts.URL+export.ApiDryRunRoute, clients.ContentTypeJSON, bytes.NewReader(request))
+			if err != nil {
+				t.Fatalf("Error sending dry run %v", err)
+			}
+			defer response.Body.Close()
+			if response.StatusCode != tt.status {
+				t.Fatalf("Returned status %d, should be %d", response.StatusCode, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var result export.DryRunResult
+			if err := json.NewDecoder(response.Body).Decode(&result); err != nil || !result.Filtered {
+				t.Errorf("Expected the result of distro, got %v (%v)", result, err)
+			}
+		})
+	}
+}
+
+// TestRegistrationExportValues sends the format, destination and encryption values export-distro adds to the
+// contract as JSON through the add, update and dry run handlers
+func TestRegistrationUpdateOptions(t *testing.T) {
+	var tests = []struct {
+		name       string
+		update     string
+		expression string
+		batch      bool
+	}{
+		{"unchanged", `{"name":"OSIClient","enable":false}`, "device == \"boiler\"", true},
+		{"replaced", `{"name":"OSIClient","filterExpression":"reading.temp > 80"}`, "reading.temp > 80", true},
+		{"cleared", `{"name":"OSIClient","filterExpression":"","batch":null}`, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := prepareTest(t)
+			defer ts.Close()
+
+			reg := export.Registration{Registration: testRegistration, Options: export.Options{
+				FilterExpression: `device == "boiler"`,
+				Batch:            &export.BatchInfo{MaxEvents: 10},
+			}}
+			request, _ := json.Marshal(reg)
+			response, err := http.Post(ts.URL+clients.ApiRegistrationRoute, clients.ContentTypeJSON, bytes.NewBuffer(request))
+			if err != nil {
+				t.Fatalf("Error adding registration %v", err)
+			}
+			response.Body.Close()
+
+			response = requestMethod(t, http.MethodPut, ts.URL+clients.ApiRegistrationRoute,
+				strings.NewReader(tt.update))
+			defer response.Body.Close()
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("Update returned status %d, should be %d", response.StatusCode, http.StatusOK)
+			}
+
+			regs := getRegistrations(t, ts.URL)
+			if len(regs) != 1 || regs[0].FilterExpression != tt.expression || (regs[0].Batch != nil) != tt.batch {
+				t.Errorf("Unexpected options after the update: %+v", regs)
+			}
+		})
+	}
+}
+
+func TestRegistrationExportValues(t *testing.T) {
+	var tests = []struct {
+		name        string
+		format      string
+		template    string
+		destination string
+		algo        string
+	}{
+		{"protobuf", export.FormatProtobuf, "", models.DestMQTT, models.EncNone},
+		{"avro", export.FormatAvro, "", models.DestRest, models.EncNone},
+		{"template", export.FormatTemplate, `{"device":{{json .Device}}}`, models.DestMQTT, models.EncNone},
+		{"kafka", models.FormatJSON, "", export.DestKafka, models.EncNone},
+		{"aesGcm", models.FormatJSON, "", models.DestMQTT, export.EncAesGcm},
+	}
+
+	defer prepareDistro(t)()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := prepareTest(t)
+			defer ts.Close()
+
+			values := fmt.Sprintf(`"format":%q,"template":%q,"destination":%q,`+
+				`"encryption":{"encryptionAlgorithm":%q,"encryptionKey":"export/reg"}`,
+				tt.format, tt.template, tt.destination, tt.algo)
+			reg := `{"name":"NAME","addressable":{"name":"Broker","protocol":"TCP","address":"127.0.0.1","port":1883,` +
+				`"topic":"events"},"compression":"NONE","enable":true,` + values + `}`
+
+			response, err := http.Post(ts.URL+clients.ApiRegistrationRoute, clients.ContentTypeJSON,
+				strings.NewReader(reg))
+			if err != nil {
+				t.Fatalf("Error adding registration %v", err)
+			}
+			defer response.Body.Close()
+			if response.StatusCode != http.StatusOK {
+				t.Errorf("Add returned status %d, should be %d", response.StatusCode, http.StatusOK)
+			}
+			regs := getRegistrations(t, ts.URL)
+			if len(regs) != 1 || regs[0].Format != tt.format || regs[0].Template != tt.template ||
+				regs[0].Destination != tt.destination || regs[0].Encryption.Algo != tt.algo {
+				t.Errorf("Registration not stored with the values: %v", regs)
+			}
+
+			createRegistration(t, ts.URL)
+			response = requestMethod(t, http.MethodPut, ts.URL+clients.ApiRegistrationRoute,
+				strings.NewReader(`{"name":"OSIClient",`+values+`}`))
+			defer response.Body.Close()
+			if response.StatusCode != http.StatusOK {
+				t.Errorf("Update returned status %d, should be %d", response.StatusCode, http.StatusOK)
+			}
+
+			response, err = http.Post(ts.URL+export.ApiDryRunRoute, clients.ContentTypeJSON,
+				strings.NewReader(`{"registration":`+reg+`,"event":{"device":"livingroomthermosat"}}`))
+			if err != nil {
+				t.Fatalf("Error sending dry run %v", err)
+			}
+			defer response.Body.Close()
+			if response.StatusCode != http.StatusOK {
+				t.Errorf("Dry run returned status %d, should be %d", response.StatusCode, http.StatusOK)
+			}
+		})
+	}
+}
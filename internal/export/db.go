@@ -13,36 +13,32 @@
  *******************************************************************************/
 package export
 
-import (
-	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
-)
-
 type DBClient interface {
 	CloseSession()
 
 	// ********************** REGISTRATION FUNCTIONS *****************************
 	// Return all the registrations
 	// UnexpectedError - failed to retrieve registrations from the database
-	Registrations() ([]contract.Registration, error)
+	Registrations() ([]Registration, error)
 
 	// Add a new registration
 	// UnexpectedError - failed to add to database
-	AddRegistration(reg contract.Registration) (string, error)
+	AddRegistration(reg Registration) (string, error)
 
 	// Update a registration
 	// UnexpectedError - problem updating in database
 	// NotFound - no registration with the ID was found
-	UpdateRegistration(reg contract.Registration) error
+	UpdateRegistration(reg Registration) error
 
 	// Get a registration by ID
 	// UnexpectedError - problem getting in database
 	// NotFound - no registration with the ID was found
-	RegistrationById(id string) (contract.Registration, error)
+	RegistrationById(id string) (Registration, error)
 
 	// Get a registration by name
 	// UnexpectedError - problem getting in database
 	// NotFound - no registration with the name was found
-	RegistrationByName(name string) (contract.Registration, error)
+	RegistrationByName(name string) (Registration, error)
 
 	// Delete a registration by ID
 	// UnexpectedError - problem getting in database
@@ -0,0 +1,130 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// avroSchema is the published schema of the AVRO format, in Parsing Canonical Form so that its fingerprint is the one
+// computed by the Avro libraries
+const avroSchema = `{"name":"org.edgexfoundry.export.Event","type":"record","fields":[` +
+	`{"name":"id","type":"string"},` +
+	`{"name":"pushed","type":"long"},` +
+	`{"name":"device","type":"string"},` +
+	`{"name":"created","type":"long"},` +
+	`{"name":"modified","type":"long"},` +
+	`{"name":"origin","type":"long"},` +
+	`{"name":"readings","type":{"type":"array","items":` +
+	`{"name":"org.edgexfoundry.export.Reading","type":"record","fields":[` +
+	`{"name":"id","type":"string"},` +
+	`{"name":"pushed","type":"long"},` +
+	`{"name":"created","type":"long"},` +
+	`{"name":"origin","type":"long"},` +
+	`{"name":"modified","type":"long"},` +
+	`{"name":"device","type":"string"},` +
+	`{"name":"name","type":"string"},` +
+	`{"name":"value","type":"string"},` +
+	`{"name":"binaryValue","type":"bytes"}]}}}]}`
+
+const avroContentType = "avro/binary"
+
+// avroSingleObjectMarker starts the Avro single object encoding, followed by the fingerprint of the schema
+var avroSingleObjectMarker = []byte{0xC3, 0x01}
+
+// crc64AvroEmpty is the initial value of the CRC-64-AVRO fingerprint of the Avro specification
+const crc64AvroEmpty uint64 = 0xc15d213aa4d7a795
+
+var crc64AvroTable = func() [256]uint64 {
+	var table [256]uint64
+	for i := range table {
+		fp := uint64(i)
+		for j := 0; j < 8; j++ {
+			fp = (fp >> 1) ^ (crc64AvroEmpty & -(fp & 1))
+		}
+		table[i] = fp
+	}
+	return table
+}()
+
+func crc64Avro(data []byte) uint64 {
+	fp := crc64AvroEmpty
+	for _, b := range data {
+		fp = (fp >> 8) ^ crc64AvroTable[byte(fp)^b]
+	}
+	return fp
+}
+
+var avroFingerprint = crc64Avro([]byte(avroSchema))
+
+// avroFormatter encodes the events as Event records of avroSchema with the single object encoding, the records being
+// preceded by the fingerprint of the schema
+type avroFormatter struct {
+}
+
+func (af avroFormatter) Format(event *contract.Event) []byte {
+	b := append([]byte{}, avroSingleObjectMarker...)
+	var fp [8]byte
+	binary.LittleEndian.PutUint64(fp[:], avroFingerprint)
+	b = append(b, fp[:]...)
+
+	b = appendAvroString(b, event.ID)
+	b = appendAvroLong(b, event.Pushed)
+	b = appendAvroString(b, event.Device)
+	b = appendAvroLong(b, event.Created)
+	b = appendAvroLong(b, event.Modified)
+	b = appendAvroLong(b, event.Origin)
+
+	// the readings are written as a single block followed by the empty block ending the array
+	if len(event.Readings) > 0 {
+		b = appendAvroLong(b, int64(len(event.Readings)))
+		for _, r := range event.Readings {
+			b = appendAvroString(b, r.Id)
+			b = appendAvroLong(b, r.Pushed)
+			b = appendAvroLong(b, r.Created)
+			b = appendAvroLong(b, r.Origin)
+			b = appendAvroLong(b, r.Modified)
+			b = appendAvroString(b, r.Device)
+			b = appendAvroString(b, r.Name)
+			b = appendAvroString(b, r.Value)
+			b = appendAvroBytes(b, r.BinaryValue)
+		}
+	}
+	return appendAvroLong(b, 0)
+}
+
+func (af avroFormatter) Schema() string {
+	return avroSchema
+}
+
+// SchemaFingerprint returns the CRC-64-AVRO fingerprint of the schema, hex encoded
+func (af avroFormatter) SchemaFingerprint() string {
+	return fmt.Sprintf("%016x", avroFingerprint)
+}
+
+func (af avroFormatter) ContentType() string {
+	return avroContentType
+}
+
+// appendAvroLong appends a zig-zag encoded long
+func appendAvroLong(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func appendAvroString(b []byte, s string) []byte {
+	return appendAvroBytes(b, []byte(s))
+}
+
+func appendAvroBytes(b []byte, v []byte) []byte {
+	b = appendAvroLong(b, int64(len(v)))
+	return append(b, v...)
+}
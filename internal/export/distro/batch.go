@@ -0,0 +1,94 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/export"
+)
+
+// defaultBatchLatency is how long a batch waits for more events when MaxLatency is not set
+const defaultBatchLatency = time.Second
+
+// eventBatch holds the events of a registration waiting to be sent together
+type eventBatch struct {
+	maxEvents int
+	maxBytes  int
+	latency   time.Duration
+
+	events        []*contract.Event
+	eventIDs      []string
+	size          int
+	correlationID string
+}
+
+func newEventBatch(info export.BatchInfo) *eventBatch {
+	return &eventBatch{
+		maxEvents: info.MaxEvents,
+		maxBytes:  info.MaxBytes,
+		latency:   configDuration(info.MaxLatency, defaultBatchLatency),
+	}
+}
+
+// add appends an event, size is the size of the event as received
+func (b *eventBatch) add(event *contract.Event, id string, size int, correlationID string) {
+	if len(b.events) == 0 {
+		b.correlationID = correlationID
+	}
+	b.events = append(b.events, event)
+	b.eventIDs = append(b.eventIDs, id)
+	b.size += size
+}
+
+func (b *eventBatch) pending() bool {
+	return len(b.events) > 0
+}
+
+// full tells whether the batch reached MaxEvents events or MaxBytes bytes
+func (b *eventBatch) full() bool {
+	return (b.maxEvents > 0 && len(b.events) >= b.maxEvents) || (b.maxBytes > 0 && b.size >= b.maxBytes)
+}
+
+func (b *eventBatch) reset() {
+	b.events = nil
+	b.eventIDs = nil
+	b.size = 0
+	b.correlationID = ""
+}
+
+// flushBatch formats the pending events of the registration as one payload, compressed and encrypted as a single
+// unit, and sends it with the content type of the format. The events are marked pushed once the payload is delivered.
+func (reg registrationInfo) flushBatch() error {
+	if reg.batch == nil || !reg.batch.pending() {
+		return nil
+	}
+	defer reg.batch.reset()
+
+	format, ok := reg.format.(batchFormatter)
+	if !ok {
+		return fmt.Errorf("format %s of registration %s cannot be batched", reg.registration.Format, reg.registration.Name)
+	}
+
+	LoggingClient.Debug(fmt.Sprintf("Sending batch of %d events with registration: %s",
+		len(reg.batch.events), reg.registration.Name))
+
+	ctx := context.WithValue(context.Background(), clients.CorrelationHeader, reg.batch.correlationID)
+	ctx = context.WithValue(ctx, clients.ContentType, format.ContentType())
+	return reg.send(queuedMessage{
+		Payload:       reg.encode(format.FormatBatch(reg.batch.events)),
+		ContentType:   format.ContentType(),
+		CorrelationID: reg.batch.correlationID,
+		EventIDs:      append([]string{}, reg.batch.eventIDs...),
+		Received:      time.Now().UnixNano(),
+	}, ctx)
+}
@@ -0,0 +1,142 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+
+	"github.com/edgexfoundry/edgex-go/internal/export"
+)
+
+func TestEventBatchFull(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	tests := []struct {
+		name     string
+		info     export.BatchInfo
+		sizes    []int
+		expected bool
+	}{
+		{"Below limits", export.BatchInfo{MaxEvents: 3, MaxBytes: 100}, []int{10, 10}, false},
+		{"Max events", export.BatchInfo{MaxEvents: 2, MaxBytes: 100}, []int{10, 10}, true},
+		{"Max bytes", export.BatchInfo{MaxEvents: 3, MaxBytes: 100}, []int{60, 40}, true},
+		{"No limits", export.BatchInfo{}, []int{60, 40}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newEventBatch(tt.info)
+			for _, size := range tt.sizes {
+				b.add(&contract.Event{}, "id", size, "")
+			}
+			if b.full() != tt.expected {
+				t.Fatalf("Expected full %v", tt.expected)
+			}
+		})
+	}
+}
+
+func TestEventBatchLatency(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	if b := newEventBatch(export.BatchInfo{}); b.latency != defaultBatchLatency {
+		t.Errorf("Expected the default latency, got %s", b.latency)
+	}
+	if b := newEventBatch(export.BatchInfo{MaxLatency: "250ms"}); b.latency != 250*time.Millisecond {
+		t.Errorf("Expected a latency of 250ms, got %s", b.latency)
+	}
+}
+
+func TestRegistrationFlushBatch(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	sender := &recordingSender{}
+	ri := newRegistrationInfo()
+	ri.format = jsonFormatter{}
+	ri.sender = sender
+	ri.batch = newEventBatch(export.BatchInfo{MaxEvents: 10})
+
+	if err := ri.flushBatch(); err != nil || len(sender.sent) != 0 {
+		t.Fatalf("Expected nothing to be sent without pending events, got %v", sender.sent)
+	}
+
+	ri.batch.add(&contract.Event{ID: "1", Device: devID1}, "1", 10, "correlation")
+	ri.batch.add(&contract.Event{ID: "2", Device: devID1}, "2", 10, "other")
+	if err := ri.flushBatch(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("Expected the batch to be sent as one payload, got %v", sender.sent)
+	}
+	var events []contract.Event
+	if err := json.Unmarshal([]byte(sender.sent[0]), &events); err != nil || len(events) != 2 {
+		t.Fatalf("Expected a JSON array of 2 events, got %s", sender.sent[0])
+	}
+	if sender.contentTypes[0] != clients.ContentTypeJSON {
+		t.Fatalf("Expected the batch to be sent as %s, got %s", clients.ContentTypeJSON, sender.contentTypes[0])
+	}
+	if ri.batch.pending() {
+		t.Fatal("Expected the batch to be reset once sent")
+	}
+}
+
+func TestRegistrationFlushBatchContentType(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	csvFormat, _ := newCSVFormatter(nil, false)
+	var tests = []struct {
+		name        string
+		format      formatter
+		contentType string
+	}{
+		{"JSON", jsonFormatter{}, clients.ContentTypeJSON},
+		{"XML", xmlFormatter{}, xmlContentType},
+		{"CSV", csvFormat, csvContentType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &recordingSender{}
+			ri := newRegistrationInfo()
+			ri.format = tt.format
+			ri.sender = sender
+			ri.batch = newEventBatch(export.BatchInfo{})
+			ri.batch.add(&contract.Event{ID: "1", Device: devID1}, "1", 10, "")
+
+			if err := ri.flushBatch(); err != nil || len(sender.sent) != 1 {
+				t.Fatalf("Expected the batch to be sent, got %v: %v", sender.sent, err)
+			}
+			if sender.contentTypes[0] != tt.contentType {
+				t.Fatalf("Expected the batch to be sent as %s, got %s", tt.contentType, sender.contentTypes[0])
+			}
+		})
+	}
+}
+
+func TestRegistrationBatchQueued(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	store, cleanup := newTestQueueStore(t)
+	defer cleanup()
+
+	sender := &recordingSender{fail: true}
+	ri := newRegistrationInfo()
+	ri.format = jsonFormatter{}
+	ri.sender = sender
+	ri.queue, _ = newRetryQueue(store, "reg")
+	ri.batch = newEventBatch(export.BatchInfo{})
+
+	ri.batch.add(&contract.Event{ID: "1"}, "1", 10, "")
+	ri.batch.add(&contract.Event{ID: "2"}, "2", 10, "")
+	ri.flushBatch()
+
+	m, _, ok, err := ri.queue.peek()
+	if err != nil || !ok {
+		t.Fatal("Expected the batch to be queued")
+	}
+	if len(m.EventIDs) != 2 || m.EventIDs[0] != "1" || m.EventIDs[1] != "2" {
+		t.Fatalf("Expected the ids of the batched events to be queued, got %v", m.EventIDs)
+	}
+}
@@ -12,17 +12,17 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
-	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
 //TODO: Since this is a service-to-service client, it should be in /pkg/clients/export
-func getRegistrations() ([]contract.Registration, error) {
+func getRegistrations() ([]export.Registration, error) {
 	url := Configuration.Clients["Export"].Url() + clients.ApiRegistrationRoute
 	return getRegistrationsURL(url)
 }
 
-func getRegistrationsURL(url string) ([]contract.Registration, error) {
+func getRegistrationsURL(url string) ([]export.Registration, error) {
 	response, err := http.Get(url)
 	if err != nil {
 		LoggingClient.Error(fmt.Sprintf("Error getting all registrations: %s. Error: %s", url, err.Error()))
@@ -32,14 +32,14 @@ func getRegistrationsURL(url string) ([]contract.Registration, error) {
 
 	// ensure we have an empty slice instead of a nil slice for better handling of JSON
 	// validation and decoding are now done together. If it decodes successfully, it's valid.
-	registrations := make([]contract.Registration, 0)
+	registrations := make([]export.Registration, 0)
 	if err := json.NewDecoder(response.Body).Decode(&registrations); err != nil {
 		LoggingClient.Error(fmt.Sprintf("error decoding registrations: %s", err.Error()))
 	}
 
 	// This is now redundant because we've already validated all of these. However, I have to include this for
 	// now because of the API's current behavior.
-	results := make([]contract.Registration, 0)
+	results := make([]export.Registration, 0)
 	for _, reg := range registrations {
 		if valid, err := reg.Validate(); valid {
 			results = append(results, reg)
@@ -50,12 +50,12 @@ func getRegistrationsURL(url string) ([]contract.Registration, error) {
 	return results, nil
 }
 
-func getRegistrationByName(name string) *contract.Registration {
+func getRegistrationByName(name string) *export.Registration {
 	url := fmt.Sprintf("%s%s/%s", Configuration.Clients["Export"].Url(), clients.ApiRegistrationByNameRoute, name)
 	return getRegistrationByNameURL(url)
 }
 
-func getRegistrationByNameURL(url string) *contract.Registration {
+func getRegistrationByNameURL(url string) *export.Registration {
 
 	response, err := http.Get(url)
 	if err != nil {
@@ -64,7 +64,7 @@ func getRegistrationByNameURL(url string) *contract.Registration {
 	}
 	defer response.Body.Close()
 
-	reg := contract.Registration{}
+	reg := export.Registration{}
 	if err := json.NewDecoder(response.Body).Decode(&reg); err != nil {
 		LoggingClient.Error(fmt.Sprintf("Could not parse json. Error: %s", err.Error()))
 		return nil
@@ -8,6 +8,7 @@
 package distro
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -15,6 +16,8 @@ import (
 	"os"
 	"testing"
 
+	"github.com/Shopify/sarama"
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
 )
@@ -230,3 +233,66 @@ func TestClientRegistrationByNameError(t *testing.T) {
 		}
 	}
 }
+
+// TestClientRegistrationRoundTrip decodes the registrations export-client serves with the values export-distro adds
+// to the contract, and runs them through update
+func TestClientRegistrationRoundTrip(t *testing.T) {
+	secretProvider = testSecretProvider{key: base64.StdEncoding.EncodeToString(make([]byte, aesGCMKeySize))}
+	defer func() { secretProvider = nil }()
+
+	tests := []struct {
+		name        string
+		format      string
+		template    string
+		destination string
+		algo        string
+		kafka       *export.KafkaInfo
+	}{
+		{"aesGcm", models.FormatJSON, "", models.DestMQTT, export.EncAesGcm, nil},
+		{"kafka", models.FormatJSON, "", export.DestKafka, models.EncNone, nil},
+		{"kafkaOptions", models.FormatJSON, "", export.DestKafka, models.EncNone,
+			&export.KafkaInfo{Brokers: []string{"kafka:9092"}, Acks: export.KafkaAcksLeader}},
+		{"protobuf", export.FormatProtobuf, "", models.DestRest, models.EncNone, nil},
+		{"avro", export.FormatAvro, "", models.DestMQTT, models.EncNone, nil},
+		{"template", export.FormatTemplate, "{{json .Device}}", models.DestRest, models.EncNone, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := export.Registration{Registration: testRegistration}
+			reg.Format = tt.format
+			reg.Template = tt.template
+			reg.Destination = tt.destination
+			reg.Encryption = models.EncryptionDetails{Algo: tt.algo, Key: "export/reg"}
+			reg.Kafka = tt.kafka
+			data, err := json.Marshal(reg)
+			if err != nil {
+				t.Fatalf("marshaling error %v", err)
+			}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(data)
+			}))
+			defer ts.Close()
+
+			decoded := getRegistrationByNameURL(ts.URL)
+			if decoded == nil {
+				t.Fatal("nil registration")
+			}
+			if decoded.Format != tt.format || decoded.Template != tt.template || decoded.Destination != tt.destination ||
+				decoded.Encryption.Algo != tt.algo {
+				t.Fatalf("Unexpected registration %v", decoded)
+			}
+			info := newRegistrationInfo()
+			if !info.update(*decoded) {
+				t.Fatal("The registration should be loaded")
+			}
+			if tt.kafka != nil {
+				sender, ok := info.sender.(*kafkaSender)
+				if !ok || sender.brokers[0] != tt.kafka.Brokers[0] ||
+					sender.config.Producer.RequiredAcks != sarama.WaitForLocal {
+					t.Errorf("The kafka options of the registration should be used: %v", info.sender)
+				}
+			}
+		})
+	}
+}
@@ -19,16 +19,17 @@ import (
 )
 
 type ConfigurationStruct struct {
-	Writable       WritableInfo
-	Certificates   map[string]CertificateInfo
-	Clients        map[string]config.ClientInfo
-	Logging        config.LoggingInfo
-	MessageQueue   config.MessageQueueInfo
-	AnalyticsQueue config.MessageQueueInfo
-	Registry       config.RegistryInfo
-	Service        config.ServiceInfo
-	SecretStore    config.SecretStoreInfo
-	Startup        config.StartupInfo
+	Writable        WritableInfo
+	Certificates    map[string]CertificateInfo
+	Clients         map[string]config.ClientInfo
+	Logging         config.LoggingInfo
+	MessageQueue    config.MessageQueueInfo
+	AnalyticsQueue  config.MessageQueueInfo
+	Registry        config.RegistryInfo
+	Service         config.ServiceInfo
+	SecretStore     config.SecretStoreInfo
+	Startup         config.StartupInfo
+	StoreAndForward StoreAndForwardInfo
 }
 
 type WritableInfo struct {
@@ -41,6 +42,22 @@ type CertificateInfo struct {
 	Key  string
 }
 
+// StoreAndForwardInfo configures the on-disk queues holding the events of the registrations until they are delivered.
+// Each registration sends its queued events in order from its own goroutine, retrying after a failure.
+type StoreAndForwardInfo struct {
+	Enabled bool
+	// Path of the file holding the queues
+	Path string
+	// RetryInterval is the delay before retrying a failed registration, doubled after each failed retry up to
+	// MaxRetryInterval
+	RetryInterval    string
+	MaxRetryInterval string
+	// MaxAge after which a queued event is dropped, e.g. '24h'. Events are kept until delivered when empty.
+	MaxAge string
+	// MaxSize is the number of events queued per registration, the oldest are dropped beyond it. Unlimited when 0.
+	MaxSize int
+}
+
 // UpdateFromRaw converts configuration received from the registry to a service-specific configuration struct which is
 // then used to overwrite the service's existing configuration struct.
 func (c *ConfigurationStruct) UpdateFromRaw(rawConfig interface{}) bool {
@@ -0,0 +1,64 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	"github.com/edgexfoundry/edgex-go/internal/pkg"
+)
+
+// dryRun runs the event, or the sample event when there is none, through the pipeline of the registration without
+// sending it
+func dryRun(request export.DryRun) (export.DryRunResult, error) {
+	reg := registrationInfo{}
+	if err := reg.updatePipeline(request.Registration); err != nil {
+		return export.DryRunResult{}, err
+	}
+
+	event := request.Event
+	if request.IsEmpty() {
+		event = export.SampleEvent()
+	}
+	data, accepted := reg.filterAndTransform(&event)
+	if !accepted {
+		return export.DryRunResult{Filtered: true}, nil
+	}
+	return export.DryRunResult{Payload: reg.encode(reg.format.Format(data))}, nil
+}
+
+func dryRunHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Failed read body. Error: %s", err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request export.DryRun
+	if err := json.Unmarshal(data, &request); err != nil {
+		LoggingClient.Error(fmt.Sprintf("Failed to parse %X", data))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if valid, err := export.ValidateRegistration(request.Registration); !valid {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := dryRun(request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pkg.Encode(result, w, LoggingClient)
+}
@@ -0,0 +1,188 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func dryRunRegistration() export.Registration {
+	return export.Registration{Registration: contract.Registration{
+		Name:        "dryrun",
+		Addressable: contract.Addressable{Name: "Test Addressable"},
+		Format:      contract.FormatJSON,
+		Compression: contract.CompGzip,
+		Destination: contract.DestRest,
+		Encryption:  contract.EncryptionDetails{Algo: contract.EncNone},
+		Filter:      contract.Filter{DeviceIDs: []string{devID1}},
+	}}
+}
+
+func TestDryRun(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	Configuration = &ConfigurationStruct{}
+
+	event := contract.Event{Device: devID1, Readings: []contract.Reading{{Name: readingName1, Value: readingValue1}}}
+	result, err := dryRun(export.DryRun{Registration: dryRunRegistration(), Event: event})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Filtered {
+		t.Fatal("Expected the event not to be filtered")
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(string(result.Payload))
+	if err != nil {
+		t.Fatalf("Expected a base64 payload: %v", err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("Expected a gzip payload: %v", err)
+	}
+	data, _ := ioutil.ReadAll(r)
+	var out contract.Event
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Expected a JSON event: %v", err)
+	}
+	if out.Device != devID1 || len(out.Readings) != 1 {
+		t.Fatalf("Unexpected event %v", out)
+	}
+}
+
+func TestDryRunFiltered(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	Configuration = &ConfigurationStruct{}
+
+	result, err := dryRun(export.DryRun{Registration: dryRunRegistration(), Event: contract.Event{Device: "id2"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Filtered || result.Payload != nil {
+		t.Fatalf("Expected the event to be filtered out, got %v", result)
+	}
+}
+
+func TestDryRunFilterExpression(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	Configuration = &ConfigurationStruct{}
+
+	reg := dryRunRegistration()
+	reg.FilterExpression = "reading." + readingName1 + " > 100"
+	event := contract.Event{Device: devID1, Readings: []contract.Reading{{Name: readingName1, Value: "20"}}}
+	result, err := dryRun(export.DryRun{Registration: reg, Event: event})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Filtered {
+		t.Fatalf("Expected the event to be filtered out by the expression, got %v", result)
+	}
+
+	event.Readings[0].Value = "120"
+	result, err = dryRun(export.DryRun{Registration: reg, Event: event})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Filtered {
+		t.Fatal("Expected the event to be accepted by the expression")
+	}
+}
+
+func TestDryRunSampleEvent(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	Configuration = &ConfigurationStruct{}
+
+	reg := dryRunRegistration()
+	reg.Filter = contract.Filter{}
+	reg.Compression = contract.CompNone
+	reg.Format = export.FormatTemplate
+	reg.Template = `{{(index .Readings 0).Name}}={{(index .Readings 0).Value}}`
+	result, err := dryRun(export.DryRun{Registration: reg})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	first := export.SampleEvent().Readings[0]
+	if expected := first.Name + "=" + first.Value; string(result.Payload) != expected {
+		t.Fatalf("Expected %s for the sample event, got %s", expected, result.Payload)
+	}
+}
+
+func TestDryRunTransform(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	Configuration = &ConfigurationStruct{}
+	valueDescriptorUnit = func(name string) (string, error) { return "", nil }
+	defer func() { valueDescriptorUnit = coreDataUnit }()
+
+	reg := dryRunRegistration()
+	reg.Compression = contract.CompNone
+	reg.Transform = &export.TransformInfo{
+		Readings: map[string]export.ReadingTransformInfo{readingName1: {Rename: "temperature"}},
+	}
+	event := contract.Event{Device: devID1, Readings: []contract.Reading{{Name: readingName1, Value: readingValue1}}}
+	result, err := dryRun(export.DryRun{Registration: reg, Event: event})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var out contract.Event
+	if err := json.Unmarshal(result.Payload, &out); err != nil {
+		t.Fatalf("Expected a JSON event: %v", err)
+	}
+	if len(out.Readings) != 1 || out.Readings[0].Name != "temperature" {
+		t.Fatalf("Expected the reading to be renamed, got %v", out)
+	}
+}
+
+func TestDryRunHandler(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	Configuration = &ConfigurationStruct{}
+
+	invalidFormat := dryRunRegistration()
+	invalidFormat.Format = "YAML"
+	invalidTemplate := dryRunRegistration()
+	invalidTemplate.Format = export.FormatTemplate
+	invalidTemplate.Template = "{{.Device"
+	invalidExpression := dryRunRegistration()
+	invalidExpression.FilterExpression = "device =="
+	protobuf := dryRunRegistration()
+	protobuf.Format = export.FormatProtobuf
+
+	tests := []struct {
+		name   string
+		body   interface{}
+		status int
+	}{
+		{"Valid", export.DryRun{Registration: dryRunRegistration(), Event: contract.Event{Device: devID1}}, http.StatusOK},
+		{"Protobuf", export.DryRun{Registration: protobuf, Event: contract.Event{Device: devID1}}, http.StatusOK},
+		{"Invalid format", export.DryRun{Registration: invalidFormat}, http.StatusBadRequest},
+		{"Invalid template", export.DryRun{Registration: invalidTemplate}, http.StatusBadRequest},
+		{"Invalid filter expression", export.DryRun{Registration: invalidExpression}, http.StatusBadRequest},
+		{"Invalid JSON", "{", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, export.ApiDryRunRoute, bytes.NewReader(body))
+			rr := httptest.NewRecorder()
+			dryRunHandler(rr, req)
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
@@ -10,8 +10,11 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
 	"crypto/sha1"
 	"encoding/base64"
+	"fmt"
+	"io"
 
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
 )
@@ -62,3 +65,66 @@ func (aesData aesEncryption) Transform(data []byte) []byte {
 
 	return encodedData
 }
+
+const (
+	// aesGCMEnvelopeVersion is the first byte of the AES-256-GCM envelope
+	aesGCMEnvelopeVersion byte = 1
+	// aesGCMKeySecret is the key of the secret holding the base64 encoded 32 bytes key
+	aesGCMKeySecret = "key"
+	aesGCMKeySize   = 32
+)
+
+// aesGCMEncryption seals every message with AES-256-GCM under a random nonce. The result is the base64 encoding of
+// the envelope
+//
+//	version (1 byte) | nonce (12 bytes) | ciphertext and tag
+//
+// where the version is authenticated along with the ciphertext.
+type aesGCMEncryption struct {
+	aead cipher.AEAD
+}
+
+// newAESGCMEncryption reads the key from the secret store, the Key of the encryption details being the path of the
+// secret rather than the key itself
+func newAESGCMEncryption(encData models.EncryptionDetails) (transformer, error) {
+	if secretProvider == nil {
+		return nil, fmt.Errorf("no secret store to read key %s from", encData.Key)
+	}
+	secrets, err := secretProvider.GetSecrets(encData.Key, aesGCMKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %s", encData.Key, err.Error())
+	}
+	key, err := base64.StdEncoding.DecodeString(secrets[aesGCMKeySecret])
+	if err != nil {
+		return nil, fmt.Errorf("key %s is not base64 encoded: %s", encData.Key, err.Error())
+	}
+	return newAESGCMEncryptionWithKey(key)
+}
+
+func newAESGCMEncryptionWithKey(key []byte) (transformer, error) {
+	if len(key) != aesGCMKeySize {
+		return nil, fmt.Errorf("AES-256-GCM key must be %d bytes, not %d", aesGCMKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aesGCMEncryption{aead: aead}, nil
+}
+
+func (aesData aesGCMEncryption) Transform(data []byte) []byte {
+	envelope := make([]byte, 1+aesData.aead.NonceSize(), 1+aesData.aead.NonceSize()+len(data)+aesData.aead.Overhead())
+	envelope[0] = aesGCMEnvelopeVersion
+	nonce := envelope[1:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		LoggingClient.Error("failed to generate nonce: " + err.Error())
+		return nil
+	}
+
+	envelope = aesData.aead.Seal(envelope, nonce, data, envelope[:1])
+	return []byte(base64.StdEncoding.EncodeToString(envelope))
+}
@@ -11,6 +11,8 @@ import (
 	"crypto/cipher"
 	"crypto/sha1"
 	"encoding/base64"
+	"errors"
+	"strings"
 
 	"testing"
 
@@ -72,3 +74,82 @@ func TestAES(t *testing.T) {
 		t.Fatal("Encoded string ", string(plainString), " is not ", string(decphrd))
 	}
 }
+
+// testSecretProvider returns the key held by the secret named "export/reg"
+type testSecretProvider struct {
+	key string
+}
+
+func (p testSecretProvider) GetSecrets(path string, keys ...string) (map[string]string, error) {
+	if path != "export/reg" {
+		return nil, errors.New("secret not found")
+	}
+	return map[string]string{"key": p.key}, nil
+}
+
+func aesGCMOpen(t *testing.T, sealed []byte, key []byte) ([]byte, error) {
+	envelope, err := base64.StdEncoding.DecodeString(string(sealed))
+	if err != nil {
+		t.Fatalf("Envelope is not base64 encoded: %v", err)
+	}
+	if envelope[0] != aesGCMEnvelopeVersion {
+		t.Fatalf("Unexpected envelope version %d", envelope[0])
+	}
+	block, _ := aes.NewCipher(key)
+	aead, _ := cipher.NewGCM(block)
+	nonce := envelope[1 : 1+aead.NonceSize()]
+	return aead.Open(nil, nonce, envelope[1+aead.NonceSize():], envelope[:1])
+}
+
+func TestAESGCM(t *testing.T) {
+	key := []byte(strings.Repeat("k", aesGCMKeySize))
+	secretProvider = testSecretProvider{key: base64.StdEncoding.EncodeToString(key)}
+	defer func() { secretProvider = nil }()
+
+	enc, err := newAESGCMEncryption(models.EncryptionDetails{Algo: "AES256GCM", Key: "export/reg"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sealed := enc.Transform([]byte(plainString))
+	opened, err := aesGCMOpen(t, sealed, key)
+	if err != nil || string(opened) != plainString {
+		t.Fatalf("Expected %s, got %s, error %v", plainString, opened, err)
+	}
+
+	if string(enc.Transform([]byte(plainString))) == string(sealed) {
+		t.Fatal("Expected a different nonce for every message")
+	}
+
+	envelope, _ := base64.StdEncoding.DecodeString(string(sealed))
+	envelope[len(envelope)-1] ^= 1
+	if _, err = aesGCMOpen(t, []byte(base64.StdEncoding.EncodeToString(envelope)), key); err == nil {
+		t.Fatal("Expected a tampered message to be rejected")
+	}
+}
+
+func TestAESGCMInvalidKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider *testSecretProvider
+		path     string
+	}{
+		{"No secret store", nil, "export/reg"},
+		{"Unknown secret", &testSecretProvider{key: base64.StdEncoding.EncodeToString(make([]byte, 32))}, "other"},
+		{"Not base64", &testSecretProvider{key: "not base64!"}, "export/reg"},
+		{"Wrong size", &testSecretProvider{key: base64.StdEncoding.EncodeToString(make([]byte, 16))}, "export/reg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secretProvider = nil
+			if tt.provider != nil {
+				secretProvider = *tt.provider
+			}
+			defer func() { secretProvider = nil }()
+
+			if _, err := newAESGCMEncryption(models.EncryptionDetails{Algo: "AES256GCM", Key: tt.path}); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,515 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// An expression filter selects the events exported by a registration, for instance
+//
+//	device =~ "boiler-.*" && reading.temp > 80 && labels contains "building-a"
+//
+// The operands are
+//   - device, origin and created: the fields of the event
+//   - reading.<name>: the value of the reading of the event with the given name
+//   - name and value: the name and value of a reading, when used only the readings accepted by the expression are kept
+//   - labels: the labels of the device of the event in core-metadata
+//   - "strings", numbers, true and false
+//
+// Comparisons are ==, !=, <, <=, >, >=, =~ and !~ (regular expression match of the whole operand, "boiler" does not
+// match "boiler-1"), and contains for a label or a substring. Operands which both parse as numbers are compared as numbers. Comparisons may be combined with &&, ||,
+// ! and parentheses. A comparison involving a reading the event does not have is false.
+
+const (
+	exprFieldDevice  = "device"
+	exprFieldOrigin  = "origin"
+	exprFieldCreated = "created"
+	exprFieldName    = "name"
+	exprFieldValue   = "value"
+	exprFieldLabels  = "labels"
+	exprReadingField = "reading."
+)
+
+// expressionFilter is the filterer of a compiled filter expression
+type expressionFilter struct {
+	expression exprNode
+	// perReading is set when the expression involves the name or value of a reading
+	perReading bool
+}
+
+// newExpressionFilter compiles the filter expression
+func newExpressionFilter(expression string) (filterer, error) {
+	tokens, err := tokenizeExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := exprParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", p.tokens[p.pos].text, p.tokens[p.pos].pos)
+	}
+	return expressionFilter{expression: node, perReading: p.perReading}, nil
+}
+
+func (filter expressionFilter) Filter(event *contract.Event) (bool, *contract.Event) {
+	if event == nil {
+		return false, nil
+	}
+
+	c := &exprContext{event: event}
+	if !filter.perReading {
+		return filter.expression.eval(c), event
+	}
+
+	auxEvent := *event
+	auxEvent.Readings = []contract.Reading{}
+	for i := range event.Readings {
+		c.reading = &event.Readings[i]
+		if filter.expression.eval(c) {
+			auxEvent.Readings = append(auxEvent.Readings, event.Readings[i])
+		}
+	}
+	return len(auxEvent.Readings) > 0, &auxEvent
+}
+
+// exprContext is what an expression is evaluated against, the labels are looked up once when first needed
+type exprContext struct {
+	event        *contract.Event
+	reading      *contract.Reading
+	labels       []string
+	labelsLoaded bool
+}
+
+func (c *exprContext) deviceLabels() []string {
+	if !c.labelsLoaded {
+		c.labels = deviceLabels(c.event.Device)
+		c.labelsLoaded = true
+	}
+	return c.labels
+}
+
+type exprNode interface {
+	eval(c *exprContext) bool
+}
+
+type exprAnd struct {
+	left, right exprNode
+}
+
+func (n exprAnd) eval(c *exprContext) bool {
+	return n.left.eval(c) && n.right.eval(c)
+}
+
+type exprOr struct {
+	left, right exprNode
+}
+
+func (n exprOr) eval(c *exprContext) bool {
+	return n.left.eval(c) || n.right.eval(c)
+}
+
+type exprNot struct {
+	node exprNode
+}
+
+func (n exprNot) eval(c *exprContext) bool {
+	return !n.node.eval(c)
+}
+
+type exprComparison struct {
+	op          string
+	left, right exprOperand
+	// re is the compiled regular expression of =~ and !~
+	re *regexp.Regexp
+}
+
+func (n exprComparison) eval(c *exprContext) bool {
+	if n.op == "contains" {
+		if _, ok := n.left.(exprLabels); ok {
+			label, _ := n.right.value(c)
+			for _, l := range c.deviceLabels() {
+				if l == label {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	left, ok := n.left.value(c)
+	if !ok {
+		return false
+	}
+	if n.re != nil {
+		return n.re.MatchString(left) == (n.op == "=~")
+	}
+	right, ok := n.right.value(c)
+	if !ok {
+		return false
+	}
+
+	if n.op == "contains" {
+		return strings.Contains(left, right)
+	}
+
+	l, lErr := strconv.ParseFloat(left, 64)
+	r, rErr := strconv.ParseFloat(right, 64)
+	if lErr != nil || rErr != nil {
+		switch n.op {
+		case "==":
+			return left == right
+		case "!=":
+			return left != right
+		}
+		// ordering is only defined for numbers
+		return false
+	}
+
+	switch n.op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	}
+	return false
+}
+
+// exprOperand returns its value and whether it is defined for the event
+type exprOperand interface {
+	value(c *exprContext) (string, bool)
+}
+
+type exprLiteral string
+
+func (o exprLiteral) value(c *exprContext) (string, bool) {
+	return string(o), true
+}
+
+type exprEventField string
+
+func (o exprEventField) value(c *exprContext) (string, bool) {
+	switch string(o) {
+	case exprFieldDevice:
+		return c.event.Device, true
+	case exprFieldOrigin:
+		return strconv.FormatInt(c.event.Origin, 10), true
+	case exprFieldCreated:
+		return strconv.FormatInt(c.event.Created, 10), true
+	}
+	return "", false
+}
+
+// exprReading is the value of the reading of the event with the given name
+type exprReading string
+
+func (o exprReading) value(c *exprContext) (string, bool) {
+	for _, reading := range c.event.Readings {
+		if reading.Name == string(o) {
+			return reading.Value, true
+		}
+	}
+	return "", false
+}
+
+// exprCurrentReading is the name or value of the reading being filtered
+type exprCurrentReading string
+
+func (o exprCurrentReading) value(c *exprContext) (string, bool) {
+	if c.reading == nil {
+		return "", false
+	}
+	if o == exprFieldName {
+		return c.reading.Name, true
+	}
+	return c.reading.Value, true
+}
+
+// exprLabels stands for the labels of the device, it can only be the left operand of contains
+type exprLabels struct{}
+
+func (o exprLabels) value(c *exprContext) (string, bool) {
+	return "", false
+}
+
+const (
+	tokenIdent = iota
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type exprToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")"}
+
+func tokenizeExpression(expression string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(expression); {
+		ch := rune(expression[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+
+		case ch == '"':
+			end := i + 1
+			for ; end < len(expression) && expression[end] != '"'; end++ {
+				if expression[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(expression) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			s, err := strconv.Unquote(expression[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %s", i, err.Error())
+			}
+			tokens = append(tokens, exprToken{kind: tokenString, text: s, pos: i})
+			i = end + 1
+
+		case unicode.IsDigit(ch) || ((ch == '-' || ch == '.') && i+1 < len(expression) &&
+			(unicode.IsDigit(rune(expression[i+1])) || expression[i+1] == '.')):
+			end := i + 1
+			for end < len(expression) && strings.ContainsRune("0123456789.eE+-", rune(expression[end])) {
+				end++
+			}
+			if _, err := strconv.ParseFloat(expression[i:end], 64); err != nil {
+				return nil, fmt.Errorf("invalid number '%s' at position %d", expression[i:end], i)
+			}
+			tokens = append(tokens, exprToken{kind: tokenNumber, text: expression[i:end], pos: i})
+			i = end
+
+		case unicode.IsLetter(ch) || ch == '_':
+			end := i + 1
+			for end < len(expression) && isIdentRune(rune(expression[end])) {
+				end++
+			}
+			tokens = append(tokens, exprToken{kind: tokenIdent, text: expression[i:end], pos: i})
+			i = end
+
+		default:
+			matched := false
+			for _, op := range exprOperators {
+				if strings.HasPrefix(expression[i:], op) {
+					tokens = append(tokens, exprToken{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected '%c' at position %d", ch, i)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// isIdentRune tells whether the rune may be part of an identifier, reading names may contain dots and dashes
+func isIdentRune(ch rune) bool {
+	return unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_' || ch == '.' || ch == '-'
+}
+
+// exprParser builds the tree of an expression by recursive descent, && binding tighter than ||
+type exprParser struct {
+	tokens     []exprToken
+	pos        int
+	perReading bool
+}
+
+func (p *exprParser) peek() (exprToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return exprToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// accept consumes the next token when it is the given operator or keyword
+func (p *exprParser) accept(text string) bool {
+	t, ok := p.peek()
+	if ok && (t.kind == tokenOperator || t.kind == tokenIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = exprOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = exprAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.accept("!") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return exprNot{node: node}, nil
+	}
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.unexpected("')'")
+		}
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := p.peek()
+	if !ok || !isComparison(t.text) {
+		return nil, p.unexpected("comparison")
+	}
+	p.pos++
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	n := exprComparison{op: t.text, left: left, right: right}
+	_, leftLabels := left.(exprLabels)
+	_, rightLabels := right.(exprLabels)
+	if rightLabels || (leftLabels && n.op != "contains") {
+		return nil, fmt.Errorf("labels can only be used as 'labels contains \"label\"' at position %d", t.pos)
+	}
+	if n.op == "=~" || n.op == "!~" {
+		pattern, ok := right.(exprLiteral)
+		if !ok {
+			return nil, fmt.Errorf("regular expression expected after '%s' at position %d", n.op, t.pos)
+		}
+		if n.re, err = regexp.Compile("^(?:" + string(pattern) + ")$"); err != nil {
+			return nil, fmt.Errorf("invalid regular expression at position %d: %s", t.pos, err.Error())
+		}
+	}
+	return n, nil
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=", "=~", "!~", "contains":
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOperand() (exprOperand, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, p.unexpected("operand")
+	}
+
+	switch t.kind {
+	case tokenString, tokenNumber:
+		p.pos++
+		return exprLiteral(t.text), nil
+	case tokenIdent:
+		p.pos++
+		switch {
+		case t.text == exprFieldDevice || t.text == exprFieldOrigin || t.text == exprFieldCreated:
+			return exprEventField(t.text), nil
+		case t.text == exprFieldName || t.text == exprFieldValue:
+			p.perReading = true
+			return exprCurrentReading(t.text), nil
+		case t.text == exprFieldLabels:
+			return exprLabels{}, nil
+		case t.text == "true" || t.text == "false":
+			return exprLiteral(t.text), nil
+		case strings.HasPrefix(t.text, exprReadingField) && len(t.text) > len(exprReadingField):
+			return exprReading(strings.TrimPrefix(t.text, exprReadingField)), nil
+		}
+		return nil, fmt.Errorf("unknown field '%s' at position %d", t.text, t.pos)
+	}
+	return nil, p.unexpected("operand")
+}
+
+func (p *exprParser) unexpected(expected string) error {
+	t, ok := p.peek()
+	if !ok {
+		return fmt.Errorf("%s expected at end of expression", expected)
+	}
+	return fmt.Errorf("%s expected, got '%s' at position %d", expected, t.text, t.pos)
+}
+
+// deviceLabels returns the labels of the device, it is replaced in tests
+var deviceLabels = metadataLabels
+
+var labelCache = newLookupCache()
+
+// metadataLabels looks up the labels of the device in core-metadata, the device has no label when the lookup fails
+func metadataLabels(device string) []string {
+	labels, err := labelCache.get(device, func() (interface{}, error) {
+		d, err := mdc.CheckForDevice(device, context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return d.Labels, nil
+	})
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("failed to look up the labels of device %s: %s", device, err.Error()))
+		return nil
+	}
+	return labels.([]string)
+}
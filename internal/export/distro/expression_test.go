@@ -0,0 +1,126 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"testing"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func expressionEvent() *contract.Event {
+	return &contract.Event{
+		Device: "boiler-1",
+		Origin: 1000,
+		Readings: []contract.Reading{
+			{Name: "temp", Value: "85.5"},
+			{Name: "state", Value: "on"},
+			{Name: "pressure", Value: "2"},
+		},
+	}
+}
+
+func TestExpressionFilter(t *testing.T) {
+	deviceLabels = func(device string) []string {
+		return []string{"building-a", "heating"}
+	}
+	defer func() { deviceLabels = metadataLabels }()
+
+	tests := []struct {
+		name       string
+		expression string
+		accepted   bool
+	}{
+		{"Regex", `device =~ "boiler-.*"`, true},
+		{"Regex mismatch", `device !~ "boiler-.*"`, false},
+		{"Regex anchored", `device =~ "boiler"`, false},
+		{"Regex alternatives anchored", `device =~ "oil|boiler-1"`, true},
+		{"Numeric", `reading.temp > 80`, true},
+		{"Numeric not matched", `reading.temp <= 80`, false},
+		{"Numeric equality", `reading.pressure == 2.0`, true},
+		{"String equality", `reading.state == "on"`, true},
+		{"Missing reading", `reading.humidity < 100`, false},
+		{"Event field", `origin >= 1000`, true},
+		{"And", `device =~ "boiler-.*" && reading.temp > 80`, true},
+		{"And not matched", `device =~ "boiler-.*" && reading.temp > 90`, false},
+		{"Or", `device == "other" || reading.state != "off"`, true},
+		{"Precedence", `device == "other" && reading.temp > 80 || reading.state == "on"`, true},
+		{"Not", `!(reading.temp > 80)`, false},
+		{"Labels", `labels contains "heating"`, true},
+		{"Missing label", `labels contains "cooling"`, false},
+		{"Substring", `device contains "boil"`, true},
+		{"String ordering", `reading.state > "a"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newExpressionFilter(tt.expression)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			event := expressionEvent()
+			accepted, res := f.Filter(event)
+			if accepted != tt.accepted {
+				t.Fatalf("Expected accepted %v", tt.accepted)
+			}
+			if res != event {
+				t.Fatal("Event should be the same")
+			}
+		})
+	}
+}
+
+func TestExpressionFilterReadings(t *testing.T) {
+	f, err := newExpressionFilter(`device == "boiler-1" && (name == "temp" || value == "on")`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	accepted, res := f.Filter(expressionEvent())
+	if !accepted {
+		t.Fatal("Event should be accepted")
+	}
+	if len(res.Readings) != 2 || res.Readings[0].Name != "temp" || res.Readings[1].Name != "state" {
+		t.Fatalf("Expected the temp and state readings, got %v", res.Readings)
+	}
+	if res.Device != "boiler-1" {
+		t.Fatal("Expected the fields of the event to be kept")
+	}
+
+	f, _ = newExpressionFilter(`value > 100`)
+	if accepted, _ = f.Filter(expressionEvent()); accepted {
+		t.Fatal("Event without accepted readings should be filtered out")
+	}
+	if accepted, _ = f.Filter(nil); accepted {
+		t.Fatal("Event should be filtered out")
+	}
+}
+
+func TestExpressionFilterInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{"Empty", ``},
+		{"Unknown field", `unit == "C"`},
+		{"Missing operand", `reading.temp >`},
+		{"Missing comparison", `reading.temp`},
+		{"Unbalanced parentheses", `(device == "a"`},
+		{"Trailing token", `device == "a" "b"`},
+		{"Unterminated string", `device == "a`},
+		{"Invalid regex", `device =~ "("`},
+		{"Regex field", `device =~ reading.pattern`},
+		{"Labels comparison", `labels == "a"`},
+		{"Unexpected character", `device == "a" & origin > 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newExpressionFilter(tt.expression); err == nil {
+				t.Fatalf("Expected an error for %s", tt.expression)
+			}
+		})
+	}
+}
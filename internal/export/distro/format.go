@@ -9,17 +9,29 @@
 package distro
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
+	"github.com/edgexfoundry/edgex-go/internal/export"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/google/uuid"
 )
 
+// Content types of the formats which can be batched, besides JSON
+const (
+	xmlContentType = "application/xml"
+	csvContentType = "text/csv"
+)
+
 type jsonFormatter struct {
 }
 
@@ -43,6 +55,21 @@ func (jsonTr jsonFormatter) Format(event *contract.Event) []byte {
 	return b
 }
 
+// FormatBatch formats the events as a JSON array
+func (jsonTr jsonFormatter) FormatBatch(events []*contract.Event) []byte {
+	b, err := json.Marshal(events)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Error parsing JSON. Error: %s", err.Error()))
+		return nil
+	}
+	return b
+}
+
+// ContentType is the content type of the events formatted as JSON
+func (jsonTr jsonFormatter) ContentType() string {
+	return clients.ContentTypeJSON
+}
+
 type xmlFormatter struct {
 }
 
@@ -55,6 +82,27 @@ func (xmlTr xmlFormatter) Format(event *contract.Event) []byte {
 	return b
 }
 
+// xmlEvents is the root element of a batch of events formatted as XML
+type xmlEvents struct {
+	XMLName xml.Name          `xml:"Events"`
+	Events  []*contract.Event `xml:"Event"`
+}
+
+// FormatBatch formats the events as the Event elements of an Events element
+func (xmlTr xmlFormatter) FormatBatch(events []*contract.Event) []byte {
+	b, err := xml.Marshal(xmlEvents{Events: events})
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Error parsing XML. Error: %s", err.Error()))
+		return nil
+	}
+	return b
+}
+
+// ContentType is the content type of the events formatted as XML
+func (xmlTr xmlFormatter) ContentType() string {
+	return xmlContentType
+}
+
 type thingsboardJSONFormatter struct {
 }
 
@@ -275,3 +323,126 @@ func (af biotFormatter) Format(event *contract.Event) []byte {
 	}
 	return msg
 }
+
+// defaultCSVColumns are the columns of the CSV format when the registration configures none
+var defaultCSVColumns = []string{
+	export.CSVColumnDevice,
+	export.CSVColumnName,
+	export.CSVColumnValue,
+	export.CSVColumnOrigin,
+	export.CSVColumnCreated,
+}
+
+// csvFormatter converts an event to CSV, one row per reading
+type csvFormatter struct {
+	columns []string
+	header  bool
+}
+
+// newCSVFormatter returns a CSV formatter writing the columns in order, preceded by a header row naming them when
+// header is true
+func newCSVFormatter(columns []string, header bool) (csvFormatter, error) {
+	if len(columns) == 0 {
+		columns = defaultCSVColumns
+	}
+	for _, c := range columns {
+		switch c {
+		case export.CSVColumnDevice, export.CSVColumnName, export.CSVColumnValue, export.CSVColumnOrigin,
+			export.CSVColumnCreated:
+		default:
+			return csvFormatter{}, fmt.Errorf("unknown CSV column '%s'", c)
+		}
+	}
+	return csvFormatter{columns: columns, header: header}, nil
+}
+
+// Format writes a row for each reading of the event. The device, origin and created columns of a reading default
+// to those of the event.
+func (cf csvFormatter) Format(event *contract.Event) []byte {
+	return cf.FormatBatch([]*contract.Event{event})
+}
+
+// FormatBatch writes the rows of the readings of all events after a single header row
+func (cf csvFormatter) FormatBatch(events []*contract.Event) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if cf.header {
+		if err := w.Write(cf.columns); err != nil {
+			LoggingClient.Error(fmt.Sprintf("Error writing CSV header: %s", err))
+			return nil
+		}
+	}
+
+	row := make([]string, len(cf.columns))
+	for _, event := range events {
+		for _, reading := range event.Readings {
+			for i, c := range cf.columns {
+				row[i] = csvValue(c, event, reading)
+			}
+			if err := w.Write(row); err != nil {
+				LoggingClient.Error(fmt.Sprintf("Error writing CSV row: %s", err))
+				return nil
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		LoggingClient.Error(fmt.Sprintf("Error writing CSV: %s", err))
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// ContentType is the content type of the events formatted as CSV
+func (cf csvFormatter) ContentType() string {
+	return csvContentType
+}
+
+func csvValue(column string, event *contract.Event, reading contract.Reading) string {
+	switch column {
+	case export.CSVColumnDevice:
+		if reading.Device != "" {
+			return reading.Device
+		}
+		return event.Device
+	case export.CSVColumnName:
+		return reading.Name
+	case export.CSVColumnValue:
+		return reading.Value
+	case export.CSVColumnOrigin:
+		if reading.Origin != 0 {
+			return strconv.FormatInt(reading.Origin, 10)
+		}
+		return strconv.FormatInt(event.Origin, 10)
+	case export.CSVColumnCreated:
+		if reading.Created != 0 {
+			return strconv.FormatInt(reading.Created, 10)
+		}
+		return strconv.FormatInt(event.Created, 10)
+	}
+	return ""
+}
+
+// templateFormatter renders the events through the text/template stored with the registration
+type templateFormatter struct {
+	template *template.Template
+}
+
+func newTemplateFormatter(text string) (formatter, error) {
+	t, err := export.NewEventTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+	return templateFormatter{template: t}, nil
+}
+
+func (tf templateFormatter) Format(event *contract.Event) []byte {
+	var buf bytes.Buffer
+	if err := tf.template.Execute(&buf, event); err != nil {
+		LoggingClient.Error(fmt.Sprintf("Error executing template. Error: %s", err.Error()))
+		return nil
+	}
+	return buf.Bytes()
+}
@@ -14,6 +14,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
@@ -149,3 +150,106 @@ func TestBIoT(t *testing.T) {
 		t.Fatalf("Error unmarshal the formatted string: %v %v", err, out)
 	}
 }
+
+func TestCSV(t *testing.T) {
+	eventIn := contract.Event{Device: devID1, Origin: 10, Created: 20}
+	eventIn.Readings = append(eventIn.Readings,
+		contract.Reading{Name: readingName1, Value: readingValue1},
+		contract.Reading{Device: "id2", Name: "sensor2", Value: "a,b", Origin: 11, Created: 21})
+
+	tests := []struct {
+		name     string
+		columns  []string
+		header   bool
+		expected string
+	}{
+		{"Default columns", nil, false, "id1,sensor1,123.45,10,20\nid2,sensor2,\"a,b\",11,21\n"},
+		{"Header", []string{export.CSVColumnName, export.CSVColumnValue}, true, "name,value\nsensor1,123.45\nsensor2,\"a,b\"\n"},
+		{"Columns", []string{export.CSVColumnCreated, export.CSVColumnDevice}, false, "20,id1\n21,id2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf, err := newCSVFormatter(tt.columns, tt.header)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			out := cf.Format(&eventIn)
+			if string(out) != tt.expected {
+				t.Fatalf("Expected %q, got %q", tt.expected, string(out))
+			}
+		})
+	}
+}
+
+func TestCSVInvalidColumn(t *testing.T) {
+	if _, err := newCSVFormatter([]string{export.CSVColumnName, "unit"}, false); err == nil {
+		t.Fatal("Expected an error for an unknown column")
+	}
+}
+
+func TestFormatBatch(t *testing.T) {
+	events := []*contract.Event{
+		{ID: "1", Device: devID1, Readings: []contract.Reading{{Name: readingName1, Value: readingValue1}}},
+		{ID: "2", Device: "id2", Readings: []contract.Reading{{Name: "sensor2", Value: "on"}}},
+	}
+
+	var jsonOut []contract.Event
+	if err := json.Unmarshal(jsonFormatter{}.FormatBatch(events), &jsonOut); err != nil {
+		t.Fatalf("Error parsing JSON batch: %v", err)
+	}
+	if len(jsonOut) != 2 || jsonOut[1].ID != "2" {
+		t.Fatalf("Expected the 2 events as a JSON array, got %v", jsonOut)
+	}
+
+	var xmlOut xmlEvents
+	if err := xml.Unmarshal(xmlFormatter{}.FormatBatch(events), &xmlOut); err != nil {
+		t.Fatalf("Error parsing XML batch: %v", err)
+	}
+	if len(xmlOut.Events) != 2 || xmlOut.Events[0].Device != devID1 {
+		t.Fatalf("Expected the 2 events as XML, got %v", xmlOut.Events)
+	}
+
+	cf, _ := newCSVFormatter([]string{export.CSVColumnDevice, export.CSVColumnValue}, true)
+	expected := "device,value\nid1,123.45\nid2,on\n"
+	if out := string(cf.FormatBatch(events)); out != expected {
+		t.Fatalf("Expected %q, got %q", expected, out)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	event := &contract.Event{Device: "dev \"1\"", Origin: 1546300800000, Readings: []contract.Reading{
+		{Name: readingName1, Value: readingValue1},
+	}}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"Fields", `{{.Device}}/{{.Origin}}`, `dev "1"/1546300800000`},
+		{"JSON", `{"device":{{json .Device}}}`, `{"device":"dev \"1\""}`},
+		{"Escape", `"{{escape .Device}}"`, `"dev \"1\""`},
+		{"Timestamp", `{{timestamp .Origin}}`, `2019-01-01T00:00:00Z`},
+		{"Timestamp layout", `{{timestamp .Origin "2006-01-02"}}`, `2019-01-01`},
+		{"Reading", `{{(reading . "sensor1").Value}}`, readingValue1},
+		{"Missing reading", `{{if hasReading . "temp"}}{{(reading . "temp").Value}}{{else}}none{{end}}`, "none"},
+		{"Readings", `{{range .Readings}}{{.Name}}={{.Value}}{{end}}`, readingName1 + "=" + readingValue1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tf, err := newTemplateFormatter(tt.text)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out := string(tf.Format(event)); out != tt.expected {
+				t.Fatalf("Expected %q, got %q", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestTemplateInvalid(t *testing.T) {
+	if _, err := newTemplateFormatter(`{{unknown .Device}}`); err == nil {
+		t.Fatal("Expected an error for an unknown function")
+	}
+}
@@ -51,6 +51,9 @@ func (sender httpSender) Send(data []byte, ctx context.Context) bool {
 			return false
 		}
 		req.Header.Set(clients.ContentType, ctx.Value(clients.ContentType).(string))
+		if fingerprint, ok := ctx.Value(schemaFingerprintKey).(string); ok {
+			req.Header.Set(SchemaFingerprintHeader, fingerprint)
+		}
 
 		c := clients.NewCorrelatedRequest(req, ctx)
 		client := &http.Client{}
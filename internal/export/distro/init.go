@@ -12,6 +12,7 @@ import (
 	"sync"
 
 	"github.com/edgexfoundry/edgex-go/internal/pkg/bootstrap/container"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/bootstrap/interfaces"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/bootstrap/startup"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/di"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/endpoint"
@@ -19,6 +20,7 @@ import (
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/coredata"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/metadata"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients/types"
 
 	"github.com/edgexfoundry/go-mod-messaging/messaging"
@@ -30,6 +32,9 @@ import (
 // Global variables
 var LoggingClient logger.LoggingClient
 var ec coredata.EventClient
+var mdc metadata.DeviceClient
+var vdc coredata.ValueDescriptorClient
+var secretProvider interfaces.SecretProvider
 var Configuration = &ConfigurationStruct{}
 var messageErrors chan error
 var messageEnvelopes chan msgTypes.MessageEnvelope
@@ -45,6 +50,24 @@ func initializeClients(useRegistry bool, registryClient registry.Client) (messag
 			Interval:    Configuration.Service.ClientMonitor,
 		},
 		endpoint.Endpoint{RegistryClient: &registryClient})
+	vdc = coredata.NewValueDescriptorClient(
+		types.EndpointParams{
+			ServiceKey:  clients.CoreDataServiceKey,
+			Path:        clients.ApiValueDescriptorRoute,
+			UseRegistry: useRegistry,
+			Url:         Configuration.Clients["CoreData"].Url() + clients.ApiValueDescriptorRoute,
+			Interval:    Configuration.Service.ClientMonitor,
+		},
+		endpoint.Endpoint{RegistryClient: &registryClient})
+	mdc = metadata.NewDeviceClient(
+		types.EndpointParams{
+			ServiceKey:  clients.CoreMetaDataServiceKey,
+			Path:        clients.ApiDeviceRoute,
+			UseRegistry: useRegistry,
+			Url:         Configuration.Clients["Metadata"].Url() + clients.ApiDeviceRoute,
+			Interval:    Configuration.Service.ClientMonitor,
+		},
+		endpoint.Endpoint{RegistryClient: &registryClient})
 
 	// Create the messaging client
 	return messaging.NewMessageClient(
@@ -62,6 +85,7 @@ func initializeClients(useRegistry bool, registryClient registry.Client) (messag
 func BootstrapHandler(wg *sync.WaitGroup, ctx context.Context, startupTimer startup.Timer, dic *di.Container) bool {
 	// update global variables.
 	LoggingClient = container.LoggingClientFrom(dic.Get)
+	secretProvider = container.SecretProviderFrom(dic.Get)
 
 	// initialize clients required by service.
 	registryClient := container.RegistryFrom(dic.Get)
@@ -78,6 +102,24 @@ func BootstrapHandler(wg *sync.WaitGroup, ctx context.Context, startupTimer star
 		return false
 	}
 
+	if Configuration.StoreAndForward.Enabled {
+		queueStore, err = openQueueStore(Configuration.StoreAndForward.Path)
+		if err != nil {
+			LoggingClient.Error("failed to open retry queues: " + err.Error())
+			return false
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			<-ctx.Done()
+			if err := queueStore.Close(); err != nil {
+				LoggingClient.Error("failed to close retry queues: " + err.Error())
+			}
+		}()
+	}
+
 	Loop(wg, ctx)
 
 	return true
@@ -8,11 +8,11 @@
 package distro
 
 import (
-	"crypto/tls"
 	"fmt"
 	"strings"
 
 	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
@@ -29,31 +29,15 @@ type iotCoreSender struct {
 }
 
 // newIoTCoreSender returns new Google IoT Core sender instance.
-func newIoTCoreSender(addr models.Addressable) sender {
-	protocol := strings.ToLower(addr.Protocol)
+func newIoTCoreSender(addr models.Addressable, info export.MqttInfo) sender {
 	broker := fmt.Sprintf("%s%s", addr.GetBaseURL(), addr.Path)
 	deviceID := extractDeviceID(addr.Publisher)
 
-	opts := MQTT.NewClientOptions()
-	opts.AddBroker(broker)
-	opts.SetClientID(addr.Publisher)
-	opts.SetUsername(addr.User)
-	opts.SetPassword(addr.Password)
-	opts.SetAutoReconnect(false)
-
-	if validateProtocol(protocol) {
-		c := Configuration.Certificates["MQTTS"]
-		cert, err := tls.LoadX509KeyPair(c.Cert, c.Key)
-		if err != nil {
-			LoggingClient.Error("Failed loading x509 data")
-			return nil
-		}
-
-		opts.SetTLSConfig(&tls.Config{
-			ClientCAs:          nil,
-			InsecureSkipVerify: true,
-			Certificates:       []tls.Certificate{cert},
-		})
+	c := Configuration.Certificates["MQTTS"]
+	opts, err := newMqttClientOptions(addr, broker, c.Cert, c.Key, info)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Invalid mqtt configuration: %s", err.Error()))
+		return nil
 	}
 
 	if addr.Topic == "" {
@@ -61,8 +45,10 @@ func newIoTCoreSender(addr models.Addressable) sender {
 	}
 
 	return &mqttSender{
-		client: MQTT.NewClient(opts),
-		topic:  addr.Topic,
+		client:   MQTT.NewClient(opts),
+		topic:    addr.Topic,
+		qos:      info.Qos,
+		retained: info.Retained,
 	}
 }
 
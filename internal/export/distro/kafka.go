@@ -0,0 +1,180 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// partitionKeyContext is the type of the context keys holding the names a Kafka message may be partitioned by
+type partitionKeyContext string
+
+const (
+	deviceNameKey  partitionKeyContext = export.KafkaPartitionKeyDevice
+	readingNameKey partitionKeyContext = export.KafkaPartitionKeyReading
+)
+
+type kafkaSender struct {
+	brokers      []string
+	config       *sarama.Config
+	topic        string
+	partitionKey string
+	producer     sarama.SyncProducer
+}
+
+// newKafkaSender - create new kafka sender. The brokers default to the address and port of the addressable, the SASL
+// credentials to its user and password. The certificate of the brokers is verified over TLS unless the options skip
+// the verification.
+func newKafkaSender(addr contract.Addressable, info export.KafkaInfo, cert string, key string) sender {
+	config, err := newKafkaConfig(addr, info, cert, key)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Invalid kafka configuration: %s", err.Error()))
+		return nil
+	}
+
+	brokers := info.Brokers
+	if len(brokers) == 0 {
+		brokers = []string{addr.Address + ":" + strconv.Itoa(addr.Port)}
+	}
+
+	switch info.PartitionKey {
+	case "", export.KafkaPartitionKeyDevice, export.KafkaPartitionKeyReading:
+	default:
+		LoggingClient.Error(fmt.Sprintf("Invalid kafka partition key: %s", info.PartitionKey))
+		return nil
+	}
+
+	return &kafkaSender{
+		brokers:      brokers,
+		config:       config,
+		topic:        addr.Topic,
+		partitionKey: info.PartitionKey,
+	}
+}
+
+func newKafkaConfig(addr contract.Addressable, info export.KafkaInfo, cert string, key string) (*sarama.Config, error) {
+	config := sarama.NewConfig()
+	config.ClientID = addr.Publisher
+	if config.ClientID == "" {
+		config.ClientID = "edgex-export-distro"
+	}
+	// required by the sync producer
+	config.Producer.Return.Successes = true
+	// record headers, holding the schema fingerprint, require Kafka 0.11
+	config.Version = sarama.V0_11_0_0
+
+	switch info.Acks {
+	case "", export.KafkaAcksAll:
+		config.Producer.RequiredAcks = sarama.WaitForAll
+	case export.KafkaAcksLeader:
+		config.Producer.RequiredAcks = sarama.WaitForLocal
+	case export.KafkaAcksNone:
+		config.Producer.RequiredAcks = sarama.NoResponse
+	default:
+		return nil, fmt.Errorf("invalid acks %s", info.Acks)
+	}
+
+	protocol := strings.ToLower(addr.Protocol)
+	if protocol == tcpsPrefix || protocol == sslPrefix || protocol == tlsPrefix {
+		tlsConfig, err := newTLSConfig(cert, key, info.TLSInfo)
+		if err != nil {
+			return nil, err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	user, password := addr.User, addr.Password
+	if info.SecretPath != "" {
+		if secretProvider == nil {
+			return nil, fmt.Errorf("no secret store to read %s from", info.SecretPath)
+		}
+		secrets, err := secretProvider.GetSecrets(info.SecretPath, "username", "password")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credentials %s: %s", info.SecretPath, err.Error())
+		}
+		user, password = secrets["username"], secrets["password"]
+	}
+	if user != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		config.Net.SASL.User = user
+		config.Net.SASL.Password = password
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// message returns the Kafka message of the data, keyed by the device or reading name held by the context. The
+// fingerprint of the schema of the data, when it has one, is sent in the SchemaFingerprintHeader record header.
+func (sender *kafkaSender) message(data []byte, ctx context.Context) *sarama.ProducerMessage {
+	msg := &sarama.ProducerMessage{
+		Topic: sender.topic,
+		Value: sarama.ByteEncoder(data),
+	}
+	if fingerprint, ok := ctx.Value(schemaFingerprintKey).(string); ok && fingerprint != "" {
+		msg.Headers = []sarama.RecordHeader{{Key: []byte(SchemaFingerprintHeader), Value: []byte(fingerprint)}}
+	}
+	if sender.partitionKey != "" {
+		if key, ok := ctx.Value(partitionKeyContext(sender.partitionKey)).(string); ok && key != "" {
+			msg.Key = sarama.StringEncoder(key)
+		}
+	}
+	return msg
+}
+
+func (sender *kafkaSender) Send(data []byte, ctx context.Context) bool {
+	if sender.producer == nil {
+		LoggingClient.Info("Connecting to kafka brokers")
+		producer, err := sarama.NewSyncProducer(sender.brokers, sender.config)
+		if err != nil {
+			LoggingClient.Error(fmt.Sprintf("Could not connect to kafka brokers, drop event. Error: %s", err.Error()))
+			return false
+		}
+		sender.producer = producer
+	}
+
+	partition, offset, err := sender.producer.SendMessage(sender.message(data, ctx))
+	if err != nil {
+		LoggingClient.Error(err.Error())
+		return false
+	}
+	LoggingClient.Debug(fmt.Sprintf("Sent data to partition %d at offset %d: %X", partition, offset, data))
+	return true
+}
+
+// Close closes the producer, the next message connecting to the brokers again
+func (sender *kafkaSender) Close() {
+	if sender.producer == nil {
+		return
+	}
+	if err := sender.producer.Close(); err != nil {
+		LoggingClient.Warn(fmt.Sprintf("Error closing kafka producer: %s", err.Error()))
+	}
+	sender.producer = nil
+}
+
+// withPartitionKeys adds the names of the device and first reading of the message to the context
+func withPartitionKeys(ctx context.Context, m queuedMessage) context.Context {
+	if m.Device != "" {
+		ctx = context.WithValue(ctx, deviceNameKey, m.Device)
+	}
+	if m.Reading != "" {
+		ctx = context.WithValue(ctx, readingNameKey, m.Reading)
+	}
+	return ctx
+}
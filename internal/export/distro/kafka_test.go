@@ -0,0 +1,241 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+const kafkaTopic = "events"
+
+// kafkaSecretProvider returns the same credentials, or error, whatever the path
+type kafkaSecretProvider struct {
+	secrets map[string]string
+	err     error
+}
+
+func (p kafkaSecretProvider) GetSecrets(path string, keys ...string) (map[string]string, error) {
+	return p.secrets, p.err
+}
+
+// newKafkaBroker starts an in-process fake broker leading the partition of the topic
+func newKafkaBroker(t *testing.T, produceResponse *sarama.MockProduceResponse) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader(kafkaTopic, 0, broker.BrokerID()),
+		// the producer sends version 3 produce requests, carrying record headers
+		"ProduceRequest": produceResponse.SetVersion(3),
+	})
+	return broker
+}
+
+func produceRequests(broker *sarama.MockBroker) []*sarama.ProduceRequest {
+	var requests []*sarama.ProduceRequest
+	for _, r := range broker.History() {
+		if request, ok := r.Request.(*sarama.ProduceRequest); ok {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+func TestKafkaSend(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	broker := newKafkaBroker(t, sarama.NewMockProduceResponse(t))
+	defer broker.Close()
+
+	addr := contract.Addressable{Topic: kafkaTopic}
+	s := newKafkaSender(addr, export.KafkaInfo{Brokers: []string{broker.Addr()}, Acks: export.KafkaAcksLeader}, "", "")
+	if s == nil {
+		t.Fatal("Expected a kafka sender")
+	}
+	if !s.Send([]byte("data"), context.Background()) {
+		t.Fatal("Expected the data to be sent")
+	}
+
+	requests := produceRequests(broker)
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 produce request, got %d", len(requests))
+	}
+	if requests[0].RequiredAcks != sarama.WaitForLocal {
+		t.Fatalf("Expected the leader acks to be required, got %d", requests[0].RequiredAcks)
+	}
+}
+
+func TestKafkaClose(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	broker := newKafkaBroker(t, sarama.NewMockProduceResponse(t))
+	defer broker.Close()
+
+	s := newKafkaSender(contract.Addressable{Topic: kafkaTopic}, export.KafkaInfo{Brokers: []string{broker.Addr()}}, "", "")
+	// closing a sender which never connected does nothing
+	s.(closer).Close()
+
+	if !s.Send([]byte("data"), context.Background()) {
+		t.Fatal("Expected the data to be sent")
+	}
+	s.(closer).Close()
+	if s.(*kafkaSender).producer != nil {
+		t.Fatal("Expected the producer to be closed")
+	}
+
+	// the sender connects again once closed
+	if !s.Send([]byte("data"), context.Background()) {
+		t.Fatal("Expected the data to be sent once reconnected")
+	}
+	s.(closer).Close()
+}
+
+func TestKafkaSendError(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	broker := newKafkaBroker(t, sarama.NewMockProduceResponse(t).SetError(kafkaTopic, 0, sarama.ErrMessageSizeTooLarge))
+	defer broker.Close()
+
+	s := newKafkaSender(contract.Addressable{Topic: kafkaTopic}, export.KafkaInfo{Brokers: []string{broker.Addr()}}, "", "")
+	if s.Send([]byte("data"), context.Background()) {
+		t.Fatal("Expected the data to fail to be sent")
+	}
+}
+
+func TestKafkaSendNoBroker(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	broker := sarama.NewMockBroker(t, 1)
+	addr := broker.Addr()
+	broker.Close()
+
+	info := export.KafkaInfo{Brokers: []string{addr}}
+	s := newKafkaSender(contract.Addressable{Topic: kafkaTopic}, info, "", "")
+	s.(*kafkaSender).config.Metadata.Retry.Max = 0
+	if s.Send([]byte("data"), context.Background()) {
+		t.Fatal("Expected the data to fail to be sent without broker")
+	}
+}
+
+func TestKafkaMessageKey(t *testing.T) {
+	ctx := withPartitionKeys(context.Background(), queuedMessage{Device: devID1, Reading: "temp"})
+
+	tests := []struct {
+		name         string
+		partitionKey string
+		ctx          context.Context
+		expected     sarama.Encoder
+	}{
+		{"No key", "", ctx, nil},
+		{"Device key", export.KafkaPartitionKeyDevice, ctx, sarama.StringEncoder(devID1)},
+		{"Reading key", export.KafkaPartitionKeyReading, ctx, sarama.StringEncoder("temp")},
+		{"Missing key", export.KafkaPartitionKeyDevice, context.Background(), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := kafkaSender{topic: kafkaTopic, partitionKey: tt.partitionKey}
+			msg := s.message([]byte("data"), tt.ctx)
+			if msg.Topic != kafkaTopic {
+				t.Fatalf("Expected topic %s, got %s", kafkaTopic, msg.Topic)
+			}
+			if msg.Key != tt.expected {
+				t.Fatalf("Expected key %v, got %v", tt.expected, msg.Key)
+			}
+		})
+	}
+}
+
+func TestKafkaMessageHeaders(t *testing.T) {
+	s := kafkaSender{topic: kafkaTopic}
+	if msg := s.message([]byte("data"), context.Background()); len(msg.Headers) != 0 {
+		t.Fatalf("Expected no headers, got %v", msg.Headers)
+	}
+
+	ctx := withSchema(context.Background(), queuedMessage{SchemaFingerprint: protobufFingerprint})
+	msg := s.message([]byte("data"), ctx)
+	if len(msg.Headers) != 1 || string(msg.Headers[0].Key) != SchemaFingerprintHeader ||
+		string(msg.Headers[0].Value) != protobufFingerprint {
+		t.Fatalf("Expected the schema fingerprint header, got %v", msg.Headers)
+	}
+}
+
+func TestKafkaConfig(t *testing.T) {
+	defer func() { secretProvider = nil }()
+
+	tests := []struct {
+		name      string
+		addr      contract.Addressable
+		info      export.KafkaInfo
+		provider  *kafkaSecretProvider
+		valid     bool
+		acks      sarama.RequiredAcks
+		user      string
+		tls       bool
+		brokerLen int
+	}{
+		{"Default", contract.Addressable{}, export.KafkaInfo{}, nil, true, sarama.WaitForAll, "", false, 1},
+		{"No acks", contract.Addressable{}, export.KafkaInfo{Acks: export.KafkaAcksNone}, nil, true, sarama.NoResponse, "", false, 1},
+		{"Invalid acks", contract.Addressable{}, export.KafkaInfo{Acks: "some"}, nil, false, 0, "", false, 0},
+		{"TLS", contract.Addressable{Protocol: "TLS"}, export.KafkaInfo{}, nil, true, sarama.WaitForAll, "", true, 1},
+		{"TLS skip verification", contract.Addressable{Protocol: "TLS"},
+			export.KafkaInfo{TLSInfo: export.TLSInfo{SkipCertVerify: true}}, nil, true, sarama.WaitForAll, "", true, 1},
+		{"TLS missing CA bundle", contract.Addressable{Protocol: "TLS"},
+			export.KafkaInfo{TLSInfo: export.TLSInfo{CACert: "missing.pem"}}, nil, false, 0, "", false, 0},
+		{"Addressable credentials", contract.Addressable{User: "user", Password: "pwd"}, export.KafkaInfo{}, nil, true,
+			sarama.WaitForAll, "user", false, 1},
+		{"Secret credentials", contract.Addressable{User: "user", Password: "pwd"}, export.KafkaInfo{SecretPath: "kafka"},
+			&kafkaSecretProvider{secrets: map[string]string{"username": "secret", "password": "pwd"}}, true,
+			sarama.WaitForAll, "secret", false, 1},
+		{"Secret error", contract.Addressable{}, export.KafkaInfo{SecretPath: "kafka"},
+			&kafkaSecretProvider{err: errors.New("sealed")}, false, 0, "", false, 0},
+		{"No secret store", contract.Addressable{}, export.KafkaInfo{SecretPath: "kafka"}, nil, false, 0, "", false, 0},
+		{"Invalid partition key", contract.Addressable{}, export.KafkaInfo{PartitionKey: "origin"}, nil, false, 0, "", false,
+			0},
+		{"Brokers", contract.Addressable{}, export.KafkaInfo{Brokers: []string{"b1:9092", "b2:9092"}}, nil, true,
+			sarama.WaitForAll, "", false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			LoggingClient = logger.NewMockClient()
+			secretProvider = nil
+			if tt.provider != nil {
+				secretProvider = *tt.provider
+			}
+
+			s := newKafkaSender(tt.addr, tt.info, "", "")
+			if !tt.valid {
+				if s != nil {
+					t.Fatal("Expected the configuration to be rejected")
+				}
+				return
+			}
+			if s == nil {
+				t.Fatal("Expected a kafka sender")
+			}
+
+			sender := s.(*kafkaSender)
+			if sender.config.Producer.RequiredAcks != tt.acks {
+				t.Errorf("Expected acks %d, got %d", tt.acks, sender.config.Producer.RequiredAcks)
+			}
+			if sender.config.Net.SASL.Enable != (tt.user != "") || sender.config.Net.SASL.User != tt.user {
+				t.Errorf("Expected SASL user '%s', got '%s'", tt.user, sender.config.Net.SASL.User)
+			}
+			if sender.config.Net.TLS.Enable != tt.tls {
+				t.Errorf("Expected TLS enabled %v", tt.tls)
+			}
+			if tt.tls && sender.config.Net.TLS.Config.InsecureSkipVerify != tt.info.SkipCertVerify {
+				t.Errorf("Expected InsecureSkipVerify %v", tt.info.SkipCertVerify)
+			}
+			if len(sender.brokers) != tt.brokerLen {
+				t.Errorf("Expected %d brokers, got %v", tt.brokerLen, sender.brokers)
+			}
+		})
+	}
+}
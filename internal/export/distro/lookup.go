@@ -0,0 +1,94 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// lookupCacheDuration is how long the result of a lookup in another service is remembered
+	lookupCacheDuration = time.Minute
+	// lookupCacheSize is the number of results a cache remembers, the oldest are forgotten beyond it
+	lookupCacheSize = 1000
+)
+
+// lookupCache remembers the results of the lookups made in other services, such as the labels of a device, so that
+// they are not made for every event. Failed lookups are not remembered, the next caller looks the key up again. A key
+// is looked up once at a time, the callers asking for it meanwhile waiting for the result, while the lookups of other
+// keys proceed.
+type lookupCache struct {
+	mutex   sync.Mutex
+	entries map[string]cachedLookup
+	pending map[string]*pendingLookup
+}
+
+type cachedLookup struct {
+	value   interface{}
+	fetched time.Time
+}
+
+// pendingLookup is a lookup in progress, done is closed once its value is set
+type pendingLookup struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func newLookupCache() *lookupCache {
+	return &lookupCache{entries: make(map[string]cachedLookup), pending: make(map[string]*pendingLookup)}
+}
+
+// get returns the value remembered for the key, calling lookup when it is not known or expired. The error of a
+// failed lookup is returned to the callers waiting for it.
+func (c *lookupCache) get(key string, lookup func() (interface{}, error)) (interface{}, error) {
+	c.mutex.Lock()
+	if cached, ok := c.entries[key]; ok && time.Since(cached.fetched) < lookupCacheDuration {
+		c.mutex.Unlock()
+		return cached.value, nil
+	}
+	if p, ok := c.pending[key]; ok {
+		c.mutex.Unlock()
+		<-p.done
+		return p.value, p.err
+	}
+	p := &pendingLookup{done: make(chan struct{})}
+	c.pending[key] = p
+	c.mutex.Unlock()
+
+	p.value, p.err = lookup()
+
+	c.mutex.Lock()
+	delete(c.pending, key)
+	if p.err == nil {
+		c.store(key, p.value)
+	}
+	c.mutex.Unlock()
+	close(p.done)
+	return p.value, p.err
+}
+
+// store remembers the value of the key, making room for it by forgetting the expired values and then the oldest one
+// when the cache is full. The mutex must be held.
+func (c *lookupCache) store(key string, value interface{}) {
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= lookupCacheSize {
+		var oldest string
+		var oldestFetched time.Time
+		for k, cached := range c.entries {
+			if time.Since(cached.fetched) >= lookupCacheDuration {
+				delete(c.entries, k)
+			} else if oldestFetched.IsZero() || cached.fetched.Before(oldestFetched) {
+				oldest, oldestFetched = k, cached.fetched
+			}
+		}
+		if len(c.entries) >= lookupCacheSize {
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[key] = cachedLookup{value: value, fetched: time.Now()}
+}
@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLookupCacheConcurrent(t *testing.T) {
+	c := newLookupCache()
+	var lookups int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := c.get("slow", func() (interface{}, error) {
+				atomic.AddInt32(&lookups, 1)
+				<-release
+				return "value", nil
+			})
+			if err != nil || value != "value" {
+				t.Errorf("Expected the looked up value, got %v", value)
+			}
+		}()
+	}
+
+	// the other keys are looked up while the slow lookup is in progress
+	done := make(chan struct{})
+	go func() {
+		c.get("fast", func() (interface{}, error) { return "fast", nil })
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the lookup of another key not to wait for the slow lookup")
+	}
+
+	close(release)
+	wg.Wait()
+	if lookups != 1 {
+		t.Fatalf("Expected the key to be looked up once, got %d lookups", lookups)
+	}
+}
+
+func TestLookupCacheFailure(t *testing.T) {
+	c := newLookupCache()
+	if _, err := c.get("key", func() (interface{}, error) { return nil, errors.New("unavailable") }); err == nil {
+		t.Fatal("Expected the lookup to fail")
+	}
+	value, err := c.get("key", func() (interface{}, error) { return "value", nil })
+	if err != nil || value != "value" {
+		t.Fatalf("Expected the failed lookup to be made again, got %v: %v", value, err)
+	}
+}
+
+func TestLookupCacheBounded(t *testing.T) {
+	c := newLookupCache()
+	for i := 0; i < lookupCacheSize+10; i++ {
+		key := strconv.Itoa(i)
+		c.get(key, func() (interface{}, error) { return key, nil })
+	}
+	if len(c.entries) != lookupCacheSize {
+		t.Fatalf("Expected the cache to hold %d values, got %d", lookupCacheSize, len(c.entries))
+	}
+	if _, ok := c.entries[strconv.Itoa(lookupCacheSize+9)]; !ok {
+		t.Fatal("Expected the latest value to be remembered")
+	}
+}
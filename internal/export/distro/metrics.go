@@ -0,0 +1,164 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+// RegistrationMetrics holds the delivery metrics of a registration. Times are in milliseconds since the epoch, 0 when
+// it never happened.
+type RegistrationMetrics struct {
+	Name string
+	// Received is the number of events received, Filtered the number of those left out by the filters
+	Received int64
+	Filtered int64
+	// FormattedBytes is the size of the payloads after formatting, compression and encryption
+	FormattedBytes int64
+	Sent           int64
+	Failed         int64
+	LastError      string
+	LastErrorTime  int64
+	LastSuccess    int64
+	// Pending is the number of events waiting to be processed by the registration
+	Pending int
+	// Healthy tells whether the last attempt to send succeeded
+	Healthy bool
+}
+
+// deliveryMetrics collects the metrics of a running registration, they are read by the REST API while the
+// registration goroutine updates them. Nothing is collected for a nil deliveryMetrics.
+type deliveryMetrics struct {
+	mutex    sync.Mutex
+	metrics  RegistrationMetrics
+	messages chan msgTypes.MessageEnvelope
+}
+
+func (m *deliveryMetrics) received() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.metrics.Received++
+}
+
+func (m *deliveryMetrics) filtered() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.metrics.Filtered++
+}
+
+func (m *deliveryMetrics) formatted(size int) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.metrics.FormattedBytes += int64(size)
+}
+
+func (m *deliveryMetrics) sent() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.metrics.Sent++
+	m.metrics.LastSuccess = time.Now().UnixNano() / int64(time.Millisecond)
+	m.metrics.Healthy = true
+}
+
+// failed records an error, sendFailed tells whether it is a failure to send
+func (m *deliveryMetrics) failed(err string, sendFailed bool) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if sendFailed {
+		m.metrics.Failed++
+		m.metrics.Healthy = false
+	}
+	m.metrics.LastError = err
+	m.metrics.LastErrorTime = time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+func (m *deliveryMetrics) snapshot() RegistrationMetrics {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s := m.metrics
+	s.Pending = len(m.messages)
+	return s
+}
+
+// metricsRegistry holds the metrics of the running registrations by registration name
+var metricsRegistry = struct {
+	sync.RWMutex
+	registrations map[string]*deliveryMetrics
+}{registrations: make(map[string]*deliveryMetrics)}
+
+// registerMetrics returns the metrics of the registration, created when it starts running. The metrics of a
+// registration are kept across updates.
+func registerMetrics(name string, messages chan msgTypes.MessageEnvelope) *deliveryMetrics {
+	metricsRegistry.Lock()
+	defer metricsRegistry.Unlock()
+
+	m, ok := metricsRegistry.registrations[name]
+	if !ok {
+		m = &deliveryMetrics{metrics: RegistrationMetrics{Name: name, Healthy: true}}
+		metricsRegistry.registrations[name] = m
+	}
+	m.mutex.Lock()
+	m.messages = messages
+	m.mutex.Unlock()
+	return m
+}
+
+// unregisterMetrics removes the metrics of a registration which stopped running, unless a registration with the
+// same name started running since
+func unregisterMetrics(name string, m *deliveryMetrics) {
+	metricsRegistry.Lock()
+	defer metricsRegistry.Unlock()
+	if metricsRegistry.registrations[name] == m {
+		delete(metricsRegistry.registrations, name)
+	}
+}
+
+// registrationMetrics returns the metrics of the running registrations sorted by name
+func registrationMetrics() []RegistrationMetrics {
+	metricsRegistry.RLock()
+	defer metricsRegistry.RUnlock()
+
+	metrics := make([]RegistrationMetrics, 0, len(metricsRegistry.registrations))
+	for _, m := range metricsRegistry.registrations {
+		metrics = append(metrics, m.snapshot())
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Name < metrics[j].Name
+	})
+	return metrics
+}
+
+// registrationMetricsByName returns the metrics of a running registration
+func registrationMetricsByName(name string) (RegistrationMetrics, bool) {
+	metricsRegistry.RLock()
+	defer metricsRegistry.RUnlock()
+
+	m, ok := metricsRegistry.registrations[name]
+	if !ok {
+		return RegistrationMetrics{}, false
+	}
+	return m.snapshot(), true
+}
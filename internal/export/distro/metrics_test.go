@@ -0,0 +1,105 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	msgTypes "github.com/edgexfoundry/go-mod-messaging/pkg/types"
+)
+
+func TestDeliveryMetrics(t *testing.T) {
+	messages := make(chan msgTypes.MessageEnvelope, 2)
+	m := registerMetrics("metrics", messages)
+	defer unregisterMetrics("metrics", m)
+
+	m.received()
+	m.received()
+	m.filtered()
+	m.formatted(10)
+	m.failed("failed to send", true)
+	messages <- msgTypes.MessageEnvelope{}
+
+	s, ok := registrationMetricsByName("metrics")
+	if !ok {
+		t.Fatal("Expected the metrics to be registered")
+	}
+	if s.Received != 2 || s.Filtered != 1 || s.FormattedBytes != 10 || s.Failed != 1 || s.Pending != 1 {
+		t.Fatalf("Unexpected metrics %+v", s)
+	}
+	if s.Healthy || s.LastError != "failed to send" || s.LastErrorTime == 0 {
+		t.Fatalf("Expected the registration to be unhealthy, got %+v", s)
+	}
+
+	m.sent()
+	if s, _ = registrationMetricsByName("metrics"); !s.Healthy || s.Sent != 1 || s.LastSuccess == 0 {
+		t.Fatalf("Expected the registration to be healthy again, got %+v", s)
+	}
+
+	// a registration restarted with the same name keeps its metrics
+	if registerMetrics("metrics", messages) != m {
+		t.Fatal("Expected the metrics to be kept")
+	}
+}
+
+func TestUnregisterMetrics(t *testing.T) {
+	old := registerMetrics("metrics", nil)
+	unregisterMetrics("metrics", old)
+	if _, ok := registrationMetricsByName("metrics"); ok {
+		t.Fatal("Expected the metrics to be removed")
+	}
+
+	current := registerMetrics("metrics", nil)
+	defer unregisterMetrics("metrics", current)
+	unregisterMetrics("metrics", old)
+	if _, ok := registrationMetricsByName("metrics"); !ok {
+		t.Fatal("Expected the metrics of the running registration to be kept")
+	}
+}
+
+func TestRegisterMetricsConcurrent(t *testing.T) {
+	m := registerMetrics("metrics", nil)
+	defer unregisterMetrics("metrics", m)
+
+	// a registration restarting while its metrics are read
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			registerMetrics("metrics", make(chan msgTypes.MessageEnvelope, 1))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		m.snapshot()
+	}
+	wg.Wait()
+}
+
+func TestRegistrationSendMetrics(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	sender := &recordingSender{fail: true}
+	ri := newRegistrationInfo()
+	ri.sender = sender
+	ri.metrics = registerMetrics("reg", ri.chMessages)
+	defer unregisterMetrics("reg", ri.metrics)
+
+	ri.send(queuedPayload("1"), context.Background())
+	sender.fail = false
+	ri.send(queuedPayload("22"), context.Background())
+
+	s, _ := registrationMetricsByName("reg")
+	if s.Sent != 1 || s.Failed != 1 || s.FormattedBytes != 3 || !s.Healthy {
+		t.Fatalf("Unexpected metrics %+v", s)
+	}
+
+	// the metrics of a registration which is not running are not collected
+	ri.metrics = nil
+	ri.send(queuedPayload("3"), context.Background())
+}
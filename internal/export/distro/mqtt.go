@@ -11,58 +11,94 @@ package distro
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
+const (
+	mqttKeepAlive            = 30 * time.Second
+	mqttMaxReconnectInterval = 10 * time.Minute
+)
+
+// mqttPublishTimeout bounds the wait for the broker to acknowledge a publish
+var mqttPublishTimeout = 30 * time.Second
+
 type mqttSender struct {
-	client MQTT.Client
-	topic  string
+	client   MQTT.Client
+	topic    string
+	qos      byte
+	retained bool
 }
 
 // newMqttSender - create new mqtt sender
-func newMqttSender(addr contract.Addressable, cert string, key string) sender {
+func newMqttSender(addr contract.Addressable, cert string, key string, info export.MqttInfo) sender {
 	protocol := strings.ToLower(addr.Protocol)
+	broker := protocol + "://" + addr.Address + ":" + strconv.Itoa(addr.Port) + addr.Path
+
+	opts, err := newMqttClientOptions(addr, broker, cert, key, info)
+	if err != nil {
+		LoggingClient.Error(fmt.Sprintf("Invalid mqtt configuration: %s", err.Error()))
+		return nil
+	}
+
+	sender := &mqttSender{
+		client:   MQTT.NewClient(opts),
+		topic:    addr.Topic,
+		qos:      info.Qos,
+		retained: info.Retained,
+	}
+
+	return sender
+}
+
+// mqttInfo returns the MQTT client options of the registration, the defaults when it has none
+func mqttInfo(reg export.Registration) export.MqttInfo {
+	if reg.Mqtt != nil {
+		return *reg.Mqtt
+	}
+	return export.MqttInfo{}
+}
+
+// newMqttClientOptions returns the options of the client of the addressable, connecting to the broker
+func newMqttClientOptions(addr contract.Addressable, broker string, cert string, key string,
+	info export.MqttInfo) (*MQTT.ClientOptions, error) {
+	if info.Qos > 2 {
+		return nil, fmt.Errorf("invalid QoS %d", info.Qos)
+	}
 
 	opts := MQTT.NewClientOptions()
-	broker := protocol + "://" + addr.Address + ":" + strconv.Itoa(addr.Port) + addr.Path
 	opts.AddBroker(broker)
 	opts.SetClientID(addr.Publisher)
 	opts.SetUsername(addr.User)
 	opts.SetPassword(addr.Password)
-	opts.SetAutoReconnect(false)
-
-	if protocol == "tcps" || protocol == "ssl" || protocol == "tls" {
-		cert, err := tls.LoadX509KeyPair(cert, key)
+	opts.SetCleanSession(!info.PersistentSession)
+	opts.SetKeepAlive(configDuration(info.KeepAlive, mqttKeepAlive))
+	opts.SetAutoReconnect(info.AutoReconnect)
+	opts.SetMaxReconnectInterval(configDuration(info.MaxReconnectInterval, mqttMaxReconnectInterval))
+	opts.SetConnectionLostHandler(func(client MQTT.Client, err error) {
+		LoggingClient.Warn(fmt.Sprintf("Lost connection to mqtt server %s: %s", broker, err.Error()))
+	})
 
+	if validateProtocol(strings.ToLower(addr.Protocol)) {
+		tlsConfig, err := newTLSConfig(cert, key, info.TLSInfo)
 		if err != nil {
-			LoggingClient.Error("Failed loading x509 data")
-			return nil
+			return nil, err
 		}
-
-		tlsConfig := &tls.Config{
-			ClientCAs:          nil,
-			InsecureSkipVerify: true,
-			Certificates:       []tls.Certificate{cert},
-		}
-
 		opts.SetTLSConfig(tlsConfig)
-
-	}
-
-	sender := &mqttSender{
-		client: MQTT.NewClient(opts),
-		topic:  addr.Topic,
 	}
-
-	return sender
+	return opts, nil
 }
 
+// Send publishes the data, connecting first when the client is not connected. While the client reconnects
+// automatically the data is not sent, as the client would drop it at QoS 0, and the retry queue takes over. A QoS 1 or
+// 2 publish the broker did not acknowledge in time is reported as sent: the client still holds it and publishes it
+// again once reconnected, so queueing it for a retry would deliver it twice.
 func (sender *mqttSender) Send(data []byte, ctx context.Context) bool {
 	if !sender.client.IsConnected() {
 		LoggingClient.Info("Connecting to mqtt server")
@@ -70,16 +106,29 @@ func (sender *mqttSender) Send(data []byte, ctx context.Context) bool {
 			LoggingClient.Error(fmt.Sprintf("Could not connect to mqtt server, drop event. Error: %s", token.Error().Error()))
 			return false
 		}
+	} else if !sender.client.IsConnectionOpen() {
+		LoggingClient.Warn(fmt.Sprintf("Reconnecting to mqtt server, event not sent to topic %s", sender.topic))
+		return false
 	}
 
-	token := sender.client.Publish(sender.topic, 0, false, data)
-	// FIXME: could be removed? set of tokens?
-	token.Wait()
+	token := sender.client.Publish(sender.topic, sender.qos, sender.retained, data)
+	if !token.WaitTimeout(mqttPublishTimeout) {
+		if sender.qos > 0 {
+			LoggingClient.Warn(fmt.Sprintf("Timed out publishing to mqtt topic %s, left to the client to deliver", sender.topic))
+			return true
+		}
+		LoggingClient.Error(fmt.Sprintf("Timed out publishing to mqtt topic %s", sender.topic))
+		return false
+	}
 	if token.Error() != nil {
 		LoggingClient.Error(token.Error().Error())
 		return false
-	} else {
-		LoggingClient.Debug(fmt.Sprintf("Sent data: %X", data))
-		return true
 	}
+	if sender.qos == 0 && !sender.client.IsConnectionOpen() {
+		// the connection was lost while publishing, the client may have dropped the data
+		LoggingClient.Warn(fmt.Sprintf("Lost connection publishing to mqtt topic %s, event not sent", sender.topic))
+		return false
+	}
+	LoggingClient.Debug(fmt.Sprintf("Sent data: %X", data))
+	return true
 }
@@ -0,0 +1,223 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	MQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestMqttClientOptions(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	addr := contract.Addressable{Protocol: "tcp", Publisher: "distro"}
+
+	opts, err := newMqttClientOptions(addr, "tcp://localhost:1883", "", "",
+		export.MqttInfo{Qos: 2, PersistentSession: true, AutoReconnect: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opts.CleanSession {
+		t.Error("Expected a persistent session")
+	}
+	if !opts.AutoReconnect {
+		t.Error("Expected the client to reconnect")
+	}
+	if opts.MaxReconnectInterval != mqttMaxReconnectInterval {
+		t.Errorf("Expected the default max reconnect interval, got %v", opts.MaxReconnectInterval)
+	}
+
+	if _, err := newMqttClientOptions(addr, "tcp://localhost:1883", "", "", export.MqttInfo{Qos: 3}); err == nil {
+		t.Fatal("Expected an error for an invalid QoS")
+	}
+}
+
+func TestMqttSenderOptions(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	addr := contract.Addressable{Protocol: "tcp", Address: "localhost", Port: 1883, Topic: "events"}
+
+	s := newMqttSender(addr, "", "", export.MqttInfo{Qos: 1, Retained: true})
+	if s == nil {
+		t.Fatal("Expected an mqtt sender")
+	}
+	sender := s.(*mqttSender)
+	if sender.qos != 1 || !sender.retained || sender.topic != "events" {
+		t.Fatalf("Unexpected sender options: %+v", sender)
+	}
+}
+
+func TestMqttInfo(t *testing.T) {
+	reg := export.Registration{}
+	if info := mqttInfo(reg); info.SkipCertVerify || info.Qos != 0 {
+		t.Errorf("Expected the defaults verifying the certificate, got %v", info)
+	}
+
+	reg.Mqtt = &export.MqttInfo{Qos: 1, TLSInfo: export.TLSInfo{SkipCertVerify: true}}
+	if info := mqttInfo(reg); !info.SkipCertVerify || info.Qos != 1 {
+		t.Errorf("Expected the options of the registration, got %v", info)
+	}
+}
+
+// mqttBroker is a minimal broker accepting publishes, acknowledging them unless dropping the connection on receipt
+type mqttBroker struct {
+	listener  net.Listener
+	published chan string
+	lost      chan error
+	mutex     sync.Mutex
+	conns     []net.Conn
+	dropNext  bool
+}
+
+func newMqttBroker(t *testing.T) *mqttBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Could not listen: %v", err)
+	}
+	b := &mqttBroker{listener: listener, published: make(chan string, 10), lost: make(chan error, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			b.mutex.Lock()
+			b.conns = append(b.conns, conn)
+			b.mutex.Unlock()
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+func (b *mqttBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := packets.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		var reply packets.ControlPacket
+		switch p := packet.(type) {
+		case *packets.ConnectPacket:
+			reply = packets.NewControlPacket(packets.Connack)
+		case *packets.PingreqPacket:
+			reply = packets.NewControlPacket(packets.Pingresp)
+		case *packets.PublishPacket:
+			b.published <- string(p.Payload)
+			b.mutex.Lock()
+			drop := b.dropNext
+			b.dropNext = false
+			b.mutex.Unlock()
+			if drop {
+				return
+			}
+			if p.Qos > 0 {
+				ack := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+				ack.MessageID = p.MessageID
+				reply = ack
+			}
+		}
+		if reply != nil {
+			if err := reply.Write(conn); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// stop closes the listener and drops the connections
+func (b *mqttBroker) stop() {
+	b.listener.Close()
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, conn := range b.conns {
+		conn.Close()
+	}
+}
+
+// sender returns a sender reconnecting to the broker, reporting the connections lost to the broker
+func (b *mqttBroker) sender(t *testing.T, qos byte) *mqttSender {
+	addr := contract.Addressable{Protocol: "tcp", Publisher: "distro", Topic: "events"}
+	opts, err := newMqttClientOptions(addr, "tcp://"+b.listener.Addr().String(), "", "",
+		export.MqttInfo{Qos: qos, AutoReconnect: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	opts.SetConnectionLostHandler(func(client MQTT.Client, err error) {
+		b.lost <- err
+	})
+	return &mqttSender{client: MQTT.NewClient(opts), topic: addr.Topic, qos: qos}
+}
+
+func (b *mqttBroker) expectPublished(t *testing.T, data string) {
+	select {
+	case published := <-b.published:
+		if published != data {
+			t.Fatalf("Expected %s to be published, got %s", data, published)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected %s to be published", data)
+	}
+}
+
+func TestMqttSendBrokerDropped(t *testing.T) {
+	broker := newMqttBroker(t)
+	sender := broker.sender(t, 0)
+	defer sender.client.Disconnect(0)
+
+	if !sender.Send([]byte("first"), context.Background()) {
+		t.Fatal("Expected the event to be sent")
+	}
+	broker.expectPublished(t, "first")
+
+	broker.stop()
+	select {
+	case <-broker.lost:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the connection to be lost")
+	}
+	if !sender.client.IsConnected() || sender.client.IsConnectionOpen() {
+		t.Fatal("Expected the client to be reconnecting")
+	}
+	if sender.Send([]byte("second"), context.Background()) {
+		t.Error("Expected the event not to be sent while reconnecting")
+	}
+}
+
+func TestMqttSendDroppedWhilePublishing(t *testing.T) {
+	timeout := mqttPublishTimeout
+	mqttPublishTimeout = 100 * time.Millisecond
+	defer func() { mqttPublishTimeout = timeout }()
+
+	broker := newMqttBroker(t)
+	defer broker.stop()
+	sender := broker.sender(t, 1)
+	defer sender.client.Disconnect(0)
+
+	if !sender.Send([]byte("first"), context.Background()) {
+		t.Fatal("Expected the event to be sent")
+	}
+	broker.expectPublished(t, "first")
+
+	broker.mutex.Lock()
+	broker.dropNext = true
+	broker.mutex.Unlock()
+	if !sender.Send([]byte("second"), context.Background()) {
+		t.Error("Expected the event left to the client not to be queued for a retry")
+	}
+	broker.expectPublished(t, "second")
+	// published again once the client reconnected
+	broker.expectPublished(t, "second")
+}
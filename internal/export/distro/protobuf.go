@@ -0,0 +1,137 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// protobufSchema is the published schema of the PROTOBUF format. Fields may be added but never renumbered so that
+// the payloads stay readable by existing consumers.
+const protobufSchema = `syntax = "proto3";
+
+package org.edgexfoundry.export.v1;
+
+message Reading {
+  string id = 1;
+  int64 pushed = 2;
+  int64 created = 3;
+  int64 origin = 4;
+  int64 modified = 5;
+  string device = 6;
+  string name = 7;
+  string value = 8;
+  bytes binary_value = 9;
+}
+
+message Event {
+  string id = 1;
+  int64 pushed = 2;
+  string device = 3;
+  int64 created = 4;
+  int64 modified = 5;
+  int64 origin = 6;
+  repeated Reading readings = 7;
+}
+`
+
+const protobufContentType = "application/x-protobuf"
+
+// protobuf wire types
+const (
+	protobufVarint          = 0
+	protobufLengthDelimited = 2
+)
+
+// protobufFingerprint is the hex encoded first 8 bytes of the SHA-256 of the schema
+var protobufFingerprint = func() string {
+	sum := sha256.Sum256([]byte(protobufSchema))
+	return hex.EncodeToString(sum[:8])
+}()
+
+// protobufFormatter encodes the events as Event messages of protobufSchema
+type protobufFormatter struct {
+}
+
+func (pf protobufFormatter) Format(event *contract.Event) []byte {
+	var b []byte
+	b = appendProtobufString(b, 1, event.ID)
+	b = appendProtobufInt(b, 2, event.Pushed)
+	b = appendProtobufString(b, 3, event.Device)
+	b = appendProtobufInt(b, 4, event.Created)
+	b = appendProtobufInt(b, 5, event.Modified)
+	b = appendProtobufInt(b, 6, event.Origin)
+	for _, r := range event.Readings {
+		b = appendProtobufMessage(b, 7, protobufReading(r))
+	}
+	return b
+}
+
+func protobufReading(r contract.Reading) []byte {
+	var b []byte
+	b = appendProtobufString(b, 1, r.Id)
+	b = appendProtobufInt(b, 2, r.Pushed)
+	b = appendProtobufInt(b, 3, r.Created)
+	b = appendProtobufInt(b, 4, r.Origin)
+	b = appendProtobufInt(b, 5, r.Modified)
+	b = appendProtobufString(b, 6, r.Device)
+	b = appendProtobufString(b, 7, r.Name)
+	b = appendProtobufString(b, 8, r.Value)
+	b = appendProtobufBytes(b, 9, r.BinaryValue)
+	return b
+}
+
+func (pf protobufFormatter) Schema() string {
+	return protobufSchema
+}
+
+func (pf protobufFormatter) SchemaFingerprint() string {
+	return protobufFingerprint
+}
+
+func (pf protobufFormatter) ContentType() string {
+	return protobufContentType
+}
+
+// appendProtobufInt appends an int64 field, omitted when 0 as proto3 does
+func appendProtobufInt(b []byte, field int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendVarint(b, uint64(field<<3|protobufVarint))
+	return appendVarint(b, uint64(v))
+}
+
+// appendProtobufString appends a string field, omitted when empty as proto3 does
+func appendProtobufString(b []byte, field int, s string) []byte {
+	return appendProtobufBytes(b, field, []byte(s))
+}
+
+// appendProtobufBytes appends a length delimited field, omitted when empty as proto3 does
+func appendProtobufBytes(b []byte, field int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	return appendProtobufMessage(b, field, v)
+}
+
+// appendProtobufMessage appends an embedded message, the elements of repeated fields being written even when empty
+func appendProtobufMessage(b []byte, field int, v []byte) []byte {
+	b = appendVarint(b, uint64(field<<3|protobufLengthDelimited))
+	b = appendVarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
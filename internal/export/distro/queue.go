@@ -0,0 +1,335 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	defaultRetryInterval    = time.Second
+	defaultMaxRetryInterval = 5 * time.Minute
+)
+
+// queueStore holds the retry queues of all registrations, it is nil when store and forward is disabled
+var queueStore *bolt.DB
+
+// openQueueStore opens the database file holding the retry queues, creating it if needed
+func openQueueStore(path string) (*bolt.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	return bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+}
+
+// queuedMessage is a formatted event waiting to be sent, along with what is needed to mark it pushed once sent
+type queuedMessage struct {
+	Payload       []byte
+	ContentType   string
+	CorrelationID string
+	// EventID identifies a JSON event, EventIDs the JSON events of a batch and Checksum a CBOR event
+	EventID  string
+	EventIDs []string
+	Checksum string
+	// Device and Reading are the names of the device and first reading of a JSON event, the keys it may be
+	// partitioned by
+	Device  string
+	Reading string
+	// SchemaFingerprint identifies the schema of an event formatted with a published schema
+	SchemaFingerprint string
+	// Received is when the event was received, in nanoseconds
+	Received int64
+}
+
+// retryQueue is the on-disk queue of the messages a registration failed to send, kept in a bucket named after the
+// registration. Messages are retried oldest first, with an exponential backoff between failed attempts. Messages are
+// pushed by the registration and drained by its retryDrainer, the mutex guarding the state they share.
+type retryQueue struct {
+	store       *bolt.DB
+	bucket      []byte
+	mutex       sync.Mutex
+	size        int
+	backoff     time.Duration
+	nextAttempt time.Time
+}
+
+// newRetryQueue returns the queue of the registration, holding the messages queued before the service restarted
+func newRetryQueue(store *bolt.DB, name string) (*retryQueue, error) {
+	q := &retryQueue{store: store, bucket: []byte(name)}
+	err := store.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(q.bucket)
+		if err != nil {
+			return err
+		}
+		q.size = b.Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// dropRetryQueue removes the queue of a deleted registration along with its messages
+func dropRetryQueue(store *bolt.DB, name string) error {
+	return store.Update(func(tx *bolt.Tx) error {
+		err := tx.DeleteBucket([]byte(name))
+		if err == bolt.ErrBucketNotFound {
+			return nil
+		}
+		return err
+	})
+}
+
+// renameRetryQueue moves the messages queued for a registration which was renamed to the queue of its new name,
+// after any message already queued there
+func renameRetryQueue(store *bolt.DB, from string, to string) error {
+	return store.Update(func(tx *bolt.Tx) error {
+		old := tx.Bucket([]byte(from))
+		if old == nil {
+			return nil
+		}
+		b, err := tx.CreateBucketIfNotExists([]byte(to))
+		if err != nil {
+			return err
+		}
+		err = old.ForEach(func(k, v []byte) error {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			key := make([]byte, 8)
+			binary.BigEndian.PutUint64(key, seq)
+			return b.Put(key, v)
+		})
+		if err != nil {
+			return err
+		}
+		return tx.DeleteBucket([]byte(from))
+	})
+}
+
+func (q *retryQueue) empty() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.size == 0
+}
+
+// push adds the message at the end of the queue, dropping the oldest messages beyond MaxSize
+func (q *retryQueue) push(m queuedMessage) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	dropped := 0
+	err := q.store.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(q.bucket)
+		if err != nil {
+			return err
+		}
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, seq)
+		if err = b.Put(key, buf.Bytes()); err != nil {
+			return err
+		}
+
+		max := Configuration.StoreAndForward.MaxSize
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && max > 0 && q.size+1-dropped > max; k, _ = c.First() {
+			if err = c.Delete(); err != nil {
+				return err
+			}
+			dropped++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	q.size += 1 - dropped
+	if dropped > 0 {
+		LoggingClient.Warn(fmt.Sprintf("retry queue %s full, %d oldest events dropped", q.bucket, dropped))
+	}
+	return nil
+}
+
+// peek returns the oldest message of the queue and its key
+func (q *retryQueue) peek() (queuedMessage, []byte, bool, error) {
+	var m queuedMessage
+	var key []byte
+	err := q.store.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(q.bucket)
+		if b == nil {
+			return nil
+		}
+		k, v := b.Cursor().First()
+		if k == nil {
+			return nil
+		}
+		key = append([]byte{}, k...)
+		return gob.NewDecoder(bytes.NewReader(v)).Decode(&m)
+	})
+	return m, key, key != nil, err
+}
+
+// remove deletes a message from the queue, unless it was dropped meanwhile to make room for newer messages
+func (q *retryQueue) remove(key []byte) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	removed := false
+	err := q.store.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(q.bucket)
+		if b == nil || b.Get(key) == nil {
+			return nil
+		}
+		removed = true
+		return b.Delete(key)
+	})
+	if err == nil && removed && q.size > 0 {
+		q.size--
+	}
+	return err
+}
+
+// retryLater delays the next attempt, doubling the delay after each failed attempt up to MaxRetryInterval
+func (q *retryQueue) retryLater(now time.Time) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.backoff == 0 {
+		q.backoff = configDuration(Configuration.StoreAndForward.RetryInterval, defaultRetryInterval)
+	} else {
+		q.backoff *= 2
+	}
+	max := configDuration(Configuration.StoreAndForward.MaxRetryInterval, defaultMaxRetryInterval)
+	if q.backoff > max {
+		q.backoff = max
+	}
+	q.nextAttempt = now.Add(q.backoff)
+}
+
+// drain sends the queued messages in order once the backoff has elapsed, until one fails to be sent or stop is
+// closed. Messages older than MaxAge are dropped.
+func (q *retryQueue) drain(now time.Time, deliver func(queuedMessage) bool, stop <-chan struct{}) {
+	q.mutex.Lock()
+	waiting := q.size == 0 || now.Before(q.nextAttempt)
+	q.mutex.Unlock()
+	if waiting {
+		return
+	}
+
+	maxAge := configDuration(Configuration.StoreAndForward.MaxAge, 0)
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		m, key, ok, err := q.peek()
+		if err != nil {
+			LoggingClient.Error(fmt.Sprintf("failed to read retry queue %s: %s", q.bucket, err.Error()))
+			return
+		}
+		if !ok {
+			break
+		}
+
+		if maxAge > 0 && now.Sub(time.Unix(0, m.Received)) > maxAge {
+			LoggingClient.Warn(fmt.Sprintf("event %s%s dropped from retry queue %s after %s",
+				m.EventID, m.Checksum, q.bucket, maxAge))
+		} else if !deliver(m) {
+			q.retryLater(now)
+			return
+		}
+
+		if err = q.remove(key); err != nil {
+			LoggingClient.Error(fmt.Sprintf("failed to remove event from retry queue %s: %s", q.bucket, err.Error()))
+			return
+		}
+	}
+
+	q.mutex.Lock()
+	q.backoff = 0
+	q.nextAttempt = time.Time{}
+	q.mutex.Unlock()
+}
+
+// retryDrainer sends the messages of a retry queue from its own goroutine, so that a slow or unreachable destination
+// does not hold up the registration receiving the events
+type retryDrainer struct {
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startRetryDrainer drains the queue when a message is queued, and every second so that the queued messages are
+// retried when no new message arrives
+func startRetryDrainer(q *retryQueue, deliver func(queuedMessage) bool) *retryDrainer {
+	d := &retryDrainer{wake: make(chan struct{}, 1), stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			q.drain(time.Now(), deliver, d.stop)
+			select {
+			case <-d.stop:
+				return
+			case <-d.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+	return d
+}
+
+// notify wakes the drainer up once a message was queued
+func (d *retryDrainer) notify() {
+	if d == nil {
+		return
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// close stops the drainer, waiting for the message being sent if any
+func (d *retryDrainer) close() {
+	close(d.stop)
+	<-d.done
+}
+
+// configDuration parses a duration of the configuration, returning def when not set
+func configDuration(value string, def time.Duration) time.Duration {
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		LoggingClient.Warn(fmt.Sprintf("invalid duration '%s': %s", value, err.Error()))
+		return def
+	}
+	return d
+}
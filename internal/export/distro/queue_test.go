@@ -0,0 +1,303 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	bolt "go.etcd.io/bbolt"
+)
+
+func newTestQueueStore(t *testing.T) (*bolt.DB, func()) {
+	dir, err := ioutil.TempDir("", "distro-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := openQueueStore(filepath.Join(dir, "queue.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, func() {
+		store.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// recordingSender fails while fail is set and records the payloads it sends along with their content type
+type recordingSender struct {
+	fail         bool
+	sent         []string
+	contentTypes []string
+}
+
+func (s *recordingSender) Send(data []byte, ctx context.Context) bool {
+	if s.fail {
+		return false
+	}
+	s.sent = append(s.sent, string(data))
+	contentType, _ := ctx.Value(clients.ContentType).(string)
+	s.contentTypes = append(s.contentTypes, contentType)
+	return true
+}
+
+func queuedPayload(payload string) queuedMessage {
+	return queuedMessage{Payload: []byte(payload), EventID: payload, Received: time.Now().UnixNano()}
+}
+
+func TestRetryQueueDrainInOrder(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	store, cleanup := newTestQueueStore(t)
+	defer cleanup()
+
+	q, err := newRetryQueue(store, "reg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"1", "2", "3"} {
+		if err = q.push(queuedPayload(p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var sent []string
+	q.drain(time.Now(), func(m queuedMessage) bool {
+		sent = append(sent, string(m.Payload))
+		return true
+	}, nil)
+	if len(sent) != 3 || sent[0] != "1" || sent[2] != "3" {
+		t.Fatalf("Expected the events to be sent in order, got %v", sent)
+	}
+	if !q.empty() {
+		t.Fatal("Expected the queue to be empty")
+	}
+}
+
+func TestRetryQueueBackoff(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	Configuration.StoreAndForward.RetryInterval = "1s"
+	Configuration.StoreAndForward.MaxRetryInterval = "3s"
+	store, cleanup := newTestQueueStore(t)
+	defer cleanup()
+
+	q, _ := newRetryQueue(store, "reg")
+	q.push(queuedPayload("1"))
+
+	attempts := 0
+	failing := func(m queuedMessage) bool {
+		attempts++
+		return false
+	}
+	now := time.Now()
+	q.drain(now, failing, nil)
+	q.drain(now.Add(500*time.Millisecond), failing, nil)
+	if attempts != 1 {
+		t.Fatalf("Expected no attempt before the retry interval, got %d attempts", attempts)
+	}
+
+	expected := []time.Duration{2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for _, backoff := range expected {
+		now = q.nextAttempt
+		q.drain(now, failing, nil)
+		if q.nextAttempt.Sub(now) != backoff {
+			t.Errorf("Expected a backoff of %s, got %s", backoff, q.nextAttempt.Sub(now))
+		}
+	}
+
+	q.drain(q.nextAttempt, func(m queuedMessage) bool { return true }, nil)
+	if !q.empty() || q.backoff != 0 {
+		t.Fatal("Expected the queue to be drained and the backoff reset")
+	}
+}
+
+func TestRetryQueueLimits(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	Configuration.StoreAndForward.MaxSize = 2
+	Configuration.StoreAndForward.MaxAge = "1m"
+	store, cleanup := newTestQueueStore(t)
+	defer cleanup()
+
+	q, _ := newRetryQueue(store, "reg")
+	old := queuedPayload("old")
+	old.Received = time.Now().Add(-2 * time.Minute).UnixNano()
+	q.push(queuedPayload("dropped"))
+	q.push(old)
+	q.push(queuedPayload("kept"))
+	if q.size != 2 {
+		t.Fatalf("Expected 2 queued events, got %d", q.size)
+	}
+
+	var sent []string
+	q.drain(time.Now(), func(m queuedMessage) bool {
+		sent = append(sent, string(m.Payload))
+		return true
+	}, nil)
+	if len(sent) != 1 || sent[0] != "kept" {
+		t.Fatalf("Expected only the recent event to be sent, got %v", sent)
+	}
+}
+
+func TestRetryQueuePersistence(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	dir, err := ioutil.TempDir("", "distro-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "queue.db")
+
+	store, _ := openQueueStore(path)
+	q, _ := newRetryQueue(store, "reg")
+	q.push(queuedPayload("1"))
+	q.push(queuedPayload("2"))
+	store.Close()
+
+	store, _ = openQueueStore(path)
+	defer store.Close()
+	q, _ = newRetryQueue(store, "reg")
+	if q.size != 2 {
+		t.Fatalf("Expected the queued events to survive a restart, got %d", q.size)
+	}
+
+	if err = dropRetryQueue(store, "reg"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok, _ := q.peek(); ok {
+		t.Fatal("Expected the queue to be removed")
+	}
+}
+
+func TestRetryQueueDrainStop(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	store, cleanup := newTestQueueStore(t)
+	defer cleanup()
+
+	q, _ := newRetryQueue(store, "reg")
+	q.push(queuedPayload("1"))
+	q.push(queuedPayload("2"))
+
+	stop := make(chan struct{})
+	sent := 0
+	q.drain(time.Now(), func(m queuedMessage) bool {
+		sent++
+		close(stop)
+		return true
+	}, stop)
+	if sent != 1 || q.size != 1 {
+		t.Fatalf("Expected the drain to stop after the first event, sent %d and %d queued", sent, q.size)
+	}
+}
+
+func TestRetryQueueRename(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	store, cleanup := newTestQueueStore(t)
+	defer cleanup()
+
+	q, _ := newRetryQueue(store, "new")
+	q.push(queuedPayload("1"))
+	q, _ = newRetryQueue(store, "old")
+	q.push(queuedPayload("2"))
+	q.push(queuedPayload("3"))
+
+	if err := renameRetryQueue(store, "old", "new"); err != nil {
+		t.Fatal(err)
+	}
+	q, _ = newRetryQueue(store, "new")
+	var sent []string
+	q.drain(time.Now(), func(m queuedMessage) bool {
+		sent = append(sent, string(m.Payload))
+		return true
+	}, nil)
+	if len(sent) != 3 || sent[0] != "1" || sent[1] != "2" || sent[2] != "3" {
+		t.Fatalf("Expected the events of the previous name to be moved in order, got %v", sent)
+	}
+	if q, _ = newRetryQueue(store, "old"); !q.empty() {
+		t.Fatal("Expected the queue of the previous name to be removed")
+	}
+}
+
+func TestRegistrationStoreAndForward(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	store, cleanup := newTestQueueStore(t)
+	defer cleanup()
+
+	ri := newRegistrationInfo()
+	ri.queue, _ = newRetryQueue(store, "reg")
+	sender := &recordingSender{fail: true}
+	ri.sender = sender
+
+	// the events are queued and sent in order by the drainer
+	ri.send(queuedPayload("1"), context.Background())
+	ri.send(queuedPayload("2"), context.Background())
+	if len(sender.sent) != 0 || ri.queue.size != 2 {
+		t.Fatalf("Expected the events to be queued, got %v", sender.sent)
+	}
+
+	ri.queue.drain(time.Now(), ri.deliver, nil)
+	if ri.queue.size != 2 || ri.queue.nextAttempt.IsZero() {
+		t.Fatal("Expected the events to stay queued until the retry interval elapsed")
+	}
+
+	sender.fail = false
+	ri.queue.drain(ri.queue.nextAttempt, ri.deliver, nil)
+	if len(sender.sent) != 2 || sender.sent[0] != "1" || sender.sent[1] != "2" || !ri.queue.empty() {
+		t.Fatalf("Expected the queued events to be sent in order, got %v", sender.sent)
+	}
+}
+
+// blockingSender sends the payloads once released
+type blockingSender struct {
+	release chan struct{}
+	sent    chan string
+}
+
+func (s *blockingSender) Send(data []byte, ctx context.Context) bool {
+	<-s.release
+	s.sent <- string(data)
+	return true
+}
+
+func TestRegistrationDrainer(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	store, cleanup := newTestQueueStore(t)
+	defer cleanup()
+
+	ri := newRegistrationInfo()
+	ri.registration.Enable = true
+	ri.queue, _ = newRetryQueue(store, "reg")
+	sender := &blockingSender{release: make(chan struct{}), sent: make(chan string, 3)}
+	ri.sender = sender
+	ri.startDrainer()
+
+	// the registration keeps queueing the events while the destination is slow
+	for _, p := range []string{"1", "2", "3"} {
+		if err := ri.send(queuedPayload(p), context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(sender.release)
+
+	for _, expected := range []string{"1", "2", "3"} {
+		select {
+		case p := <-sender.sent:
+			if p != expected {
+				t.Fatalf("Expected event %s to be sent, got %s", expected, p)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected event %s to be sent", expected)
+		}
+	}
+	ri.stopDrainer()
+	if !ri.queue.empty() {
+		t.Fatal("Expected the queue to be drained")
+	}
+}
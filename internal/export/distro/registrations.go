@@ -21,6 +21,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/edgexfoundry/edgex-go/internal/export"
+
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
@@ -31,6 +33,9 @@ import (
 )
 
 const (
+	// registrationBufferSize is the number of events a registration buffers while it is busy sending
+	registrationBufferSize = 64
+
 	awsMQTTPort         int    = 8883
 	awsThingUpdateTopic string = "$aws/things/%s/shadow/update"
 )
@@ -39,16 +44,27 @@ var registrationChanges chan contract.NotifyUpdate = make(chan contract.NotifyUp
 
 // RegistrationInfo - registration info
 type registrationInfo struct {
-	registration contract.Registration
+	// id of the registration, set before the goroutine of the registration starts so that renames are followed
+	id           string
+	registration export.Registration
 	format       formatter
 	compression  transformer
 	encrypt      transformer
 	sender       sender
 	filter       []filterer
+	transform    eventTransformer
 
-	chRegistration chan *contract.Registration
+	chRegistration chan *export.Registration
 	chMessages     chan msgTypes.MessageEnvelope
 
+	// queue holds the messages waiting to be sent when store and forward is enabled, drainer sending them
+	queue   *retryQueue
+	drainer *retryDrainer
+	// batch holds the events waiting to be sent together when batching is configured for the registration
+	batch *eventBatch
+	// metrics are collected while the registration is running
+	metrics *deliveryMetrics
+
 	deleteFlag bool
 }
 
@@ -60,14 +76,71 @@ func RefreshRegistrations(update contract.NotifyUpdate) {
 func newRegistrationInfo() *registrationInfo {
 	reg := &registrationInfo{}
 
-	reg.chRegistration = make(chan *contract.Registration)
-	reg.chMessages = make(chan msgTypes.MessageEnvelope)
+	reg.chRegistration = make(chan *export.Registration)
+	reg.chMessages = make(chan msgTypes.MessageEnvelope, registrationBufferSize)
 	return reg
 }
 
-func (reg *registrationInfo) update(newReg contract.Registration) bool {
+func (reg *registrationInfo) update(newReg export.Registration) bool {
+	previousName := reg.registration.Name
 	reg.registration = newReg
 
+	if queueStore != nil && (reg.queue == nil || previousName != newReg.Name) {
+		// the messages queued under the previous name of a renamed registration are kept
+		if reg.queue != nil {
+			if err := renameRetryQueue(queueStore, previousName, newReg.Name); err != nil {
+				LoggingClient.Error(fmt.Sprintf("failed to move retry queue of registration %s to %s: %s",
+					previousName, newReg.Name, err.Error()))
+			}
+		}
+		queue, err := newRetryQueue(queueStore, newReg.Name)
+		if err != nil {
+			LoggingClient.Error(fmt.Sprintf("failed to open retry queue of registration %s: %s", newReg.Name, err.Error()))
+		}
+		reg.queue = queue
+	}
+
+	if err := reg.updatePipeline(newReg); err != nil {
+		LoggingClient.Warn(err.Error())
+		return false
+	}
+
+	reg.closeSender()
+	sender, err := newSender(newReg)
+	if err != nil {
+		LoggingClient.Warn(err.Error())
+		return false
+	}
+	reg.sender = sender
+	return reg.sender != nil
+}
+
+// startDrainer sends the queued messages of the registration from their own goroutine, when store and forward is
+// enabled. The drainer works on a copy of the registration, it is restarted when the registration is updated.
+func (reg *registrationInfo) startDrainer() {
+	if reg.queue != nil && reg.registration.Enable {
+		reg.drainer = startRetryDrainer(reg.queue, reg.deliver)
+	}
+}
+
+func (reg *registrationInfo) stopDrainer() {
+	if reg.drainer != nil {
+		reg.drainer.close()
+		reg.drainer = nil
+	}
+}
+
+// closeSender closes the connection the sender of the registration holds, if any, and drops the sender
+func (reg *registrationInfo) closeSender() {
+	if c, ok := reg.sender.(closer); ok {
+		c.Close()
+	}
+	reg.sender = nil
+}
+
+// updatePipeline sets up the filters, transformation, format, compression, encryption and batching of the events
+// of the registration
+func (reg *registrationInfo) updatePipeline(newReg export.Registration) error {
 	reg.format = nil
 	switch newReg.Format {
 	case contract.FormatJSON:
@@ -83,14 +156,29 @@ func (reg *registrationInfo) update(newReg contract.Registration) bool {
 	case contract.FormatAWSJSON:
 		reg.format = awsFormatter{}
 	case contract.FormatCSV:
-		// TODO reg.format = distro.NewCsvFormat()
+		info := export.CSVInfo{}
+		if newReg.CSV != nil {
+			info = *newReg.CSV
+		}
+		format, err := newCSVFormatter(info.Columns, info.Header)
+		if err != nil {
+			return fmt.Errorf("CSV format not supported: %s", err.Error())
+		}
+		reg.format = format
 	case contract.FormatThingsBoardJSON:
 		reg.format = thingsboardJSONFormatter{}
 	case contract.FormatNOOP:
 		reg.format = noopFormatter{}
+	case export.FormatProtobuf, export.FormatAvro:
+		reg.format = schemaFormatters[newReg.Format]
+	case export.FormatTemplate:
+		format, err := newTemplateFormatter(newReg.Template)
+		if err != nil {
+			return fmt.Errorf("Template of registration %s not valid: %s", newReg.Name, err.Error())
+		}
+		reg.format = format
 	default:
-		LoggingClient.Warn(fmt.Sprintf("Format not supported: %s", newReg.Format))
-		return false
+		return fmt.Errorf("Format not supported: %s", newReg.Format)
 	}
 
 	reg.compression = nil
@@ -104,38 +192,7 @@ func (reg *registrationInfo) update(newReg contract.Registration) bool {
 	case contract.CompZip:
 		reg.compression = &zlibTransformer{}
 	default:
-		LoggingClient.Warn(fmt.Sprintf("Compression not supported: %s", newReg.Compression))
-		return false
-	}
-
-	reg.sender = nil
-	switch newReg.Destination {
-	case contract.DestMQTT, contract.DestAzureMQTT:
-		c := Configuration.Certificates["MQTTS"]
-		reg.sender = newMqttSender(newReg.Addressable, c.Cert, c.Key)
-	case contract.DestAWSMQTT:
-		newReg.Addressable.Protocol = "tls"
-		newReg.Addressable.Path = ""
-		newReg.Addressable.Topic = fmt.Sprintf(awsThingUpdateTopic, newReg.Addressable.Topic)
-		newReg.Addressable.Port = awsMQTTPort
-		c := Configuration.Certificates["AWS"]
-		reg.sender = newMqttSender(newReg.Addressable, c.Cert, c.Key)
-	case contract.DestZMQ:
-		reg.sender = newZeroMQEventPublisher()
-	case contract.DestIotCoreMQTT:
-		reg.sender = newIoTCoreSender(newReg.Addressable)
-	case contract.DestRest:
-		reg.sender = newHTTPSender(newReg.Addressable)
-	case contract.DestXMPP:
-		reg.sender = newXMPPSender(newReg.Addressable)
-
-	default:
-		LoggingClient.Warn(fmt.Sprintf("Destination not supported: %s", newReg.Destination))
-		return false
-	}
-
-	if reg.sender == nil {
-		return false
+		return fmt.Errorf("Compression not supported: %s", newReg.Compression)
 	}
 
 	reg.encrypt = nil
@@ -146,9 +203,33 @@ func (reg *registrationInfo) update(newReg contract.Registration) bool {
 		reg.encrypt = nil
 	case contract.EncAes:
 		reg.encrypt = newAESEncryption(newReg.Encryption)
+	case export.EncAesGcm:
+		encrypt, err := newAESGCMEncryption(newReg.Encryption)
+		if err != nil {
+			return fmt.Errorf("Encryption of registration %s not valid: %s", newReg.Name, err.Error())
+		}
+		reg.encrypt = encrypt
 	default:
-		LoggingClient.Warn(fmt.Sprintf("Encryption not supported: %s", newReg.Encryption.Algo))
-		return false
+		return fmt.Errorf("Encryption not supported: %s", newReg.Encryption.Algo)
+	}
+
+	reg.batch = nil
+	if newReg.Batch != nil {
+		if _, ok := reg.format.(batchFormatter); ok {
+			reg.batch = newEventBatch(*newReg.Batch)
+		} else {
+			LoggingClient.Warn(fmt.Sprintf("Format %s cannot be batched, events of registration %s sent one by one",
+				newReg.Format, newReg.Name))
+		}
+	}
+
+	reg.transform = nil
+	if newReg.Transform != nil {
+		transform, err := newReadingTransform(*newReg.Transform)
+		if err != nil {
+			return fmt.Errorf("Transform of registration %s not valid: %s", newReg.Name, err.Error())
+		}
+		reg.transform = transform
 	}
 
 	reg.filter = nil
@@ -163,11 +244,53 @@ func (reg *registrationInfo) update(newReg contract.Registration) bool {
 		LoggingClient.Debug(fmt.Sprintf("Value descriptor filter added: %s", newReg.Filter.ValueDescriptorIDs))
 	}
 
-	return true
+	if newReg.FilterExpression != "" {
+		f, err := newExpressionFilter(newReg.FilterExpression)
+		if err != nil {
+			return fmt.Errorf("Filter expression of registration %s not valid: %s", newReg.Name, err.Error())
+		}
+		reg.filter = append(reg.filter, f)
+		LoggingClient.Debug(fmt.Sprintf("Filter expression added: %s", newReg.FilterExpression))
+	}
+
+	return nil
+}
+
+// newSender returns the sender of the destination of the registration, nil when it could not be created
+func newSender(newReg export.Registration) (sender, error) {
+	switch newReg.Destination {
+	case contract.DestMQTT, contract.DestAzureMQTT:
+		c := Configuration.Certificates["MQTTS"]
+		return newMqttSender(newReg.Addressable, c.Cert, c.Key, mqttInfo(newReg)), nil
+	case contract.DestAWSMQTT:
+		newReg.Addressable.Protocol = "tls"
+		newReg.Addressable.Path = ""
+		newReg.Addressable.Topic = fmt.Sprintf(awsThingUpdateTopic, newReg.Addressable.Topic)
+		newReg.Addressable.Port = awsMQTTPort
+		c := Configuration.Certificates["AWS"]
+		return newMqttSender(newReg.Addressable, c.Cert, c.Key, mqttInfo(newReg)), nil
+	case contract.DestZMQ:
+		return newZeroMQEventPublisher(), nil
+	case contract.DestIotCoreMQTT:
+		return newIoTCoreSender(newReg.Addressable, mqttInfo(newReg)), nil
+	case contract.DestRest:
+		return newHTTPSender(newReg.Addressable), nil
+	case contract.DestXMPP:
+		return newXMPPSender(newReg.Addressable), nil
+	case export.DestKafka:
+		c := Configuration.Certificates["KAFKA"]
+		info := export.KafkaInfo{}
+		if newReg.Kafka != nil {
+			info = *newReg.Kafka
+		}
+		return newKafkaSender(newReg.Addressable, info, c.Cert, c.Key), nil
+	}
+	return nil, fmt.Errorf("Destination not supported: %s", newReg.Destination)
 }
 
 func (reg registrationInfo) processMessage(msg msgTypes.MessageEnvelope) {
 	var err error
+	reg.metrics.received()
 	ctx := context.WithValue(context.Background(), clients.CorrelationHeader, msg.CorrelationID)
 	ctx = context.WithValue(ctx, clients.ContentType, msg.ContentType)
 	switch msg.ContentType {
@@ -181,6 +304,7 @@ func (reg registrationInfo) processMessage(msg msgTypes.MessageEnvelope) {
 
 	if err != nil {
 		LoggingClient.Error(err.Error())
+		reg.metrics.failed(err.Error(), false)
 	}
 
 	LoggingClient.Debug(fmt.Sprintf("Sent event with registration: %s", reg.registration.Name))
@@ -193,22 +317,60 @@ func (reg registrationInfo) handleJSON(msg msgTypes.MessageEnvelope, ctx context
 		return errors.New("unable to parse event from string " + str)
 	}
 
-	data := event.ToContract()
+	data, accepted := reg.filterAndTransform(event.ToContract())
+	if !accepted {
+		LoggingClient.Debug("Event filtered " + event.ID)
+		reg.metrics.filtered()
+		return
+	}
+
+	if reg.format == nil {
+		LoggingClient.Warn("registrationInfo with nil format " + reg.registration.Name)
+		return
+	}
+
+	if reg.batch != nil {
+		reg.batch.add(data, event.ID, len(msg.Payload), msg.CorrelationID)
+		if reg.batch.full() {
+			return reg.flushBatch()
+		}
+		return
+	}
+
+	m := queuedMessage{
+		Payload:       reg.encode(reg.format.Format(data)),
+		ContentType:   msg.ContentType,
+		CorrelationID: msg.CorrelationID,
+		EventID:       event.ID,
+		Device:        data.Device,
+		Received:      time.Now().UnixNano(),
+	}
+	if len(data.Readings) > 0 {
+		m.Reading = data.Readings[0].Name
+	}
+	reg.applySchema(&m)
+	return reg.send(m, ctx)
+}
+
+// filterAndTransform runs the event through the filters of the registration, the event left by the filters is
+// transformed unless it was filtered out
+func (reg registrationInfo) filterAndTransform(data *contract.Event) (*contract.Event, bool) {
 	for _, f := range reg.filter {
 		var accepted bool
 		accepted, data = f.Filter(data)
 		if !accepted {
-			LoggingClient.Debug("Event filtered " + event.ID)
-			return
+			return nil, false
 		}
 	}
 
-	if reg.format == nil {
-		LoggingClient.Warn("registrationInfo with nil format " + reg.registration.Name)
-		return
+	if reg.transform != nil {
+		data = reg.transform.TransformEvent(data)
 	}
-	formatted := reg.format.Format(data)
+	return data, true
+}
 
+// encode compresses and encrypts the formatted events
+func (reg registrationInfo) encode(formatted []byte) []byte {
 	compressed := formatted
 	if reg.compression != nil {
 		compressed = reg.compression.Transform(formatted)
@@ -218,37 +380,150 @@ func (reg registrationInfo) handleJSON(msg msgTypes.MessageEnvelope, ctx context
 	if reg.encrypt != nil {
 		bytes = reg.encrypt.Transform(compressed)
 	}
-	if reg.sender.Send(bytes, ctx) && Configuration.Writable.MarkPushed {
-		return ec.MarkPushed(event.ID, ctx)
-	}
-	return
+	return bytes
 }
 
 func (reg registrationInfo) handleCBOR(msg msgTypes.MessageEnvelope, ctx context.Context) (err error) {
-	if reg.sender.Send(msg.Payload, ctx) && Configuration.Writable.MarkPushed {
-		//Cannot use CBOR Content-Type when calling back to core-data. Ensure Content-Type is JSON.
-		ctxCallback := context.WithValue(context.Background(), clients.CorrelationHeader, msg.CorrelationID)
-		ctxCallback = context.WithValue(ctxCallback, clients.ContentType, clients.ContentTypeJSON)
-		return ec.MarkPushedByChecksum(msg.Checksum, ctxCallback)
+	// CBOR events are sent as received, after the pending batch so that the events are sent in order
+	if err = reg.flushBatch(); err != nil {
+		LoggingClient.Error(err.Error())
 	}
-	return
+	return reg.send(queuedMessage{
+		Payload:       msg.Payload,
+		ContentType:   msg.ContentType,
+		CorrelationID: msg.CorrelationID,
+		Checksum:      msg.Checksum,
+		Received:      time.Now().UnixNano(),
+	}, ctx)
+}
+
+// send sends the message and marks its event pushed. When store and forward is enabled, the message is queued
+// instead and sent in order by the drainer of the queue, retrying until it is delivered.
+func (reg registrationInfo) send(m queuedMessage, ctx context.Context) error {
+	reg.metrics.formatted(len(m.Payload))
+	if reg.queue == nil {
+		ctx = withPartitionKeys(ctx, m)
+		ctx = withSchema(ctx, m)
+		if reg.transmit(m.Payload, ctx) && Configuration.Writable.MarkPushed {
+			return markPushed(m, ctx)
+		}
+		return nil
+	}
+
+	if err := reg.queue.push(m); err != nil {
+		return errors.Wrap(err, "failed to queue event for registration "+reg.registration.Name)
+	}
+	reg.drainer.notify()
+	return nil
+}
+
+// deliver sends a queued message, the event is marked pushed once sent
+func (reg registrationInfo) deliver(m queuedMessage) bool {
+	ctx := context.WithValue(context.Background(), clients.CorrelationHeader, m.CorrelationID)
+	ctx = context.WithValue(ctx, clients.ContentType, m.ContentType)
+	ctx = withPartitionKeys(ctx, m)
+	ctx = withSchema(ctx, m)
+	if !reg.transmit(m.Payload, ctx) {
+		return false
+	}
+
+	if Configuration.Writable.MarkPushed {
+		if err := markPushed(m, ctx); err != nil {
+			LoggingClient.Error(err.Error())
+		}
+	}
+	return true
+}
+
+// transmit sends the payload with the sender of the registration, recording the outcome in its metrics
+func (reg registrationInfo) transmit(payload []byte, ctx context.Context) bool {
+	if !reg.sender.Send(payload, ctx) {
+		reg.metrics.failed(fmt.Sprintf("failed to send to %s", reg.registration.Destination), true)
+		return false
+	}
+	reg.metrics.sent()
+	return true
+}
+
+// markPushed marks the events of a sent message pushed in core-data
+func markPushed(m queuedMessage, ctx context.Context) error {
+	if len(m.EventIDs) > 0 {
+		for _, id := range m.EventIDs {
+			if err := ec.MarkPushed(id, ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if m.Checksum == "" {
+		return ec.MarkPushed(m.EventID, ctx)
+	}
+
+	//Cannot use CBOR Content-Type when calling back to core-data. Ensure Content-Type is JSON.
+	ctxCallback := context.WithValue(context.Background(), clients.CorrelationHeader, m.CorrelationID)
+	ctxCallback = context.WithValue(ctxCallback, clients.ContentType, clients.ContentTypeJSON)
+	return ec.MarkPushedByChecksum(m.Checksum, ctxCallback)
 }
 
 func registrationLoop(reg *registrationInfo) {
 	LoggingClient.Info(fmt.Sprintf("registration loop started: %s", reg.registration.Name))
+
+	reg.metrics = registerMetrics(reg.registration.Name, reg.chMessages)
+	reg.startDrainer()
+	defer func() {
+		reg.stopDrainer()
+		unregisterMetrics(reg.registration.Name, reg.metrics)
+		reg.closeSender()
+	}()
+
+	// the pending batch is sent once its latency elapsed
+	var flushTimer *time.Timer
+	var flush <-chan time.Time
+	stopFlushTimer := func() {
+		if flushTimer != nil {
+			flushTimer.Stop()
+			flushTimer, flush = nil, nil
+		}
+	}
+	defer stopFlushTimer()
+
 	for {
 		select {
 		case msg := <-reg.chMessages:
 			if reg.registration.Enable {
 				reg.processMessage(msg)
 			}
+			if reg.batch == nil || !reg.batch.pending() {
+				stopFlushTimer()
+			} else if flushTimer == nil {
+				flushTimer = time.NewTimer(reg.batch.latency)
+				flush = flushTimer.C
+			}
+
+		case <-flush:
+			flushTimer, flush = nil, nil
+			if err := reg.flushBatch(); err != nil {
+				LoggingClient.Error(err.Error())
+			}
 
 		case newReg := <-reg.chRegistration:
+			// the pending events are sent with the registration they were received for
+			stopFlushTimer()
+			if err := reg.flushBatch(); err != nil {
+				LoggingClient.Error(err.Error())
+			}
 			if newReg == nil {
 				LoggingClient.Info("Terminating registration goroutine")
 				return
 			} else {
+				previousName := reg.registration.Name
+				reg.stopDrainer()
 				if reg.update(*newReg) {
+					if previousName != reg.registration.Name {
+						unregisterMetrics(previousName, reg.metrics)
+						reg.metrics = registerMetrics(reg.registration.Name, reg.chMessages)
+					}
+					reg.startDrainer()
 					LoggingClient.Info(fmt.Sprintf("Registration %s updated: OK", reg.registration.Name))
 				} else {
 					LoggingClient.Info(fmt.Sprintf("Registration %s updated: OK, terminating goroutine", reg.registration.Name))
@@ -270,6 +545,11 @@ func updateRunningRegistrations(
 			if k == update.Name {
 				v.chRegistration <- nil
 				delete(running, k)
+				if queueStore != nil {
+					if err := dropRetryQueue(queueStore, k); err != nil {
+						LoggingClient.Error(fmt.Sprintf("failed to remove retry queue of registration %s: %s", k, err.Error()))
+					}
+				}
 				return nil
 			}
 		}
@@ -279,19 +559,30 @@ func updateRunningRegistrations(
 		if reg == nil {
 			return fmt.Errorf("Could not find registration")
 		}
-		for k, v := range running {
-			if k == update.Name {
-				v.chRegistration <- reg
-				return nil
+		v, ok := running[update.Name]
+		if !ok {
+			// a renamed registration is running under its previous name
+			for k, r := range running {
+				if reg.ID != "" && r.id == reg.ID {
+					v, ok = r, true
+					delete(running, k)
+					running[reg.Name] = v
+					break
+				}
 			}
 		}
-		return fmt.Errorf("Could not find running registration")
+		if !ok {
+			return fmt.Errorf("Could not find running registration")
+		}
+		v.chRegistration <- reg
+		return nil
 	case contract.NotifyUpdateAdd:
 		reg := getRegistrationByName(update.Name)
 		if reg == nil {
 			return fmt.Errorf("Could not find registration")
 		}
 		regInfo := newRegistrationInfo()
+		regInfo.id = reg.ID
 		if regInfo.update(*reg) {
 			running[reg.Name] = regInfo
 			go registrationLoop(regInfo)
@@ -332,6 +623,7 @@ func Loop(wg *sync.WaitGroup, ctx context.Context) {
 		// Create new goroutines for each registration
 		for _, reg := range allRegs {
 			regInfo := newRegistrationInfo()
+			regInfo.id = reg.ID
 			if regInfo.update(reg) {
 				registrations[reg.Name] = regInfo
 				go registrationLoop(regInfo)
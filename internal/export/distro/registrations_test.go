@@ -9,8 +9,14 @@ package distro
 import (
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
 	"github.com/edgexfoundry/go-mod-core-contracts/clients"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
@@ -20,8 +26,8 @@ import (
 	"github.com/ugorji/go/codec"
 )
 
-func validRegistration() contract.Registration {
-	r := contract.Registration{}
+func validRegistration() export.Registration {
+	r := export.Registration{}
 	r.Addressable = contract.Addressable{Id: uuid.New().String(), Name: "Test Addressable"}
 	r.Format = contract.FormatJSON
 	r.Compression = contract.CompNone
@@ -38,7 +44,7 @@ func TestRegistrationInfoUpdate(t *testing.T) {
 		t.Fatal("RegistrationInfo should not be nil")
 	}
 
-	r := contract.Registration{}
+	r := export.Registration{}
 	if ri.update(r) {
 		t.Fatal("An empty registration is not valid")
 	}
@@ -53,6 +59,22 @@ func TestRegistrationInfoUpdate(t *testing.T) {
 		t.Fatal("Registration with invalid fields")
 	}
 
+	r = validRegistration()
+	r.Format = contract.FormatCSV
+	if !ri.update(r) || ri.format == nil {
+		t.Fatal("CSV registration should be good")
+	}
+
+	r.CSV = &export.CSVInfo{Columns: []string{export.CSVColumnValue}, Header: true}
+	if !ri.update(r) || ri.format.(csvFormatter).columns[0] != export.CSVColumnValue || !ri.format.(csvFormatter).header {
+		t.Fatal("CSV registration with its columns should be good")
+	}
+
+	r.CSV = &export.CSVInfo{Columns: []string{"invalid"}}
+	if ri.update(r) {
+		t.Fatal("CSV registration with invalid columns")
+	}
+
 	r = validRegistration()
 	r.Compression = "invalid"
 	if ri.update(r) {
@@ -92,6 +114,50 @@ func (sender *dummyStruct) Transform(data []byte) []byte {
 	return data
 }
 
+// closingSender records whether it was closed
+type closingSender struct {
+	closed bool
+}
+
+func (s *closingSender) Send(data []byte, ctx context.Context) bool {
+	return true
+}
+
+func (s *closingSender) Close() {
+	s.closed = true
+}
+
+func TestRegistrationInfoUpdateClosesSender(t *testing.T) {
+	ri := newRegistrationInfo()
+	previous := &closingSender{}
+	ri.sender = previous
+
+	if !ri.update(validRegistration()) {
+		t.Fatal("This registration should be good")
+	}
+	if !previous.closed {
+		t.Fatal("Expected the sender replaced by the update to be closed")
+	}
+}
+
+func TestRegistrationInfoLoopClosesSender(t *testing.T) {
+	ri := newRegistrationInfo()
+	ri.update(validRegistration())
+	s := &closingSender{}
+	ri.sender = s
+
+	done := make(chan struct{})
+	go func() {
+		registrationLoop(ri)
+		close(done)
+	}()
+	ri.chRegistration <- nil
+	<-done
+	if !s.closed {
+		t.Fatal("Expected the sender to be closed once the registration terminates")
+	}
+}
+
 func TestRegistrationInfoEvent(t *testing.T) {
 	const (
 		dummyDev     = "dummyDev"
@@ -225,6 +291,66 @@ func TestUpdateRunningRegistrations(t *testing.T) {
 
 }
 
+func TestRegistrationInfoRenameKeepsQueue(t *testing.T) {
+	Configuration = &ConfigurationStruct{}
+	store, cleanup := newTestQueueStore(t)
+	defer cleanup()
+	queueStore = store
+	defer func() { queueStore = nil }()
+
+	r := validRegistration()
+	r.Name = "old"
+	ri := newRegistrationInfo()
+	if !ri.update(r) {
+		t.Fatal("This registration should be good")
+	}
+	ri.queue.push(queuedPayload("1"))
+
+	r.Name = "new"
+	if !ri.update(r) {
+		t.Fatal("This registration should be good")
+	}
+	if string(ri.queue.bucket) != "new" || ri.queue.size != 1 {
+		t.Fatalf("Expected the queued event to follow the renamed registration, got %d in %s",
+			ri.queue.size, ri.queue.bucket)
+	}
+}
+
+func TestUpdateRunningRegistrationsRename(t *testing.T) {
+	renamed := validRegistration()
+	renamed.ID = "id"
+	renamed.Name = "new"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(renamed)
+	}))
+	defer ts.Close()
+	u, _ := url.Parse(ts.URL)
+	port, _ := strconv.Atoi(u.Port())
+	Configuration = &ConfigurationStruct{Clients: map[string]config.ClientInfo{
+		"Export": {Protocol: u.Scheme, Host: u.Hostname(), Port: port},
+	}}
+
+	ri := newRegistrationInfo()
+	ri.id = "id"
+	running := map[string]*registrationInfo{"old": ri}
+	received := make(chan *export.Registration, 1)
+	go func() {
+		received <- <-ri.chRegistration
+	}()
+
+	err := updateRunningRegistrations(running, contract.NotifyUpdate{Name: "new",
+		Operation: contract.NotifyUpdateUpdate})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if running["new"] != ri || running["old"] != nil {
+		t.Fatal("Expected the running registration to be renamed")
+	}
+	if reg := <-received; reg.Name != "new" {
+		t.Fatalf("Expected the renamed registration to be sent, got %s", reg.Name)
+	}
+}
+
 func BenchmarkProcessEvent(b *testing.B) {
 	var Dummy = &dummyStruct{}
 
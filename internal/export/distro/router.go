@@ -17,11 +17,15 @@ import (
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/gorilla/mux"
 
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	"github.com/edgexfoundry/edgex-go/internal/pkg"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/correlation"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/telemetry"
 )
 
+// apiRegistrationMetricsRoute returns the delivery metrics of the running registrations
+const apiRegistrationMetricsRoute = clients.ApiMetricsRoute + "/registrations"
+
 // Test if the service is working
 func pingHandler(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set(clients.ContentType, clients.ContentTypeText)
@@ -65,14 +69,44 @@ func replyNotifyRegistrations(w http.ResponseWriter, r *http.Request) {
 	RefreshRegistrations(update)
 }
 
+// distroMetrics adds the delivery metrics of the running registrations to the system usage
+type distroMetrics struct {
+	telemetry.SystemUsage
+	Registrations []RegistrationMetrics
+}
+
 func metricsHandler(w http.ResponseWriter, _ *http.Request) {
-	s := telemetry.NewSystemUsage()
+	s := distroMetrics{
+		SystemUsage:   telemetry.NewSystemUsage(),
+		Registrations: registrationMetrics(),
+	}
 
 	pkg.Encode(s, w, LoggingClient)
 
 	return
 }
 
+func registrationMetricsHandler(w http.ResponseWriter, _ *http.Request) {
+	pkg.Encode(registrationMetrics(), w, LoggingClient)
+}
+
+func registrationMetricsByNameHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	m, ok := registrationMetricsByName(name)
+	if !ok {
+		LoggingClient.Error(fmt.Sprintf("No running registration named %s", name))
+		http.Error(w, "Registration not running: "+name, http.StatusNotFound)
+		return
+	}
+
+	// an unhealthy registration is reported as unavailable so that it can be probed
+	if !m.Healthy {
+		w.Header().Set(clients.ContentType, clients.ContentTypeJSON)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	pkg.Encode(m, w, LoggingClient)
+}
+
 func LoadRestRoutes() *mux.Router {
 	r := mux.NewRouter()
 
@@ -84,11 +118,17 @@ func LoadRestRoutes() *mux.Router {
 
 	// Metrics
 	r.HandleFunc(clients.ApiMetricsRoute, metricsHandler).Methods(http.MethodGet)
+	r.HandleFunc(apiRegistrationMetricsRoute, registrationMetricsHandler).Methods(http.MethodGet)
+	r.HandleFunc(apiRegistrationMetricsRoute+"/{name}", registrationMetricsByNameHandler).Methods(http.MethodGet)
 
 	// Version
 	r.HandleFunc(clients.ApiVersionRoute, pkg.VersionHandler).Methods(http.MethodGet)
 
 	r.HandleFunc(clients.ApiNotifyRegistrationRoute, replyNotifyRegistrations).Methods(http.MethodPut)
+	r.HandleFunc(export.ApiDryRunRoute, dryRunHandler).Methods(http.MethodPost)
+
+	// Schemas of the binary formats
+	r.HandleFunc(apiSchemaRoute+"/{format}", schemaHandler).Methods(http.MethodGet)
 
 	r.Use(correlation.ManageHeader)
 	r.Use(correlation.OnResponseComplete)
@@ -86,3 +86,50 @@ func TestReplyNotifyRegistrations(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistrationMetrics(t *testing.T) {
+	healthy := registerMetrics("healthy", nil)
+	defer unregisterMetrics("healthy", healthy)
+	unhealthy := registerMetrics("unhealthy", nil)
+	defer unregisterMetrics("unhealthy", unhealthy)
+	unhealthy.failed("failed to send", true)
+
+	var tests = []struct {
+		name   string
+		route  string
+		status int
+	}{
+		{"all", apiRegistrationMetricsRoute, http.StatusOK},
+		{"healthy", apiRegistrationMetricsRoute + "/healthy", http.StatusOK},
+		{"unhealthy", apiRegistrationMetricsRoute + "/unhealthy", http.StatusServiceUnavailable},
+		{"notRunning", apiRegistrationMetricsRoute + "/other", http.StatusNotFound},
+	}
+	ts := httptest.NewServer(LoadRestRoutes())
+	defer ts.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := http.Get(ts.URL + tt.route)
+			if err != nil {
+				t.Fatalf("Error getting metrics: %v", err)
+			}
+			defer response.Body.Close()
+			if response.StatusCode != tt.status {
+				t.Errorf("Returned status %d, should be %d", response.StatusCode, tt.status)
+			}
+		})
+	}
+
+	response, err := http.Get(ts.URL + clients.ApiMetricsRoute)
+	if err != nil {
+		t.Fatalf("Error getting metrics: %v", err)
+	}
+	defer response.Body.Close()
+	var metrics distroMetrics
+	if err = json.NewDecoder(response.Body).Decode(&metrics); err != nil {
+		t.Fatalf("Error decoding metrics: %v", err)
+	}
+	if len(metrics.Registrations) != 2 || metrics.Registrations[0].Name != "healthy" {
+		t.Errorf("Expected the metrics of the 2 registrations, got %v", metrics.Registrations)
+	}
+}
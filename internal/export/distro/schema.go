@@ -0,0 +1,81 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/gorilla/mux"
+)
+
+// SchemaFingerprintHeader is the HTTP header, and the Kafka record header, holding the fingerprint of the schema of
+// the payload. MQTT has no message headers: MQTT consumers read the schema and its fingerprint from apiSchemaRoute,
+// the Avro payloads also starting with the fingerprint of their schema as required by the Avro single object encoding.
+const SchemaFingerprintHeader = "X-Schema-Fingerprint"
+
+// apiSchemaRoute serves the published schemas of the binary formats by format
+const apiSchemaRoute = clients.ApiBase + "/schema"
+
+// schemaFormatter is implemented by the formats encoding the events with a published schema
+type schemaFormatter interface {
+	formatter
+	// Schema returns the published schema
+	Schema() string
+	// SchemaFingerprint identifies the schema, it changes whenever the schema does
+	SchemaFingerprint() string
+	// ContentType is the content type of the formatted events
+	ContentType() string
+}
+
+// schemaFormatters holds the formats with a published schema by format
+var schemaFormatters = map[string]schemaFormatter{
+	export.FormatProtobuf: protobufFormatter{},
+	export.FormatAvro:     avroFormatter{},
+}
+
+type schemaContext string
+
+const schemaFingerprintKey schemaContext = "schemaFingerprint"
+
+// applySchema sets the schema fingerprint of a message formatted with a schema, and its content type unless the
+// message is compressed or encrypted, the payload no longer being of the content type of the format then
+func (reg registrationInfo) applySchema(m *queuedMessage) {
+	if format, ok := reg.format.(schemaFormatter); ok {
+		if reg.compression == nil && reg.encrypt == nil {
+			m.ContentType = format.ContentType()
+		}
+		m.SchemaFingerprint = format.SchemaFingerprint()
+	}
+}
+
+// withSchema sets the content type and schema fingerprint of a message formatted with a schema in the context
+func withSchema(ctx context.Context, m queuedMessage) context.Context {
+	if m.SchemaFingerprint == "" {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, clients.ContentType, m.ContentType)
+	return context.WithValue(ctx, schemaFingerprintKey, m.SchemaFingerprint)
+}
+
+// schemaHandler replies with the published schema of a format, its fingerprint in the SchemaFingerprintHeader
+func schemaHandler(w http.ResponseWriter, r *http.Request) {
+	format := mux.Vars(r)["format"]
+	f, ok := schemaFormatters[format]
+	if !ok {
+		LoggingClient.Error(fmt.Sprintf("No schema for format %s", format))
+		http.Error(w, "No schema for format "+format, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set(clients.ContentType, clients.ContentTypeText)
+	w.Header().Set(SchemaFingerprintHeader, f.SchemaFingerprint())
+	w.Write([]byte(f.Schema()))
+}
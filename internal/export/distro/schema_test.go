@@ -0,0 +1,234 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	"github.com/edgexfoundry/go-mod-core-contracts/clients/logger"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+var schemaEvent = contract.Event{ID: "e1", Device: devID1, Created: 10, Origin: -5, Readings: []contract.Reading{
+	{Id: "r1", Device: devID1, Name: readingName1, Value: readingValue1, Origin: 1546300800000},
+	{Name: "image", BinaryValue: []byte{0, 1, 2}},
+	{},
+}}
+
+// protobufDecoder reads the fields of a protobuf message
+type protobufDecoder struct {
+	data []byte
+}
+
+func (d *protobufDecoder) varint(t *testing.T) uint64 {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		t.Fatalf("Invalid varint in %v", d.data)
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// fields returns the varint and length delimited fields of the message by field number
+func (d *protobufDecoder) fields(t *testing.T) (map[int]int64, map[int][][]byte) {
+	varints := make(map[int]int64)
+	delimited := make(map[int][][]byte)
+	for len(d.data) > 0 {
+		key := d.varint(t)
+		field := int(key >> 3)
+		switch key & 7 {
+		case protobufVarint:
+			varints[field] = int64(d.varint(t))
+		case protobufLengthDelimited:
+			n := d.varint(t)
+			delimited[field] = append(delimited[field], d.data[:n])
+			d.data = d.data[n:]
+		default:
+			t.Fatalf("Unexpected wire type of key %d", key)
+		}
+	}
+	return varints, delimited
+}
+
+func TestProtobufFormat(t *testing.T) {
+	out := protobufFormatter{}.Format(&schemaEvent)
+
+	varints, delimited := (&protobufDecoder{out}).fields(t)
+	if string(delimited[1][0]) != "e1" || string(delimited[3][0]) != devID1 {
+		t.Fatalf("Unexpected event strings %v", delimited)
+	}
+	if varints[4] != 10 || varints[6] != -5 {
+		t.Fatalf("Unexpected event numbers %v", varints)
+	}
+	if _, ok := varints[2]; ok {
+		t.Fatal("Expected default values to be omitted")
+	}
+	if len(delimited[7]) != 3 {
+		t.Fatalf("Expected 3 readings, got %d", len(delimited[7]))
+	}
+
+	rVarints, rDelimited := (&protobufDecoder{delimited[7][0]}).fields(t)
+	if string(rDelimited[7][0]) != readingName1 || string(rDelimited[8][0]) != readingValue1 ||
+		rVarints[4] != 1546300800000 {
+		t.Fatalf("Unexpected reading %v %v", rVarints, rDelimited)
+	}
+	_, rDelimited = (&protobufDecoder{delimited[7][1]}).fields(t)
+	if !bytes.Equal(rDelimited[9][0], []byte{0, 1, 2}) {
+		t.Fatalf("Unexpected binary value %v", rDelimited[9])
+	}
+	if len(delimited[7][2]) != 0 {
+		t.Fatalf("Expected an empty reading, got %v", delimited[7][2])
+	}
+}
+
+// avroDecoder reads Avro binary encoded values
+type avroDecoder struct {
+	data []byte
+}
+
+func (d *avroDecoder) long(t *testing.T) int64 {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		t.Fatalf("Invalid long in %v", d.data)
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *avroDecoder) bytes(t *testing.T) []byte {
+	n := d.long(t)
+	v := d.data[:n]
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *avroDecoder) string(t *testing.T) string {
+	return string(d.bytes(t))
+}
+
+func TestAvroFormat(t *testing.T) {
+	out := avroFormatter{}.Format(&schemaEvent)
+
+	if !bytes.Equal(out[:2], avroSingleObjectMarker) {
+		t.Fatalf("Expected the single object marker, got %v", out[:2])
+	}
+	if binary.LittleEndian.Uint64(out[2:10]) != avroFingerprint {
+		t.Fatal("Expected the fingerprint of the schema")
+	}
+
+	d := &avroDecoder{out[10:]}
+	event := contract.Event{ID: d.string(t), Pushed: d.long(t), Device: d.string(t), Created: d.long(t),
+		Modified: d.long(t), Origin: d.long(t)}
+	for n := d.long(t); n != 0; n = d.long(t) {
+		for i := int64(0); i < n; i++ {
+			r := contract.Reading{Id: d.string(t), Pushed: d.long(t), Created: d.long(t), Origin: d.long(t),
+				Modified: d.long(t), Device: d.string(t), Name: d.string(t), Value: d.string(t)}
+			if v := d.bytes(t); len(v) > 0 {
+				r.BinaryValue = v
+			}
+			event.Readings = append(event.Readings, r)
+		}
+	}
+	if len(d.data) != 0 {
+		t.Fatalf("Unexpected trailing data %v", d.data)
+	}
+	if !reflect.DeepEqual(event, schemaEvent) {
+		t.Fatalf("Expected %v, got %v", schemaEvent, event)
+	}
+}
+
+func TestAvroFormatNoReadings(t *testing.T) {
+	out := avroFormatter{}.Format(&contract.Event{})
+	// marker, fingerprint, 2 empty strings, 4 zero longs and the empty array
+	if len(out) != 2+8+2+4+1 {
+		t.Fatalf("Unexpected encoding %v", out)
+	}
+}
+
+func TestCRC64Avro(t *testing.T) {
+	// fingerprints of the Avro specification test suite
+	tests := map[string]uint64{
+		`"int"`:  0x7275d51a3f395c8f,
+		`"null"`: 0x63dd24e7cc258f8a,
+	}
+	for schema, expected := range tests {
+		if fp := crc64Avro([]byte(schema)); fp != expected {
+			t.Errorf("Expected fingerprint %x of %s, got %x", expected, schema, fp)
+		}
+	}
+}
+
+func TestAvroSchema(t *testing.T) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(avroSchema), &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+}
+
+func TestSchemaHandler(t *testing.T) {
+	LoggingClient = logger.NewMockClient()
+	r := LoadRestRoutes()
+
+	tests := []struct {
+		name        string
+		format      string
+		status      int
+		fingerprint string
+	}{
+		{"Protobuf", "PROTOBUF", http.StatusOK, protobufFingerprint},
+		{"Avro", "AVRO", http.StatusOK, avroFormatter{}.SchemaFingerprint()},
+		{"No schema", "JSON", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, apiSchemaRoute+"/"+tt.format, nil))
+			if rr.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, rr.Code)
+			}
+			if rr.Header().Get(SchemaFingerprintHeader) != tt.fingerprint {
+				t.Fatalf("Expected fingerprint '%s', got '%s'", tt.fingerprint, rr.Header().Get(SchemaFingerprintHeader))
+			}
+		})
+	}
+}
+
+func TestApplySchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		reg         registrationInfo
+		contentType string
+		fingerprint string
+	}{
+		{"Protobuf", registrationInfo{format: protobufFormatter{}}, protobufContentType, protobufFingerprint},
+		{"Avro", registrationInfo{format: avroFormatter{}}, avroContentType, avroFormatter{}.SchemaFingerprint()},
+		{"Compressed", registrationInfo{format: avroFormatter{}, compression: &gzipTransformer{}},
+			clients.ContentTypeJSON, avroFormatter{}.SchemaFingerprint()},
+		{"Encrypted", registrationInfo{format: protobufFormatter{}, encrypt: &aesEncryption{}}, clients.ContentTypeJSON,
+			protobufFingerprint},
+		{"No schema", registrationInfo{format: jsonFormatter{}}, clients.ContentTypeJSON, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := queuedMessage{ContentType: clients.ContentTypeJSON}
+			tt.reg.applySchema(&m)
+			if m.ContentType != tt.contentType {
+				t.Errorf("Expected content type '%s', got '%s'", tt.contentType, m.ContentType)
+			}
+			if m.SchemaFingerprint != tt.fingerprint {
+				t.Errorf("Expected fingerprint '%s', got '%s'", tt.fingerprint, m.SchemaFingerprint)
+			}
+		})
+	}
+}
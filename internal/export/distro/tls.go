@@ -0,0 +1,46 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/edgexfoundry/edgex-go/internal/export"
+)
+
+// newTLSConfig returns the TLS configuration of a client verifying the certificate of the server as configured by
+// info, and presenting the certificate when there is one
+func newTLSConfig(cert string, key string, info export.TLSInfo) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: info.SkipCertVerify,
+		ServerName:         info.ServerName,
+	}
+
+	if info.CACert != "" {
+		pem, err := ioutil.ReadFile(info.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %s", err.Error())
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA bundle %s", info.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cert != "" {
+		certificate, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed loading x509 data: %s", err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,106 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/internal/export"
+)
+
+// writeCACert writes a self-signed CA certificate to a PEM file of the directory
+func writeCACert(t *testing.T, dir string) string {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "broker CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	path := filepath.Join(dir, "ca.pem")
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	return path
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatalf("Failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	caCert := writeCACert(t, dir)
+	invalid := filepath.Join(dir, "invalid.pem")
+	if err := ioutil.WriteFile(invalid, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		info  export.TLSInfo
+		valid bool
+		roots bool
+	}{
+		{"System roots", export.TLSInfo{ServerName: "broker"}, true, false},
+		{"CA bundle", export.TLSInfo{CACert: caCert}, true, true},
+		{"Skip verification", export.TLSInfo{SkipCertVerify: true}, true, false},
+		{"Missing CA bundle", export.TLSInfo{CACert: filepath.Join(dir, "missing.pem")}, false, false},
+		{"Invalid CA bundle", export.TLSInfo{CACert: invalid}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := newTLSConfig("", "", tt.info)
+			if !tt.valid {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tlsConfig.InsecureSkipVerify != tt.info.SkipCertVerify {
+				t.Errorf("Expected InsecureSkipVerify %v", tt.info.SkipCertVerify)
+			}
+			if tlsConfig.ServerName != tt.info.ServerName {
+				t.Errorf("Expected server name '%s', got '%s'", tt.info.ServerName, tlsConfig.ServerName)
+			}
+			if (tlsConfig.RootCAs != nil) != tt.roots {
+				t.Errorf("Expected root CAs %v", tt.roots)
+			}
+		})
+	}
+}
+
+func TestTLSConfigInvalidCertificate(t *testing.T) {
+	if _, err := newTLSConfig("missing.crt", "missing.key", export.TLSInfo{}); err == nil {
+		t.Fatal("Expected an error for a missing client certificate")
+	}
+}
@@ -0,0 +1,160 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// unitConversion converts a value as value*scale + offset
+type unitConversion struct {
+	scale  float64
+	offset float64
+}
+
+// unitConversions holds the supported unit conversions, keyed by lower case source and target units
+var unitConversions = map[string]map[string]unitConversion{
+	"c":    {"f": {1.8, 32}, "k": {1, 273.15}},
+	"f":    {"c": {5.0 / 9, -32 * 5.0 / 9}, "k": {5.0 / 9, 273.15 - 32*5.0/9}},
+	"k":    {"c": {1, -273.15}, "f": {1.8, -459.67}},
+	"m":    {"ft": {1 / 0.3048, 0}, "km": {0.001, 0}},
+	"ft":   {"m": {0.3048, 0}},
+	"km":   {"m": {1000, 0}, "mi": {1 / 1.609344, 0}},
+	"mi":   {"km": {1.609344, 0}},
+	"pa":   {"kpa": {0.001, 0}, "bar": {0.00001, 0}, "psi": {1 / 6894.757, 0}},
+	"kpa":  {"pa": {1000, 0}, "bar": {0.01, 0}, "psi": {1 / 6.894757, 0}},
+	"bar":  {"pa": {100000, 0}, "kpa": {100, 0}, "psi": {14.503774, 0}},
+	"psi":  {"pa": {6894.757, 0}, "kpa": {6.894757, 0}, "bar": {1 / 14.503774, 0}},
+	"kg":   {"lb": {1 / 0.45359237, 0}, "g": {1000, 0}},
+	"g":    {"kg": {0.001, 0}},
+	"lb":   {"kg": {0.45359237, 0}},
+	"km/h": {"m/s": {1 / 3.6, 0}, "mph": {1 / 1.609344, 0}},
+	"m/s":  {"km/h": {3.6, 0}},
+	"mph":  {"km/h": {1.609344, 0}},
+}
+
+// readingTransform transforms the readings of the events of a registration before they are formatted
+type readingTransform struct {
+	readings map[string]export.ReadingTransformInfo
+	tags     []contract.Reading
+}
+
+// newReadingTransform checks the transformation configured for a registration
+func newReadingTransform(info export.TransformInfo) (eventTransformer, error) {
+	for name, r := range info.Readings {
+		if r.Decimals < 0 {
+			return nil, fmt.Errorf("negative Decimals for reading %s", name)
+		}
+	}
+
+	// tags are added in a stable order
+	keys := make([]string, 0, len(info.Tags))
+	for k := range info.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tags := make([]contract.Reading, len(keys))
+	for i, k := range keys {
+		tags[i] = contract.Reading{Name: k, Value: info.Tags[k]}
+	}
+
+	return readingTransform{readings: info.Readings, tags: tags}, nil
+}
+
+// TransformEvent returns a copy of the event with its numeric readings converted, scaled, offset and rounded, and
+// renamed. A reading whose unit could not be looked up is dropped rather than exported unconverted. The static tags
+// are appended as readings of the event.
+func (rt readingTransform) TransformEvent(event *contract.Event) *contract.Event {
+	auxEvent := *event
+	auxEvent.Readings = make([]contract.Reading, 0, len(event.Readings)+len(rt.tags))
+
+	for _, reading := range event.Readings {
+		if info, ok := rt.readings[reading.Name]; ok {
+			value, err := transformValue(reading.Name, reading.Value, info)
+			if err != nil {
+				LoggingClient.Error(fmt.Sprintf("reading %s of event %s dropped: %s", reading.Name, event.ID, err.Error()))
+				continue
+			}
+			reading.Value = value
+			if info.Rename != "" {
+				reading.Name = info.Rename
+			}
+		}
+		auxEvent.Readings = append(auxEvent.Readings, reading)
+	}
+
+	for _, tag := range rt.tags {
+		tag.Device = event.Device
+		tag.Origin = event.Origin
+		auxEvent.Readings = append(auxEvent.Readings, tag)
+	}
+	return &auxEvent
+}
+
+// transformValue applies the unit conversion, then the scale and offset and finally the rounding to a numeric value,
+// other values are returned unchanged. It fails when the unit of the value cannot be looked up.
+func transformValue(name string, value string, info export.ReadingTransformInfo) (string, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value, nil
+	}
+
+	if info.Unit != "" {
+		from, err := valueDescriptorUnit(name)
+		if err != nil {
+			return "", err
+		}
+		if !strings.EqualFold(from, info.Unit) {
+			conversion, ok := unitConversions[strings.ToLower(from)][strings.ToLower(info.Unit)]
+			if !ok {
+				LoggingClient.Warn(fmt.Sprintf("no conversion of reading %s from '%s' to '%s'", name, from, info.Unit))
+				return value, nil
+			}
+			v = v*conversion.scale + conversion.offset
+		}
+	}
+
+	if info.Scale != 0 {
+		v *= info.Scale
+	}
+	v += info.Offset
+
+	if info.Round {
+		p := math.Pow10(info.Decimals)
+		v = math.Round(v*p) / p
+		return strconv.FormatFloat(v, 'f', info.Decimals, 64), nil
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64), nil
+}
+
+// valueDescriptorUnit returns the UomLabel of the value descriptor of the reading, it is replaced in tests
+var valueDescriptorUnit = coreDataUnit
+
+var unitCache = newLookupCache()
+
+// coreDataUnit looks up the UomLabel of the value descriptor in core-data
+func coreDataUnit(name string) (string, error) {
+	unit, err := unitCache.get(name, func() (interface{}, error) {
+		vd, err := vdc.ValueDescriptorForName(name, context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return vd.UomLabel, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the unit of value descriptor %s: %s", name, err.Error())
+	}
+	return unit.(string), nil
+}
@@ -0,0 +1,120 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package distro
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+func TestTransformValue(t *testing.T) {
+	valueDescriptorUnit = func(name string) (string, error) {
+		if name == "flow" {
+			return "", errors.New("core-data unavailable")
+		}
+		return map[string]string{"temp": "C", "pressure": "bar"}[name], nil
+	}
+	defer func() { valueDescriptorUnit = coreDataUnit }()
+
+	tests := []struct {
+		name     string
+		reading  string
+		value    string
+		info     export.ReadingTransformInfo
+		expected string
+		fails    bool
+	}{
+		{"No transformation", "temp", "21.5", export.ReadingTransformInfo{}, "21.5", false},
+		{"Unit conversion", "temp", "100", export.ReadingTransformInfo{Unit: "F"}, "212", false},
+		{"Same unit", "temp", "21.5", export.ReadingTransformInfo{Unit: "c"}, "21.5", false},
+		{"Unknown conversion", "pressure", "2", export.ReadingTransformInfo{Unit: "C"}, "2", false},
+		{"Unknown unit", "humidity", "40", export.ReadingTransformInfo{Unit: "%"}, "40", false},
+		{"Scale and offset", "temp", "10", export.ReadingTransformInfo{Scale: 0.5, Offset: -1}, "4", false},
+		{"Round", "temp", "21.456", export.ReadingTransformInfo{Round: true, Decimals: 1}, "21.5", false},
+		{"Round to integer", "temp", "21.5", export.ReadingTransformInfo{Round: true}, "22", false},
+		{"Conversion then round", "pressure", "1.5", export.ReadingTransformInfo{Unit: "psi", Round: true, Decimals: 2}, "21.76", false},
+		{"Not a number", "state", "on", export.ReadingTransformInfo{Scale: 2}, "on", false},
+		{"Unit lookup failed", "flow", "3", export.ReadingTransformInfo{Unit: "l/s"}, "", true},
+		{"Unit lookup not needed", "flow", "3", export.ReadingTransformInfo{Scale: 2}, "6", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := transformValue(tt.reading, tt.value, tt.info)
+			if (err != nil) != tt.fails {
+				t.Fatalf("Expected failure %v, got %v", tt.fails, err)
+			}
+			if value != tt.expected {
+				t.Fatalf("Expected %s, got %s", tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestReadingTransform(t *testing.T) {
+	valueDescriptorUnit = func(name string) (string, error) { return "", nil }
+	defer func() { valueDescriptorUnit = coreDataUnit }()
+
+	rt, err := newReadingTransform(export.TransformInfo{
+		Readings: map[string]export.ReadingTransformInfo{"temp": {Rename: "temperature", Scale: 10}},
+		Tags:     map[string]string{"site": "plant-1", "line": "2"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	event := &contract.Event{Device: devID1, Origin: 10, Readings: []contract.Reading{
+		{Name: "temp", Value: "2.5"},
+		{Name: "state", Value: "on"},
+	}}
+	res := rt.TransformEvent(event)
+
+	expected := []contract.Reading{
+		{Name: "temperature", Value: "25"},
+		{Name: "state", Value: "on"},
+		{Device: devID1, Origin: 10, Name: "line", Value: "2"},
+		{Device: devID1, Origin: 10, Name: "site", Value: "plant-1"},
+	}
+	if len(res.Readings) != len(expected) {
+		t.Fatalf("Expected %d readings, got %v", len(expected), res.Readings)
+	}
+	for i, r := range expected {
+		if !reflect.DeepEqual(res.Readings[i], r) {
+			t.Errorf("Expected reading %v, got %v", r, res.Readings[i])
+		}
+	}
+	if event.Readings[0].Name != "temp" || len(event.Readings) != 2 {
+		t.Fatal("Expected the original event to be left unchanged")
+	}
+}
+
+func TestReadingTransformUnitUnknown(t *testing.T) {
+	valueDescriptorUnit = func(name string) (string, error) { return "", errors.New("core-data unavailable") }
+	defer func() { valueDescriptorUnit = coreDataUnit }()
+
+	rt, _ := newReadingTransform(export.TransformInfo{
+		Readings: map[string]export.ReadingTransformInfo{"temp": {Unit: "F"}},
+	})
+	event := &contract.Event{Device: devID1, Readings: []contract.Reading{
+		{Name: "temp", Value: "21.5"},
+		{Name: "state", Value: "on"},
+	}}
+	res := rt.TransformEvent(event)
+	if len(res.Readings) != 1 || res.Readings[0].Name != "state" {
+		t.Fatalf("Expected the reading which could not be converted to be dropped, got %v", res.Readings)
+	}
+}
+
+func TestReadingTransformInvalid(t *testing.T) {
+	info := export.TransformInfo{Readings: map[string]export.ReadingTransformInfo{"temp": {Round: true, Decimals: -1}}}
+	if _, err := newReadingTransform(info); err == nil {
+		t.Fatal("Expected an error for negative decimals")
+	}
+}
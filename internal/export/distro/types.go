@@ -20,16 +20,32 @@ type sender interface {
 	Send(data []byte, ctx context.Context) bool
 }
 
+// closer is implemented by the senders holding a connection, which is closed once the sender is no longer used
+type closer interface {
+	Close()
+}
+
 // Formatter - Format interface
 type formatter interface {
 	Format(event *contract.Event) []byte
 }
 
+// batchFormatter formats the events of a batch as one payload of its content type
+type batchFormatter interface {
+	FormatBatch(events []*contract.Event) []byte
+	ContentType() string
+}
+
 // Transformer - Transform interface
 type transformer interface {
 	Transform(data []byte) []byte
 }
 
+// eventTransformer transforms the events between filtering and formatting
+type eventTransformer interface {
+	TransformEvent(event *contract.Event) *contract.Event
+}
+
 // Filter - Filter interface
 type filterer interface {
 	Filter(event *contract.Event) (bool, *contract.Event)
@@ -0,0 +1,50 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"github.com/edgexfoundry/go-mod-core-contracts/clients"
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// ApiDryRunRoute is the route of the dry runs of registrations, served by both export-client and export-distro
+const ApiDryRunRoute = clients.ApiRegistrationRoute + "/dryrun"
+
+// DryRun asks for the event to be run through the filters, format, compression and encryption of the registration,
+// the registration does not need to exist. The sample event is run through it when the request holds no event.
+type DryRun struct {
+	Registration Registration   `json:"registration"`
+	Event        contract.Event `json:"event"`
+}
+
+// DryRunResult holds the payload the registration would send for the event of a dry run, none when the event is
+// filtered out
+type DryRunResult struct {
+	Filtered bool   `json:"filtered"`
+	Payload  []byte `json:"payload,omitempty"`
+}
+
+// SampleEvent returns the event the templates of the registrations are validated with, and the one run through the
+// registration of a dry run holding no event
+func SampleEvent() contract.Event {
+	return contract.Event{
+		ID:     "8b9b5c6e-3f1d-4a55-9c1e-5b7e2f0a6d3c",
+		Device: "sample-device",
+		Origin: 1546300800000,
+		Readings: []contract.Reading{
+			{Id: "0c6a4f1e-9d2b-4e8a-b5f3-7a1d9c2e4b60", Device: "sample-device", Name: "temperature",
+				Value: "21.5", Origin: 1546300800000},
+			{Id: "5e2d8b7a-1c4f-4a9e-8d6b-3f0e2a7c9b15", Device: "sample-device", Name: "humidity",
+				Value: "40", Origin: 1546300800000},
+		},
+	}
+}
+
+// IsEmpty tells whether the dry run holds no event, the sample event is then run through the registration
+func (d DryRun) IsEmpty() bool {
+	return d.Event.Device == "" && len(d.Event.Readings) == 0
+}
@@ -0,0 +1,119 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+// Kafka partition keys and acknowledgements of the KafkaInfo of a registration
+const (
+	KafkaPartitionKeyDevice  = "device"
+	KafkaPartitionKeyReading = "reading"
+
+	KafkaAcksAll    = "all"
+	KafkaAcksLeader = "leader"
+	KafkaAcksNone   = "none"
+)
+
+// Columns of the CSV format
+const (
+	CSVColumnDevice  = "device"
+	CSVColumnName    = "name"
+	CSVColumnValue   = "value"
+	CSVColumnOrigin  = "origin"
+	CSVColumnCreated = "created"
+)
+
+// Options are the settings of the registration export-distro adds to the contract
+type Options struct {
+	// Template is the text/template rendering the events of the FormatTemplate format
+	Template string `json:"template,omitempty"`
+	// FilterExpression filters the events with an expression over their device, origin and readings, such as
+	// 'device =~ "boiler-.*" && reading.temp > 80', after the device and value descriptor filters
+	FilterExpression string `json:"filterExpression,omitempty"`
+	// Transform transforms the events accepted by the filters before they are formatted, when not nil
+	Transform *TransformInfo `json:"transform,omitempty"`
+	// Kafka configures the producer of the KAFKA_TOPIC destination, the defaults being used when nil
+	Kafka *KafkaInfo `json:"kafka,omitempty"`
+	// Mqtt configures the client of the MQTT, AWS and IoT Core destinations, the defaults being used when nil
+	Mqtt *MqttInfo `json:"mqtt,omitempty"`
+	// Batch batches the events of the registration, which are sent one by one when nil
+	Batch *BatchInfo `json:"batch,omitempty"`
+	// CSV configures the rows of the CSV format, all the columns being written without header when nil
+	CSV *CSVInfo `json:"csv,omitempty"`
+}
+
+// TransformInfo configures the transformation of the events of a registration before they are formatted. Readings
+// holds the transformation of the readings keyed by reading name, Tags the static tags added as readings to every
+// event.
+type TransformInfo struct {
+	Readings map[string]ReadingTransformInfo `json:"readings,omitempty"`
+	Tags     map[string]string               `json:"tags,omitempty"`
+}
+
+// ReadingTransformInfo configures the transformation of a reading. A numeric value is converted from the UomLabel of
+// its value descriptor to Unit, multiplied by Scale when set, added Offset and rounded to Decimals decimals when Round
+// is set. The reading is renamed Rename when set.
+type ReadingTransformInfo struct {
+	Rename   string  `json:"rename,omitempty"`
+	Unit     string  `json:"unit,omitempty"`
+	Scale    float64 `json:"scale,omitempty"`
+	Offset   float64 `json:"offset,omitempty"`
+	Round    bool    `json:"round,omitempty"`
+	Decimals int     `json:"decimals,omitempty"`
+}
+
+// BatchInfo configures the batching of the events of a registration. The events are sent together once MaxEvents
+// events or MaxBytes bytes, as received, are pending, or MaxLatency (1s by default) after the first one. Only the
+// formats of BatchFormats can be batched.
+type BatchInfo struct {
+	MaxEvents  int    `json:"maxEvents,omitempty"`
+	MaxBytes   int    `json:"maxBytes,omitempty"`
+	MaxLatency string `json:"maxLatency,omitempty"`
+}
+
+// CSVInfo configures the CSV format of a registration. Each reading is written as a row of the Columns among device,
+// name, value, origin and created, all of them in this order when empty. Header writes a row naming the columns
+// before the readings.
+type CSVInfo struct {
+	Columns []string `json:"columns,omitempty"`
+	Header  bool     `json:"header,omitempty"`
+}
+
+// TLSInfo configures the verification of the certificate of the server a registration connects to over TLS. The
+// certificate is verified against the CA bundle CACert, the system roots when empty, and ServerName, the address of
+// the server when empty, unless SkipCertVerify is set.
+type TLSInfo struct {
+	CACert         string `json:"caCert,omitempty"`
+	ServerName     string `json:"serverName,omitempty"`
+	SkipCertVerify bool   `json:"skipCertVerify,omitempty"`
+}
+
+// KafkaInfo configures the producer of a Kafka registration. Brokers default to the address and port of the
+// addressable. Messages are keyed by the name of the device or of the first reading of their event as PartitionKey is
+// device or reading, unkeyed when empty. Acks is all (the default), leader or none. The SASL username and password are
+// read from SecretPath in the secret store when set, taken from the addressable otherwise.
+type KafkaInfo struct {
+	Brokers      []string `json:"brokers,omitempty"`
+	PartitionKey string   `json:"partitionKey,omitempty"`
+	Acks         string   `json:"acks,omitempty"`
+	SecretPath   string   `json:"secretPath,omitempty"`
+	TLSInfo
+}
+
+// MqttInfo configures the MQTT client of a registration sending to an MQTT broker or to Google IoT Core. Messages are
+// published with Qos (0, 1 or 2) and the Retained flag. The broker keeps the session of the client across connections
+// when PersistentSession is set. KeepAlive is the keepalive interval, 30s by default. The client reconnects when the
+// connection is lost if AutoReconnect is set, waiting up to MaxReconnectInterval (10m by default) between attempts. A
+// registration without MqttInfo publishes with QoS 0 over clean sessions without reconnecting, the certificate of the
+// broker being verified.
+type MqttInfo struct {
+	Qos                  byte   `json:"qos,omitempty"`
+	Retained             bool   `json:"retained,omitempty"`
+	PersistentSession    bool   `json:"persistentSession,omitempty"`
+	KeepAlive            string `json:"keepAlive,omitempty"`
+	AutoReconnect        bool   `json:"autoReconnect,omitempty"`
+	MaxReconnectInterval string `json:"maxReconnectInterval,omitempty"`
+	TLSInfo
+}
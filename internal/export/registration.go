@@ -0,0 +1,242 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// EncAesGcm is the AES-256-GCM encryption algorithm. The Key of the encryption details is the path of the secret
+// holding the key in the secret store of export-distro rather than the key itself, the InitVector is not used.
+const EncAesGcm = "AES256GCM"
+
+// FormatProtobuf and FormatAvro are the binary formats encoding the events with the published schemas of
+// export-distro
+const (
+	FormatProtobuf = "PROTOBUF"
+	FormatAvro     = "AVRO"
+)
+
+// DestKafka is the Kafka destination, the messages are produced to the topic of the addressable
+const DestKafka = "KAFKA_TOPIC"
+
+// Formats, Compressions, Algorithms and Destinations are the values of the registrations export-distro supports
+var (
+	Formats = []string{
+		contract.FormatJSON,
+		contract.FormatXML,
+		contract.FormatSerialized,
+		contract.FormatIoTCoreJSON,
+		contract.FormatAzureJSON,
+		contract.FormatAWSJSON,
+		contract.FormatThingsBoardJSON,
+		contract.FormatCSV,
+		contract.FormatNOOP,
+		FormatTemplate,
+		FormatProtobuf,
+		FormatAvro,
+	}
+	// BatchFormats are the formats whose events can be sent in batches
+	BatchFormats = []string{
+		contract.FormatJSON,
+		contract.FormatXML,
+		contract.FormatSerialized,
+		contract.FormatIoTCoreJSON,
+		contract.FormatCSV,
+	}
+	Compressions = []string{contract.CompNone, contract.CompGzip, contract.CompZip}
+	Algorithms   = []string{contract.EncNone, contract.EncAes, EncAesGcm}
+	Destinations = []string{
+		contract.DestMQTT,
+		contract.DestIotCoreMQTT,
+		contract.DestAzureMQTT,
+		contract.DestRest,
+		contract.DestXMPP,
+		contract.DestAWSMQTT,
+		contract.DestZMQ,
+		DestKafka,
+	}
+)
+
+// Registration is the registration stored by export-client and run by export-distro. Decoding it does not go
+// through the validation of the contract, which rejects the format, encryption and destination values export-distro
+// adds, the registration being validated by ValidateRegistration instead.
+type Registration struct {
+	contract.Registration
+	Options
+}
+
+// registrationAlias has the fields of the contract registration without its validating UnmarshalJSON
+type registrationAlias contract.Registration
+
+// UnmarshalJSON decodes the registration and its options without validating them
+func (r *Registration) UnmarshalJSON(data []byte) error {
+	var alias registrationAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+	var options Options
+	if err := json.Unmarshal(data, &options); err != nil {
+		return err
+	}
+	r.Registration = contract.Registration(alias)
+	r.Options = options
+	return nil
+}
+
+// Validate validates the registration with ValidateRegistration rather than with the validation of the contract
+func (r Registration) Validate() (bool, error) {
+	return ValidateRegistration(r)
+}
+
+// ValidateRegistration validates the registration: it requires a name and an addressable, rejects the format,
+// compression, encryption and destination export-distro cannot build, and checks the options of those which have
+// some. It does not go through the validation of the contract, which knows none of the values export-distro adds.
+func ValidateRegistration(r Registration) (bool, error) {
+	if err := validateRequired(r.Registration); err != nil {
+		return false, err
+	}
+	if err := validateSupported(r.Registration); err != nil {
+		return false, err
+	}
+	if r.Format == FormatTemplate {
+		if err := validateTemplate(r.Template); err != nil {
+			return false, err
+		}
+	}
+	if r.Destination == DestKafka {
+		if r.Addressable.Topic == "" {
+			return false, errors.New("a topic is required by the " + DestKafka + " destination")
+		}
+		if err := validateKafka(r.Kafka); err != nil {
+			return false, err
+		}
+	}
+	if r.Mqtt != nil && r.Mqtt.Qos > 2 {
+		return false, fmt.Errorf("mqtt QoS not supported: %d", r.Mqtt.Qos)
+	}
+	if err := validateBatch(r.Batch, r.Format); err != nil {
+		return false, err
+	}
+	if err := validateCSV(r.CSV); err != nil {
+		return false, err
+	}
+	if r.Encryption.Algo == EncAesGcm && r.Encryption.Key == "" {
+		return false, errors.New("the path of the key secret is required by " + EncAesGcm + " encryption")
+	}
+	return true, nil
+}
+
+// validateRequired checks the registration has a name, an addressable with an id or a name, and no empty id in its
+// filter
+func validateRequired(reg contract.Registration) error {
+	if reg.Name == "" {
+		return errors.New("name is required")
+	}
+	if reg.Addressable.Id == "" && reg.Addressable.Name == "" {
+		return errors.New("an addressable with an id or a name is required")
+	}
+	for _, id := range reg.Filter.DeviceIDs {
+		if id == "" {
+			return errors.New("empty device in the filter")
+		}
+	}
+	for _, id := range reg.Filter.ValueDescriptorIDs {
+		if id == "" {
+			return errors.New("empty value descriptor in the filter")
+		}
+	}
+	return nil
+}
+
+// validateSupported checks export-distro supports the format, compression, encryption and destination of the
+// registration, no compression and no encryption being the default
+func validateSupported(reg contract.Registration) error {
+	if !supported(Formats, reg.Format) {
+		return errors.New("format not supported: " + reg.Format)
+	}
+	if reg.Compression != "" && !supported(Compressions, reg.Compression) {
+		return errors.New("compression not supported: " + reg.Compression)
+	}
+	if reg.Encryption.Algo != "" && !supported(Algorithms, reg.Encryption.Algo) {
+		return errors.New("encryption not supported: " + reg.Encryption.Algo)
+	}
+	if !supported(Destinations, reg.Destination) {
+		return errors.New("destination not supported: " + reg.Destination)
+	}
+	return nil
+}
+
+// validateKafka checks the partition key and acknowledgements of the Kafka options, when there are some
+func validateKafka(info *KafkaInfo) error {
+	if info == nil {
+		return nil
+	}
+	switch info.PartitionKey {
+	case "", KafkaPartitionKeyDevice, KafkaPartitionKeyReading:
+	default:
+		return errors.New("kafka partition key not supported: " + info.PartitionKey)
+	}
+	switch info.Acks {
+	case "", KafkaAcksAll, KafkaAcksLeader, KafkaAcksNone:
+	default:
+		return errors.New("kafka acks not supported: " + info.Acks)
+	}
+	return nil
+}
+
+// validateBatch checks the format can be batched and the limits of the batch options, when there are some
+func validateBatch(info *BatchInfo, format string) error {
+	if info == nil {
+		return nil
+	}
+	if !supported(BatchFormats, format) {
+		return errors.New("format cannot be batched: " + format)
+	}
+	if info.MaxEvents < 0 || info.MaxBytes < 0 {
+		return errors.New("batch limits cannot be negative")
+	}
+	if info.MaxLatency != "" {
+		latency, err := time.ParseDuration(info.MaxLatency)
+		if err != nil {
+			return fmt.Errorf("batch latency not valid: %s", err.Error())
+		}
+		if latency <= 0 {
+			return errors.New("batch latency must be positive: " + info.MaxLatency)
+		}
+	}
+	return nil
+}
+
+// validateCSV checks the columns of the CSV options, when there are some
+func validateCSV(info *CSVInfo) error {
+	if info == nil {
+		return nil
+	}
+	for _, c := range info.Columns {
+		switch c {
+		case CSVColumnDevice, CSVColumnName, CSVColumnValue, CSVColumnOrigin, CSVColumnCreated:
+		default:
+			return errors.New("CSV column not supported: " + c)
+		}
+	}
+	return nil
+}
+
+func supported(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -7,6 +7,8 @@
 package export
 
 import (
+	"encoding/json"
+
 	"github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/google/uuid"
 	"testing"
@@ -63,3 +65,289 @@ func TestRegistrationValid(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRegistrationAesGcm(t *testing.T) {
+	var tests = []struct {
+		name       string
+		encryption models.EncryptionDetails
+		valid      bool
+	}{
+		{"aesGcm", models.EncryptionDetails{Algo: EncAesGcm, Key: "export/reg"}, true},
+		{"aesGcmWithoutKey", models.EncryptionDetails{Algo: EncAesGcm}, false},
+		{"aes", models.EncryptionDetails{Algo: models.EncAes}, true},
+		{"wrongEncryption", models.EncryptionDetails{Algo: "INVALID"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := models.Registration{
+				Name:        "reg",
+				Addressable: models.Addressable{Id: uuid.New().String(), Name: "Test Addressable"},
+				Compression: models.CompNone,
+				Format:      models.FormatJSON,
+				Destination: models.DestMQTT,
+				Encryption:  tt.encryption,
+			}
+			if valid, err := ValidateRegistration(Registration{Registration: r}); valid != tt.valid {
+				t.Errorf("ValidateRegistration should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestValidateRegistrationKafka(t *testing.T) {
+	var tests = []struct {
+		name        string
+		destination string
+		topic       string
+		kafka       *KafkaInfo
+		valid       bool
+	}{
+		{"kafka", DestKafka, "events", nil, true},
+		{"kafkaWithoutTopic", DestKafka, "", nil, false},
+		{"wrongDestination", "INVALID", "events", nil, false},
+		{"kafkaOptions", DestKafka, "events", &KafkaInfo{PartitionKey: KafkaPartitionKeyDevice, Acks: KafkaAcksNone},
+			true},
+		{"invalidPartitionKey", DestKafka, "events", &KafkaInfo{PartitionKey: "origin"}, false},
+		{"invalidAcks", DestKafka, "events", &KafkaInfo{Acks: "some"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := models.Registration{
+				Name:        "reg",
+				Addressable: models.Addressable{Id: uuid.New().String(), Name: "Test Addressable", Topic: tt.topic},
+				Compression: models.CompNone,
+				Format:      models.FormatJSON,
+				Destination: tt.destination,
+				Encryption:  models.EncryptionDetails{Algo: models.EncNone},
+			}
+			reg := Registration{Registration: r, Options: Options{Kafka: tt.kafka}}
+			if valid, err := ValidateRegistration(reg); valid != tt.valid {
+				t.Errorf("ValidateRegistration should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestValidateRegistrationMqtt(t *testing.T) {
+	var tests = []struct {
+		name  string
+		mqtt  *MqttInfo
+		valid bool
+	}{
+		{"noOptions", nil, true},
+		{"options", &MqttInfo{Qos: 2, AutoReconnect: true, TLSInfo: TLSInfo{ServerName: "broker"}}, true},
+		{"invalidQos", &MqttInfo{Qos: 3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := models.Registration{
+				Name:        "reg",
+				Addressable: models.Addressable{Id: uuid.New().String(), Name: "Test Addressable", Topic: "events"},
+				Compression: models.CompNone,
+				Format:      models.FormatJSON,
+				Destination: models.DestMQTT,
+				Encryption:  models.EncryptionDetails{Algo: models.EncNone},
+			}
+			reg := Registration{Registration: r, Options: Options{Mqtt: tt.mqtt}}
+			if valid, err := ValidateRegistration(reg); valid != tt.valid {
+				t.Errorf("ValidateRegistration should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestValidateRegistrationBatch(t *testing.T) {
+	var tests = []struct {
+		name   string
+		format string
+		batch  *BatchInfo
+		valid  bool
+	}{
+		{"noBatch", models.FormatJSON, nil, true},
+		{"batch", models.FormatCSV, &BatchInfo{MaxEvents: 100, MaxBytes: 65536, MaxLatency: "500ms"}, true},
+		{"defaultLatency", models.FormatXML, &BatchInfo{MaxEvents: 10}, true},
+		{"formatNotBatched", FormatTemplate, &BatchInfo{MaxEvents: 10}, false},
+		{"negativeEvents", models.FormatJSON, &BatchInfo{MaxEvents: -1}, false},
+		{"invalidLatency", models.FormatJSON, &BatchInfo{MaxLatency: "soon"}, false},
+		{"zeroLatency", models.FormatJSON, &BatchInfo{MaxLatency: "0s"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := models.Registration{
+				Name:        "reg",
+				Addressable: models.Addressable{Id: uuid.New().String(), Name: "Test Addressable"},
+				Compression: models.CompNone,
+				Format:      tt.format,
+				Destination: models.DestRest,
+				Encryption:  models.EncryptionDetails{Algo: models.EncNone},
+			}
+			reg := Registration{Registration: r, Options: Options{Template: "{{json .Device}}", Batch: tt.batch}}
+			if valid, err := ValidateRegistration(reg); valid != tt.valid {
+				t.Errorf("ValidateRegistration should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestValidateRegistrationCSV(t *testing.T) {
+	var tests = []struct {
+		name  string
+		csv   *CSVInfo
+		valid bool
+	}{
+		{"noOptions", nil, true},
+		{"columns", &CSVInfo{Columns: []string{CSVColumnDevice, CSVColumnValue}, Header: true}, true},
+		{"defaultColumns", &CSVInfo{Header: true}, true},
+		{"unknownColumn", &CSVInfo{Columns: []string{CSVColumnName, "unit"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := models.Registration{
+				Name:        "reg",
+				Addressable: models.Addressable{Id: uuid.New().String(), Name: "Test Addressable"},
+				Format:      models.FormatCSV,
+				Destination: models.DestRest,
+			}
+			reg := Registration{Registration: r, Options: Options{CSV: tt.csv}}
+			if valid, err := ValidateRegistration(reg); valid != tt.valid {
+				t.Errorf("ValidateRegistration should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestValidateRegistrationTemplate(t *testing.T) {
+	var tests = []struct {
+		name     string
+		format   string
+		template string
+		valid    bool
+	}{
+		{"template", FormatTemplate, `{"device":{{json .Device}},"value":{{(reading . "temp").Value}}}`, true},
+		{"emptyTemplate", FormatTemplate, "", true},
+		{"indexTemplate", FormatTemplate, `{"first":{{json (index .Readings 0).Value}}}`, true},
+		{"rangeTemplate", FormatTemplate, `[{{range $i, $r := .Readings}}{{if $i}},{{end}}{{json $r.Name}}{{end}}]`, true},
+		{"unknownReadingField", FormatTemplate, "{{(index .Readings 0).Unit}}", false},
+		{"unknownFunction", FormatTemplate, "{{device .}}", false},
+		{"unknownField", FormatTemplate, "{{.Name}}", false},
+		{"unterminated", FormatTemplate, "{{.Device", false},
+		{"templateText", FormatTemplate + ":{{json .Device}}", "", false},
+		{"json", models.FormatJSON, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Registration{Registration: models.Registration{
+				Name:        "reg",
+				Addressable: models.Addressable{Id: uuid.New().String(), Name: "Test Addressable"},
+				Compression: models.CompNone,
+				Format:      tt.format,
+				Destination: models.DestMQTT,
+				Encryption:  models.EncryptionDetails{Algo: models.EncNone},
+			}}
+			r.Template = tt.template
+			if valid, err := ValidateRegistration(r); valid != tt.valid {
+				t.Errorf("ValidateRegistration should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestValidateRegistrationRequired(t *testing.T) {
+	valid := func() models.Registration {
+		return models.Registration{
+			Name:        "reg",
+			Addressable: models.Addressable{Name: "Test Addressable"},
+			Format:      models.FormatJSON,
+			Destination: models.DestXMPP,
+			Filter:      models.Filter{DeviceIDs: []string{"boiler"}, ValueDescriptorIDs: []string{"temp"}},
+		}
+	}
+	var tests = []struct {
+		name   string
+		update func(r *models.Registration)
+		valid  bool
+	}{
+		{"valid", func(r *models.Registration) {}, true},
+		{"noName", func(r *models.Registration) { r.Name = "" }, false},
+		{"noAddressable", func(r *models.Registration) { r.Addressable = models.Addressable{} }, false},
+		{"addressableId", func(r *models.Registration) { r.Addressable = models.Addressable{Id: uuid.New().String()} }, true},
+		{"emptyDevice", func(r *models.Registration) { r.Filter.DeviceIDs = []string{"boiler", ""} }, false},
+		{"emptyValueDescriptor", func(r *models.Registration) { r.Filter.ValueDescriptorIDs = []string{""} }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid()
+			tt.update(&r)
+			if valid, err := ValidateRegistration(Registration{Registration: r}); valid != tt.valid {
+				t.Errorf("ValidateRegistration should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestValidateRegistrationSupported(t *testing.T) {
+	var tests = []struct {
+		name        string
+		format      string
+		compression string
+		algo        string
+		destination string
+		valid       bool
+	}{
+		{"supported", models.FormatCSV, models.CompGzip, EncAesGcm, models.DestZMQ, true},
+		{"defaults", models.FormatJSON, "", "", models.DestRest, true},
+		{"protobuf", FormatProtobuf, models.CompNone, models.EncNone, DestKafka, true},
+		{"avro", FormatAvro, models.CompGzip, models.EncNone, models.DestRest, true},
+		{"wrongFormat", "YAML", models.CompNone, models.EncNone, models.DestMQTT, false},
+		{"emptyFormat", "", models.CompNone, models.EncNone, models.DestMQTT, false},
+		{"wrongCompression", models.FormatJSON, "LZ4", models.EncNone, models.DestMQTT, false},
+		{"wrongEncryption", models.FormatJSON, models.CompNone, "DES", models.DestMQTT, false},
+		{"wrongDestination", models.FormatJSON, models.CompNone, models.EncNone, "AMQP_QUEUE", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := models.Registration{
+				Name:        "reg",
+				Addressable: models.Addressable{Id: uuid.New().String(), Name: "Test Addressable", Topic: "events"},
+				Compression: tt.compression,
+				Format:      tt.format,
+				Destination: tt.destination,
+				Encryption:  models.EncryptionDetails{Algo: tt.algo, Key: "export/reg"},
+			}
+			if valid, err := ValidateRegistration(Registration{Registration: r}); valid != tt.valid {
+				t.Errorf("ValidateRegistration should return %v instead of %v, err: %v", tt.valid, valid, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationUnmarshal(t *testing.T) {
+	data := `{"name":"reg","addressable":{"name":"Test Addressable","topic":"events"},"format":"TEMPLATE",` +
+		`"template":"{{json .Device}}","destination":"KAFKA_TOPIC",` +
+		`"encryption":{"encryptionAlgorithm":"AES256GCM","encryptionKey":"export/reg"},` +
+		`"kafka":{"brokers":["kafka:9092"],"acks":"leader","caCert":"/etc/kafka/ca.pem","serverName":"kafka"}}`
+
+	var r Registration
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatalf("Registration should be decoded: %v", err)
+	}
+	if r.Format != FormatTemplate || r.Template != "{{json .Device}}" || r.Destination != DestKafka ||
+		r.Encryption.Algo != EncAesGcm {
+		t.Errorf("Unexpected registration %v", r)
+	}
+	if r.Kafka == nil || len(r.Kafka.Brokers) != 1 || r.Kafka.Acks != KafkaAcksLeader ||
+		r.Kafka.CACert != "/etc/kafka/ca.pem" || r.Kafka.ServerName != "kafka" || r.Kafka.SkipCertVerify {
+		t.Errorf("Unexpected kafka options %v", r.Kafka)
+	}
+	if valid, err := r.Validate(); !valid {
+		t.Errorf("Registration should be valid: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+//
+// Copyright (c) 2019 Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"text/template"
+	"time"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+)
+
+// FormatTemplate is the format rendering the events through a text/template. The template text is held by the
+// Template of the registration, e.g. {"device":{{json .Device}}}
+const FormatTemplate = "TEMPLATE"
+
+// templateFuncs are the helper functions available to the templates:
+//   - timestamp formats milliseconds since the epoch as RFC 3339, or with the given layout
+//   - now returns the current time in milliseconds since the epoch
+//   - json encodes a value as JSON, escape escapes a string to be embedded in a JSON string
+//   - reading returns the reading of the event with the given name, hasReading whether there is one
+var templateFuncs = template.FuncMap{
+	"timestamp": func(ms int64, layout ...string) string {
+		t := time.Unix(0, ms*int64(time.Millisecond)).UTC()
+		if len(layout) > 0 {
+			return t.Format(layout[0])
+		}
+		return t.Format(time.RFC3339Nano)
+	},
+	"now": func() int64 {
+		return time.Now().UnixNano() / int64(time.Millisecond)
+	},
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"escape": func(s string) string {
+		b, _ := json.Marshal(s)
+		return string(b[1 : len(b)-1])
+	},
+	"reading": func(event *contract.Event, name string) contract.Reading {
+		for _, r := range event.Readings {
+			if r.Name == name {
+				return r
+			}
+		}
+		return contract.Reading{}
+	},
+	"hasReading": func(event *contract.Event, name string) bool {
+		for _, r := range event.Readings {
+			if r.Name == name {
+				return true
+			}
+		}
+		return false
+	},
+}
+
+// NewEventTemplate parses the template text of a registration. The template is executed with the *contract.Event
+// to format.
+func NewEventTemplate(text string) (*template.Template, error) {
+	return template.New(FormatTemplate).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// validateTemplate parses the template and executes it with the sample event, rejecting the references to unknown
+// fields
+func validateTemplate(text string) error {
+	t, err := NewEventTemplate(text)
+	if err != nil {
+		return fmt.Errorf("invalid template: %s", err.Error())
+	}
+	sample := SampleEvent()
+	if err := t.Execute(ioutil.Discard, &sample); err != nil {
+		return fmt.Errorf("invalid template: %s", err.Error())
+	}
+	return nil
+}
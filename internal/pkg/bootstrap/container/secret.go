@@ -17,6 +17,7 @@ package container
 import (
 	"github.com/edgexfoundry/go-mod-secrets/pkg"
 
+	"github.com/edgexfoundry/edgex-go/internal/pkg/bootstrap/interfaces"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/di"
 )
 
@@ -27,3 +28,11 @@ var SecretClientName = di.TypeInstanceToName((*pkg.SecretClient)(nil))
 func SecretClientFrom(get di.Get) pkg.SecretClient {
 	return get(SecretClientName).(pkg.SecretClient)
 }
+
+// SecretProviderName contains the name of the interfaces.SecretProvider implementation in the DIC.
+var SecretProviderName = di.TypeInstanceToName((*interfaces.SecretProvider)(nil))
+
+// SecretProviderFrom helper function queries the DIC and returns the interfaces.SecretProvider implementation.
+func SecretProviderFrom(get di.Get) interfaces.SecretProvider {
+	return get(SecretProviderName).(interfaces.SecretProvider)
+}
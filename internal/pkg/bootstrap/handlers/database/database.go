@@ -25,6 +25,7 @@ import (
 	"github.com/edgexfoundry/edgex-go/internal/pkg/bootstrap/startup"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/embedded"
 	dbInterfaces "github.com/edgexfoundry/edgex-go/internal/pkg/db/interfaces"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/mongo"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/redis"
@@ -87,6 +88,14 @@ func (d Database) newDBClient(loggingClient logger.LoggingClient, credentials co
 				loggingClient)
 		}
 		return redis.NewClient(db.Configuration{Host: databaseInfo.Host, Port: databaseInfo.Port}, loggingClient)
+	case db.EmbeddedDB:
+		// For the embedded database Host is the directory holding the data files, each held open by a single service.
+		return embedded.NewClient(
+			db.Configuration{
+				Host:         databaseInfo.Host,
+				Timeout:      databaseInfo.Timeout,
+				DatabaseName: databaseInfo.Name,
+			})
 	default:
 		return nil, db.ErrUnsupportedDatabase
 	}
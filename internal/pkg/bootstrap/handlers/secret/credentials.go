@@ -15,15 +15,17 @@
 package secret
 
 import (
+	"errors"
+
 	"github.com/edgexfoundry/edgex-go/internal/pkg/config"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 )
 
 func (s *SecretProvider) GetDatabaseCredentials(database config.Database) (config.Credentials, error) {
-	// If security is disabled or the database is Redis then we are to use the credentials supplied by the
-	// configuration. The reason we do this for Redis is because Redis does not have an authentication nor an
-	// authorization mechanism.
-	if !s.isSecurityEnabled() || database.Type == db.RedisDB {
+	// If security is disabled or the database is Redis or embedded then we are to use the credentials supplied
+	// by the configuration. The reason we do this for Redis is because Redis does not have an authentication nor
+	// an authorization mechanism. The embedded database is a local file protected by file system permissions.
+	if !s.isSecurityEnabled() || database.Type == db.RedisDB || database.Type == db.EmbeddedDB {
 		return config.Credentials{
 			Username: database.Username,
 			Password: database.Password,
@@ -41,3 +43,12 @@ func (s *SecretProvider) GetDatabaseCredentials(database config.Database) (confi
 	}, nil
 
 }
+
+// GetSecrets retrieves the secrets stored at the path. Unlike database credentials, these secrets have no fallback in
+// the configuration so an error is returned when security is disabled.
+func (s *SecretProvider) GetSecrets(path string, keys ...string) (map[string]string, error) {
+	if !s.isSecurityEnabled() {
+		return nil, errors.New("secret store is disabled")
+	}
+	return s.secretClient.GetSecrets(path, keys...)
+}
@@ -0,0 +1,21 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package interfaces
+
+// SecretProvider interface provides an abstraction for obtaining secrets other than database credentials.
+type SecretProvider interface {
+	// GetSecrets retrieves the secrets stored at the path, relative to the secret store path of the service.
+	GetSecrets(path string, keys ...string) (map[string]string, error)
+}
@@ -24,6 +24,7 @@ type Event struct {
 	Bytes         []byte // This will NOT be marshaled via the JSON below. It is only populated and read as an instance member.
 	CorrelationId string
 	Checksum      string
+	Flags         map[string]string // The validation rule violations the readings were accepted with, by reading name
 	contract.Event
 }
 
@@ -50,6 +51,7 @@ func (e Event) MarshalJSON() ([]byte, error) {
 	test := struct {
 		CorrelationId *string            `json:"correlation-id,omitempty"`
 		Checksum      *string            `json:"checksum,omitempty"`
+		Flags         map[string]string  `json:"flags,omitempty"`
 		ID            *string            `json:"id,omitempty"`
 		Pushed        int64              `json:"pushed,omitempty"`
 		Device        *string            `json:"device,omitempty"` // Device identifier (name or id)
@@ -58,6 +60,7 @@ func (e Event) MarshalJSON() ([]byte, error) {
 		Origin        int64              `json:"origin,omitempty"`
 		Readings      []contract.Reading `json:"readings,omitempty"` // List of readings
 	}{
+		Flags:    e.Flags,
 		Pushed:   e.Pushed,
 		Created:  e.Created,
 		Modified: e.Modified,
@@ -0,0 +1,53 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package db
+
+// ReadingBucket holds the statistics of the numeric readings created within one time bucket of an
+// aggregation. Readings whose value is not a finite number (NaN or infinite) are not part of any bucket.
+type ReadingBucket struct {
+	// Start of the bucket, the bucket covers [Start, Start + width)
+	Start int64
+	Count int
+	Sum   float64
+	Min   float64
+	Max   float64
+	// First and Last are the values of the oldest and the most recent reading of the bucket
+	First float64
+	Last  float64
+}
+
+// Add accumulates a value into the bucket. Values must be added in creation order.
+func (b *ReadingBucket) Add(value float64) {
+	if b.Count == 0 {
+		b.Min = value
+		b.Max = value
+		b.First = value
+	}
+	if value < b.Min {
+		b.Min = value
+	}
+	if value > b.Max {
+		b.Max = value
+	}
+	b.Sum += value
+	b.Last = value
+	b.Count++
+}
+
+// BucketStart returns the start of the bucket holding a reading created at the given time when
+// buckets of the given width are laid out from start.
+func BucketStart(created int64, start int64, width int64) int64 {
+	return created - (created-start)%width
+}
@@ -21,8 +21,9 @@ import (
 
 const (
 	// Databases
-	MongoDB = "mongodb"
-	RedisDB = "redisdb"
+	MongoDB    = "mongodb"
+	RedisDB    = "redisdb"
+	EmbeddedDB = "embedded"
 
 	// Data
 	EventsCollection          = "event"
@@ -46,6 +47,9 @@ const (
 	Interval         = "interval"
 	IntervalAction   = "intervalAction"
 
+	// Command
+	CommandAudit = "commandAudit"
+
 	// Notification
 	Notification = "notification"
 	Subscription = "subscription"
@@ -0,0 +1,202 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Package embedded implements the DBClient interfaces on top of an on-disk key/value store that runs
+// inside the service process, so that no separate database daemon is required.
+package embedded
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// fileExtension is appended to the configured database name to form the name of the data file.
+const fileExtension = ".db"
+
+// collections lists every bucket created when the data file is initialized.
+var collections = []string{
+	db.EventsCollection, db.ReadingsCollection, db.ValueDescriptorCollection,
+	db.ExportCollection,
+	db.Addressable, db.Command, db.DeviceService, db.DeviceReport, db.DeviceProfile, db.Device, db.ProvisionWatcher,
+	db.Notification, db.Subscription, db.Transmission,
+	db.Interval, db.IntervalAction,
+	db.CommandAudit,
+}
+
+func init() {
+	// Device.Location is declared as interface{} and is populated from JSON request bodies.
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+// Client represents an embedded database client
+type Client struct {
+	store *bolt.DB
+}
+
+// NewClient returns a pointer to an embedded database client. The data file is named after
+// config.DatabaseName and is created inside the directory given by config.Host.
+//
+// The data file is held open, and locked, until CloseSession is called: it cannot be used by two
+// services at once, e.g. core-metadata and core-command, which then need a database server.
+func NewClient(config db.Configuration) (*Client, error) {
+	if config.DatabaseName == "" {
+		return nil, db.ErrNameEmpty
+	}
+
+	if config.Host != "" {
+		if err := os.MkdirAll(config.Host, 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	path := filepath.Join(config.Host, config.DatabaseName+fileExtension)
+	store, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Duration(config.Timeout) * time.Millisecond})
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{store: store}
+
+	err = c.update(func(tx *bolt.Tx) error {
+		for _, col := range collections {
+			if _, err := tx.CreateBucketIfNotExists([]byte(col)); err != nil {
+				return err
+			}
+		}
+		return createEventIndexes(tx)
+	})
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// CloseSession closes the data file
+func (c *Client) CloseSession() {
+	c.store.Close()
+}
+
+// view runs fn inside a read-only transaction.
+func (c *Client) view(fn func(tx *bolt.Tx) error) error {
+	return c.store.View(fn)
+}
+
+// update runs fn inside a read-write transaction. All changes are rolled back if fn returns an error.
+func (c *Client) update(fn func(tx *bolt.Tx) error) error {
+	return c.store.Update(fn)
+}
+
+// Objects are gob encoded rather than JSON encoded so that the validation performed by the contract
+// models' UnmarshalJSON does not reject partially populated records that were accepted on write.
+func marshalObject(in interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(in)
+	return buf.Bytes(), err
+}
+
+func unmarshalObject(in []byte, out interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(in)).Decode(out)
+}
+
+// getObject decodes the object stored under id in the collection into out.
+func getObject(tx *bolt.Tx, col string, id string, out interface{}) error {
+	v := tx.Bucket([]byte(col)).Get([]byte(id))
+	if v == nil {
+		return db.ErrNotFound
+	}
+	return unmarshalObject(v, out)
+}
+
+// putObject stores the object under id in the collection, replacing any previous value.
+func putObject(tx *bolt.Tx, col string, id string, in interface{}) error {
+	m, err := marshalObject(in)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket([]byte(col)).Put([]byte(id), m)
+}
+
+// deleteObject removes the object stored under id from the collection.
+func deleteObject(tx *bolt.Tx, col string, id string) error {
+	b := tx.Bucket([]byte(col))
+	if b.Get([]byte(id)) == nil {
+		return db.ErrNotFound
+	}
+	return b.Delete([]byte(id))
+}
+
+// existsObject reports whether an object is stored under id in the collection.
+func existsObject(tx *bolt.Tx, col string, id string) bool {
+	return tx.Bucket([]byte(col)).Get([]byte(id)) != nil
+}
+
+// forEachObject calls fn with the encoded value of every object in the collection.
+func forEachObject(tx *bolt.Tx, col string, fn func(v []byte) error) error {
+	return tx.Bucket([]byte(col)).ForEach(func(k, v []byte) error {
+		return fn(v)
+	})
+}
+
+// countObjects returns the number of objects in the collection.
+func countObjects(tx *bolt.Tx, col string) int {
+	return tx.Bucket([]byte(col)).Stats().KeyN
+}
+
+// scrubCollection removes every object from the collection.
+func scrubCollection(tx *bolt.Tx, col string) error {
+	if err := tx.DeleteBucket([]byte(col)); err != nil && err != bolt.ErrBucketNotFound {
+		return err
+	}
+	_, err := tx.CreateBucket([]byte(col))
+	return err
+}
+
+// applyLimit truncates the number of results to limit. A limit of zero or less means no limit.
+func applyLimit(count int, limit int) int {
+	if limit > 0 && count > limit {
+		return limit
+	}
+	return count
+}
+
+// applyOffset returns the bounds of the page starting at offset and holding at most limit of count
+// items. A limit of zero or less means no limit.
+func applyOffset(count int, limit int, offset int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > count {
+		offset = count
+	}
+	return offset, offset + applyLimit(count-offset, limit)
+}
+
+// containsString reports whether s is one of the values.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+// Unlike the Mongo and Redis clients, the embedded client does not need a running database so these
+// tests are always executed.
+
+package embedded
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	correlation "github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db/test"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+func newTestClient(t *testing.T, dir string, name string) *Client {
+	client, err := NewClient(db.Configuration{Host: dir, DatabaseName: name, Timeout: 5000})
+	if err != nil {
+		t.Fatalf("Could not create embedded database: %v", err)
+	}
+	return client
+}
+
+func TestEmbeddedDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embedded")
+	if err != nil {
+		t.Fatalf("Could not create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	test.TestDataDB(t, newTestClient(t, dir, "coredata"))
+	test.TestMetadataDB(t, newTestClient(t, dir, "metadata"))
+	test.TestCommandDB(t, newTestClient(t, dir, "metadata"))
+	test.TestExportDB(t, newTestClient(t, dir, "exportclient"))
+	test.TestNotificationsDB(t, newTestClient(t, dir, "notifications"))
+	test.TestSchedulerDB(t, newTestClient(t, dir, "scheduler"))
+}
+
+func TestEmbeddedDBPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embedded")
+	if err != nil {
+		t.Fatalf("Could not create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := newTestClient(t, dir, "coredata")
+	r := contract.Reading{Name: "temperature", Device: "device1", Value: "21"}
+	id, err := client.AddReading(r)
+	if err != nil {
+		t.Fatalf("Error adding reading: %v", err)
+	}
+	client.CloseSession()
+
+	// A new client on the same directory must see the data written by the previous one
+	client = newTestClient(t, dir, "coredata")
+	defer client.CloseSession()
+	stored, err := client.ReadingById(id)
+	if err != nil {
+		t.Fatalf("Error getting reading after reopening the database: %v", err)
+	}
+	if stored.Name != r.Name || stored.Device != r.Device {
+		t.Fatalf("Reading does not match: %v - %v", stored, r)
+	}
+}
+
+func TestEmbeddedEventFlags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embedded")
+	if err != nil {
+		t.Fatalf("Could not create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := newTestClient(t, dir, "coredata")
+	defer client.CloseSession()
+	e := correlation.Event{
+		Flags: map[string]string{"state": "enum: ajar is not one of open, closed"},
+		Event: contract.Event{Device: "device1", Readings: []contract.Reading{{Name: "state", Value: "ajar"}}},
+	}
+	id, err := client.AddEvent(e)
+	if err != nil {
+		t.Fatalf("Error adding event: %v", err)
+	}
+
+	// The flags are kept with the stored event, the contract model has no room for them
+	var stored embeddedEvent
+	err = client.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.EventsCollection, id, &stored)
+	})
+	if err != nil {
+		t.Fatalf("Error getting the stored event: %v", err)
+	}
+	if stored.Flags["state"] != e.Flags["state"] {
+		t.Fatalf("Expected the stored event to be flagged with %v, got %v", e.Flags, stored.Flags)
+	}
+}
+
+func TestEmbeddedEventIndexesBuilt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embedded")
+	if err != nil {
+		t.Fatalf("Could not create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := newTestClient(t, dir, "coredata")
+	for i := 0; i < 3; i++ {
+		if _, err := client.AddEvent(correlation.Event{Event: contract.Event{Device: "device1", Created: int64(i + 1)}}); err != nil {
+			t.Fatalf("Error adding event: %v", err)
+		}
+	}
+	// a data file written before the indexes existed
+	err = client.update(func(tx *bolt.Tx) error {
+		for index := range eventIndexes {
+			if err := tx.DeleteBucket([]byte(index)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Error deleting the index: %v", err)
+	}
+	client.CloseSession()
+
+	client = newTestClient(t, dir, "coredata")
+	defer client.CloseSession()
+	events, err := client.EventsByCreationTimeAfter(0, 10, 1, "", 10)
+	if err != nil {
+		t.Fatalf("Error getting events: %v", err)
+	}
+	if len(events) != 3 || events[0].Created != 1 || events[2].Created != 3 {
+		t.Fatalf("Expected the events to be indexed when the client is created, got %v", events)
+	}
+
+	events, err = client.EventsByCreationTimeAfter(0, 10, events[0].Created, events[0].ID, 1)
+	if err != nil {
+		t.Fatalf("Error getting events: %v", err)
+	}
+	if len(events) != 1 || events[0].Created != 2 {
+		t.Fatalf("Expected the page to start after the first event, got %v", events)
+	}
+
+	if count, err := client.EventCountByDeviceId("device1"); err != nil || count != 3 {
+		t.Fatalf("Expected the 3 events of the device to be indexed, got %d %v", count, err)
+	}
+}
+
+func TestEmbeddedDataFileLocked(t *testing.T) {
+	dir, err := ioutil.TempDir("", "embedded")
+	if err != nil {
+		t.Fatalf("Could not create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := newTestClient(t, dir, "metadata")
+	// the data file is held open by the first client until its session is closed
+	if _, err := NewClient(db.Configuration{Host: dir, DatabaseName: "metadata", Timeout: 100}); err == nil {
+		t.Fatal("Expected the data file to be locked")
+	}
+	client.CloseSession()
+
+	client = newTestClient(t, dir, "metadata")
+	client.CloseSession()
+}
+
+func TestNewClientRequiresName(t *testing.T) {
+	if _, err := NewClient(db.Configuration{Host: os.TempDir()}); err != db.ErrNameEmpty {
+		t.Fatalf("Expected %v, got %v", db.ErrNameEmpty, err)
+	}
+}
+
+func BenchmarkEmbeddedDB(b *testing.B) {
+	dir, err := ioutil.TempDir("", "embedded")
+	if err != nil {
+		b.Fatalf("Could not create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	client, err := NewClient(db.Configuration{Host: dir, DatabaseName: "coredata", Timeout: 5000})
+	if err != nil {
+		b.Fatalf("Could not create embedded database: %v", err)
+	}
+
+	test.BenchmarkDB(b, client)
+}
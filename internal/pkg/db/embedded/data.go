@@ -0,0 +1,985 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package embedded
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+
+	correlation "github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+	"github.com/imdario/mergo"
+	bolt "go.etcd.io/bbolt"
+)
+
+// embeddedEvent is the stored representation of an event. Readings are stored in their own collection
+// and referenced by id so that they can be queried and deleted independently of the event.
+type embeddedEvent struct {
+	contract.Event
+	Checksum   string
+	Flags      map[string]string
+	ReadingIds []string
+}
+
+// The buckets indexing the events. Their keys end with the id of the event, the creation time being big-endian so
+// that the keys sort in time order.
+const (
+	// eventsByCreated keys are the creation time followed by the id
+	eventsByCreated = db.EventsCollection + ":created"
+	// eventsByDevice keys are the device, a zero byte, the creation time and the id
+	eventsByDevice = db.EventsCollection + ":device"
+	// eventsByChecksum keys are the checksum, a zero byte and the id, the events without a checksum are not indexed
+	eventsByChecksum = db.EventsCollection + ":checksum"
+)
+
+// eventIndexes returns the key of an event in each index, nil when the event is not indexed
+var eventIndexes = map[string]func(se embeddedEvent) []byte{
+	eventsByCreated: func(se embeddedEvent) []byte {
+		return createdKey(se.Created, se.ID)
+	},
+	eventsByDevice: func(se embeddedEvent) []byte {
+		return append(indexPrefix(se.Device), createdKey(se.Created, se.ID)...)
+	},
+	eventsByChecksum: func(se embeddedEvent) []byte {
+		if se.Checksum == "" {
+			return nil
+		}
+		return append(indexPrefix(se.Checksum), se.ID...)
+	},
+}
+
+// createdKey returns the key of the event in the creation time index
+func createdKey(created int64, id string) []byte {
+	key := make([]byte, 8, 8+len(id))
+	binary.BigEndian.PutUint64(key, uint64(created))
+	return append(key, id...)
+}
+
+// indexPrefix returns the prefix of the keys of the events with the value in the device and checksum indexes
+func indexPrefix(value string) []byte {
+	return append([]byte(value), 0)
+}
+
+// indexEvent adds the event to the indexes
+func indexEvent(tx *bolt.Tx, se embeddedEvent) error {
+	for index, key := range eventIndexes {
+		if k := key(se); k != nil {
+			if err := tx.Bucket([]byte(index)).Put(k, []byte{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unindexEvent removes the event from the indexes
+func unindexEvent(tx *bolt.Tx, se embeddedEvent) error {
+	for index, key := range eventIndexes {
+		if k := key(se); k != nil {
+			if err := tx.Bucket([]byte(index)).Delete(k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createEventIndexes creates the missing indexes, indexing the events stored before they existed
+func createEventIndexes(tx *bolt.Tx) error {
+	for index, key := range eventIndexes {
+		if tx.Bucket([]byte(index)) != nil {
+			continue
+		}
+		b, err := tx.CreateBucket([]byte(index))
+		if err != nil {
+			return err
+		}
+		err = forEachObject(tx, db.EventsCollection, func(v []byte) error {
+			var se embeddedEvent
+			if err := unmarshalObject(v, &se); err != nil {
+				return err
+			}
+			if k := key(se); k != nil {
+				return b.Put(k, []byte{})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexedEventIds returns the ids of the events whose keys in the index run from the first key at or after from while
+// within holds
+func indexedEventIds(tx *bolt.Tx, index string, from []byte, within func(k []byte) bool) []string {
+	var ids []string
+	cursor := tx.Bucket([]byte(index)).Cursor()
+	for k, _ := cursor.Seek(from); k != nil && within(k); k, _ = cursor.Next() {
+		ids = append(ids, indexedEventId(index, k))
+	}
+	return ids
+}
+
+// indexedEventId returns the id of the event from its key in the index
+func indexedEventId(index string, key []byte) string {
+	switch index {
+	case eventsByCreated:
+		return string(key[8:])
+	case eventsByDevice:
+		return string(key[bytes.IndexByte(key, 0)+9:])
+	default:
+		return string(key[bytes.IndexByte(key, 0)+1:])
+	}
+}
+
+// keyCreated returns the creation time of the event from its key in the creation time index
+func keyCreated(key []byte) int64 {
+	return int64(binary.BigEndian.Uint64(key[:8]))
+}
+
+// withPrefix returns the predicate of the keys of the events with the value in the device or checksum index
+func withPrefix(prefix []byte) func(k []byte) bool {
+	return func(k []byte) bool { return bytes.HasPrefix(k, prefix) }
+}
+
+// createdUntil returns the predicate of the keys of the events created until the time in the creation time index
+func createdUntil(end int64) func(k []byte) bool {
+	return func(k []byte) bool { return keyCreated(k) <= end }
+}
+
+// ******************************* EVENTS **********************************
+
+// Return all the events
+// Sort the events in descending order by modification time
+func (c *Client) Events() ([]contract.Event, error) {
+	return c.getEvents(func(e embeddedEvent) bool { return true }, 0)
+}
+
+// Return events up to the max number specified
+// Sort the events in descending order by modification time
+func (c *Client) EventsWithLimit(limit int) ([]contract.Event, error) {
+	if limit == 0 {
+		return []contract.Event{}, nil
+	}
+	return c.getEvents(func(e embeddedEvent) bool { return true }, limit)
+}
+
+// Add a new event
+// Readings that are part of the event are added to the readings collection
+func (c *Client) AddEvent(e correlation.Event) (string, error) {
+	ids, err := c.AddEvents([]correlation.Event{e})
+	if err != nil {
+		return "", err
+	}
+	return ids[0], nil
+}
+
+// Add a batch of events in a single transaction
+// Returns the ids of the events in the order of the batch
+func (c *Client) AddEvents(events []correlation.Event) ([]string, error) {
+	ids := make([]string, len(events))
+	for i, e := range events {
+		if e.ID == "" {
+			ids[i] = uuid.New().String()
+			continue
+		}
+		if _, err := uuid.Parse(e.ID); err != nil {
+			return nil, db.ErrInvalidObjectId
+		}
+		ids[i] = e.ID
+	}
+
+	ts := db.MakeTimestamp()
+	err := c.update(func(tx *bolt.Tx) error {
+		for i, e := range events {
+			e.ID = ids[i]
+			if err := putEvent(tx, e, ts); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// putEvent stores a new event and its readings
+func putEvent(tx *bolt.Tx, e correlation.Event, ts int64) error {
+	if e.Created == 0 {
+		e.Created = ts
+	}
+	e.Modified = ts
+
+	se := embeddedEvent{Event: e.Event, Checksum: e.Checksum, Flags: e.Flags}
+	se.Readings = nil
+
+	for i := range e.Readings {
+		r := e.Readings[i]
+		if r.Device == "" {
+			r.Device = e.Device
+		}
+		id, err := putReading(tx, r, ts)
+		if err != nil {
+			return err
+		}
+		se.ReadingIds = append(se.ReadingIds, id)
+	}
+	if err := putObject(tx, db.EventsCollection, e.ID, se); err != nil {
+		return err
+	}
+	return indexEvent(tx, se)
+}
+
+// Update an event - do NOT update readings
+// NotFound - no event with the ID was found
+func (c *Client) UpdateEvent(e correlation.Event) error {
+	if _, err := uuid.Parse(e.ID); err != nil {
+		return db.ErrInvalidObjectId
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		var stored embeddedEvent
+		if err := getObject(tx, db.EventsCollection, e.ID, &stored); err != nil {
+			return err
+		}
+
+		if err := unindexEvent(tx, stored); err != nil {
+			return err
+		}
+
+		updated := e.Event
+		updated.Readings = nil
+		updated.Modified = db.MakeTimestamp()
+		if err := mergo.Merge(&updated, stored.Event); err != nil {
+			return err
+		}
+
+		stored.Event = updated
+		if e.Checksum != "" {
+			stored.Checksum = e.Checksum
+		}
+		if e.Flags != nil {
+			stored.Flags = e.Flags
+		}
+		if err := putObject(tx, db.EventsCollection, e.ID, stored); err != nil {
+			return err
+		}
+		return indexEvent(tx, stored)
+	})
+}
+
+// Get an event by id
+func (c *Client) EventById(id string) (contract.Event, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return contract.Event{}, db.ErrInvalidObjectId
+	}
+
+	var e contract.Event
+	err := c.view(func(tx *bolt.Tx) error {
+		var se embeddedEvent
+		if err := getObject(tx, db.EventsCollection, id, &se); err != nil {
+			return err
+		}
+		e = toContractEvent(tx, se)
+		return nil
+	})
+	return e, err
+}
+
+// EventsByChecksum get events with matching checksum
+func (c *Client) EventsByChecksum(checksum string) ([]contract.Event, error) {
+	prefix := indexPrefix(checksum)
+	events, err := c.getIndexedEvents(eventsByChecksum, prefix, withPrefix(prefix), 0, 0)
+	if err != nil {
+		return events, err
+	}
+
+	if len(events) == 0 {
+		return events, db.ErrNotFound
+	}
+
+	return events, nil
+}
+
+// Get the number of events
+func (c *Client) EventCount() (count int, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		count = countObjects(tx, db.EventsCollection)
+		return nil
+	})
+	return count, err
+}
+
+// Get the number of events for the device
+func (c *Client) EventCountByDeviceId(id string) (count int, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		prefix := indexPrefix(id)
+		count = len(indexedEventIds(tx, eventsByDevice, prefix, withPrefix(prefix)))
+		return nil
+	})
+	return count, err
+}
+
+// Delete an event by ID. Readings are not deleted
+// 404 - Event not found
+func (c *Client) DeleteEventById(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return db.ErrInvalidObjectId
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteEvent(tx, id)
+	})
+}
+
+// deleteEvent removes the event stored under id, and its index entries
+func deleteEvent(tx *bolt.Tx, id string) error {
+	var se embeddedEvent
+	if err := getObject(tx, db.EventsCollection, id, &se); err != nil {
+		return err
+	}
+	if err := unindexEvent(tx, se); err != nil {
+		return err
+	}
+	return deleteObject(tx, db.EventsCollection, id)
+}
+
+// DeleteEventsByDevice Delete events and readings associated with the specified device ID
+func (c *Client) DeleteEventsByDevice(deviceId string) (count int, err error) {
+	err = c.update(func(tx *bolt.Tx) error {
+		if err := deleteReadings(tx, func(r contract.Reading) bool { return r.Device == deviceId }); err != nil {
+			return err
+		}
+
+		prefix := indexPrefix(deviceId)
+		ids := indexedEventIds(tx, eventsByDevice, prefix, withPrefix(prefix))
+		for _, id := range ids {
+			if err := deleteEvent(tx, id); err != nil {
+				return err
+			}
+		}
+		count = len(ids)
+		return nil
+	})
+	return count, err
+}
+
+// Get a list of events based on the device id and limit
+// A limit of zero returns all of the events for the device
+func (c *Client) EventsForDeviceLimit(id string, limit int) ([]contract.Event, error) {
+	return c.EventsForDeviceWithOffset(id, limit, 0)
+}
+
+// Get a page of events based on the device id, skipping the first offset events
+func (c *Client) EventsForDeviceWithOffset(id string, limit int, offset int) ([]contract.Event, error) {
+	prefix := indexPrefix(id)
+	return c.getIndexedEvents(eventsByDevice, prefix, withPrefix(prefix), limit, offset)
+}
+
+// Get a list of events based on the device id
+func (c *Client) EventsForDevice(id string) ([]contract.Event, error) {
+	return c.EventsForDeviceLimit(id, 0)
+}
+
+// Return a list of events whose creation time is between startTime and endTime
+// Limit the number of results by limit
+func (c *Client) EventsByCreationTime(startTime, endTime int64, limit int) ([]contract.Event, error) {
+	return c.EventsByCreationTimeWithOffset(startTime, endTime, limit, 0)
+}
+
+// Return a page of events whose creation time is between startTime and endTime
+// The first offset events are skipped and the number of results is limited by limit
+func (c *Client) EventsByCreationTimeWithOffset(startTime, endTime int64, limit int, offset int) ([]contract.Event, error) {
+	if limit == 0 {
+		return []contract.Event{}, nil
+	}
+	return c.getIndexedEvents(eventsByCreated, createdKey(startTime, ""), createdUntil(endTime), limit, offset)
+}
+
+// Return a page of events whose creation time is between startTime and endTime in ascending order of creation
+// time and id, starting after the event created at afterCreated with the id afterId
+// The page is read from the creation time index, from its first key past both bounds
+func (c *Client) EventsByCreationTimeAfter(startTime, endTime int64, afterCreated int64, afterId string, limit int) ([]contract.Event, error) {
+	events := []contract.Event{}
+	if limit == 0 {
+		return events, nil
+	}
+
+	from := createdKey(startTime, "")
+	var after []byte
+	if afterId != "" {
+		after = createdKey(afterCreated, afterId)
+		if bytes.Compare(after, from) > 0 {
+			from = after
+		}
+	}
+
+	err := c.view(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(eventsByCreated)).Cursor()
+		for k, _ := cursor.Seek(from); k != nil && (limit < 0 || len(events) < limit); k, _ = cursor.Next() {
+			if keyCreated(k) > endTime {
+				break
+			}
+			if after != nil && bytes.Equal(k, after) {
+				continue
+			}
+			var se embeddedEvent
+			if err := getObject(tx, db.EventsCollection, indexedEventId(eventsByCreated, k), &se); err != nil {
+				return err
+			}
+			events = append(events, toContractEvent(tx, se))
+		}
+		return nil
+	})
+	if err != nil {
+		return []contract.Event{}, err
+	}
+	return events, nil
+}
+
+// Get Events that are older than the given age (defined by age = now - created)
+func (c *Client) EventsOlderThanAge(age int64) ([]contract.Event, error) {
+	expireDate := db.MakeTimestamp() - age
+	return c.getIndexedEvents(eventsByCreated, createdKey(0, ""), createdUntil(expireDate), 0, 0)
+}
+
+// Get all of the events that have been pushed
+func (c *Client) EventsPushed() ([]contract.Event, error) {
+	return c.getEvents(func(e embeddedEvent) bool { return e.Pushed > 0 }, 0)
+}
+
+// Delete all of the readings and all of the events
+func (c *Client) ScrubAllEvents() error {
+	return c.update(func(tx *bolt.Tx) error {
+		if err := scrubCollection(tx, db.ReadingsCollection); err != nil {
+			return err
+		}
+		for index := range eventIndexes {
+			if err := scrubCollection(tx, index); err != nil {
+				return err
+			}
+		}
+		return scrubCollection(tx, db.EventsCollection)
+	})
+}
+
+// getEvents returns the events matching the predicate, most recently modified first
+func (c *Client) getEvents(match func(e embeddedEvent) bool, limit int) ([]contract.Event, error) {
+	return c.getEventsPage(match, limit, 0)
+}
+
+// getEventsPage returns a page of the events matching the predicate, most recently modified first
+func (c *Client) getEventsPage(match func(e embeddedEvent) bool, limit int, offset int) ([]contract.Event, error) {
+	events := []contract.Event{}
+	err := c.view(func(tx *bolt.Tx) error {
+		var stored []embeddedEvent
+		err := forEachObject(tx, db.EventsCollection, func(v []byte) error {
+			var se embeddedEvent
+			if err := unmarshalObject(v, &se); err != nil {
+				return err
+			}
+			if match(se) {
+				stored = append(stored, se)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		events = toContractEventsPage(tx, stored, limit, offset)
+		return nil
+	})
+	if err != nil {
+		return []contract.Event{}, err
+	}
+	return events, nil
+}
+
+// getIndexedEvents returns a page of the events found in the index, whose keys run from the first key at or after
+// from while within holds, most recently modified first
+func (c *Client) getIndexedEvents(
+	index string,
+	from []byte,
+	within func(k []byte) bool,
+	limit int,
+	offset int) ([]contract.Event, error) {
+
+	events := []contract.Event{}
+	err := c.view(func(tx *bolt.Tx) error {
+		ids := indexedEventIds(tx, index, from, within)
+		stored := make([]embeddedEvent, len(ids))
+		for i, id := range ids {
+			if err := getObject(tx, db.EventsCollection, id, &stored[i]); err != nil {
+				return err
+			}
+		}
+		events = toContractEventsPage(tx, stored, limit, offset)
+		return nil
+	})
+	if err != nil {
+		return []contract.Event{}, err
+	}
+	return events, nil
+}
+
+// toContractEventsPage sorts the events, most recently modified first, and returns the page starting at offset
+func toContractEventsPage(tx *bolt.Tx, stored []embeddedEvent, limit int, offset int) []contract.Event {
+	sort.SliceStable(stored, func(i, j int) bool { return stored[i].Modified > stored[j].Modified })
+	from, to := applyOffset(len(stored), limit, offset)
+
+	events := []contract.Event{}
+	for _, se := range stored[from:to] {
+		events = append(events, toContractEvent(tx, se))
+	}
+	return events
+}
+
+// toContractEvent populates the event's readings from the readings collection. Readings which have
+// been deleted independently of the event are skipped.
+func toContractEvent(tx *bolt.Tx, se embeddedEvent) contract.Event {
+	e := se.Event
+	e.Readings = nil
+	for _, id := range se.ReadingIds {
+		var r contract.Reading
+		if err := getObject(tx, db.ReadingsCollection, id, &r); err != nil {
+			continue
+		}
+		e.Readings = append(e.Readings, r)
+	}
+	return e
+}
+
+// ************************ READINGS ************************************
+
+// Return a list of readings sorted by modification time
+func (c *Client) Readings() ([]contract.Reading, error) {
+	return c.getReadings(func(r contract.Reading) bool { return true }, 0)
+}
+
+// Post a new reading
+func (c *Client) AddReading(r contract.Reading) (id string, err error) {
+	if r.Id != "" {
+		if _, err := uuid.Parse(r.Id); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	}
+
+	err = c.update(func(tx *bolt.Tx) error {
+		id, err = putReading(tx, r, db.MakeTimestamp())
+		return err
+	})
+	return id, err
+}
+
+// Update a reading
+// 404 - reading cannot be found
+func (c *Client) UpdateReading(r contract.Reading) error {
+	if _, err := uuid.Parse(r.Id); err != nil {
+		return db.ErrInvalidObjectId
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		var stored contract.Reading
+		if err := getObject(tx, db.ReadingsCollection, r.Id, &stored); err != nil {
+			return err
+		}
+
+		r.Modified = db.MakeTimestamp()
+		if err := mergo.Merge(&r, stored); err != nil {
+			return err
+		}
+		return putObject(tx, db.ReadingsCollection, r.Id, r)
+	})
+}
+
+// Get a reading by ID
+func (c *Client) ReadingById(id string) (r contract.Reading, err error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return contract.Reading{}, db.ErrInvalidObjectId
+	}
+
+	err = c.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.ReadingsCollection, id, &r)
+	})
+	if err != nil {
+		return contract.Reading{}, err
+	}
+	return r, nil
+}
+
+// Get the count of readings
+func (c *Client) ReadingCount() (count int, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		count = countObjects(tx, db.ReadingsCollection)
+		return nil
+	})
+	return count, err
+}
+
+// Delete a reading by ID
+// 404 - can't find the reading with the given id
+func (c *Client) DeleteReadingById(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return db.ErrInvalidObjectId
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteObject(tx, db.ReadingsCollection, id)
+	})
+}
+
+// Delete all of the readings for the given device
+func (c *Client) DeleteReadingsByDevice(deviceId string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteReadings(tx, func(r contract.Reading) bool { return r.Device == deviceId })
+	})
+}
+
+// Return a list of readings for the given device
+// A limit of zero returns all of the readings for the device
+func (c *Client) ReadingsByDevice(id string, limit int) ([]contract.Reading, error) {
+	return c.ReadingsByDeviceWithOffset(id, limit, 0)
+}
+
+// Return a page of readings for the given device, skipping the first offset readings
+func (c *Client) ReadingsByDeviceWithOffset(id string, limit int, offset int) ([]contract.Reading, error) {
+	return c.getReadingsPage(func(r contract.Reading) bool { return r.Device == id }, limit, offset)
+}
+
+// Return a list of readings for the given value descriptor
+// A limit of zero returns all of the readings for the value descriptor
+func (c *Client) ReadingsByValueDescriptor(name string, limit int) ([]contract.Reading, error) {
+	return c.ReadingsByValueDescriptorWithOffset(name, limit, 0)
+}
+
+// Return a page of readings for the given value descriptor, skipping the first offset readings
+func (c *Client) ReadingsByValueDescriptorWithOffset(name string, limit int, offset int) ([]contract.Reading, error) {
+	return c.getReadingsPage(func(r contract.Reading) bool { return r.Name == name }, limit, offset)
+}
+
+// Return a list of readings whose name is in the list of value descriptor names
+func (c *Client) ReadingsByValueDescriptorNames(names []string, limit int) ([]contract.Reading, error) {
+	if limit == 0 {
+		return []contract.Reading{}, nil
+	}
+	return c.getReadings(func(r contract.Reading) bool { return containsString(names, r.Name) }, limit)
+}
+
+// Return a list of readings whose creation time is in-between start and end
+// Limit by the limit parameter
+func (c *Client) ReadingsByCreationTime(start, end int64, limit int) ([]contract.Reading, error) {
+	return c.ReadingsByCreationTimeWithOffset(start, end, limit, 0)
+}
+
+// Return a page of readings whose creation time is in-between start and end
+// The first offset readings are skipped
+func (c *Client) ReadingsByCreationTimeWithOffset(start, end int64, limit int, offset int) ([]contract.Reading, error) {
+	if limit == 0 {
+		return []contract.Reading{}, nil
+	}
+	return c.getReadingsPage(func(r contract.Reading) bool {
+		return r.Created >= start && r.Created <= end
+	}, limit, offset)
+}
+
+// Return a list of readings that match the device and value descriptor name
+func (c *Client) ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) ([]contract.Reading, error) {
+	if limit == 0 {
+		return []contract.Reading{}, nil
+	}
+	return c.getReadings(func(r contract.Reading) bool {
+		return r.Device == deviceId && r.Name == valueDescriptor
+	}, limit)
+}
+
+// Aggregate the numeric readings of a device for a value descriptor into buckets of the given width
+// Readings whose value is not a finite number are ignored
+func (c *Client) ReadingBuckets(deviceId, valueDescriptor string, start, end int64, width int64) ([]db.ReadingBucket, error) {
+	readings, err := c.getReadings(func(r contract.Reading) bool {
+		return r.Device == deviceId && r.Name == valueDescriptor && r.Created >= start && r.Created <= end
+	}, 0)
+	if err != nil {
+		return []db.ReadingBucket{}, err
+	}
+
+	// Values must be added to the buckets in creation order
+	sort.SliceStable(readings, func(i, j int) bool { return readings[i].Created < readings[j].Created })
+
+	buckets := []db.ReadingBucket{}
+	for _, r := range readings {
+		value, err := strconv.ParseFloat(r.Value, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		bucketStart := db.BucketStart(r.Created, start, width)
+		if len(buckets) == 0 || buckets[len(buckets)-1].Start != bucketStart {
+			buckets = append(buckets, db.ReadingBucket{Start: bucketStart})
+		}
+		buckets[len(buckets)-1].Add(value)
+	}
+	return buckets, nil
+}
+
+// getReadings returns the readings matching the predicate, most recently modified first
+func (c *Client) getReadings(match func(r contract.Reading) bool, limit int) ([]contract.Reading, error) {
+	return c.getReadingsPage(match, limit, 0)
+}
+
+// getReadingsPage returns a page of the readings matching the predicate, most recently modified first
+func (c *Client) getReadingsPage(match func(r contract.Reading) bool, limit int, offset int) ([]contract.Reading, error) {
+	readings := []contract.Reading{}
+	err := c.view(func(tx *bolt.Tx) error {
+		return forEachObject(tx, db.ReadingsCollection, func(v []byte) error {
+			var r contract.Reading
+			if err := unmarshalObject(v, &r); err != nil {
+				return err
+			}
+			if match(r) {
+				readings = append(readings, r)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.Reading{}, err
+	}
+
+	sort.SliceStable(readings, func(i, j int) bool { return readings[i].Modified > readings[j].Modified })
+	from, to := applyOffset(len(readings), limit, offset)
+	return readings[from:to], nil
+}
+
+// putReading stores a new reading, assigning it an id if it does not already have one
+func putReading(tx *bolt.Tx, r contract.Reading, ts int64) (string, error) {
+	if r.Id == "" {
+		r.Id = uuid.New().String()
+	}
+	if r.Created == 0 {
+		r.Created = ts
+	}
+	r.Modified = ts
+
+	if err := putObject(tx, db.ReadingsCollection, r.Id, r); err != nil {
+		return "", err
+	}
+	return r.Id, nil
+}
+
+// deleteReadings removes every reading matching the predicate
+func deleteReadings(tx *bolt.Tx, match func(r contract.Reading) bool) error {
+	var ids []string
+	err := forEachObject(tx, db.ReadingsCollection, func(v []byte) error {
+		var r contract.Reading
+		if err := unmarshalObject(v, &r); err != nil {
+			return err
+		}
+		if match(r) {
+			ids = append(ids, r.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := deleteObject(tx, db.ReadingsCollection, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ************************* VALUE DESCRIPTORS *****************************
+
+// Return a list of all the value descriptors
+func (c *Client) ValueDescriptors() ([]contract.ValueDescriptor, error) {
+	return c.getValueDescriptors(func(v contract.ValueDescriptor) bool { return true })
+}
+
+// Add a value descriptor
+// 409 - Formatting is bad or it is not unique
+func (c *Client) AddValueDescriptor(v contract.ValueDescriptor) (string, error) {
+	if v.Id != "" {
+		if _, err := uuid.Parse(v.Id); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	} else {
+		v.Id = uuid.New().String()
+	}
+
+	ts := db.MakeTimestamp()
+	if v.Created == 0 {
+		v.Created = ts
+	}
+	v.Modified = ts
+
+	err := c.update(func(tx *bolt.Tx) error {
+		exists, err := valueDescriptorNameExists(tx, v.Name, v.Id)
+		if err != nil {
+			return err
+		} else if exists {
+			return db.ErrNotUnique
+		}
+		return putObject(tx, db.ValueDescriptorCollection, v.Id, v)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return v.Id, nil
+}
+
+// Update a value descriptor
+// 404 - not found
+// 409 - name is not unique
+func (c *Client) UpdateValueDescriptor(v contract.ValueDescriptor) error {
+	if _, err := uuid.Parse(v.Id); err != nil {
+		return db.ErrInvalidObjectId
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		var stored contract.ValueDescriptor
+		if err := getObject(tx, db.ValueDescriptorCollection, v.Id, &stored); err != nil {
+			return err
+		}
+
+		exists, err := valueDescriptorNameExists(tx, v.Name, v.Id)
+		if err != nil {
+			return err
+		} else if exists {
+			return db.ErrNotUnique
+		}
+
+		v.Modified = db.MakeTimestamp()
+		if err := mergo.Merge(&v, stored); err != nil {
+			return err
+		}
+		return putObject(tx, db.ValueDescriptorCollection, v.Id, v)
+	})
+}
+
+// Delete a value descriptor based on the ID
+func (c *Client) DeleteValueDescriptorById(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return db.ErrInvalidObjectId
+	}
+
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteObject(tx, db.ValueDescriptorCollection, id)
+	})
+}
+
+// Return a value descriptor based on the name
+func (c *Client) ValueDescriptorByName(name string) (contract.ValueDescriptor, error) {
+	vds, err := c.getValueDescriptors(func(v contract.ValueDescriptor) bool { return v.Name == name })
+	if err != nil {
+		return contract.ValueDescriptor{}, err
+	}
+	if len(vds) == 0 {
+		return contract.ValueDescriptor{}, db.ErrNotFound
+	}
+	return vds[0], nil
+}
+
+// Return all of the value descriptors based on the names
+func (c *Client) ValueDescriptorsByName(names []string) ([]contract.ValueDescriptor, error) {
+	return c.getValueDescriptors(func(v contract.ValueDescriptor) bool { return containsString(names, v.Name) })
+}
+
+// Return a value descriptor based on the id
+func (c *Client) ValueDescriptorById(id string) (v contract.ValueDescriptor, err error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return contract.ValueDescriptor{}, db.ErrInvalidObjectId
+	}
+
+	err = c.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.ValueDescriptorCollection, id, &v)
+	})
+	if err != nil {
+		return contract.ValueDescriptor{}, err
+	}
+	return v, nil
+}
+
+// Return all the value descriptors that match the UOM label
+func (c *Client) ValueDescriptorsByUomLabel(uomLabel string) ([]contract.ValueDescriptor, error) {
+	return c.getValueDescriptors(func(v contract.ValueDescriptor) bool { return v.UomLabel == uomLabel })
+}
+
+// Return value descriptors based on if it has the label
+func (c *Client) ValueDescriptorsByLabel(label string) ([]contract.ValueDescriptor, error) {
+	return c.getValueDescriptors(func(v contract.ValueDescriptor) bool { return containsString(v.Labels, label) })
+}
+
+// Return value descriptors based on the type
+func (c *Client) ValueDescriptorsByType(t string) ([]contract.ValueDescriptor, error) {
+	return c.getValueDescriptors(func(v contract.ValueDescriptor) bool { return v.Type == t })
+}
+
+// Delete all of the value descriptors
+func (c *Client) ScrubAllValueDescriptors() error {
+	return c.update(func(tx *bolt.Tx) error {
+		return scrubCollection(tx, db.ValueDescriptorCollection)
+	})
+}
+
+func (c *Client) getValueDescriptors(match func(v contract.ValueDescriptor) bool) ([]contract.ValueDescriptor, error) {
+	vds := []contract.ValueDescriptor{}
+	err := c.view(func(tx *bolt.Tx) error {
+		return forEachObject(tx, db.ValueDescriptorCollection, func(b []byte) error {
+			var v contract.ValueDescriptor
+			if err := unmarshalObject(b, &v); err != nil {
+				return err
+			}
+			if match(v) {
+				vds = append(vds, v)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []contract.ValueDescriptor{}, err
+	}
+	return vds, nil
+}
+
+// valueDescriptorNameExists reports whether a value descriptor other than the one identified by id
+// already uses the name
+func valueDescriptorNameExists(tx *bolt.Tx, name string, id string) (bool, error) {
+	exists := false
+	err := forEachObject(tx, db.ValueDescriptorCollection, func(b []byte) error {
+		var v contract.ValueDescriptor
+		if err := unmarshalObject(b, &v); err != nil {
+			return err
+		}
+		if v.Name == name && v.Id != id {
+			exists = true
+		}
+		return nil
+	})
+	return exists, err
+}
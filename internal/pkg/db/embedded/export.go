@@ -0,0 +1,146 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package embedded
+
+import (
+	"github.com/edgexfoundry/edgex-go/internal/export"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// ********************** REGISTRATION FUNCTIONS *****************************
+// Return all the registrations
+// UnexpectedError - failed to retrieve registrations from the database
+func (c *Client) Registrations() ([]export.Registration, error) {
+	return c.getRegistrations(func(r export.Registration) bool { return true })
+}
+
+// Add a new registration
+// UnexpectedError - failed to add to database
+func (c *Client) AddRegistration(reg export.Registration) (string, error) {
+	if reg.ID != "" {
+		if _, err := uuid.Parse(reg.ID); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	} else {
+		reg.ID = uuid.New().String()
+	}
+
+	ts := db.MakeTimestamp()
+	if reg.Created == 0 {
+		reg.Created = ts
+	}
+	reg.Modified = ts
+
+	err := c.update(func(tx *bolt.Tx) error {
+		return putObject(tx, db.ExportCollection, reg.ID, reg)
+	})
+	if err != nil {
+		return "", err
+	}
+	return reg.ID, nil
+}
+
+// Update a registration
+// UnexpectedError - problem updating in database
+// NotFound - no registration with the ID was found
+func (c *Client) UpdateRegistration(reg export.Registration) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored export.Registration
+		if err := getObject(tx, db.ExportCollection, reg.ID, &stored); err != nil {
+			return err
+		}
+
+		reg.Created = stored.Created
+		reg.Modified = db.MakeTimestamp()
+		return putObject(tx, db.ExportCollection, reg.ID, reg)
+	})
+}
+
+// Get a registration by ID
+// UnexpectedError - problem getting in database
+// NotFound - no registration with the ID was found
+func (c *Client) RegistrationById(id string) (r export.Registration, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.ExportCollection, id, &r)
+	})
+	if err != nil {
+		return export.Registration{}, err
+	}
+	return r, nil
+}
+
+// Get a registration by name
+// UnexpectedError - problem getting in database
+// NotFound - no registration with the name was found
+func (c *Client) RegistrationByName(name string) (export.Registration, error) {
+	regs, err := c.getRegistrations(func(r export.Registration) bool { return r.Name == name })
+	if err != nil {
+		return export.Registration{}, err
+	}
+	if len(regs) == 0 {
+		return export.Registration{}, db.ErrNotFound
+	}
+	return regs[0], nil
+}
+
+// Delete a registration by ID
+// UnexpectedError - problem getting in database
+// NotFound - no registration with the ID was found
+func (c *Client) DeleteRegistrationById(id string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteObject(tx, db.ExportCollection, id)
+	})
+}
+
+// Delete a registration by name
+// UnexpectedError - problem getting in database
+// NotFound - no registration with the name was found
+func (c *Client) DeleteRegistrationByName(name string) error {
+	r, err := c.RegistrationByName(name)
+	if err != nil {
+		return err
+	}
+	return c.DeleteRegistrationById(r.ID)
+}
+
+// ScrubAllRegistrations deletes all export related data
+func (c *Client) ScrubAllRegistrations() error {
+	return c.update(func(tx *bolt.Tx) error {
+		return scrubCollection(tx, db.ExportCollection)
+	})
+}
+
+func (c *Client) getRegistrations(match func(r export.Registration) bool) ([]export.Registration, error) {
+	regs := []export.Registration{}
+	err := c.view(func(tx *bolt.Tx) error {
+		return forEachObject(tx, db.ExportCollection, func(v []byte) error {
+			var r export.Registration
+			if err := unmarshalObject(v, &r); err != nil {
+				return err
+			}
+			if match(r) {
+				regs = append(regs, r)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return []export.Registration{}, err
+	}
+	return regs, nil
+}
@@ -0,0 +1,1180 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package embedded
+
+import (
+	"errors"
+	"sort"
+
+	command "github.com/edgexfoundry/edgex-go/internal/core/command/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Devices, device services and provision watchers refer to the objects they depend on by id. The
+// referenced objects are looked up again when the record is read so that updates to them are seen.
+
+type embeddedDevice struct {
+	contract.Device
+	ServiceId string
+	ProfileId string
+}
+
+type embeddedDeviceService struct {
+	contract.DeviceService
+	AddressableId string
+}
+
+type embeddedProvisionWatcher struct {
+	contract.ProvisionWatcher
+	ServiceId string
+	ProfileId string
+}
+
+type embeddedCommand struct {
+	contract.Command
+	DeviceId string
+}
+
+// validId returns id if it is a valid UUID, otherwise a newly generated one
+func validId(id string) string {
+	if _, err := uuid.Parse(id); err != nil {
+		return uuid.New().String()
+	}
+	return id
+}
+
+/* -----------------------------Device Report-------------------------- */
+
+func (c *Client) GetAllDeviceReports() ([]contract.DeviceReport, error) {
+	return c.getDeviceReports(func(dr contract.DeviceReport) bool { return true })
+}
+
+func (c *Client) GetDeviceReportByName(n string) (contract.DeviceReport, error) {
+	drs, err := c.getDeviceReports(func(dr contract.DeviceReport) bool { return dr.Name == n })
+	if err != nil {
+		return contract.DeviceReport{}, err
+	}
+	if len(drs) == 0 {
+		return contract.DeviceReport{}, db.ErrNotFound
+	}
+	return drs[0], nil
+}
+
+func (c *Client) GetDeviceReportByDeviceName(n string) ([]contract.DeviceReport, error) {
+	return c.getDeviceReports(func(dr contract.DeviceReport) bool { return dr.Device == n })
+}
+
+func (c *Client) GetDeviceReportById(id string) (dr contract.DeviceReport, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.DeviceReport, id, &dr)
+	})
+	if err != nil {
+		return contract.DeviceReport{}, err
+	}
+	return dr, nil
+}
+
+func (c *Client) GetDeviceReportsByAction(n string) ([]contract.DeviceReport, error) {
+	return c.getDeviceReports(func(dr contract.DeviceReport) bool { return dr.Action == n })
+}
+
+func (c *Client) AddDeviceReport(dr contract.DeviceReport) (string, error) {
+	dr.Id = validId(dr.Id)
+
+	ts := db.MakeTimestamp()
+	if dr.Created == 0 {
+		dr.Created = ts
+	}
+	dr.Modified = ts
+
+	err := c.update(func(tx *bolt.Tx) error {
+		existing, err := queryDeviceReports(tx, func(o contract.DeviceReport) bool { return o.Name == dr.Name })
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+		return putObject(tx, db.DeviceReport, dr.Id, dr)
+	})
+	if err != nil {
+		return "", err
+	}
+	return dr.Id, nil
+}
+
+func (c *Client) UpdateDeviceReport(dr contract.DeviceReport) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored contract.DeviceReport
+		if err := getObject(tx, db.DeviceReport, dr.Id, &stored); err != nil {
+			return err
+		}
+
+		existing, err := queryDeviceReports(tx, func(o contract.DeviceReport) bool {
+			return o.Name == dr.Name && o.Id != dr.Id
+		})
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		dr.Created = stored.Created
+		dr.Modified = db.MakeTimestamp()
+		return putObject(tx, db.DeviceReport, dr.Id, dr)
+	})
+}
+
+func (c *Client) DeleteDeviceReportById(id string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteObject(tx, db.DeviceReport, id)
+	})
+}
+
+func (c *Client) getDeviceReports(match func(dr contract.DeviceReport) bool) (drs []contract.DeviceReport, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		drs, err = queryDeviceReports(tx, match)
+		return err
+	})
+	if err != nil {
+		return []contract.DeviceReport{}, err
+	}
+	return drs, nil
+}
+
+func queryDeviceReports(tx *bolt.Tx, match func(dr contract.DeviceReport) bool) ([]contract.DeviceReport, error) {
+	drs := []contract.DeviceReport{}
+	err := forEachObject(tx, db.DeviceReport, func(v []byte) error {
+		var dr contract.DeviceReport
+		if err := unmarshalObject(v, &dr); err != nil {
+			return err
+		}
+		if match(dr) {
+			drs = append(drs, dr)
+		}
+		return nil
+	})
+	return drs, err
+}
+
+/* ----------------------------- Device ---------------------------------- */
+
+func (c *Client) AddDevice(d contract.Device, commands []contract.Command) (string, error) {
+	d.Id = validId(d.Id)
+
+	ts := db.MakeTimestamp()
+	if d.Created == 0 {
+		d.Created = ts
+	}
+	d.Modified = ts
+
+	err := c.update(func(tx *bolt.Tx) error {
+		existing, err := queryDevices(tx, func(o embeddedDevice) bool { return o.Name == d.Name })
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		sd, err := toEmbeddedDevice(tx, d)
+		if err != nil {
+			return err
+		}
+		if err = putObject(tx, db.Device, d.Id, sd); err != nil {
+			return err
+		}
+
+		for _, cmd := range commands {
+			cmd.Id = uuid.New().String()
+			if cmd.Created == 0 {
+				cmd.Created = ts
+			}
+			cmd.Modified = ts
+			if err = putObject(tx, db.Command, cmd.Id, embeddedCommand{Command: cmd, DeviceId: d.Id}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return d.Id, nil
+}
+
+// UpdateDevice updates the device record. The commands associated with the device are left unchanged.
+func (c *Client) UpdateDevice(d contract.Device) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored embeddedDevice
+		if err := getObject(tx, db.Device, d.Id, &stored); err != nil {
+			return err
+		}
+
+		existing, err := queryDevices(tx, func(o embeddedDevice) bool { return o.Name == d.Name && o.Id != d.Id })
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		if d.Service.Id == "" && d.Service.Name == "" {
+			d.Service.Id = stored.ServiceId
+		}
+		if d.Profile.Id == "" && d.Profile.Name == "" {
+			d.Profile.Id = stored.ProfileId
+		}
+		d.Created = stored.Created
+		d.Modified = db.MakeTimestamp()
+
+		sd, err := toEmbeddedDevice(tx, d)
+		if err != nil {
+			return err
+		}
+		return putObject(tx, db.Device, d.Id, sd)
+	})
+}
+
+// DeleteDeviceById removes the device along with its commands
+func (c *Client) DeleteDeviceById(id string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		if err := deleteObject(tx, db.Device, id); err != nil {
+			return err
+		}
+
+		cmds, err := queryCommands(tx, func(o embeddedCommand) bool { return o.DeviceId == id })
+		if err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			if err = deleteObject(tx, db.Command, cmd.Id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *Client) GetAllDevices() ([]contract.Device, error) {
+	return c.getDevices(func(d embeddedDevice) bool { return true })
+}
+
+func (c *Client) GetDevicesByProfileId(id string) ([]contract.Device, error) {
+	return c.getDevices(func(d embeddedDevice) bool { return d.ProfileId == id })
+}
+
+func (c *Client) GetDeviceById(id string) (d contract.Device, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		var sd embeddedDevice
+		if err := getObject(tx, db.Device, id, &sd); err != nil {
+			return err
+		}
+		d = toContractDevice(tx, sd)
+		return nil
+	})
+	if err != nil {
+		return contract.Device{}, err
+	}
+	return d, nil
+}
+
+func (c *Client) GetDeviceByName(n string) (contract.Device, error) {
+	ds, err := c.getDevices(func(d embeddedDevice) bool { return d.Name == n })
+	if err != nil {
+		return contract.Device{}, err
+	}
+	if len(ds) == 0 {
+		return contract.Device{}, db.ErrNotFound
+	}
+	return ds[0], nil
+}
+
+func (c *Client) GetDevicesByServiceId(id string) ([]contract.Device, error) {
+	return c.getDevices(func(d embeddedDevice) bool { return d.ServiceId == id })
+}
+
+func (c *Client) GetDevicesWithLabel(l string) ([]contract.Device, error) {
+	return c.getDevices(func(d embeddedDevice) bool { return containsString(d.Labels, l) })
+}
+
+func (c *Client) getDevices(match func(d embeddedDevice) bool) ([]contract.Device, error) {
+	ds := []contract.Device{}
+	err := c.view(func(tx *bolt.Tx) error {
+		stored, err := queryDevices(tx, match)
+		if err != nil {
+			return err
+		}
+		for _, sd := range stored {
+			ds = append(ds, toContractDevice(tx, sd))
+		}
+		return nil
+	})
+	if err != nil {
+		return []contract.Device{}, err
+	}
+	return ds, nil
+}
+
+func queryDevices(tx *bolt.Tx, match func(d embeddedDevice) bool) ([]embeddedDevice, error) {
+	var ds []embeddedDevice
+	err := forEachObject(tx, db.Device, func(v []byte) error {
+		var sd embeddedDevice
+		if err := unmarshalObject(v, &sd); err != nil {
+			return err
+		}
+		if match(sd) {
+			ds = append(ds, sd)
+		}
+		return nil
+	})
+	return ds, err
+}
+
+func toEmbeddedDevice(tx *bolt.Tx, d contract.Device) (embeddedDevice, error) {
+	ds, err := resolveDeviceService(tx, d.Service)
+	if err != nil {
+		return embeddedDevice{}, err
+	}
+	dp, err := resolveDeviceProfile(tx, d.Profile)
+	if err != nil {
+		return embeddedDevice{}, err
+	}
+
+	sd := embeddedDevice{Device: d, ServiceId: ds.Id, ProfileId: dp.Id}
+	sd.Service = contract.DeviceService{}
+	sd.Profile = contract.DeviceProfile{}
+	return sd, nil
+}
+
+func toContractDevice(tx *bolt.Tx, sd embeddedDevice) contract.Device {
+	d := sd.Device
+	d.Service = deviceServiceOrRef(tx, sd.ServiceId)
+	d.Profile = deviceProfileOrRef(tx, sd.ProfileId)
+	return d
+}
+
+/* -----------------------------Device Profile----------------------------- */
+
+func (c *Client) GetDeviceProfileById(id string) (dp contract.DeviceProfile, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.DeviceProfile, id, &dp)
+	})
+	if err != nil {
+		return contract.DeviceProfile{}, err
+	}
+	return dp, nil
+}
+
+func (c *Client) GetAllDeviceProfiles() ([]contract.DeviceProfile, error) {
+	return c.getDeviceProfiles(func(dp contract.DeviceProfile) bool { return true })
+}
+
+func (c *Client) GetDeviceProfilesByModel(model string) ([]contract.DeviceProfile, error) {
+	return c.getDeviceProfiles(func(dp contract.DeviceProfile) bool { return dp.Model == model })
+}
+
+func (c *Client) GetDeviceProfilesWithLabel(l string) ([]contract.DeviceProfile, error) {
+	return c.getDeviceProfiles(func(dp contract.DeviceProfile) bool { return containsString(dp.Labels, l) })
+}
+
+func (c *Client) GetDeviceProfilesByManufacturerModel(man string, mod string) ([]contract.DeviceProfile, error) {
+	return c.getDeviceProfiles(func(dp contract.DeviceProfile) bool {
+		return dp.Manufacturer == man && dp.Model == mod
+	})
+}
+
+func (c *Client) GetDeviceProfilesByManufacturer(man string) ([]contract.DeviceProfile, error) {
+	return c.getDeviceProfiles(func(dp contract.DeviceProfile) bool { return dp.Manufacturer == man })
+}
+
+func (c *Client) GetDeviceProfileByName(n string) (contract.DeviceProfile, error) {
+	dps, err := c.getDeviceProfiles(func(dp contract.DeviceProfile) bool { return dp.Name == n })
+	if err != nil {
+		return contract.DeviceProfile{}, err
+	}
+	if len(dps) == 0 {
+		return contract.DeviceProfile{}, db.ErrNotFound
+	}
+	return dps[0], nil
+}
+
+func (c *Client) AddDeviceProfile(dp contract.DeviceProfile) (string, error) {
+	dp.Id = validId(dp.Id)
+
+	ts := db.MakeTimestamp()
+	if dp.Created == 0 {
+		dp.Created = ts
+	}
+	dp.Modified = ts
+
+	err := c.update(func(tx *bolt.Tx) error {
+		existing, err := queryDeviceProfiles(tx, func(o contract.DeviceProfile) bool { return o.Name == dp.Name })
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+		return putObject(tx, db.DeviceProfile, dp.Id, dp)
+	})
+	if err != nil {
+		return "", err
+	}
+	return dp.Id, nil
+}
+
+func (c *Client) UpdateDeviceProfile(dp contract.DeviceProfile) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored contract.DeviceProfile
+		if err := getObject(tx, db.DeviceProfile, dp.Id, &stored); err != nil {
+			return err
+		}
+
+		existing, err := queryDeviceProfiles(tx, func(o contract.DeviceProfile) bool {
+			return o.Name == dp.Name && o.Id != dp.Id
+		})
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		dp.Created = stored.Created
+		dp.Modified = db.MakeTimestamp()
+		return putObject(tx, db.DeviceProfile, dp.Id, dp)
+	})
+}
+
+func (c *Client) DeleteDeviceProfileById(id string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteObject(tx, db.DeviceProfile, id)
+	})
+}
+
+func (c *Client) getDeviceProfiles(match func(dp contract.DeviceProfile) bool) (dps []contract.DeviceProfile, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		dps, err = queryDeviceProfiles(tx, match)
+		return err
+	})
+	if err != nil {
+		return []contract.DeviceProfile{}, err
+	}
+	return dps, nil
+}
+
+func queryDeviceProfiles(tx *bolt.Tx, match func(dp contract.DeviceProfile) bool) ([]contract.DeviceProfile, error) {
+	dps := []contract.DeviceProfile{}
+	err := forEachObject(tx, db.DeviceProfile, func(v []byte) error {
+		var dp contract.DeviceProfile
+		if err := unmarshalObject(v, &dp); err != nil {
+			return err
+		}
+		if match(dp) {
+			dps = append(dps, dp)
+		}
+		return nil
+	})
+	return dps, err
+}
+
+// resolveDeviceProfile looks up the referenced device profile by id, or by name if the id is unknown
+func resolveDeviceProfile(tx *bolt.Tx, ref contract.DeviceProfile) (contract.DeviceProfile, error) {
+	var dp contract.DeviceProfile
+	if ref.Id != "" {
+		if err := getObject(tx, db.DeviceProfile, ref.Id, &dp); err == nil {
+			return dp, nil
+		}
+	}
+
+	dps, err := queryDeviceProfiles(tx, func(o contract.DeviceProfile) bool { return o.Name == ref.Name })
+	if err != nil {
+		return contract.DeviceProfile{}, err
+	}
+	if ref.Name == "" || len(dps) == 0 {
+		return contract.DeviceProfile{}, errors.New("Invalid Device Profile")
+	}
+	return dps[0], nil
+}
+
+// deviceProfileOrRef returns the device profile with the given id. If it has since been removed only
+// the id is returned.
+func deviceProfileOrRef(tx *bolt.Tx, id string) contract.DeviceProfile {
+	var dp contract.DeviceProfile
+	if err := getObject(tx, db.DeviceProfile, id, &dp); err != nil {
+		return contract.DeviceProfile{Id: id}
+	}
+	return dp
+}
+
+/* -----------------------------------Addressable--------------------------*/
+
+func (c *Client) UpdateAddressable(a contract.Addressable) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored contract.Addressable
+		if err := getObject(tx, db.Addressable, a.Id, &stored); err != nil {
+			return err
+		}
+
+		existing, err := queryAddressables(tx, func(o contract.Addressable) bool {
+			return o.Name == a.Name && o.Id != a.Id
+		})
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		a.Created = stored.Created
+		a.Modified = db.MakeTimestamp()
+		return putObject(tx, db.Addressable, a.Id, a)
+	})
+}
+
+func (c *Client) AddAddressable(a contract.Addressable) (string, error) {
+	a.Id = validId(a.Id)
+
+	ts := db.MakeTimestamp()
+	if a.Created == 0 {
+		a.Created = ts
+	}
+	a.Modified = ts
+
+	err := c.update(func(tx *bolt.Tx) error {
+		existing, err := queryAddressables(tx, func(o contract.Addressable) bool { return o.Name == a.Name })
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+		return putObject(tx, db.Addressable, a.Id, a)
+	})
+	if err != nil {
+		return "", err
+	}
+	return a.Id, nil
+}
+
+func (c *Client) GetAddressableById(id string) (a contract.Addressable, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.Addressable, id, &a)
+	})
+	if err != nil {
+		return contract.Addressable{}, err
+	}
+	return a, nil
+}
+
+func (c *Client) GetAddressableByName(n string) (contract.Addressable, error) {
+	as, err := c.getAddressables(func(a contract.Addressable) bool { return a.Name == n })
+	if err != nil {
+		return contract.Addressable{}, err
+	}
+	if len(as) == 0 {
+		return contract.Addressable{}, db.ErrNotFound
+	}
+	return as[0], nil
+}
+
+func (c *Client) GetAddressablesByTopic(t string) ([]contract.Addressable, error) {
+	return c.getAddressables(func(a contract.Addressable) bool { return a.Topic == t })
+}
+
+func (c *Client) GetAddressablesByPort(p int) ([]contract.Addressable, error) {
+	return c.getAddressables(func(a contract.Addressable) bool { return a.Port == p })
+}
+
+func (c *Client) GetAddressablesByPublisher(p string) ([]contract.Addressable, error) {
+	return c.getAddressables(func(a contract.Addressable) bool { return a.Publisher == p })
+}
+
+func (c *Client) GetAddressablesByAddress(add string) ([]contract.Addressable, error) {
+	return c.getAddressables(func(a contract.Addressable) bool { return a.Address == add })
+}
+
+func (c *Client) GetAddressables() ([]contract.Addressable, error) {
+	return c.getAddressables(func(a contract.Addressable) bool { return true })
+}
+
+func (c *Client) DeleteAddressableById(id string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteObject(tx, db.Addressable, id)
+	})
+}
+
+func (c *Client) getAddressables(match func(a contract.Addressable) bool) (as []contract.Addressable, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		as, err = queryAddressables(tx, match)
+		return err
+	})
+	if err != nil {
+		return []contract.Addressable{}, err
+	}
+	return as, nil
+}
+
+func queryAddressables(tx *bolt.Tx, match func(a contract.Addressable) bool) ([]contract.Addressable, error) {
+	as := []contract.Addressable{}
+	err := forEachObject(tx, db.Addressable, func(v []byte) error {
+		var a contract.Addressable
+		if err := unmarshalObject(v, &a); err != nil {
+			return err
+		}
+		if match(a) {
+			as = append(as, a)
+		}
+		return nil
+	})
+	return as, err
+}
+
+// resolveAddressable looks up the referenced addressable by id, or by name if the id is unknown
+func resolveAddressable(tx *bolt.Tx, ref contract.Addressable) (contract.Addressable, error) {
+	var a contract.Addressable
+	if ref.Id != "" {
+		if err := getObject(tx, db.Addressable, ref.Id, &a); err == nil {
+			return a, nil
+		}
+	}
+
+	as, err := queryAddressables(tx, func(o contract.Addressable) bool { return o.Name == ref.Name })
+	if err != nil {
+		return contract.Addressable{}, err
+	}
+	if ref.Name == "" || len(as) == 0 {
+		return contract.Addressable{}, errors.New("Invalid addressable")
+	}
+	return as[0], nil
+}
+
+/* ----------------------------- Device Service ----------------------------------*/
+
+func (c *Client) GetDeviceServiceByName(n string) (contract.DeviceService, error) {
+	dss, err := c.getDeviceServices(func(ds embeddedDeviceService) bool { return ds.Name == n })
+	if err != nil {
+		return contract.DeviceService{}, err
+	}
+	if len(dss) == 0 {
+		return contract.DeviceService{}, db.ErrNotFound
+	}
+	return dss[0], nil
+}
+
+func (c *Client) GetDeviceServiceById(id string) (ds contract.DeviceService, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		var sds embeddedDeviceService
+		if err := getObject(tx, db.DeviceService, id, &sds); err != nil {
+			return err
+		}
+		ds = toContractDeviceService(tx, sds)
+		return nil
+	})
+	if err != nil {
+		return contract.DeviceService{}, err
+	}
+	return ds, nil
+}
+
+func (c *Client) GetAllDeviceServices() ([]contract.DeviceService, error) {
+	return c.getDeviceServices(func(ds embeddedDeviceService) bool { return true })
+}
+
+func (c *Client) GetDeviceServicesByAddressableId(id string) ([]contract.DeviceService, error) {
+	return c.getDeviceServices(func(ds embeddedDeviceService) bool { return ds.AddressableId == id })
+}
+
+func (c *Client) GetDeviceServicesWithLabel(l string) ([]contract.DeviceService, error) {
+	return c.getDeviceServices(func(ds embeddedDeviceService) bool { return containsString(ds.Labels, l) })
+}
+
+func (c *Client) AddDeviceService(ds contract.DeviceService) (string, error) {
+	ds.Id = validId(ds.Id)
+
+	ts := db.MakeTimestamp()
+	if ds.Created == 0 {
+		ds.Created = ts
+	}
+	ds.Modified = ts
+
+	err := c.update(func(tx *bolt.Tx) error {
+		existing, err := queryDeviceServices(tx, func(o embeddedDeviceService) bool { return o.Name == ds.Name })
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		sds, err := toEmbeddedDeviceService(tx, ds)
+		if err != nil {
+			return err
+		}
+		return putObject(tx, db.DeviceService, ds.Id, sds)
+	})
+	if err != nil {
+		return "", err
+	}
+	return ds.Id, nil
+}
+
+func (c *Client) UpdateDeviceService(ds contract.DeviceService) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored embeddedDeviceService
+		if err := getObject(tx, db.DeviceService, ds.Id, &stored); err != nil {
+			return err
+		}
+
+		existing, err := queryDeviceServices(tx, func(o embeddedDeviceService) bool {
+			return o.Name == ds.Name && o.Id != ds.Id
+		})
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		if ds.Addressable.Id == "" && ds.Addressable.Name == "" {
+			ds.Addressable.Id = stored.AddressableId
+		}
+		ds.Created = stored.Created
+		ds.Modified = db.MakeTimestamp()
+
+		sds, err := toEmbeddedDeviceService(tx, ds)
+		if err != nil {
+			return err
+		}
+		return putObject(tx, db.DeviceService, ds.Id, sds)
+	})
+}
+
+func (c *Client) DeleteDeviceServiceById(id string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteObject(tx, db.DeviceService, id)
+	})
+}
+
+func (c *Client) getDeviceServices(match func(ds embeddedDeviceService) bool) ([]contract.DeviceService, error) {
+	dss := []contract.DeviceService{}
+	err := c.view(func(tx *bolt.Tx) error {
+		stored, err := queryDeviceServices(tx, match)
+		if err != nil {
+			return err
+		}
+		for _, sds := range stored {
+			dss = append(dss, toContractDeviceService(tx, sds))
+		}
+		return nil
+	})
+	if err != nil {
+		return []contract.DeviceService{}, err
+	}
+	return dss, nil
+}
+
+func queryDeviceServices(tx *bolt.Tx, match func(ds embeddedDeviceService) bool) ([]embeddedDeviceService, error) {
+	var dss []embeddedDeviceService
+	err := forEachObject(tx, db.DeviceService, func(v []byte) error {
+		var sds embeddedDeviceService
+		if err := unmarshalObject(v, &sds); err != nil {
+			return err
+		}
+		if match(sds) {
+			dss = append(dss, sds)
+		}
+		return nil
+	})
+	return dss, err
+}
+
+func toEmbeddedDeviceService(tx *bolt.Tx, ds contract.DeviceService) (embeddedDeviceService, error) {
+	a, err := resolveAddressable(tx, ds.Addressable)
+	if err != nil {
+		return embeddedDeviceService{}, err
+	}
+
+	sds := embeddedDeviceService{DeviceService: ds, AddressableId: a.Id}
+	sds.Addressable = contract.Addressable{}
+	return sds, nil
+}
+
+func toContractDeviceService(tx *bolt.Tx, sds embeddedDeviceService) contract.DeviceService {
+	ds := sds.DeviceService
+	if err := getObject(tx, db.Addressable, sds.AddressableId, &ds.Addressable); err != nil {
+		ds.Addressable = contract.Addressable{Id: sds.AddressableId}
+	}
+	return ds
+}
+
+// resolveDeviceService looks up the referenced device service by id, or by name if the id is unknown
+func resolveDeviceService(tx *bolt.Tx, ref contract.DeviceService) (contract.DeviceService, error) {
+	var sds embeddedDeviceService
+	if ref.Id != "" {
+		if err := getObject(tx, db.DeviceService, ref.Id, &sds); err == nil {
+			return toContractDeviceService(tx, sds), nil
+		}
+	}
+
+	dss, err := queryDeviceServices(tx, func(o embeddedDeviceService) bool { return o.Name == ref.Name })
+	if err != nil {
+		return contract.DeviceService{}, err
+	}
+	if ref.Name == "" || len(dss) == 0 {
+		return contract.DeviceService{}, errors.New("Invalid Device Service")
+	}
+	return toContractDeviceService(tx, dss[0]), nil
+}
+
+// deviceServiceOrRef returns the device service with the given id. If it has since been removed only
+// the id is returned.
+func deviceServiceOrRef(tx *bolt.Tx, id string) contract.DeviceService {
+	var sds embeddedDeviceService
+	if err := getObject(tx, db.DeviceService, id, &sds); err != nil {
+		return contract.DeviceService{Id: id}
+	}
+	return toContractDeviceService(tx, sds)
+}
+
+//  ----------------------Provision Watcher -----------------------------*/
+
+func (c *Client) GetAllProvisionWatchers() ([]contract.ProvisionWatcher, error) {
+	return c.getProvisionWatchers(func(pw embeddedProvisionWatcher) bool { return true })
+}
+
+func (c *Client) GetProvisionWatcherByName(n string) (contract.ProvisionWatcher, error) {
+	pws, err := c.getProvisionWatchers(func(pw embeddedProvisionWatcher) bool { return pw.Name == n })
+	if err != nil {
+		return contract.ProvisionWatcher{}, err
+	}
+	if len(pws) == 0 {
+		return contract.ProvisionWatcher{}, db.ErrNotFound
+	}
+	return pws[0], nil
+}
+
+func (c *Client) GetProvisionWatchersByIdentifier(k string, v string) ([]contract.ProvisionWatcher, error) {
+	return c.getProvisionWatchers(func(pw embeddedProvisionWatcher) bool {
+		val, ok := pw.Identifiers[k]
+		return ok && val == v
+	})
+}
+
+func (c *Client) GetProvisionWatchersByServiceId(id string) ([]contract.ProvisionWatcher, error) {
+	return c.getProvisionWatchers(func(pw embeddedProvisionWatcher) bool { return pw.ServiceId == id })
+}
+
+func (c *Client) GetProvisionWatchersByProfileId(id string) ([]contract.ProvisionWatcher, error) {
+	return c.getProvisionWatchers(func(pw embeddedProvisionWatcher) bool { return pw.ProfileId == id })
+}
+
+func (c *Client) GetProvisionWatcherById(id string) (pw contract.ProvisionWatcher, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		var spw embeddedProvisionWatcher
+		if err := getObject(tx, db.ProvisionWatcher, id, &spw); err != nil {
+			return err
+		}
+		pw = toContractProvisionWatcher(tx, spw)
+		return nil
+	})
+	if err != nil {
+		return contract.ProvisionWatcher{}, err
+	}
+	return pw, nil
+}
+
+func (c *Client) AddProvisionWatcher(pw contract.ProvisionWatcher) (string, error) {
+	pw.Id = validId(pw.Id)
+
+	ts := db.MakeTimestamp()
+	if pw.Created == 0 {
+		pw.Created = ts
+	}
+	pw.Modified = ts
+
+	err := c.update(func(tx *bolt.Tx) error {
+		existing, err := queryProvisionWatchers(tx, func(o embeddedProvisionWatcher) bool { return o.Name == pw.Name })
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		spw, err := toEmbeddedProvisionWatcher(tx, pw)
+		if err != nil {
+			return err
+		}
+		return putObject(tx, db.ProvisionWatcher, pw.Id, spw)
+	})
+	if err != nil {
+		return "", err
+	}
+	return pw.Id, nil
+}
+
+func (c *Client) UpdateProvisionWatcher(pw contract.ProvisionWatcher) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored embeddedProvisionWatcher
+		if err := getObject(tx, db.ProvisionWatcher, pw.Id, &stored); err != nil {
+			return err
+		}
+
+		existing, err := queryProvisionWatchers(tx, func(o embeddedProvisionWatcher) bool {
+			return o.Name == pw.Name && o.Id != pw.Id
+		})
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		if pw.Service.Id == "" && pw.Service.Name == "" {
+			pw.Service.Id = stored.ServiceId
+		}
+		if pw.Profile.Id == "" && pw.Profile.Name == "" {
+			pw.Profile.Id = stored.ProfileId
+		}
+		pw.Created = stored.Created
+		pw.Modified = db.MakeTimestamp()
+
+		spw, err := toEmbeddedProvisionWatcher(tx, pw)
+		if err != nil {
+			return err
+		}
+		return putObject(tx, db.ProvisionWatcher, pw.Id, spw)
+	})
+}
+
+func (c *Client) DeleteProvisionWatcherById(id string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteObject(tx, db.ProvisionWatcher, id)
+	})
+}
+
+func (c *Client) getProvisionWatchers(match func(pw embeddedProvisionWatcher) bool) ([]contract.ProvisionWatcher, error) {
+	pws := []contract.ProvisionWatcher{}
+	err := c.view(func(tx *bolt.Tx) error {
+		stored, err := queryProvisionWatchers(tx, match)
+		if err != nil {
+			return err
+		}
+		for _, spw := range stored {
+			pws = append(pws, toContractProvisionWatcher(tx, spw))
+		}
+		return nil
+	})
+	if err != nil {
+		return []contract.ProvisionWatcher{}, err
+	}
+	return pws, nil
+}
+
+func queryProvisionWatchers(tx *bolt.Tx, match func(pw embeddedProvisionWatcher) bool) ([]embeddedProvisionWatcher, error) {
+	var pws []embeddedProvisionWatcher
+	err := forEachObject(tx, db.ProvisionWatcher, func(v []byte) error {
+		var spw embeddedProvisionWatcher
+		if err := unmarshalObject(v, &spw); err != nil {
+			return err
+		}
+		if match(spw) {
+			pws = append(pws, spw)
+		}
+		return nil
+	})
+	return pws, err
+}
+
+func toEmbeddedProvisionWatcher(tx *bolt.Tx, pw contract.ProvisionWatcher) (embeddedProvisionWatcher, error) {
+	dp, err := resolveDeviceProfile(tx, pw.Profile)
+	if err != nil {
+		return embeddedProvisionWatcher{}, err
+	}
+	ds, err := resolveDeviceService(tx, pw.Service)
+	if err != nil {
+		return embeddedProvisionWatcher{}, err
+	}
+
+	spw := embeddedProvisionWatcher{ProvisionWatcher: pw, ServiceId: ds.Id, ProfileId: dp.Id}
+	spw.Service = contract.DeviceService{}
+	spw.Profile = contract.DeviceProfile{}
+	return spw, nil
+}
+
+func toContractProvisionWatcher(tx *bolt.Tx, spw embeddedProvisionWatcher) contract.ProvisionWatcher {
+	pw := spw.ProvisionWatcher
+	pw.Service = deviceServiceOrRef(tx, spw.ServiceId)
+	pw.Profile = deviceProfileOrRef(tx, spw.ProfileId)
+	return pw
+}
+
+//  ------------------------Command -------------------------------------*/
+
+func (c *Client) GetAllCommands() ([]contract.Command, error) {
+	return c.getCommands(func(cmd embeddedCommand) bool { return true })
+}
+
+func (c *Client) GetCommandById(id string) (cmd contract.Command, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		var sc embeddedCommand
+		if err := getObject(tx, db.Command, id, &sc); err != nil {
+			return err
+		}
+		cmd = sc.Command
+		return nil
+	})
+	if err != nil {
+		return contract.Command{}, err
+	}
+	return cmd, nil
+}
+
+func (c *Client) GetCommandsByName(n string) ([]contract.Command, error) {
+	return c.getCommands(func(cmd embeddedCommand) bool { return cmd.Name == n })
+}
+
+// GetCommandsByDeviceId returns the commands of the device. An unknown device has no commands.
+func (c *Client) GetCommandsByDeviceId(did string) ([]contract.Command, error) {
+	return c.getCommands(func(cmd embeddedCommand) bool { return cmd.DeviceId == did })
+}
+
+func (c *Client) GetCommandByNameAndDeviceId(cname string, did string) (contract.Command, error) {
+	cmds, err := c.getCommands(func(cmd embeddedCommand) bool { return cmd.Name == cname && cmd.DeviceId == did })
+	if err != nil {
+		return contract.Command{}, err
+	}
+	if len(cmds) == 0 {
+		return contract.Command{}, db.ErrNotFound
+	}
+	return cmds[0], nil
+}
+
+func (c *Client) getCommands(match func(cmd embeddedCommand) bool) ([]contract.Command, error) {
+	cmds := []contract.Command{}
+	err := c.view(func(tx *bolt.Tx) error {
+		stored, err := queryCommands(tx, match)
+		if err != nil {
+			return err
+		}
+		for _, sc := range stored {
+			cmds = append(cmds, sc.Command)
+		}
+		return nil
+	})
+	if err != nil {
+		return []contract.Command{}, err
+	}
+	return cmds, nil
+}
+
+func queryCommands(tx *bolt.Tx, match func(cmd embeddedCommand) bool) ([]embeddedCommand, error) {
+	var cmds []embeddedCommand
+	err := forEachObject(tx, db.Command, func(v []byte) error {
+		var sc embeddedCommand
+		if err := unmarshalObject(v, &sc); err != nil {
+			return err
+		}
+		if match(sc) {
+			cmds = append(cmds, sc)
+		}
+		return nil
+	})
+	return cmds, err
+}
+
+//  ------------------------Command Audit -------------------------------*/
+
+func (c *Client) AddCommandAudit(a command.Audit) (string, error) {
+	if a.Id == "" {
+		a.Id = uuid.New().String()
+	}
+	if a.Created == 0 {
+		a.Created = db.MakeTimestamp()
+	}
+
+	err := c.update(func(tx *bolt.Tx) error {
+		return putObject(tx, db.CommandAudit, a.Id, a)
+	})
+	if err != nil {
+		return "", err
+	}
+	return a.Id, nil
+}
+
+func (c *Client) GetCommandAudits(device string, start int64, end int64, status int, limit int) ([]command.Audit, error) {
+	audits := []command.Audit{}
+	err := c.view(func(tx *bolt.Tx) error {
+		return forEachObject(tx, db.CommandAudit, func(v []byte) error {
+			var a command.Audit
+			if err := unmarshalObject(v, &a); err != nil {
+				return err
+			}
+			if a.Created >= start && a.Created <= end &&
+				(device == "" || a.Device == device) && (status == 0 || a.StatusCode == status) {
+				audits = append(audits, a)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(audits, func(i, j int) bool { return audits[i].Created > audits[j].Created })
+	return audits[:applyLimit(len(audits), limit)], nil
+}
+
+func (c *Client) DeleteCommandAuditsOld(age int64) (int, error) {
+	end := db.MakeTimestamp() - age
+	count := 0
+	err := c.update(func(tx *bolt.Tx) error {
+		var ids []string
+		err := forEachObject(tx, db.CommandAudit, func(v []byte) error {
+			var a command.Audit
+			if err := unmarshalObject(v, &a); err != nil {
+				return err
+			}
+			if a.Created < end {
+				ids = append(ids, a.Id)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if err := deleteObject(tx, db.CommandAudit, id); err != nil {
+				return err
+			}
+		}
+		count = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (c *Client) ScrubMetadata() error {
+	return c.update(func(tx *bolt.Tx) error {
+		for _, col := range []string{
+			db.Addressable, db.Command, db.DeviceService, db.DeviceReport, db.DeviceProfile,
+			db.Device, db.ProvisionWatcher, db.CommandAudit,
+		} {
+			if err := scrubCollection(tx, col); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,550 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package embedded
+
+import (
+	"sort"
+
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+/* ----------------------- Notifications ------------------------*/
+
+func (c *Client) GetNotifications() ([]contract.Notification, error) {
+	return c.getNotifications(func(n contract.Notification) bool { return true }, 0)
+}
+
+func (c *Client) GetNotificationById(id string) (n contract.Notification, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.Notification, id, &n)
+	})
+	if err != nil {
+		return contract.Notification{}, err
+	}
+	return n, nil
+}
+
+func (c *Client) GetNotificationBySlug(slug string) (contract.Notification, error) {
+	ns, err := c.getNotifications(func(n contract.Notification) bool { return n.Slug == slug }, 1)
+	if err != nil {
+		return contract.Notification{}, err
+	}
+	if len(ns) == 0 {
+		return contract.Notification{}, db.ErrNotFound
+	}
+	return ns[0], nil
+}
+
+func (c *Client) GetNotificationBySender(sender string, limit int) ([]contract.Notification, error) {
+	return c.getNotifications(func(n contract.Notification) bool { return n.Sender == sender }, limit)
+}
+
+func (c *Client) GetNotificationsByLabels(labels []string, limit int) ([]contract.Notification, error) {
+	return c.getNotifications(func(n contract.Notification) bool {
+		for _, l := range n.Labels {
+			if containsString(labels, l) {
+				return true
+			}
+		}
+		return false
+	}, limit)
+}
+
+func (c *Client) GetNotificationsByStartEnd(start int64, end int64, limit int) ([]contract.Notification, error) {
+	return c.getNotifications(func(n contract.Notification) bool {
+		return n.Created >= start && n.Created <= end
+	}, limit)
+}
+
+func (c *Client) GetNotificationsByStart(start int64, limit int) ([]contract.Notification, error) {
+	return c.getNotifications(func(n contract.Notification) bool { return n.Created >= start }, limit)
+}
+
+func (c *Client) GetNotificationsByEnd(end int64, limit int) ([]contract.Notification, error) {
+	return c.getNotifications(func(n contract.Notification) bool { return n.Created <= end }, limit)
+}
+
+func (c *Client) GetNewNotifications(limit int) ([]contract.Notification, error) {
+	return c.getNotifications(func(n contract.Notification) bool { return n.Status == contract.New }, limit)
+}
+
+func (c *Client) GetNewNormalNotifications(limit int) ([]contract.Notification, error) {
+	return c.getNotifications(func(n contract.Notification) bool {
+		return n.Status == contract.New && n.Severity == contract.Normal
+	}, limit)
+}
+
+func (c *Client) AddNotification(n contract.Notification) (string, error) {
+	if n.Slug == "" {
+		return "", db.ErrSlugEmpty
+	}
+
+	if n.ID != "" {
+		if _, err := uuid.Parse(n.ID); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	} else {
+		n.ID = uuid.New().String()
+	}
+
+	ts := db.MakeTimestamp()
+	if n.Created == 0 {
+		n.Created = ts
+	}
+	n.Modified = ts
+
+	err := c.update(func(tx *bolt.Tx) error {
+		existing, err := queryNotifications(tx, func(o contract.Notification) bool { return o.Slug == n.Slug })
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+		return putObject(tx, db.Notification, n.ID, n)
+	})
+	if err != nil {
+		return "", err
+	}
+	return n.ID, nil
+}
+
+func (c *Client) UpdateNotification(n contract.Notification) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored contract.Notification
+		if err := getObject(tx, db.Notification, n.ID, &stored); err != nil {
+			return err
+		}
+
+		n.Created = stored.Created
+		n.Modified = db.MakeTimestamp()
+		return putObject(tx, db.Notification, n.ID, n)
+	})
+}
+
+func (c *Client) MarkNotificationProcessed(n contract.Notification) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored contract.Notification
+		if err := getObject(tx, db.Notification, n.ID, &stored); err != nil {
+			return err
+		}
+
+		stored.Status = contract.Processed
+		stored.Modified = db.MakeTimestamp()
+		return putObject(tx, db.Notification, n.ID, stored)
+	})
+}
+
+func (c *Client) DeleteNotificationById(id string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var n contract.Notification
+		if err := getObject(tx, db.Notification, id, &n); err != nil {
+			return err
+		}
+		return deleteNotificationAndAssociatedTransmissions(tx, n)
+	})
+}
+
+func (c *Client) DeleteNotificationBySlug(slug string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		ns, err := queryNotifications(tx, func(n contract.Notification) bool { return n.Slug == slug })
+		if err != nil {
+			return err
+		}
+		if len(ns) == 0 {
+			return db.ErrNotFound
+		}
+		return deleteNotificationAndAssociatedTransmissions(tx, ns[0])
+	})
+}
+
+// DeleteNotificationsOld remove all the processed notifications that are older than the given age
+func (c *Client) DeleteNotificationsOld(age int) error {
+	end := db.MakeTimestamp() - int64(age)
+	return c.deleteNotifications(func(n contract.Notification) bool {
+		return n.Modified <= end && n.Status == contract.Processed
+	})
+}
+
+func (c *Client) getNotifications(match func(n contract.Notification) bool, limit int) ([]contract.Notification, error) {
+	var ns []contract.Notification
+	err := c.view(func(tx *bolt.Tx) (err error) {
+		ns, err = queryNotifications(tx, match)
+		return err
+	})
+	if err != nil {
+		return []contract.Notification{}, err
+	}
+
+	sort.SliceStable(ns, func(i, j int) bool { return ns[i].Created < ns[j].Created })
+	return ns[:applyLimit(len(ns), limit)], nil
+}
+
+func (c *Client) deleteNotifications(match func(n contract.Notification) bool) error {
+	return c.update(func(tx *bolt.Tx) error {
+		ns, err := queryNotifications(tx, match)
+		if err != nil {
+			return err
+		}
+		for _, n := range ns {
+			if err = deleteNotificationAndAssociatedTransmissions(tx, n); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func queryNotifications(tx *bolt.Tx, match func(n contract.Notification) bool) ([]contract.Notification, error) {
+	ns := []contract.Notification{}
+	err := forEachObject(tx, db.Notification, func(v []byte) error {
+		var n contract.Notification
+		if err := unmarshalObject(v, &n); err != nil {
+			return err
+		}
+		if match(n) {
+			ns = append(ns, n)
+		}
+		return nil
+	})
+	return ns, err
+}
+
+func deleteNotificationAndAssociatedTransmissions(tx *bolt.Tx, n contract.Notification) error {
+	ts, err := queryTransmissions(tx, func(t contract.Transmission) bool { return t.Notification.Slug == n.Slug })
+	if err != nil {
+		return err
+	}
+	for _, t := range ts {
+		if err = deleteObject(tx, db.Transmission, t.ID); err != nil {
+			return err
+		}
+	}
+	return deleteObject(tx, db.Notification, n.ID)
+}
+
+/* ----------------------- Subscriptions ------------------------*/
+
+func (c *Client) GetSubscriptionBySlug(slug string) (contract.Subscription, error) {
+	subs, err := c.getSubscriptions(func(s contract.Subscription) bool { return s.Slug == slug })
+	if err != nil {
+		return contract.Subscription{}, err
+	}
+	if len(subs) == 0 {
+		return contract.Subscription{}, db.ErrNotFound
+	}
+	return subs[0], nil
+}
+
+func (c *Client) GetSubscriptionByCategories(categories []string) ([]contract.Subscription, error) {
+	return c.getSubscriptions(func(s contract.Subscription) bool { return subscribedToCategory(s, categories) })
+}
+
+func (c *Client) GetSubscriptionByLabels(labels []string) ([]contract.Subscription, error) {
+	return c.getSubscriptions(func(s contract.Subscription) bool { return subscribedToLabel(s, labels) })
+}
+
+func (c *Client) GetSubscriptionByCategoriesLabels(categories []string, labels []string) ([]contract.Subscription, error) {
+	return c.getSubscriptions(func(s contract.Subscription) bool {
+		return subscribedToCategory(s, categories) && subscribedToLabel(s, labels)
+	})
+}
+
+func (c *Client) GetSubscriptionByReceiver(receiver string) ([]contract.Subscription, error) {
+	return c.getSubscriptions(func(s contract.Subscription) bool { return s.Receiver == receiver })
+}
+
+func (c *Client) GetSubscriptionById(id string) (s contract.Subscription, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.Subscription, id, &s)
+	})
+	if err != nil {
+		return contract.Subscription{}, err
+	}
+	return s, nil
+}
+
+func (c *Client) DeleteSubscriptionById(id string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteObject(tx, db.Subscription, id)
+	})
+}
+
+func (c *Client) AddSubscription(sub contract.Subscription) (string, error) {
+	if sub.Slug == "" {
+		return "", db.ErrSlugEmpty
+	}
+
+	if sub.ID != "" {
+		if _, err := uuid.Parse(sub.ID); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	} else {
+		sub.ID = uuid.New().String()
+	}
+
+	ts := db.MakeTimestamp()
+	if sub.Created == 0 {
+		sub.Created = ts
+	}
+	sub.Modified = ts
+
+	err := c.update(func(tx *bolt.Tx) error {
+		existing, err := querySubscriptions(tx, func(o contract.Subscription) bool { return o.Slug == sub.Slug })
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+		return putObject(tx, db.Subscription, sub.ID, sub)
+	})
+	if err != nil {
+		return "", err
+	}
+	return sub.ID, nil
+}
+
+func (c *Client) UpdateSubscription(sub contract.Subscription) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored contract.Subscription
+		if err := getObject(tx, db.Subscription, sub.ID, &stored); err != nil {
+			return err
+		}
+
+		existing, err := querySubscriptions(tx, func(o contract.Subscription) bool {
+			return o.Slug == sub.Slug && o.ID != sub.ID
+		})
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		sub.Created = stored.Created
+		sub.Modified = db.MakeTimestamp()
+		return putObject(tx, db.Subscription, sub.ID, sub)
+	})
+}
+
+func (c *Client) DeleteSubscriptionBySlug(slug string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		subs, err := querySubscriptions(tx, func(s contract.Subscription) bool { return s.Slug == slug })
+		if err != nil {
+			return err
+		}
+		if len(subs) == 0 {
+			return db.ErrNotFound
+		}
+		return deleteObject(tx, db.Subscription, subs[0].ID)
+	})
+}
+
+// Return all the subscriptions
+// UnexpectedError - failed to retrieve subscriptions from the database
+func (c *Client) GetSubscriptions() ([]contract.Subscription, error) {
+	return c.getSubscriptions(func(s contract.Subscription) bool { return true })
+}
+
+func (c *Client) getSubscriptions(match func(s contract.Subscription) bool) (subs []contract.Subscription, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		subs, err = querySubscriptions(tx, match)
+		return err
+	})
+	if err != nil {
+		return []contract.Subscription{}, err
+	}
+	return subs, nil
+}
+
+func querySubscriptions(tx *bolt.Tx, match func(s contract.Subscription) bool) ([]contract.Subscription, error) {
+	subs := []contract.Subscription{}
+	err := forEachObject(tx, db.Subscription, func(v []byte) error {
+		var s contract.Subscription
+		if err := unmarshalObject(v, &s); err != nil {
+			return err
+		}
+		if match(s) {
+			subs = append(subs, s)
+		}
+		return nil
+	})
+	return subs, err
+}
+
+func subscribedToCategory(s contract.Subscription, categories []string) bool {
+	for _, category := range s.SubscribedCategories {
+		if containsString(categories, string(category)) {
+			return true
+		}
+	}
+	return false
+}
+
+func subscribedToLabel(s contract.Subscription, labels []string) bool {
+	for _, label := range s.SubscribedLabels {
+		if containsString(labels, label) {
+			return true
+		}
+	}
+	return false
+}
+
+/* ----------------------- Transmissions ------------------------*/
+
+// limits for transmissions here refer to resend counts. Only transmissions that have been resent no
+// more than limit times are returned. A limit of zero or less returns transmissions regardless of
+// their resend count.
+
+func (c *Client) AddTransmission(t contract.Transmission) (string, error) {
+	if t.ID != "" {
+		if _, err := uuid.Parse(t.ID); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	} else {
+		t.ID = uuid.New().String()
+	}
+
+	ts := db.MakeTimestamp()
+	if t.Created == 0 {
+		t.Created = ts
+	}
+	t.Modified = ts
+
+	err := c.update(func(tx *bolt.Tx) error {
+		return putObject(tx, db.Transmission, t.ID, t)
+	})
+	if err != nil {
+		return "", err
+	}
+	return t.ID, nil
+}
+
+func (c *Client) UpdateTransmission(t contract.Transmission) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored contract.Transmission
+		if err := getObject(tx, db.Transmission, t.ID, &stored); err != nil {
+			return err
+		}
+
+		t.Created = stored.Created
+		t.Modified = db.MakeTimestamp()
+		return putObject(tx, db.Transmission, t.ID, t)
+	})
+}
+
+// DeleteTransmission removes the transmissions with the given status that are older than age
+func (c *Client) DeleteTransmission(age int64, status contract.TransmissionStatus) error {
+	end := db.MakeTimestamp() - age
+	return c.update(func(tx *bolt.Tx) error {
+		ts, err := queryTransmissions(tx, func(t contract.Transmission) bool {
+			return t.Modified < end && t.Status == status
+		})
+		if err != nil {
+			return err
+		}
+		for _, t := range ts {
+			if err = deleteObject(tx, db.Transmission, t.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *Client) GetTransmissionById(id string) (t contract.Transmission, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.Transmission, id, &t)
+	})
+	if err != nil {
+		return contract.Transmission{}, err
+	}
+	return t, nil
+}
+
+func (c *Client) GetTransmissionsByNotificationSlug(slug string, limit int) ([]contract.Transmission, error) {
+	return c.getTransmissions(func(t contract.Transmission) bool { return t.Notification.Slug == slug }, limit)
+}
+
+func (c *Client) GetTransmissionsByNotificationSlugAndStartEnd(slug string, start int64, end int64, limit int) ([]contract.Transmission, error) {
+	return c.getTransmissions(func(t contract.Transmission) bool {
+		return t.Notification.Slug == slug && t.Created >= start && t.Created <= end
+	}, limit)
+}
+
+func (c *Client) GetTransmissionsByStartEnd(start int64, end int64, limit int) ([]contract.Transmission, error) {
+	return c.getTransmissions(func(t contract.Transmission) bool {
+		return t.Created >= start && t.Created <= end
+	}, limit)
+}
+
+func (c *Client) GetTransmissionsByStart(start int64, limit int) ([]contract.Transmission, error) {
+	return c.getTransmissions(func(t contract.Transmission) bool { return t.Created >= start }, limit)
+}
+
+func (c *Client) GetTransmissionsByEnd(end int64, limit int) ([]contract.Transmission, error) {
+	return c.getTransmissions(func(t contract.Transmission) bool { return t.Created <= end }, limit)
+}
+
+func (c *Client) GetTransmissionsByStatus(limit int, status contract.TransmissionStatus) ([]contract.Transmission, error) {
+	return c.getTransmissions(func(t contract.Transmission) bool { return t.Status == status }, limit)
+}
+
+func (c *Client) getTransmissions(match func(t contract.Transmission) bool, limit int) ([]contract.Transmission, error) {
+	var ts []contract.Transmission
+	err := c.view(func(tx *bolt.Tx) (err error) {
+		ts, err = queryTransmissions(tx, func(t contract.Transmission) bool {
+			return (limit <= 0 || t.ResendCount <= limit) && match(t)
+		})
+		return err
+	})
+	if err != nil {
+		return []contract.Transmission{}, err
+	}
+
+	sort.SliceStable(ts, func(i, j int) bool { return ts[i].Created < ts[j].Created })
+	return ts, nil
+}
+
+func queryTransmissions(tx *bolt.Tx, match func(t contract.Transmission) bool) ([]contract.Transmission, error) {
+	ts := []contract.Transmission{}
+	err := forEachObject(tx, db.Transmission, func(v []byte) error {
+		var t contract.Transmission
+		if err := unmarshalObject(v, &t); err != nil {
+			return err
+		}
+		if match(t) {
+			ts = append(ts, t)
+		}
+		return nil
+	})
+	return ts, err
+}
+
+/* ----------------------- General Cleanup ------------------------*/
+
+// Cleanup removes all of the notifications and their transmissions
+func (c *Client) Cleanup() error {
+	return c.CleanupOld(0)
+}
+
+// CleanupOld removes the notifications that have not been modified within age milliseconds, along
+// with their transmissions
+func (c *Client) CleanupOld(age int) error {
+	end := db.MakeTimestamp() - int64(age)
+	return c.deleteNotifications(func(n contract.Notification) bool { return n.Modified <= end })
+}
@@ -0,0 +1,308 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package embedded
+
+import (
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
+
+	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
+	"github.com/google/uuid"
+	"github.com/imdario/mergo"
+	bolt "go.etcd.io/bbolt"
+)
+
+// ******************************* INTERVALS **********************************
+
+// Return all the Interval(s)
+func (c *Client) Intervals() ([]contract.Interval, error) {
+	return c.getIntervals(func(i contract.Interval) bool { return true }, 0)
+}
+
+// Return Interval(s) up to the max number specified
+func (c *Client) IntervalsWithLimit(limit int) ([]contract.Interval, error) {
+	return c.getIntervals(func(i contract.Interval) bool { return true }, limit)
+}
+
+// Return an Interval by name
+func (c *Client) IntervalByName(name string) (contract.Interval, error) {
+	intervals, err := c.getIntervals(func(i contract.Interval) bool { return i.Name == name }, 1)
+	if err != nil {
+		return contract.Interval{}, err
+	}
+	if len(intervals) == 0 {
+		return contract.Interval{}, db.ErrNotFound
+	}
+	return intervals[0], nil
+}
+
+// Return an Interval by ID
+func (c *Client) IntervalById(id string) (interval contract.Interval, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.Interval, id, &interval)
+	})
+	if err != nil {
+		return contract.Interval{}, err
+	}
+	return interval, nil
+}
+
+// Add a new Interval
+func (c *Client) AddInterval(interval contract.Interval) (string, error) {
+	if interval.ID != "" {
+		if _, err := uuid.Parse(interval.ID); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	} else {
+		interval.ID = uuid.New().String()
+	}
+
+	if interval.Timestamps.Created == 0 {
+		ts := db.MakeTimestamp()
+		interval.Timestamps.Created = ts
+		interval.Timestamps.Modified = ts
+	}
+
+	err := c.update(func(tx *bolt.Tx) error {
+		existing, err := queryIntervals(tx, func(o contract.Interval) bool { return o.Name == interval.Name })
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+		return putObject(tx, db.Interval, interval.ID, interval)
+	})
+	if err != nil {
+		return "", err
+	}
+	return interval.ID, nil
+}
+
+// Update an Interval. Fields which are not set are left unchanged.
+func (c *Client) UpdateInterval(interval contract.Interval) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored contract.Interval
+		if err := getObject(tx, db.Interval, interval.ID, &stored); err != nil {
+			return err
+		}
+
+		existing, err := queryIntervals(tx, func(o contract.Interval) bool {
+			return o.Name == interval.Name && o.ID != interval.ID
+		})
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		interval.Timestamps.Modified = db.MakeTimestamp()
+		if err = mergo.Merge(&interval, stored); err != nil {
+			return err
+		}
+		return putObject(tx, db.Interval, interval.ID, interval)
+	})
+}
+
+// Remove an Interval by ID
+func (c *Client) DeleteIntervalById(id string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteObject(tx, db.Interval, id)
+	})
+}
+
+// Scrub all the Interval(s) from the database (only used in test)
+func (c *Client) ScrubAllIntervals() (count int, err error) {
+	err = c.update(func(tx *bolt.Tx) error {
+		count = countObjects(tx, db.Interval)
+		return scrubCollection(tx, db.Interval)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (c *Client) getIntervals(match func(i contract.Interval) bool, limit int) ([]contract.Interval, error) {
+	var intervals []contract.Interval
+	err := c.view(func(tx *bolt.Tx) (err error) {
+		intervals, err = queryIntervals(tx, match)
+		return err
+	})
+	if err != nil {
+		return []contract.Interval{}, err
+	}
+	return intervals[:applyLimit(len(intervals), limit)], nil
+}
+
+func queryIntervals(tx *bolt.Tx, match func(i contract.Interval) bool) ([]contract.Interval, error) {
+	intervals := []contract.Interval{}
+	err := forEachObject(tx, db.Interval, func(v []byte) error {
+		var i contract.Interval
+		if err := unmarshalObject(v, &i); err != nil {
+			return err
+		}
+		if match(i) {
+			intervals = append(intervals, i)
+		}
+		return nil
+	})
+	return intervals, err
+}
+
+// ******************************* INTERVAL ACTIONS **********************************
+
+// Return all the IntervalAction(s)
+func (c *Client) IntervalActions() ([]contract.IntervalAction, error) {
+	return c.getIntervalActions(func(a contract.IntervalAction) bool { return true }, 0)
+}
+
+// Return IntervalAction(s) up to the max number specified
+func (c *Client) IntervalActionsWithLimit(limit int) ([]contract.IntervalAction, error) {
+	return c.getIntervalActions(func(a contract.IntervalAction) bool { return true }, limit)
+}
+
+// Return the IntervalAction(s) belonging to the named Interval
+func (c *Client) IntervalActionsByIntervalName(name string) ([]contract.IntervalAction, error) {
+	return c.getIntervalActions(func(a contract.IntervalAction) bool { return a.Interval == name }, 0)
+}
+
+// Return the IntervalAction(s) with the given target
+func (c *Client) IntervalActionsByTarget(name string) ([]contract.IntervalAction, error) {
+	return c.getIntervalActions(func(a contract.IntervalAction) bool { return a.Target == name }, 0)
+}
+
+// Return an IntervalAction by ID
+func (c *Client) IntervalActionById(id string) (action contract.IntervalAction, err error) {
+	err = c.view(func(tx *bolt.Tx) error {
+		return getObject(tx, db.IntervalAction, id, &action)
+	})
+	if err != nil {
+		return contract.IntervalAction{}, err
+	}
+	return action, nil
+}
+
+// Return an IntervalAction by name
+func (c *Client) IntervalActionByName(name string) (contract.IntervalAction, error) {
+	actions, err := c.getIntervalActions(func(a contract.IntervalAction) bool { return a.Name == name }, 1)
+	if err != nil {
+		return contract.IntervalAction{}, err
+	}
+	if len(actions) == 0 {
+		return contract.IntervalAction{}, db.ErrNotFound
+	}
+	return actions[0], nil
+}
+
+// Add a new IntervalAction
+func (c *Client) AddIntervalAction(action contract.IntervalAction) (string, error) {
+	if action.ID != "" {
+		if _, err := uuid.Parse(action.ID); err != nil {
+			return "", db.ErrInvalidObjectId
+		}
+	} else {
+		action.ID = uuid.New().String()
+	}
+
+	if action.Created == 0 {
+		ts := db.MakeTimestamp()
+		action.Created = ts
+		action.Modified = ts
+	}
+
+	err := c.update(func(tx *bolt.Tx) error {
+		existing, err := queryIntervalActions(tx, func(o contract.IntervalAction) bool { return o.Name == action.Name })
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+		return putObject(tx, db.IntervalAction, action.ID, action)
+	})
+	if err != nil {
+		return "", err
+	}
+	return action.ID, nil
+}
+
+// Update an IntervalAction. Fields which are not set are left unchanged.
+func (c *Client) UpdateIntervalAction(action contract.IntervalAction) error {
+	return c.update(func(tx *bolt.Tx) error {
+		var stored contract.IntervalAction
+		if err := getObject(tx, db.IntervalAction, action.ID, &stored); err != nil {
+			return err
+		}
+
+		existing, err := queryIntervalActions(tx, func(o contract.IntervalAction) bool {
+			return o.Name == action.Name && o.ID != action.ID
+		})
+		if err != nil {
+			return err
+		} else if len(existing) > 0 {
+			return db.ErrNotUnique
+		}
+
+		action.Modified = db.MakeTimestamp()
+		if err = mergo.Merge(&action, stored); err != nil {
+			return err
+		}
+		return putObject(tx, db.IntervalAction, action.ID, action)
+	})
+}
+
+// Remove an IntervalAction by ID
+func (c *Client) DeleteIntervalActionById(id string) error {
+	return c.update(func(tx *bolt.Tx) error {
+		return deleteObject(tx, db.IntervalAction, id)
+	})
+}
+
+// Scrub all the IntervalAction(s) from the database (only used in test)
+func (c *Client) ScrubAllIntervalActions() (count int, err error) {
+	err = c.update(func(tx *bolt.Tx) error {
+		count = countObjects(tx, db.IntervalAction)
+		return scrubCollection(tx, db.IntervalAction)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (c *Client) getIntervalActions(match func(a contract.IntervalAction) bool, limit int) ([]contract.IntervalAction, error) {
+	var actions []contract.IntervalAction
+	err := c.view(func(tx *bolt.Tx) (err error) {
+		actions, err = queryIntervalActions(tx, match)
+		return err
+	})
+	if err != nil {
+		return []contract.IntervalAction{}, err
+	}
+	return actions[:applyLimit(len(actions), limit)], nil
+}
+
+func queryIntervalActions(tx *bolt.Tx, match func(a contract.IntervalAction) bool) ([]contract.IntervalAction, error) {
+	actions := []contract.IntervalAction{}
+	err := forEachObject(tx, db.IntervalAction, func(v []byte) error {
+		var a contract.IntervalAction
+		if err := unmarshalObject(v, &a); err != nil {
+			return err
+		}
+		if match(a) {
+			actions = append(actions, a)
+		}
+		return nil
+	})
+	return actions, err
+}
@@ -15,7 +15,10 @@
 package interfaces
 
 import (
+	command "github.com/edgexfoundry/edgex-go/internal/core/command/models"
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	correlation "github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
+	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 )
 
@@ -25,6 +28,7 @@ type DBClient interface {
 	Events() ([]contract.Event, error)
 	EventsWithLimit(limit int) ([]contract.Event, error)
 	AddEvent(e correlation.Event) (string, error)
+	AddEvents(events []correlation.Event) ([]string, error)
 	UpdateEvent(e correlation.Event) error
 	EventById(id string) (contract.Event, error)
 	EventsByChecksum(checksum string) ([]contract.Event, error)
@@ -33,8 +37,10 @@ type DBClient interface {
 	DeleteEventById(id string) error
 	DeleteEventsByDevice(deviceId string) (int, error)
 	EventsForDeviceLimit(id string, limit int) ([]contract.Event, error)
+	EventsForDeviceWithOffset(id string, limit int, offset int) ([]contract.Event, error)
 	EventsForDevice(id string) ([]contract.Event, error)
 	EventsByCreationTime(startTime, endTime int64, limit int) ([]contract.Event, error)
+	EventsByCreationTimeWithOffset(startTime, endTime int64, limit int, offset int) ([]contract.Event, error)
 	EventsOlderThanAge(age int64) ([]contract.Event, error)
 	EventsPushed() ([]contract.Event, error)
 	ScrubAllEvents() error
@@ -47,10 +53,14 @@ type DBClient interface {
 	DeleteReadingById(id string) error
 	DeleteReadingsByDevice(deviceId string) error
 	ReadingsByDevice(id string, limit int) ([]contract.Reading, error)
+	ReadingsByDeviceWithOffset(id string, limit int, offset int) ([]contract.Reading, error)
 	ReadingsByValueDescriptor(name string, limit int) ([]contract.Reading, error)
+	ReadingsByValueDescriptorWithOffset(name string, limit int, offset int) ([]contract.Reading, error)
 	ReadingsByValueDescriptorNames(names []string, limit int) ([]contract.Reading, error)
 	ReadingsByCreationTime(start, end int64, limit int) ([]contract.Reading, error)
+	ReadingsByCreationTimeWithOffset(start, end int64, limit int, offset int) ([]contract.Reading, error)
 	ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) ([]contract.Reading, error)
+	ReadingBuckets(deviceId, valueDescriptor string, start, end int64, width int64) ([]db.ReadingBucket, error)
 
 	ValueDescriptors() ([]contract.ValueDescriptor, error)
 	AddValueDescriptor(v contract.ValueDescriptor) (string, error)
@@ -64,11 +74,11 @@ type DBClient interface {
 	ValueDescriptorsByType(t string) ([]contract.ValueDescriptor, error)
 	ScrubAllValueDescriptors() error
 
-	Registrations() ([]contract.Registration, error)
-	AddRegistration(r contract.Registration) (string, error)
-	UpdateRegistration(reg contract.Registration) error
-	RegistrationById(id string) (contract.Registration, error)
-	RegistrationByName(name string) (contract.Registration, error)
+	Registrations() ([]export.Registration, error)
+	AddRegistration(r export.Registration) (string, error)
+	UpdateRegistration(reg export.Registration) error
+	RegistrationById(id string) (export.Registration, error)
+	RegistrationByName(name string) (export.Registration, error)
 	DeleteRegistrationById(id string) error
 	DeleteRegistrationByName(name string) error
 	ScrubAllRegistrations() error
@@ -139,6 +149,10 @@ type DBClient interface {
 	GetCommandsByDeviceId(did string) ([]contract.Command, error)
 	GetCommandByNameAndDeviceId(cname string, did string) (contract.Command, error)
 
+	AddCommandAudit(a command.Audit) (string, error)
+	GetCommandAudits(device string, start int64, end int64, status int, limit int) ([]command.Audit, error)
+	DeleteCommandAuditsOld(age int64) (int, error)
+
 	ScrubMetadata() error
 
 	GetNotifications() ([]contract.Notification, error)
@@ -42,6 +42,12 @@ func TestMongoDB(t *testing.T) {
 	}
 	test.TestMetadataDB(t, mongo)
 
+	mongo, err = NewClient(config)
+	if err != nil {
+		t.Fatalf("Could not connect: %v", err)
+	}
+	test.TestCommandDB(t, mongo)
+
 	config.DatabaseName = "export"
 	mongo, err = NewClient(config)
 	if err != nil {
@@ -14,6 +14,8 @@
 package mongo
 
 import (
+	"math"
+
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
@@ -52,43 +54,86 @@ func (mc MongoClient) AddEvent(e correlation.Event) (string, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
 
+	readings, mapped, id, err := mc.mapEventForAdd(e)
+	if err != nil {
+		return "", err
+	}
+
 	// Add the readings
-	if len(e.Readings) > 0 {
-		var ui []interface{}
-		for i, reading := range e.Readings {
-			var r models.Reading
-			id, err := r.FromContract(reading)
-			if err != nil {
-				return "", err
-			}
+	if len(readings) > 0 {
+		if err := s.DB(mc.database.Name).C(db.ReadingsCollection).Insert(readings...); err != nil {
+			return "", errorMap(err)
+		}
+	}
 
-			r.TimestampForAdd()
+	if err = s.DB(mc.database.Name).C(db.EventsCollection).Insert(mapped); err != nil {
+		return "", errorMap(err)
+	}
+	return id, nil
+}
 
-			if reading.Device == "" {
-				r.Device = e.Device
-			}
+// Add a batch of events, the readings of all the events are inserted at once followed by the events
+// Returns the ids of the events in the order of the batch
+func (mc MongoClient) AddEvents(events []correlation.Event) ([]string, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
 
-			ui = append(ui, r)
+	var readings []interface{}
+	mapped := make([]interface{}, len(events))
+	ids := make([]string, len(events))
+	for i, e := range events {
+		r, m, id, err := mc.mapEventForAdd(e)
+		if err != nil {
+			return nil, err
+		}
+		readings = append(readings, r...)
+		mapped[i] = m
+		ids[i] = id
+	}
 
-			e.Readings[i].Id = id
+	if len(readings) > 0 {
+		if err := s.DB(mc.database.Name).C(db.ReadingsCollection).Insert(readings...); err != nil {
+			return nil, errorMap(err)
 		}
-		if err := s.DB(mc.database.Name).C(db.ReadingsCollection).Insert(ui...); err != nil {
-			return "", errorMap(err)
+	}
+	if len(mapped) > 0 {
+		if err := s.DB(mc.database.Name).C(db.EventsCollection).Insert(mapped...); err != nil {
+			return nil, errorMap(err)
+		}
+	}
+	return ids, nil
+}
+
+// mapEventForAdd maps a new event and its readings to their stored form, the readings are given their ids
+func (mc MongoClient) mapEventForAdd(e correlation.Event) ([]interface{}, models.Event, string, error) {
+	var readings []interface{}
+	for i, reading := range e.Readings {
+		var r models.Reading
+		id, err := r.FromContract(reading)
+		if err != nil {
+			return nil, models.Event{}, "", err
 		}
+
+		r.TimestampForAdd()
+
+		if reading.Device == "" {
+			r.Device = e.Device
+		}
+
+		readings = append(readings, r)
+
+		e.Readings[i].Id = id
 	}
 
 	var mapped models.Event
 	id, err := mapped.FromContract(e, mc)
 	if err != nil {
-		return "", err
+		return nil, models.Event{}, "", err
 	}
 
 	mapped.TimestampForAdd()
 
-	if err = s.DB(mc.database.Name).C(db.EventsCollection).Insert(mapped); err != nil {
-		return "", errorMap(err)
-	}
-	return id, nil
+	return readings, mapped, id, nil
 }
 
 // Update an event - do NOT update readings
@@ -190,7 +235,12 @@ func (mc MongoClient) DeleteEventsByDevice(deviceId string) (int, error) {
 
 // Get a list of events based on the device id and limit
 func (mc MongoClient) EventsForDeviceLimit(id string, limit int) ([]contract.Event, error) {
-	return mc.mapEvents(mc.getEventsLimit(bson.M{"device": id}, limit))
+	return mc.EventsForDeviceWithOffset(id, limit, 0)
+}
+
+// Get a page of events based on the device id, skipping the first offset events
+func (mc MongoClient) EventsForDeviceWithOffset(id string, limit int, offset int) ([]contract.Event, error) {
+	return mc.mapEvents(mc.getEventsPage(bson.M{"device": id}, limit, offset))
 }
 
 // Get a list of events based on the device id
@@ -201,11 +251,71 @@ func (mc MongoClient) EventsForDevice(id string) ([]contract.Event, error) {
 // Return a list of events whose creation time is between startTime and endTime
 // Limit the number of results by limit
 func (mc MongoClient) EventsByCreationTime(startTime, endTime int64, limit int) ([]contract.Event, error) {
+	return mc.EventsByCreationTimeWithOffset(startTime, endTime, limit, 0)
+}
+
+// Return a page of events whose creation time is between startTime and endTime
+// The first offset events are skipped and the number of results is limited by limit
+func (mc MongoClient) EventsByCreationTimeWithOffset(startTime, endTime int64, limit int, offset int) ([]contract.Event, error) {
+	query := bson.M{"created": bson.M{
+		"$gte": startTime,
+		"$lte": endTime,
+	}}
+	return mc.mapEvents(mc.getEventsPage(query, limit, offset))
+}
+
+// Return a page of events whos creation time is between startTime and endTime in ascending order of creation time
+// and id, starting after the event created at afterCreated with the id afterId
+func (mc MongoClient) EventsByCreationTimeAfter(startTime, endTime int64, afterCreated int64, afterId string, limit int) ([]contract.Event, error) {
+	if limit == 0 {
+		return []contract.Event{}, nil
+	}
+
 	query := bson.M{"created": bson.M{
 		"$gte": startTime,
 		"$lte": endTime,
 	}}
-	return mc.mapEvents(mc.getEventsLimit(query, limit))
+	if afterId != "" {
+		after, err := afterEventQuery(afterCreated, afterId)
+		if err != nil {
+			return []contract.Event{}, err
+		}
+		query = bson.M{"$and": []bson.M{query, after}}
+	}
+
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	var me []models.Event
+	// The events given a UUID are identified by it, the others by their BSON id. Those without a UUID sort first.
+	err := s.DB(mc.database.Name).C(db.EventsCollection).Find(query).Sort("created", "uuid", "_id").Limit(limit).All(&me)
+	if err != nil {
+		return []contract.Event{}, errorMap(err)
+	}
+	return mc.mapEvents(me, nil)
+}
+
+// afterEventQuery selects the events which sort after the event created at the given time with the given id, in
+// the order of creation time, UUID and BSON id
+func afterEventQuery(created int64, id string) (bson.M, error) {
+	name, value, err := idToQueryParameters(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sameCreated := bson.M{"created": created}
+	if name == "uuid" {
+		sameCreated["uuid"] = bson.M{"$gt": value}
+	} else {
+		sameCreated["$or"] = []bson.M{
+			{"uuid": bson.M{"$exists": true}},
+			{"uuid": bson.M{"$exists": false}, "_id": bson.M{"$gt": value}},
+		}
+	}
+	return bson.M{"$or": []bson.M{
+		{"created": bson.M{"$gt": created}},
+		sameCreated,
+	}}, nil
 }
 
 // Get Events that are older than the given age (defined by age = now - created)
@@ -252,6 +362,12 @@ func (mc MongoClient) getEvents(q bson.M) (me []models.Event, err error) {
 // Get events with a limit
 // Sort the list before applying the limit so we can return the most recent events
 func (mc MongoClient) getEventsLimit(q bson.M, limit int) (me []models.Event, err error) {
+	return mc.getEventsPage(q, limit, 0)
+}
+
+// Get a page of events with a limit, skipping the first offset events
+// The id is used as a secondary sort key so consecutive pages neither overlap nor leave gaps
+func (mc MongoClient) getEventsPage(q bson.M, limit int, offset int) (me []models.Event, err error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
 
@@ -260,7 +376,7 @@ func (mc MongoClient) getEventsLimit(q bson.M, limit int) (me []models.Event, er
 		return []models.Event{}, nil
 	}
 
-	err = s.DB(mc.database.Name).C(db.EventsCollection).Find(q).Sort("-modified").Limit(limit).All(&me)
+	err = s.DB(mc.database.Name).C(db.EventsCollection).Find(q).Sort("-modified", "-_id").Skip(offset).Limit(limit).All(&me)
 	if err != nil {
 		return []models.Event{}, errorMap(err)
 	}
@@ -398,13 +514,23 @@ func (mc MongoClient) DeleteReadingsByDevice(deviceId string) error {
 // Return a list of readings for the given device (id or name)
 // Sort the list of readings on creation date
 func (mc MongoClient) ReadingsByDevice(id string, limit int) ([]contract.Reading, error) {
-	return mapReadings(mc.getReadingsLimit(bson.M{"device": id}, limit))
+	return mc.ReadingsByDeviceWithOffset(id, limit, 0)
+}
+
+// Return a page of readings for the given device, skipping the first offset readings
+func (mc MongoClient) ReadingsByDeviceWithOffset(id string, limit int, offset int) ([]contract.Reading, error) {
+	return mapReadings(mc.getReadingsPage(bson.M{"device": id}, limit, offset))
 }
 
 // Return a list of readings for the given value descriptor
 // Limit by the given limit
 func (mc MongoClient) ReadingsByValueDescriptor(name string, limit int) ([]contract.Reading, error) {
-	return mapReadings(mc.getReadingsLimit(bson.M{"name": name}, limit))
+	return mc.ReadingsByValueDescriptorWithOffset(name, limit, 0)
+}
+
+// Return a page of readings for the given value descriptor, skipping the first offset readings
+func (mc MongoClient) ReadingsByValueDescriptorWithOffset(name string, limit int, offset int) ([]contract.Reading, error) {
+	return mapReadings(mc.getReadingsPage(bson.M{"name": name}, limit, offset))
 }
 
 // Return a list of readings whose name is in the list of value descriptor names
@@ -415,7 +541,13 @@ func (mc MongoClient) ReadingsByValueDescriptorNames(names []string, limit int)
 // Return a list of readings whose creation time is in-between start and end
 // Limit by the limit parameter
 func (mc MongoClient) ReadingsByCreationTime(start, end int64, limit int) ([]contract.Reading, error) {
-	return mapReadings(mc.getReadingsLimit(bson.M{"created": bson.M{"$gte": start, "$lte": end}}, limit))
+	return mc.ReadingsByCreationTimeWithOffset(start, end, limit, 0)
+}
+
+// Return a page of readings whose creation time is in-between start and end
+// The first offset readings are skipped
+func (mc MongoClient) ReadingsByCreationTimeWithOffset(start, end int64, limit int, offset int) ([]contract.Reading, error) {
+	return mapReadings(mc.getReadingsPage(bson.M{"created": bson.M{"$gte": start, "$lte": end}}, limit, offset))
 }
 
 // Return a list of readings for a device filtered by the value descriptor and limited by the limit
@@ -424,9 +556,87 @@ func (mc MongoClient) ReadingsByDeviceAndValueDescriptor(deviceId, valueDescript
 	return mapReadings(mc.getReadingsLimit(bson.M{"device": deviceId, "name": valueDescriptor}, limit))
 }
 
+// Aggregate the numeric readings of a device for a value descriptor into buckets of the given width
+// The values are stored as strings so they are converted in the pipeline with $convert, which requires MongoDB 4.0 or
+// later; the aggregation fails on earlier versions. Readings whose value can't be converted to a finite number are
+// ignored, as NaN and infinite values are by the other databases.
+func (mc MongoClient) ReadingBuckets(deviceId, valueDescriptor string, start, end int64, width int64) ([]db.ReadingBucket, error) {
+	s := mc.getSessionCopy()
+	defer s.Close()
+
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"device":  deviceId,
+			"name":    valueDescriptor,
+			"created": bson.M{"$gte": start, "$lte": end},
+		}},
+		{"$sort": bson.M{"created": 1}},
+		{"$project": bson.M{
+			"created": 1,
+			"value": bson.M{"$convert": bson.M{
+				"input":   "$value",
+				"to":      "double",
+				"onError": nil,
+				"onNull":  nil,
+			}},
+		}},
+		{"$match": bson.M{"value": bson.M{
+			"$ne":  nil,
+			"$nin": []interface{}{math.NaN(), math.Inf(1), math.Inf(-1)},
+		}}},
+		{"$group": bson.M{
+			"_id": bson.M{"$subtract": []interface{}{
+				"$created",
+				bson.M{"$mod": []interface{}{bson.M{"$subtract": []interface{}{"$created", start}}, width}},
+			}},
+			"count": bson.M{"$sum": 1},
+			"sum":   bson.M{"$sum": "$value"},
+			"min":   bson.M{"$min": "$value"},
+			"max":   bson.M{"$max": "$value"},
+			"first": bson.M{"$first": "$value"},
+			"last":  bson.M{"$last": "$value"},
+		}},
+		{"$sort": bson.M{"_id": 1}},
+	}
+
+	var results []struct {
+		Start int64   `bson:"_id"`
+		Count int     `bson:"count"`
+		Sum   float64 `bson:"sum"`
+		Min   float64 `bson:"min"`
+		Max   float64 `bson:"max"`
+		First float64 `bson:"first"`
+		Last  float64 `bson:"last"`
+	}
+	err := s.DB(mc.database.Name).C(db.ReadingsCollection).Pipe(pipeline).All(&results)
+	if err != nil {
+		return []db.ReadingBucket{}, errorMap(err)
+	}
+
+	buckets := make([]db.ReadingBucket, len(results))
+	for i, r := range results {
+		buckets[i] = db.ReadingBucket{
+			Start: r.Start,
+			Count: r.Count,
+			Sum:   r.Sum,
+			Min:   r.Min,
+			Max:   r.Max,
+			First: r.First,
+			Last:  r.Last,
+		}
+	}
+	return buckets, nil
+}
+
 // Return a list of readings that match.
 // Sort the list before applying the limit so we can return the most recent readings
 func (mc MongoClient) getReadingsLimit(q bson.M, limit int) ([]models.Reading, error) {
+	return mc.getReadingsPage(q, limit, 0)
+}
+
+// Return a page of readings that match, skipping the first offset readings
+// The id is used as a secondary sort key so consecutive pages neither overlap nor leave gaps
+func (mc MongoClient) getReadingsPage(q bson.M, limit int, offset int) ([]models.Reading, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
 
@@ -436,7 +646,7 @@ func (mc MongoClient) getReadingsLimit(q bson.M, limit int) ([]models.Reading, e
 	}
 
 	var readings []models.Reading
-	if err := s.DB(mc.database.Name).C(db.ReadingsCollection).Find(q).Sort("-modified").Limit(limit).All(&readings); err != nil {
+	if err := s.DB(mc.database.Name).C(db.ReadingsCollection).Find(q).Sort("-modified", "-_id").Skip(offset).Limit(limit).All(&readings); err != nil {
 		return []models.Reading{}, errorMap(err)
 	}
 	return readings, nil
@@ -14,9 +14,9 @@
 package mongo
 
 import (
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/mongo/models"
-	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/globalsign/mgo/bson"
 )
 
@@ -24,13 +24,13 @@ import (
 
 // Return all the registrations
 // UnexpectedError - failed to retrieve registrations from the database
-func (mc MongoClient) Registrations() ([]contract.Registration, error) {
+func (mc MongoClient) Registrations() ([]export.Registration, error) {
 	return mapRegistrations(mc.getRegistrations(bson.M{}))
 }
 
 // Add a new registration
 // UnexpectedError - failed to add to database
-func (mc MongoClient) AddRegistration(r contract.Registration) (string, error) {
+func (mc MongoClient) AddRegistration(r export.Registration) (string, error) {
 	s := mc.getSessionCopy()
 	defer s.Close()
 
@@ -51,7 +51,7 @@ func (mc MongoClient) AddRegistration(r contract.Registration) (string, error) {
 // Update a registration
 // UnexpectedError - problem updating in database
 // NotFound - no registration with the ID was found
-func (mc MongoClient) UpdateRegistration(reg contract.Registration) error {
+func (mc MongoClient) UpdateRegistration(reg export.Registration) error {
 	var mapped models.Registration
 	id, err := mapped.FromContract(reg)
 	if err != nil {
@@ -66,10 +66,10 @@ func (mc MongoClient) UpdateRegistration(reg contract.Registration) error {
 // Get a registration by ID
 // UnexpectedError - problem getting in database
 // NotFound - no registration with the ID was found
-func (mc MongoClient) RegistrationById(id string) (contract.Registration, error) {
+func (mc MongoClient) RegistrationById(id string) (export.Registration, error) {
 	reg, err := mc.registrationById(id)
 	if err != nil {
-		return contract.Registration{}, err
+		return export.Registration{}, err
 	}
 	return reg.ToContract(), nil
 }
@@ -91,10 +91,10 @@ func (mc MongoClient) registrationById(id string) (models.Registration, error) {
 // Get a registration by name
 // UnexpectedError - problem getting in database
 // NotFound - no registration with the name was found
-func (mc MongoClient) RegistrationByName(name string) (contract.Registration, error) {
+func (mc MongoClient) RegistrationByName(name string) (export.Registration, error) {
 	reg, err := mc.registrationByName(name)
 	if err != nil {
-		return contract.Registration{}, err
+		return export.Registration{}, err
 	}
 	return reg.ToContract(), nil
 }
@@ -165,12 +165,12 @@ func (mc MongoClient) deleteRegistration(q bson.M) error {
 	return errorMap(s.DB(mc.database.Name).C(db.ExportCollection).Remove(q))
 }
 
-func mapRegistrations(registrations []models.Registration, err error) ([]contract.Registration, error) {
+func mapRegistrations(registrations []models.Registration, err error) ([]export.Registration, error) {
 	if err != nil {
-		return []contract.Registration{}, err
+		return []export.Registration{}, err
 	}
 
-	mapped := make([]contract.Registration, 0)
+	mapped := make([]export.Registration, 0)
 	for _, r := range registrations {
 		mapped = append(mapped, r.ToContract())
 	}
@@ -17,6 +17,7 @@ package mongo
 import (
 	"errors"
 	"fmt"
+	command "github.com/edgexfoundry/edgex-go/internal/core/command/models"
 	types "github.com/edgexfoundry/edgex-go/internal/core/metadata/errors"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db/mongo/models"
@@ -975,6 +976,63 @@ func mapCommands(commands []models.Command, err error) ([]contract.Command, erro
 	return mapped, nil
 }
 
+//  ------------------------Command Audit -------------------------------*/
+
+func (mc MongoClient) AddCommandAudit(a command.Audit) (string, error) {
+	s := mc.session.Copy()
+	defer s.Close()
+
+	var mapped models.CommandAudit
+	id, err := mapped.FromContract(a)
+	if err != nil {
+		return "", err
+	}
+	if mapped.Created == 0 {
+		mapped.Created = db.MakeTimestamp()
+	}
+
+	if err = s.DB(mc.database.Name).C(db.CommandAudit).Insert(mapped); err != nil {
+		return "", errorMap(err)
+	}
+	return id, nil
+}
+
+func (mc MongoClient) GetCommandAudits(device string, start int64, end int64, status int, limit int) ([]command.Audit, error) {
+	s := mc.session.Copy()
+	defer s.Close()
+
+	q := bson.M{"created": bson.M{"$gte": start, "$lte": end}}
+	if device != "" {
+		q["device"] = device
+	}
+	if status != 0 {
+		q["statusCode"] = status
+	}
+
+	var audits []models.CommandAudit
+	if err := s.DB(mc.database.Name).C(db.CommandAudit).Find(q).Sort("-created").Limit(limit).All(&audits); err != nil {
+		return nil, errorMap(err)
+	}
+
+	mapped := make([]command.Audit, 0)
+	for _, a := range audits {
+		mapped = append(mapped, a.ToContract())
+	}
+	return mapped, nil
+}
+
+func (mc MongoClient) DeleteCommandAuditsOld(age int64) (int, error) {
+	s := mc.session.Copy()
+	defer s.Close()
+
+	q := bson.M{"created": bson.M{"$lt": db.MakeTimestamp() - age}}
+	info, err := s.DB(mc.database.Name).C(db.CommandAudit).RemoveAll(q)
+	if err != nil {
+		return 0, errorMap(err)
+	}
+	return info.Removed, nil
+}
+
 // Scrub all metadata
 func (mc MongoClient) ScrubMetadata() error {
 	s := mc.session.Copy()
@@ -1008,6 +1066,10 @@ func (mc MongoClient) ScrubMetadata() error {
 	if err != nil {
 		return errorMap(err)
 	}
+	_, err = s.DB(mc.database.Name).C(db.CommandAudit).RemoveAll(nil)
+	if err != nil {
+		return errorMap(err)
+	}
 
 	return nil
 }
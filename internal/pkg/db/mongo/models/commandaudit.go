@@ -0,0 +1,80 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package models
+
+import (
+	command "github.com/edgexfoundry/edgex-go/internal/core/command/models"
+	"github.com/globalsign/mgo/bson"
+)
+
+type CommandAudit struct {
+	ID            bson.ObjectId `bson:"_id,omitempty"`
+	Uuid          string        `bson:"uuid,omitempty"`
+	Created       int64         `bson:"created"`
+	Device        string        `bson:"device"`
+	Command       string        `bson:"command"`
+	Method        string        `bson:"method"`
+	Body          string        `bson:"body"`
+	QueryParams   string        `bson:"queryParams"`
+	Caller        string        `bson:"caller"`
+	CallerSource  string        `bson:"callerSource"`
+	CorrelationId string        `bson:"correlationId"`
+	StatusCode    int           `bson:"statusCode"`
+	Latency       int64         `bson:"latency"`
+	Response      string        `bson:"response"`
+	Truncated     bool          `bson:"truncated"`
+}
+
+func (a *CommandAudit) ToContract() (c command.Audit) {
+	c.Id = toContractId(a.ID, a.Uuid)
+	c.Created = a.Created
+	c.Device = a.Device
+	c.Command = a.Command
+	c.Method = a.Method
+	c.Body = a.Body
+	c.QueryParams = a.QueryParams
+	c.Caller = a.Caller
+	c.CallerSource = a.CallerSource
+	c.CorrelationId = a.CorrelationId
+	c.StatusCode = a.StatusCode
+	c.Latency = a.Latency
+	c.Response = a.Response
+	c.Truncated = a.Truncated
+	return
+}
+
+func (a *CommandAudit) FromContract(from command.Audit) (id string, err error) {
+	a.ID, a.Uuid, err = fromContractId(from.Id)
+	if err != nil {
+		return
+	}
+
+	a.Created = from.Created
+	a.Device = from.Device
+	a.Command = from.Command
+	a.Method = from.Method
+	a.Body = from.Body
+	a.QueryParams = from.QueryParams
+	a.Caller = from.Caller
+	a.CallerSource = from.CallerSource
+	a.CorrelationId = from.CorrelationId
+	a.StatusCode = from.StatusCode
+	a.Latency = from.Latency
+	a.Response = from.Response
+	a.Truncated = from.Truncated
+
+	id = toContractId(a.ID, a.Uuid)
+	return
+}
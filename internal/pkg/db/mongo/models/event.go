@@ -24,15 +24,16 @@ import (
 )
 
 type Event struct {
-	Created  int64         `bson:"created"`
-	Modified int64         `bson:"modified"`
-	Origin   int64         `bson:"origin"`
-	Id       bson.ObjectId `bson:"_id,omitempty"`
-	Uuid     string        `bson:"uuid,omitempty"`
-	Pushed   int64         `bson:"pushed"`
-	Device   string        `bson:"device"`             // Device identifier (name or id)
-	Readings []mgo.DBRef   `bson:"readings,omitempty"` // List of readings
-	Checksum string        `bson:"checksum,omitempty"` // checksum used to identify events
+	Created  int64             `bson:"created"`
+	Modified int64             `bson:"modified"`
+	Origin   int64             `bson:"origin"`
+	Id       bson.ObjectId     `bson:"_id,omitempty"`
+	Uuid     string            `bson:"uuid,omitempty"`
+	Pushed   int64             `bson:"pushed"`
+	Device   string            `bson:"device"`             // Device identifier (name or id)
+	Readings []mgo.DBRef       `bson:"readings,omitempty"` // List of readings
+	Checksum string            `bson:"checksum,omitempty"` // checksum used to identify events
+	Flags    map[string]string `bson:"flags,omitempty"`    // validation rule violations by reading name
 }
 
 func (e *Event) ToContract(transform readingTransform) (c contract.Event, err error) {
@@ -71,6 +72,7 @@ func (e *Event) FromContract(from correlation.Event, transform readingTransform)
 	e.Pushed = from.Pushed
 	e.Device = from.Device
 	e.Checksum = from.Checksum
+	e.Flags = from.Flags
 
 	e.Readings = []mgo.DBRef{}
 	for _, reading := range from.Readings {
@@ -94,7 +96,11 @@ func (e *Event) TimestampForUpdate() {
 	e.Modified = db.MakeTimestamp()
 }
 
+// TimestampForAdd sets the modification time, and the creation time unless it is already set as with the aggregates
+// of the retention job
 func (e *Event) TimestampForAdd() {
 	e.TimestampForUpdate()
-	e.Created = e.Modified
+	if e.Created == 0 {
+		e.Created = e.Modified
+	}
 }
@@ -78,7 +78,10 @@ func (r *Reading) TimestampForUpdate() {
 	r.Modified = db.MakeTimestamp()
 }
 
+// TimestampForAdd sets the modification time, and the creation time when the reading was not given one
 func (r *Reading) TimestampForAdd() {
 	r.TimestampForUpdate()
-	r.Created = r.Modified
+	if r.Created == 0 {
+		r.Created = r.Modified
+	}
 }
@@ -15,8 +15,8 @@
 package models
 
 import (
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
-	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/globalsign/mgo/bson"
 )
 
@@ -45,9 +45,10 @@ type Registration struct {
 	Compression string
 	Enable      bool
 	Destination string
+	Options     export.Options
 }
 
-func (r *Registration) ToContract() (c contract.Registration) {
+func (r *Registration) ToContract() (c export.Registration) {
 	id := r.Uuid
 	if id == "" {
 		id = r.ID.Hex()
@@ -71,11 +72,12 @@ func (r *Registration) ToContract() (c contract.Registration) {
 	c.Compression = r.Compression
 	c.Enable = r.Enable
 	c.Destination = r.Destination
+	c.Options = r.Options
 
 	return
 }
 
-func (r *Registration) FromContract(from contract.Registration) (id string, err error) {
+func (r *Registration) FromContract(from export.Registration) (id string, err error) {
 	r.ID, r.Uuid, err = fromContractId(from.ID)
 	if err != nil {
 		return
@@ -104,6 +106,7 @@ func (r *Registration) FromContract(from contract.Registration) (id string, err
 	r.Compression = from.Compression
 	r.Enable = from.Enable
 	r.Destination = from.Destination
+	r.Options = from.Options
 
 	id = toContractId(r.ID, r.Uuid)
 	return
@@ -60,6 +60,13 @@ func TestRedisDB(t *testing.T) {
 	test.TestMetadataDB(t, rc)
 	rc.CloseSession()
 
+	rc, err = NewClient(config)
+	if err != nil {
+		t.Fatalf("Could not connect with Redis: %v", err)
+	}
+	test.TestCommandDB(t, rc)
+	rc.CloseSession()
+
 	rc, err = NewClient(config)
 	if err != nil {
 		t.Fatalf("Could not connect with Redis: %v", err)
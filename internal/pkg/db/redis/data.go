@@ -15,6 +15,7 @@ package redis
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
@@ -95,7 +96,36 @@ func (c *Client) AddEvent(e correlation.Event) (id string, err error) {
 			return "", db.ErrInvalidObjectId
 		}
 	}
-	return addEvent(conn, e)
+	return addEvent(conn, true, e)
+}
+
+// Add a batch of events in a single transaction
+// Returns the ids of the events in the order of the batch
+func (c *Client) AddEvents(events []correlation.Event) ([]string, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	ids := make([]string, len(events))
+	_ = conn.Send("MULTI")
+	for i, e := range events {
+		if e.ID != "" {
+			if _, err := uuid.Parse(e.ID); err != nil {
+				_, _ = conn.Do("DISCARD")
+				return nil, db.ErrInvalidObjectId
+			}
+		}
+		id, err := addEvent(conn, false, e)
+		if err != nil {
+			_, _ = conn.Do("DISCARD")
+			return nil, err
+		}
+		ids[i] = id
+	}
+	if _, err := conn.Do("EXEC"); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
 }
 
 // Update an event - do NOT update readings
@@ -128,7 +158,7 @@ func (c *Client) UpdateEvent(e correlation.Event) (err error) {
 		return err
 	}
 
-	_, err = addEvent(conn, e)
+	_, err = addEvent(conn, true, e)
 	return err
 }
 
@@ -334,10 +364,15 @@ func (c *Client) deleteRenamedEvents(device string) {
 
 // Get a list of events based on the device id and limit
 func (c *Client) EventsForDeviceLimit(id string, limit int) (events []contract.Event, err error) {
+	return c.EventsForDeviceWithOffset(id, limit, 0)
+}
+
+// Get a page of events based on the device id, skipping the first offset events
+func (c *Client) EventsForDeviceWithOffset(id string, limit int, offset int) (events []contract.Event, err error) {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
-	objects, err := getObjectsByRange(conn, db.EventsCollection+":device:"+id, 0, limit-1)
+	objects, err := getObjectsByRange(conn, db.EventsCollection+":device:"+id, offset, rangeEnd(limit, offset))
 	if err != nil {
 		if err != redis.ErrNil {
 			return events, err
@@ -368,10 +403,16 @@ func (c *Client) EventsForDevice(id string) (events []contract.Event, err error)
 // Return a list of events whos creation time is between startTime and endTime
 // Limit the number of results by limit
 func (c *Client) EventsByCreationTime(startTime, endTime int64, limit int) (events []contract.Event, err error) {
+	return c.EventsByCreationTimeWithOffset(startTime, endTime, limit, 0)
+}
+
+// Return a page of events whos creation time is between startTime and endTime
+// The first offset events are skipped and the number of results is limited by limit
+func (c *Client) EventsByCreationTimeWithOffset(startTime, endTime int64, limit int, offset int) (events []contract.Event, err error) {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
-	objects, err := getObjectsByScore(conn, db.EventsCollection+":created", startTime, endTime, limit)
+	objects, err := getObjectsByScoreWithOffset(conn, db.EventsCollection+":created", startTime, endTime, limit, offset)
 	if err != nil {
 		if err != redis.ErrNil {
 			return events, err
@@ -387,6 +428,30 @@ func (c *Client) EventsByCreationTime(startTime, endTime int64, limit int) (even
 	return events, nil
 }
 
+// Return a page of events whos creation time is between startTime and endTime in ascending order of creation time
+// and id, starting after the event created at afterCreated with the id afterId
+func (c *Client) EventsByCreationTimeAfter(startTime, endTime int64, afterCreated int64, afterId string, limit int) (events []contract.Event, err error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	if limit == 0 {
+		return events, nil
+	}
+
+	objects, err := getObjectsByScoreAfter(conn, db.EventsCollection+":created", startTime, endTime, afterCreated, afterId, limit)
+	if err != nil {
+		return events, err
+	}
+
+	events = make([]contract.Event, len(objects))
+	err = unmarshalEvents(objects, events)
+	if err != nil {
+		return events, err
+	}
+
+	return events, nil
+}
+
 // Return a list of readings for a device filtered by the value descriptor and limited by the limit
 // The readings are linked to the device through an event
 func (c *Client) ReadingsByDeviceAndValueDescriptor(deviceId, valueDescriptor string, limit int) (readings []contract.Reading, err error) {
@@ -699,10 +764,15 @@ func (c *Client) deleteRenamedReadings(device string) {
 // 404 - meta data checking enabled and can't find the device
 // Sort the list of readings on creation date
 func (c *Client) ReadingsByDevice(id string, limit int) (readings []contract.Reading, err error) {
+	return c.ReadingsByDeviceWithOffset(id, limit, 0)
+}
+
+// Return a page of readings for the given device, skipping the first offset readings
+func (c *Client) ReadingsByDeviceWithOffset(id string, limit int, offset int) (readings []contract.Reading, err error) {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
-	objects, err := getObjectsByRevRange(conn, db.ReadingsCollection+":device:"+id, 0, limit-1)
+	objects, err := getObjectsByRevRange(conn, db.ReadingsCollection+":device:"+id, offset, rangeEnd(limit, offset))
 	if err != nil {
 		if err != redis.ErrNil {
 			return readings, err
@@ -723,10 +793,15 @@ func (c *Client) ReadingsByDevice(id string, limit int) (readings []contract.Rea
 // Return a list of readings for the given value descriptor
 // 413 - the number exceeds the current max limit
 func (c *Client) ReadingsByValueDescriptor(name string, limit int) (readings []contract.Reading, err error) {
+	return c.ReadingsByValueDescriptorWithOffset(name, limit, 0)
+}
+
+// Return a page of readings for the given value descriptor, skipping the first offset readings
+func (c *Client) ReadingsByValueDescriptorWithOffset(name string, limit int, offset int) (readings []contract.Reading, err error) {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
-	objects, err := getObjectsByRange(conn, db.ReadingsCollection+":name:"+name, 0, limit-1)
+	objects, err := getObjectsByRange(conn, db.ReadingsCollection+":name:"+name, offset, rangeEnd(limit, offset))
 	if err != nil {
 		if err != redis.ErrNil {
 			return readings, err
@@ -783,6 +858,12 @@ func (c *Client) ReadingsByValueDescriptorNames(names []string, limit int) (read
 
 // Return a list of readings whos created time is between the start and end times
 func (c *Client) ReadingsByCreationTime(start, end int64, limit int) (readings []contract.Reading, err error) {
+	return c.ReadingsByCreationTimeWithOffset(start, end, limit, 0)
+}
+
+// Return a page of readings whos created time is between the start and end times
+// The first offset readings are skipped
+func (c *Client) ReadingsByCreationTimeWithOffset(start, end int64, limit int, offset int) (readings []contract.Reading, err error) {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
@@ -790,7 +871,7 @@ func (c *Client) ReadingsByCreationTime(start, end int64, limit int) (readings [
 		return readings, nil
 	}
 
-	objects, err := getObjectsByScore(conn, db.ReadingsCollection+":created", start, end, limit)
+	objects, err := getObjectsByScoreWithOffset(conn, db.ReadingsCollection+":created", start, end, limit, offset)
 	if err != nil {
 		return readings, err
 	}
@@ -806,6 +887,58 @@ func (c *Client) ReadingsByCreationTime(start, end int64, limit int) (readings [
 	return readings, nil
 }
 
+// Aggregate the numeric readings of a device for a value descriptor into buckets of the given width
+// The aggregation is run by a script on the server so only the buckets are transferred
+func (c *Client) ReadingBuckets(deviceId, valueDescriptor string, start, end int64, width int64) (buckets []db.ReadingBucket, err error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	s := scripts["aggregateReadings"]
+	replies, err := redis.Values(s.Do(conn,
+		db.ReadingsCollection+":device:"+deviceId,
+		db.ReadingsCollection+":name:"+valueDescriptor,
+		start, end, width))
+	if err != nil && err != redis.ErrNil {
+		return buckets, err
+	}
+
+	buckets = make([]db.ReadingBucket, len(replies))
+	for i, reply := range replies {
+		fields, err := redis.Strings(reply, nil)
+		if err != nil {
+			return []db.ReadingBucket{}, err
+		}
+		if buckets[i], err = parseReadingBucket(fields); err != nil {
+			return []db.ReadingBucket{}, err
+		}
+	}
+
+	return buckets, nil
+}
+
+// parseReadingBucket converts the fields returned by the aggregation script
+// (start, count, sum, min, max, first, last) to a bucket
+func parseReadingBucket(fields []string) (b db.ReadingBucket, err error) {
+	if len(fields) != 7 {
+		return b, fmt.Errorf("unexpected number of aggregation fields: %d", len(fields))
+	}
+
+	if b.Start, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+		return b, err
+	}
+	if b.Count, err = strconv.Atoi(fields[1]); err != nil {
+		return b, err
+	}
+	values := []*float64{&b.Sum, &b.Min, &b.Max, &b.First, &b.Last}
+	for i, v := range values {
+		if *v, err = strconv.ParseFloat(fields[i+2], 64); err != nil {
+			return b, err
+		}
+	}
+
+	return b, nil
+}
+
 // ************************** VALUE DESCRIPTOR FUNCTIONS ***************************
 // Add a value descriptor
 // 409 - Formatting is bad or it is not unique
@@ -1031,7 +1164,7 @@ func (c *Client) ScrubAllValueDescriptors() error {
 }
 
 // ************************** HELPER FUNCTIONS ***************************
-func addEvent(conn redis.Conn, e correlation.Event) (id string, err error) {
+func addEvent(conn redis.Conn, tx bool, e correlation.Event) (id string, err error) {
 	if e.Created == 0 {
 		e.Created = db.MakeTimestamp()
 	}
@@ -1051,7 +1184,9 @@ func addEvent(conn redis.Conn, e correlation.Event) (id string, err error) {
 		}
 	}
 
-	_ = conn.Send("MULTI")
+	if tx {
+		_ = conn.Send("MULTI")
+	}
 	_ = conn.Send("SET", e.ID, m)
 	_ = conn.Send("ZADD", db.EventsCollection, 0, e.ID)
 	_ = conn.Send("ZADD", db.EventsCollection+":created", e.Created, e.ID)
@@ -1084,7 +1219,9 @@ func addEvent(conn redis.Conn, e correlation.Event) (id string, err error) {
 		_ = conn.Send("ZADD", rids...)
 	}
 
-	_, err = conn.Do("EXEC")
+	if tx {
+		_, err = conn.Do("EXEC")
+	}
 	return e.ID, err
 }
 
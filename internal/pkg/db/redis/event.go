@@ -26,6 +26,7 @@ import (
 type redisEvent struct {
 	ID       string
 	Checksum string
+	Flags    map[string]string
 	Pushed   int64
 	Device   string
 	Created  int64
@@ -37,6 +38,7 @@ func marshalEvent(event correlation.Event) (out []byte, err error) {
 	s := redisEvent{
 		ID:       event.ID,
 		Checksum: event.Checksum,
+		Flags:    event.Flags,
 		Pushed:   event.Pushed,
 		Device:   event.Device,
 		Created:  event.Created,
@@ -16,8 +16,8 @@ package redis
 import (
 	"encoding/json"
 
+	"github.com/edgexfoundry/edgex-go/internal/export"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
-	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
 	"github.com/gomodule/redigo/redis"
 	"github.com/google/uuid"
 )
@@ -25,7 +25,7 @@ import (
 // ********************** REGISTRATION FUNCTIONS *****************************
 // Return all the registrations
 // UnexpectedError - failed to retrieve registrations from the database
-func (c *Client) Registrations() (r []contract.Registration, err error) {
+func (c *Client) Registrations() (r []export.Registration, err error) {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
@@ -34,7 +34,7 @@ func (c *Client) Registrations() (r []contract.Registration, err error) {
 		return nil, err
 	}
 
-	r = make([]contract.Registration, len(objects))
+	r = make([]export.Registration, len(objects))
 	for i, object := range objects {
 		err = json.Unmarshal(object, &r[i])
 		if err != nil {
@@ -47,7 +47,7 @@ func (c *Client) Registrations() (r []contract.Registration, err error) {
 
 // Add a new registration
 // UnexpectedError - failed to add to database
-func (c *Client) AddRegistration(reg contract.Registration) (id string, err error) {
+func (c *Client) AddRegistration(reg export.Registration) (id string, err error) {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
@@ -64,7 +64,7 @@ func (c *Client) AddRegistration(reg contract.Registration) (id string, err erro
 // Update a registration
 // UnexpectedError - problem updating in database
 // NotFound - no registration with the ID was found
-func (c *Client) UpdateRegistration(reg contract.Registration) error {
+func (c *Client) UpdateRegistration(reg export.Registration) error {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
@@ -80,7 +80,7 @@ func (c *Client) UpdateRegistration(reg contract.Registration) error {
 // Get a registration by ID
 // UnexpectedError - problem getting in database
 // NotFound - no registration with the ID was found
-func (c *Client) RegistrationById(id string) (r contract.Registration, err error) {
+func (c *Client) RegistrationById(id string) (r export.Registration, err error) {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
@@ -91,7 +91,7 @@ func (c *Client) RegistrationById(id string) (r contract.Registration, err error
 // Get a registration by name
 // UnexpectedError - problem getting in database
 // NotFound - no registration with the name was found
-func (c *Client) RegistrationByName(name string) (r contract.Registration, err error) {
+func (c *Client) RegistrationByName(name string) (r export.Registration, err error) {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
@@ -134,7 +134,7 @@ func (c *Client) ScrubAllRegistrations() (err error) {
 	return unlinkCollection(conn, db.ExportCollection)
 }
 
-func addRegistration(conn redis.Conn, r contract.Registration) (id string, err error) {
+func addRegistration(conn redis.Conn, r export.Registration) (id string, err error) {
 	if r.ID == "" {
 		r.ID = uuid.New().String()
 	}
@@ -166,7 +166,7 @@ func deleteRegistration(conn redis.Conn, id string) error {
 		return err
 	}
 
-	r := contract.Registration{}
+	r := export.Registration{}
 	_ = unmarshalObject(object, &r)
 
 	_ = conn.Send("MULTI")
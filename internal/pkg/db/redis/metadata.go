@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strconv"
 
+	command "github.com/edgexfoundry/edgex-go/internal/core/command/models"
 	types "github.com/edgexfoundry/edgex-go/internal/core/metadata/errors"
 	"github.com/edgexfoundry/edgex-go/internal/pkg/db"
 	contract "github.com/edgexfoundry/go-mod-core-contracts/models"
@@ -1240,13 +1241,130 @@ func deleteCommand(conn redis.Conn, cmd contract.Command) {
 	_ = conn.Send("SREM", db.Command+":name:"+cmd.Name, cmd.Id)
 }
 
+//  ------------------------Command Audit -------------------------------*/
+func (c *Client) AddCommandAudit(a command.Audit) (string, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	if a.Id == "" {
+		a.Id = uuid.New().String()
+	}
+	if a.Created == 0 {
+		a.Created = db.MakeTimestamp()
+	}
+
+	m, err := marshalObject(a)
+	if err != nil {
+		return "", err
+	}
+
+	_ = conn.Send("MULTI")
+	_ = conn.Send("SET", a.Id, m)
+	for _, key := range commandAuditKeys(a) {
+		_ = conn.Send("ZADD", key, a.Created, a.Id)
+	}
+	_, err = conn.Do("EXEC")
+	return a.Id, err
+}
+
+// commandAuditKey returns the key of the sorted set indexing the audit records by creation time, for the device
+// and status when they are given
+func commandAuditKey(device string, status int) string {
+	key := db.CommandAudit
+	if device != "" {
+		key += ":device:" + device
+	}
+	if status != 0 {
+		key += ":status:" + strconv.Itoa(status)
+	}
+	return key
+}
+
+// commandAuditKeys returns the keys of all the sorted sets indexing the audit record
+func commandAuditKeys(a command.Audit) []string {
+	return []string{
+		commandAuditKey("", 0),
+		commandAuditKey(a.Device, 0),
+		commandAuditKey("", a.StatusCode),
+		commandAuditKey(a.Device, a.StatusCode),
+	}
+}
+
+// GetCommandAudits returns the audit records from the most recent
+func (c *Client) GetCommandAudits(device string, start int64, end int64, status int, limit int) ([]command.Audit, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	args := []interface{}{commandAuditKey(device, status), end, start}
+	if limit > 0 {
+		args = append(args, "LIMIT", 0, limit)
+	}
+	ids, err := redis.Values(conn.Do("ZREVRANGEBYSCORE", args...))
+	if err != nil && err != redis.ErrNil {
+		return nil, err
+	}
+
+	audits := make([]command.Audit, 0)
+	if len(ids) == 0 {
+		return audits, nil
+	}
+	objects, err := redis.ByteSlices(conn.Do("MGET", ids...))
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		if object == nil {
+			continue
+		}
+		var a command.Audit
+		if err := unmarshalObject(object, &a); err != nil {
+			return nil, err
+		}
+		audits = append(audits, a)
+	}
+	return audits, nil
+}
+
+func (c *Client) DeleteCommandAuditsOld(age int64) (int, error) {
+	conn := c.Pool.Get()
+	defer conn.Close()
+
+	end := "(" + strconv.FormatInt(db.MakeTimestamp()-age, 10)
+	ids, err := redis.Values(conn.Do("ZRANGEBYSCORE", db.CommandAudit, "-inf", end))
+	if err != nil && err != redis.ErrNil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	objects, err := redis.ByteSlices(conn.Do("MGET", ids...))
+	if err != nil {
+		return 0, err
+	}
+
+	_ = conn.Send("MULTI")
+	for i, object := range objects {
+		var a command.Audit
+		_ = unmarshalObject(object, &a)
+		_ = conn.Send("DEL", ids[i])
+		for _, key := range commandAuditKeys(a) {
+			_ = conn.Send("ZREM", key, ids[i])
+		}
+	}
+	_, err = conn.Do("EXEC")
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
 func (c *Client) ScrubMetadata() (err error) {
 	conn := c.Pool.Get()
 	defer conn.Close()
 
 	cols := []string{
 		db.Addressable, db.Command, db.DeviceService, db.DeviceReport, db.DeviceProfile,
-		db.Device, db.ProvisionWatcher,
+		db.Device, db.ProvisionWatcher, db.CommandAudit,
 	}
 
 	for _, col := range cols {
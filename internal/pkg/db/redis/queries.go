@@ -112,6 +112,15 @@ func getObjectsByValues(conn redis.Conn, vals ...string) (objects [][]byte, err
 	return objects, nil
 }
 
+// rangeEnd returns the inclusive end index of a sorted set range holding limit entries from offset
+// A limit of 0 selects all the remaining entries
+func rangeEnd(limit int, offset int) int {
+	if limit == 0 {
+		return -1
+	}
+	return offset + limit - 1
+}
+
 // getObjectsByRange retrieves the entries for keys enumerated in a sorted set. The entries are retrieved in the sorted set order.
 func getObjectsByRange(conn redis.Conn, key string, start, end int) (objects [][]byte, err error) {
 	return getObjectsBySomeRange(conn, "ZRANGE", key, start, end)
@@ -179,16 +188,27 @@ func getObjectsByRangeFilter(conn redis.Conn, key string, filter string, start,
 }
 
 func getObjectsByScore(conn redis.Conn, key string, start, end int64, limit int) (objects [][]byte, err error) {
+	return getObjectsByScoreWithOffset(conn, key, start, end, limit, 0)
+}
+
+// Return a page of objects by a score from a zset, skipping the first offset entries
+// if limit is 0, all the remaining entries are returned
+// if end is negative, it is considered as positive infinity
+func getObjectsByScoreWithOffset(conn redis.Conn, key string, start, end int64, limit int, offset int) (objects [][]byte, err error) {
 	args := []interface{}{key, start}
 	if end < 0 {
 		args = append(args, "+inf")
 	} else {
 		args = append(args, end)
 	}
-	if limit != 0 {
+	if limit != 0 || offset != 0 {
+		count := limit
+		if count == 0 {
+			count = -1
+		}
 		args = append(args, "LIMIT")
-		args = append(args, 0)
-		args = append(args, limit)
+		args = append(args, offset)
+		args = append(args, count)
 	}
 	ids, err := redis.Values(conn.Do("ZRANGEBYSCORE", args...))
 	if err != nil && err != redis.ErrNil {
@@ -204,6 +224,47 @@ func getObjectsByScore(conn redis.Conn, key string, start, end int64, limit int)
 	return objects, nil
 }
 
+// Return up to limit objects of a zset whose score is between start and end, in the order of the zset, which come
+// after the entry of score afterScore and id afterId. The entry need not exist anymore, entries of equal score being
+// ordered by id. An empty afterId starts with the first entry from start.
+// if end is negative, it is considered as positive infinity
+func getObjectsByScoreAfter(conn redis.Conn, key string, start, end int64, afterScore int64, afterId string, limit int) (objects [][]byte, err error) {
+	var ids []interface{}
+	min := strconv.FormatInt(start, 10)
+	if afterId != "" {
+		ties, err := redis.Strings(conn.Do("ZRANGEBYSCORE", key, afterScore, afterScore))
+		if err != nil && err != redis.ErrNil {
+			return nil, err
+		}
+		for _, id := range ties {
+			if id > afterId && len(ids) < limit {
+				ids = append(ids, id)
+			}
+		}
+		min = "(" + strconv.FormatInt(afterScore, 10)
+	}
+
+	if len(ids) < limit {
+		var max interface{} = end
+		if end < 0 {
+			max = "+inf"
+		}
+		more, err := redis.Values(conn.Do("ZRANGEBYSCORE", key, min, max, "LIMIT", 0, limit-len(ids)))
+		if err != nil && err != redis.ErrNil {
+			return nil, err
+		}
+		ids = append(ids, more...)
+	}
+
+	if len(ids) > 0 {
+		objects, err = redis.ByteSlices(conn.Do("MGET", ids...))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
 // addObject is responsible for setting the object's primary record and then sending the appropriate
 // follow-on commands as provided by the caller.
 
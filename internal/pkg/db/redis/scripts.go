@@ -87,6 +87,45 @@ const (
 	end
 	return rep
 	`
+	scriptAggregateReadings = `
+	local start = tonumber(ARGV[1])
+	local width = tonumber(ARGV[3])
+	local ids = redis.call('ZRANGEBYSCORE', KEYS[1], ARGV[1], ARGV[2], 'WITHSCORES')
+	local buckets = {}
+	local order = {}
+	for i = 1, #ids, 2 do
+		local id = ids[i]
+		if redis.call('ZSCORE', KEYS[2], id) then
+			local o = redis.call('GET', id)
+			local v = o and tonumber(cjson.decode(o).value)
+			if v ~= nil and v == v and v ~= math.huge and v ~= -math.huge then
+				local created = tonumber(ids[i+1])
+				local s = created - (created - start) % width
+				local b = buckets[s]
+				if b == nil then
+					b = {count = 0, sum = 0, min = v, max = v, first = v}
+					buckets[s] = b
+					table.insert(order, s)
+				end
+				b.count = b.count + 1
+				b.sum = b.sum + v
+				if v < b.min then b.min = v end
+				if v > b.max then b.max = v end
+				b.last = v
+			end
+		end
+	end
+	local rep = {}
+	for _, s in ipairs(order) do
+		local b = buckets[s]
+		table.insert(rep, {
+			string.format('%.0f', s), tostring(b.count), string.format('%.17g', b.sum),
+			string.format('%.17g', b.min), string.format('%.17g', b.max),
+			string.format('%.17g', b.first), string.format('%.17g', b.last),
+		})
+	end
+	return rep
+	`
 	scriptUnlinkZsetMembers = `
 	local magic = 4096
 	local ids = redis.call('ZRANGE', KEYS[1], 0, -1)
@@ -114,6 +153,7 @@ var scripts = map[string]redis.Script{
 	"getObjectsByRange":       *redis.NewScript(1, scriptGetObjectsByRange),
 	"getObjectsByRangeFilter": *redis.NewScript(2, scriptGetObjectsByRangeFilter),
 	"getObjectsByScore":       *redis.NewScript(1, scriptGetObjectsByScore),
+	"aggregateReadings":       *redis.NewScript(2, scriptAggregateReadings),
 	"unlinkZsetMembers":       *redis.NewScript(1, scriptUnlinkZsetMembers),
 	"unlinkCollection":        *redis.NewScript(0, scriptUnlinkCollection),
 }
@@ -0,0 +1,90 @@
+//
+// Copyright (c) 2019 Dell Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"testing"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/command/interfaces"
+	command "github.com/edgexfoundry/edgex-go/internal/core/command/models"
+	dataBase "github.com/edgexfoundry/edgex-go/internal/pkg/db"
+	"github.com/google/uuid"
+)
+
+func TestCommandDB(t *testing.T, db interfaces.DBClient) {
+	testDBCommandAudit(t, db)
+
+	db.CloseSession()
+}
+
+func testDBCommandAudit(t *testing.T, db interfaces.DBClient) {
+	// The device is unique so that the records of previous runs are not matched
+	device := "audit-" + uuid.New().String()
+	now := dataBase.MakeTimestamp()
+	audits := []command.Audit{
+		{Created: now - 3000, Device: device, Command: "setpoint", Method: "PUT", Body: `{"setpoint":"20"}`, StatusCode: 200},
+		{Created: now - 2000, Device: device, Command: "setpoint", Method: "PUT", StatusCode: 423},
+		{Created: now - 1000, Device: device, Command: "temperature", Method: "GET", StatusCode: 200, Response: "21"},
+		{Created: now - 1000, Device: device + "-other", Command: "temperature", Method: "GET", StatusCode: 200},
+	}
+	for i, a := range audits {
+		id, err := db.AddCommandAudit(a)
+		if err != nil {
+			t.Fatalf("Error adding command audit %v: %v", a, err)
+		}
+		if id == "" {
+			t.Fatalf("Expected an ID for the command audit %v", a)
+		}
+		audits[i].Id = id
+	}
+
+	tests := []struct {
+		name     string
+		device   string
+		start    int64
+		end      int64
+		status   int
+		limit    int
+		expected []int
+	}{
+		{"Device", device, 0, now, 0, 0, []int{2, 1, 0}},
+		{"Time range", device, now - 2500, now - 1000, 0, 0, []int{2, 1}},
+		{"Status", device, 0, now, 200, 0, []int{2, 0}},
+		{"Limit", device, 0, now, 0, 2, []int{2, 1}},
+		{"Status and limit", device, 0, now, 200, 1, []int{2}},
+		{"Unknown device", "unknown-" + device, 0, now, 0, 0, []int{}},
+	}
+	for _, tt := range tests {
+		found, err := db.GetCommandAudits(tt.device, tt.start, tt.end, tt.status, tt.limit)
+		if err != nil {
+			t.Fatalf("%s: error getting command audits: %v", tt.name, err)
+		}
+		if len(found) != len(tt.expected) {
+			t.Fatalf("%s: expected %d command audits, found %d", tt.name, len(tt.expected), len(found))
+		}
+		for i, e := range tt.expected {
+			if found[i] != audits[e] {
+				t.Fatalf("%s: expected command audit %v, found %v", tt.name, audits[e], found[i])
+			}
+		}
+	}
+
+	count, err := db.DeleteCommandAuditsOld(1500)
+	if err != nil {
+		t.Fatalf("Error deleting old command audits: %v", err)
+	}
+	if count < 2 {
+		t.Fatalf("Expected at least 2 command audits deleted, deleted %d", count)
+	}
+	found, err := db.GetCommandAudits(device, 0, now, 0, 0)
+	if err != nil {
+		t.Fatalf("Error getting command audits: %v", err)
+	}
+	if len(found) != 1 || found[0].Id != audits[2].Id {
+		t.Fatalf("Expected the recent command audit to be kept, found %v", found)
+	}
+}
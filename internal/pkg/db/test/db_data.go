@@ -11,6 +11,7 @@ import (
 	"math"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/edgexfoundry/edgex-go/internal/core/data/interfaces"
 	correlation "github.com/edgexfoundry/edgex-go/internal/pkg/correlation/models"
@@ -181,6 +182,20 @@ func testDBReadings(t *testing.T, db interfaces.DBClient) {
 	if len(readings) != 1 {
 		t.Fatalf("There should be 1 readings, not %d", len(readings))
 	}
+	readings, err = db.ReadingsByDeviceWithOffset("name1", 10, 1)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByDeviceWithOffset: %v", err)
+	}
+	if len(readings) != 1 {
+		t.Fatalf("There should be 1 readings, not %d", len(readings))
+	}
+	readings, err = db.ReadingsByDeviceWithOffset("name1", 10, 2)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByDeviceWithOffset: %v", err)
+	}
+	if len(readings) != 0 {
+		t.Fatalf("There should be 0 readings, not %d", len(readings))
+	}
 
 	readings, err = db.ReadingsByValueDescriptor("name1", 10)
 	if err != nil {
@@ -210,6 +225,20 @@ func testDBReadings(t *testing.T, db interfaces.DBClient) {
 	if len(readings) != 0 {
 		t.Fatalf("There should be 0 readings, not %d", len(readings))
 	}
+	readings, err = db.ReadingsByValueDescriptorWithOffset("name1", 1, 1)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByValueDescriptorWithOffset: %v", err)
+	}
+	if len(readings) != 1 {
+		t.Fatalf("There should be 1 readings, not %d", len(readings))
+	}
+	readings, err = db.ReadingsByValueDescriptorWithOffset("name1", 10, 2)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByValueDescriptorWithOffset: %v", err)
+	}
+	if len(readings) != 0 {
+		t.Fatalf("There should be 0 readings, not %d", len(readings))
+	}
 
 	readings, err = db.ReadingsByValueDescriptorNames([]string{"name1", "name2"}, 10)
 	if err != nil {
@@ -254,6 +283,32 @@ func testDBReadings(t *testing.T, db interfaces.DBClient) {
 	if len(readings) != 100 {
 		t.Fatalf("There should be 100 readings, not %d", len(readings))
 	}
+	readings, err = db.ReadingsByCreationTimeWithOffset(beforeTime, afterTime, 100, 100)
+	if err != nil {
+		t.Fatalf("Error getting ReadingsByCreationTimeWithOffset: %v", err)
+	}
+	if len(readings) != 10 {
+		t.Fatalf("There should be 10 readings, not %d", len(readings))
+	}
+	seen := make(map[string]bool)
+	for offset := 0; ; offset += 30 {
+		readings, err = db.ReadingsByCreationTimeWithOffset(beforeTime, afterTime, 30, offset)
+		if err != nil {
+			t.Fatalf("Error getting ReadingsByCreationTimeWithOffset: %v", err)
+		}
+		if len(readings) == 0 {
+			break
+		}
+		for _, r := range readings {
+			if seen[r.Id] {
+				t.Fatalf("Reading %s returned in more than one page", r.Id)
+			}
+			seen[r.Id] = true
+		}
+	}
+	if len(seen) != 110 {
+		t.Fatalf("There should be 110 readings across all pages, not %d", len(seen))
+	}
 
 	r := contract.Reading{}
 	r.Id = id
@@ -286,6 +341,76 @@ func testDBReadings(t *testing.T, db interfaces.DBClient) {
 	}
 }
 
+func testDBReadingBuckets(t *testing.T, db interfaces.DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all readings")
+	}
+
+	beforeTime := dbp.MakeTimestamp()
+	for i := 1; i <= 5; i++ {
+		r := contract.Reading{Name: "aggname", Device: "aggdevice", Value: strconv.Itoa(i)}
+		if _, err = db.AddReading(r); err != nil {
+			t.Fatalf("Error adding reading: %v", err)
+		}
+		// Make sure every reading has its own creation time so first and last are well defined
+		time.Sleep(2 * time.Millisecond)
+	}
+	// Readings which are not numeric or which belong to another device are not aggregated
+	ignored := []contract.Reading{
+		{Name: "aggname", Device: "aggdevice", Value: "text"},
+		{Name: "aggname", Device: "aggdevice", Value: "NaN"},
+		{Name: "aggname", Device: "aggdevice", Value: "Infinity"},
+		{Name: "aggname", Device: "aggdevice", Value: "-Infinity"},
+		{Name: "aggname", Device: "aggdevice", Value: "1e400"},
+		{Name: "aggname", Device: "otherdevice", Value: "100"},
+	}
+	for _, r := range ignored {
+		if _, err = db.AddReading(r); err != nil {
+			t.Fatalf("Error adding reading: %v", err)
+		}
+	}
+	afterTime := dbp.MakeTimestamp()
+
+	buckets, err := db.ReadingBuckets("aggdevice", "aggname", beforeTime, afterTime, afterTime-beforeTime+1)
+	if err != nil {
+		t.Fatalf("Error getting ReadingBuckets: %v", err)
+	}
+	if len(buckets) != 1 {
+		t.Fatalf("There should be 1 bucket, not %d", len(buckets))
+	}
+	b := buckets[0]
+	if b.Start != beforeTime {
+		t.Fatalf("Bucket should start at %d, not %d", beforeTime, b.Start)
+	}
+	if b.Count != 5 || b.Sum != 15 || b.Min != 1 || b.Max != 5 || b.First != 1 || b.Last != 5 {
+		t.Fatalf("Unexpected bucket %+v", b)
+	}
+
+	buckets, err = db.ReadingBuckets("aggdevice", "aggname", beforeTime, afterTime, 1)
+	if err != nil {
+		t.Fatalf("Error getting ReadingBuckets: %v", err)
+	}
+	count := 0
+	for i, b := range buckets {
+		if i > 0 && b.Start <= buckets[i-1].Start {
+			t.Fatalf("Buckets should be sorted by start time")
+		}
+		count += b.Count
+	}
+	if count != 5 {
+		t.Fatalf("There should be 5 readings in the buckets, not %d", count)
+	}
+
+	buckets, err = db.ReadingBuckets("aggdevice", "noname", beforeTime, afterTime, 1000)
+	if err != nil {
+		t.Fatalf("Error getting ReadingBuckets: %v", err)
+	}
+	if len(buckets) != 0 {
+		t.Fatalf("There should be 0 buckets, not %d", len(buckets))
+	}
+}
+
 func testDBEvents(t *testing.T, db interfaces.DBClient) {
 	err := db.ScrubAllEvents()
 	if err != nil {
@@ -393,6 +518,20 @@ func testDBEvents(t *testing.T, db interfaces.DBClient) {
 	if len(events) != 0 {
 		t.Fatalf("There should be 0 events, not %d", len(events))
 	}
+	events, err = db.EventsForDeviceWithOffset("name1", 10, 1)
+	if err != nil {
+		t.Fatalf("Error getting EventsForDeviceWithOffset: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("There should be 1 events, not %d", len(events))
+	}
+	events, err = db.EventsForDeviceWithOffset("name1", 10, 2)
+	if err != nil {
+		t.Fatalf("Error getting EventsForDeviceWithOffset: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("There should be 0 events, not %d", len(events))
+	}
 
 	events, err = db.EventsForDevice("name1")
 	if err != nil {
@@ -430,6 +569,57 @@ func testDBEvents(t *testing.T, db interfaces.DBClient) {
 	if len(events) != 100 {
 		t.Fatalf("There should be 100 events, not %d", len(events))
 	}
+	events, err = db.EventsByCreationTimeWithOffset(beforeTime, afterTime, 100, 100)
+	if err != nil {
+		t.Fatalf("Error getting EventsByCreationTimeWithOffset: %v", err)
+	}
+	if len(events) != 10 {
+		t.Fatalf("There should be 10 events, not %d", len(events))
+	}
+	seen := make(map[string]bool)
+	for offset := 0; ; offset += 30 {
+		events, err = db.EventsByCreationTimeWithOffset(beforeTime, afterTime, 30, offset)
+		if err != nil {
+			t.Fatalf("Error getting EventsByCreationTimeWithOffset: %v", err)
+		}
+		if len(events) == 0 {
+			break
+		}
+		for _, e := range events {
+			if seen[e.ID] {
+				t.Fatalf("Event %s returned in more than one page", e.ID)
+			}
+			seen[e.ID] = true
+		}
+	}
+	if len(seen) != 110 {
+		t.Fatalf("There should be 110 events across all pages, not %d", len(seen))
+	}
+	seen = make(map[string]bool)
+	var afterCreated int64
+	var afterId string
+	for {
+		events, err = db.EventsByCreationTimeAfter(beforeTime, afterTime, afterCreated, afterId, 30)
+		if err != nil {
+			t.Fatalf("Error getting EventsByCreationTimeAfter: %v", err)
+		}
+		if len(events) == 0 {
+			break
+		}
+		for _, e := range events {
+			if seen[e.ID] {
+				t.Fatalf("Event %s returned in more than one page", e.ID)
+			}
+			if afterId != "" && (e.Created < afterCreated || (e.Created == afterCreated && e.ID <= afterId)) {
+				t.Fatalf("Event %s returned out of creation time and id order", e.ID)
+			}
+			seen[e.ID] = true
+			afterCreated, afterId = e.Created, e.ID
+		}
+	}
+	if len(seen) != 110 {
+		t.Fatalf("There should be 110 events across all pages, not %d", len(seen))
+	}
 
 	events, err = db.EventsOlderThanAge(0)
 	if err != nil {
@@ -498,6 +688,61 @@ func testDBEvents(t *testing.T, db interfaces.DBClient) {
 	}
 }
 
+func testDBAddEvents(t *testing.T, db interfaces.DBClient) {
+	err := db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events: %v\n", err)
+	}
+
+	ids, err := db.AddEvents(nil)
+	if err != nil {
+		t.Fatalf("Error adding an empty batch of events: %v", err)
+	}
+	if len(ids) != 0 {
+		t.Fatalf("There should be 0 ids instead of %d", len(ids))
+	}
+
+	batch := make([]correlation.Event, 3)
+	for i := range batch {
+		name := fmt.Sprintf("batch%d", i)
+		batch[i].Device = name
+		batch[i].Readings = []contract.Reading{{Name: name, Value: "1"}, {Name: name, Value: "2"}}
+	}
+	ids, err = db.AddEvents(batch)
+	if err != nil {
+		t.Fatalf("Error adding a batch of events: %v", err)
+	}
+	if len(ids) != len(batch) {
+		t.Fatalf("There should be %d ids instead of %d", len(batch), len(ids))
+	}
+
+	for i, id := range ids {
+		e, err := db.EventById(id)
+		if err != nil {
+			t.Fatalf("Error getting event %s of the batch: %v", id, err)
+		}
+		if e.Device != batch[i].Device {
+			t.Fatalf("Event %d of the batch should be for device %s instead of %s", i, batch[i].Device, e.Device)
+		}
+		if len(e.Readings) != 2 {
+			t.Fatalf("Event %d of the batch should have 2 readings instead of %d", i, len(e.Readings))
+		}
+	}
+
+	count, err := db.ReadingCount()
+	if err != nil {
+		t.Fatalf("Error getting readings count: %v", err)
+	}
+	if count != 6 {
+		t.Fatalf("There should be 6 readings instead of %d", count)
+	}
+
+	err = db.ScrubAllEvents()
+	if err != nil {
+		t.Fatalf("Error removing all events")
+	}
+}
+
 func testDBValueDescriptors(t *testing.T, db interfaces.DBClient) {
 	err := db.ScrubAllValueDescriptors()
 	if err != nil {
@@ -659,7 +904,9 @@ func testDBValueDescriptors(t *testing.T, db interfaces.DBClient) {
 
 func TestDataDB(t *testing.T, db interfaces.DBClient) {
 	testDBReadings(t, db)
+	testDBReadingBuckets(t, db)
 	testDBEvents(t, db)
+	testDBAddEvents(t, db)
 	testDBValueDescriptors(t, db)
 
 	db.CloseSession()
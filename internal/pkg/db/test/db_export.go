@@ -26,8 +26,13 @@ func TestExportDB(t *testing.T, db export.DBClient) {
 		t.Fatalf("Error: expected 0 registrations; found %d", len(regs))
 	}
 
-	r := models.Registration{}
+	r := export.Registration{}
 	r.Name = "name"
+	r.Addressable = models.Addressable{Name: "addressable", Topic: "events"}
+	r.Format = export.FormatProtobuf
+	r.Destination = export.DestKafka
+	r.Encryption = models.EncryptionDetails{Algo: export.EncAesGcm, Key: "export/name"}
+	r.Template = "{{json .Device}}"
 	id, err := db.AddRegistration(r)
 	if err != nil {
 		t.Fatalf("Error adding registration %v: %v", r, err)
@@ -47,6 +52,10 @@ func TestExportDB(t *testing.T, db export.DBClient) {
 	if r2.ID != id {
 		t.Fatalf("Id does not match %s - %s", r2.ID, id)
 	}
+	if r2.Format != r.Format || r2.Destination != r.Destination || r2.Encryption != r.Encryption ||
+		r2.Template != r.Template {
+		t.Fatalf("Registration values do not match %v - %v", r2, r)
+	}
 	_, err = db.RegistrationById("INVALID")
 	if err == nil {
 		t.Fatalf("Registration should not be found")
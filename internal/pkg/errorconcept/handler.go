@@ -69,3 +69,14 @@ func (e *handler) HandleManyVariants(w http.ResponseWriter, err error, allowable
 	}
 	e.Handle(w, err, defaultError)
 }
+
+// StatusCode returns the HTTP status code of the first of the allowable errors matching the error, or of the default
+// error when none of them is matched. It is used when an error is reported within the body of a response.
+func StatusCode(err error, allowableErrors []ErrorConceptType, defaultError ErrorConceptType) int {
+	for key := range allowableErrors {
+		if allowableErrors[key].isA(err) {
+			return allowableErrors[key].httpErrorCode()
+		}
+	}
+	return defaultError.httpErrorCode()
+}
@@ -0,0 +1,43 @@
+/*******************************************************************************
+ * Copyright 2019 Dell Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software distributed under the License
+ * is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express
+ * or implied. See the License for the specific language governing permissions and limitations under
+ * the License.
+ *******************************************************************************/
+
+package errorconcept
+
+import (
+	"net/http"
+
+	"github.com/edgexfoundry/edgex-go/internal/core/data/errors"
+)
+
+var Readings readingErrorConcept
+
+// readingErrorConcept represents the accessor for the reading-specific error concepts
+type readingErrorConcept struct {
+	InvalidAggregation readingInvalidAggregation
+}
+
+type readingInvalidAggregation struct{}
+
+func (r readingInvalidAggregation) httpErrorCode() int {
+	return http.StatusBadRequest
+}
+
+func (r readingInvalidAggregation) isA(err error) bool {
+	_, ok := err.(errors.ErrInvalidAggregation)
+	return ok
+}
+
+func (r readingInvalidAggregation) message(err error) string {
+	return err.Error()
+}